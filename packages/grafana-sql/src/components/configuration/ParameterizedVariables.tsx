@@ -0,0 +1,55 @@
+import React from 'react';
+
+import { DataSourceSettings } from '@grafana/data';
+import { ConfigSubSection, Stack } from '@grafana/experimental';
+import { Field, Icon, Label, Switch, Tooltip } from '@grafana/ui';
+
+import { SQLOptions } from '../../types';
+
+interface Props {
+  onOptionsChange: Function;
+  options: DataSourceSettings<SQLOptions>;
+}
+
+export const ParameterizedVariables = (props: Props) => {
+  const { onOptionsChange, options } = props;
+  const jsonData = options.jsonData;
+
+  const onParameterizedVariablesChanged = () => {
+    onOptionsChange({
+      ...options,
+      jsonData: {
+        ...jsonData,
+        parameterizedVariables: !jsonData.parameterizedVariables,
+      },
+    });
+  };
+
+  return (
+    <ConfigSubSection title="Template variables">
+      <Field
+        label={
+          <Label>
+            <Stack gap={0.5}>
+              <span>Bind variables as query parameters</span>
+              <Tooltip
+                content={
+                  <span>
+                    If enabled, the values of the template variables are sent with the query and bound as query
+                    parameters instead of being interpolated into the SQL, so that a value can never change the
+                    structure of the query. Queries that reference variables that are not bound are rejected. Variables
+                    can&apos;t be used in the arguments of macros, or as table and column names.
+                  </span>
+                }
+              >
+                <Icon name="info-circle" size="sm" />
+              </Tooltip>
+            </Stack>
+          </Label>
+        }
+      >
+        <Switch value={jsonData.parameterizedVariables || false} onChange={onParameterizedVariablesChanged} />
+      </Field>
+    </ConfigSubSection>
+  );
+};
@@ -32,7 +32,7 @@ import {
 import { ResponseParser } from '../ResponseParser';
 import { SqlQueryEditor } from '../components/QueryEditor';
 import { MACRO_NAMES } from '../constants';
-import { DB, SQLQuery, SQLOptions, SqlQueryModel, QueryFormat } from '../types';
+import { DB, SQLQuery, SQLOptions, SqlQueryModel, QueryFormat, SQLQueryVariable } from '../types';
 import migrateAnnotation from '../utils/migration';
 
 import { isSqlDatasourceDatabaseSelectionFeatureFlagEnabled } from './../components/QueryEditorFeatureFlag.utils';
@@ -44,6 +44,7 @@ export abstract class SqlDatasource extends DataSourceWithBackend<SQLQuery, SQLO
   interval: string;
   db: DB;
   preconfiguredDatabase: string;
+  parameterizedVariables: boolean;
 
   constructor(
     instanceSettings: DataSourceInstanceSettings<SQLOptions>,
@@ -61,6 +62,7 @@ export abstract class SqlDatasource extends DataSourceWithBackend<SQLQuery, SQLO
       1) the ConfigurationEditor.tsx, OR 2) the provisioning config file, either under `jsondata.database`, or simply `database`.
     */
     this.preconfiguredDatabase = settingsData.database ?? '';
+    this.parameterizedVariables = settingsData.parameterizedVariables ?? false;
     this.annotations = {
       prepareAnnotation: migrateAnnotation,
       QueryEditor: SqlQueryEditor,
@@ -116,7 +118,16 @@ export abstract class SqlDatasource extends DataSourceWithBackend<SQLQuery, SQLO
     return !query.hide;
   }
 
-  applyTemplateVariables(target: SQLQuery, scopedVars: ScopedVars) {
+  applyTemplateVariables(target: SQLQuery, scopedVars: ScopedVars): SQLQuery {
+    if (this.parameterizedVariables) {
+      return {
+        refId: target.refId,
+        datasource: this.getRef(),
+        rawSql: target.rawSql,
+        format: target.format,
+        variables: this.getQueryVariables(target.rawSql, scopedVars),
+      };
+    }
     return {
       refId: target.refId,
       datasource: this.getRef(),
@@ -125,6 +136,31 @@ export abstract class SqlDatasource extends DataSourceWithBackend<SQLQuery, SQLO
     };
   }
 
+  /**
+   * Returns the values of the template variables referenced in the SQL, which the backend binds as query parameters
+   * instead of them being interpolated into the SQL. Macros are left to the backend.
+   */
+  getQueryVariables(rawSql: string | undefined, scopedVars: ScopedVars): SQLQueryVariable[] {
+    const variables: SQLQueryVariable[] = [];
+    const names = new Set<string>();
+    for (const match of (rawSql ?? '').matchAll(/\$\{(\w+)\}|\$(\w+)/g)) {
+      const name = match[1] ?? match[2];
+      if (names.has(name) || name.startsWith('__') || !this.templateSrv.containsTemplate(`$${name}`)) {
+        continue;
+      }
+      names.add(name);
+
+      let values: Array<string | number> = [];
+      this.templateSrv.replace(`$${name}`, scopedVars, (value: string | number | Array<string | number>) => {
+        values = Array.isArray(value) ? value : [value];
+        return '';
+      });
+      const type = values.length > 0 && values.every((v) => typeof v === 'number') ? 'number' : 'string';
+      variables.push({ name, type, values: values.map(String) });
+    }
+    return variables;
+  }
+
   query(request: DataQueryRequest<SQLQuery>): Observable<DataQueryResponse> {
     // This logic reenables the previous SQL behavior regarding what databases are available for the user to query.
     if (isSqlDatasourceDatabaseSelectionFeatureFlagEnabled()) {
@@ -199,14 +235,20 @@ export abstract class SqlDatasource extends DataSourceWithBackend<SQLQuery, SQLO
       ...getSearchFilterScopedVar({ query, wildcardChar: '%', options }),
     };
 
-    const rawSql = this.templateSrv.replace(query, scopedVars, this.interpolateVariable);
-
-    const interpolatedQuery: SQLQuery = {
-      refId: refId,
-      datasource: this.getRef(),
-      rawSql,
-      format: QueryFormat.Table,
-    };
+    const interpolatedQuery: SQLQuery = this.parameterizedVariables
+      ? {
+          refId: refId,
+          datasource: this.getRef(),
+          rawSql: query,
+          format: QueryFormat.Table,
+          variables: this.getQueryVariables(query, scopedVars),
+        }
+      : {
+          refId: refId,
+          datasource: this.getRef(),
+          rawSql: this.templateSrv.replace(query, scopedVars, this.interpolateVariable),
+          format: QueryFormat.Table,
+        };
 
     // NOTE: we can remove this try-catch when https://github.com/grafana/grafana/issues/82250
     // is fixed.
@@ -4,6 +4,7 @@ export type {
   SQLExpression,
   SQLOptions,
   SQLQuery,
+  SQLQueryVariable,
   SqlQueryModel,
   SQLSelectableValue,
 } from './types';
@@ -12,6 +13,7 @@ export { SqlDatasource } from './datasource/SqlDatasource';
 export { formatSQL } from './utils/formatSQL';
 export { ConnectionLimits } from './components/configuration/ConnectionLimits';
 export { Divider } from './components/configuration/Divider';
+export { ParameterizedVariables } from './components/configuration/ParameterizedVariables';
 export { TLSSecretsConfig } from './components/configuration/TLSSecretsConfig';
 export { useMigrateDatabaseFields } from './components/configuration/useMigrateDatabaseFields';
 export { SqlQueryEditor } from './components/QueryEditor';
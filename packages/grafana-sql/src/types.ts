@@ -43,6 +43,7 @@ export interface SQLOptions extends SQLConnectionLimits, DataSourceJsonData {
   database: string;
   url: string;
   timeInterval: string;
+  parameterizedVariables?: boolean;
 }
 
 export enum QueryFormat {
@@ -59,6 +60,16 @@ export interface SQLQuery extends DataQuery {
   sql?: SQLExpression;
   editorMode?: EditorMode;
   rawQuery?: boolean;
+  variables?: SQLQueryVariable[];
+}
+
+/**
+ * A template variable that is bound as a query parameter instead of being interpolated into the SQL.
+ */
+export interface SQLQueryVariable {
+  name: string;
+  type?: 'string' | 'number' | 'boolean' | 'time';
+  values: string[];
 }
 
 export interface NameValue {
@@ -82,6 +82,13 @@ func runPluginCommand(command func(commandLine utils.CommandLine) error) func(co
 	}
 }
 
+// runCommand runs a command that needs neither the configuration nor the services of Grafana.
+func runCommand(command func(commandLine utils.CommandLine) error) func(context *cli.Context) error {
+	return func(context *cli.Context) error {
+		return command(&utils.ContextCommandLine{Context: context})
+	}
+}
+
 var pluginCommands = []*cli.Command{
 	{
 		Name:   "install",
@@ -167,6 +174,44 @@ var adminCommands = []*cli.Command{
 			},
 		},
 	},
+	{
+		Name:  "alerting",
+		Usage: "Runs alerting commands",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "convert-prometheus-rules",
+				Usage:  "convert-prometheus-rules <Prometheus rule file> <provisioning file>. Converts Prometheus rules to a provisioning file of alert rules",
+				Action: runCommand(convertPrometheusRulesCommand),
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "datasource-uid",
+						Usage: "The UID of the Prometheus data source that the queries of the rules use",
+					},
+					&cli.StringFlag{
+						Name:  "folder",
+						Usage: "The title of the folder of the rules",
+					},
+					&cli.IntFlag{
+						Name:  "org-id",
+						Usage: "The ID of the organization of the rules",
+						Value: 1,
+					},
+				},
+			},
+			{
+				Name:   "test-rules",
+				Usage:  "test-rules <test file>... Runs the unit tests of alert rules against the input series of the test files",
+				Action: runCommand(testRulesCommand),
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "app-url",
+						Usage: "The URL of Grafana used in the labels and annotations of the alerts",
+						Value: "http://localhost:3000/",
+					},
+				},
+			},
+		},
+	},
 	{
 		Name:  "user-manager",
 		Usage: "Runs different helpful user commands",
@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/services/ngalert/api"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/prom"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// convertPrometheusRulesCommand converts a Prometheus rule file to an alerting provisioning file. The rules are
+// validated like the rules imported with the API. Provisioning files cannot have recording rules, so the recording
+// rules are left out of the file with a warning.
+func convertPrometheusRulesCommand(c utils.CommandLine) error {
+	if c.Args().Len() != 2 {
+		return errors.New("usage: convert-prometheus-rules <Prometheus rule file> <provisioning file>")
+	}
+	datasourceUID := c.String("datasource-uid")
+	if datasourceUID == "" {
+		return errors.New("--datasource-uid must be specified")
+	}
+	folder := c.String("folder")
+	if folder == "" {
+		return errors.New("--folder must be specified")
+	}
+	orgID := int64(c.Int("org-id"))
+
+	b, err := os.ReadFile(c.Args().Get(0))
+	if err != nil {
+		return fmt.Errorf("failed to read the rule file: %w", err)
+	}
+	var file apimodels.PrometheusRuleFile
+	if err := yaml.Unmarshal(b, &file); err != nil {
+		return fmt.Errorf("failed to parse the rule file: %w", err)
+	}
+
+	limits := api.RuleLimits{
+		DefaultRuleEvaluationInterval: setting.DefaultRuleEvaluationInterval,
+		BaseInterval:                  setting.SchedulerBaseInterval,
+		RecordingRulesAllowed:         false,
+	}
+	groups, ruleErrors, err := api.ConvertPrometheusRuleGroups(file.Groups, prom.Config{DatasourceUID: datasourceUID}, orgID, "", limits)
+	if err != nil {
+		return err
+	}
+	for _, e := range ruleErrors {
+		if isRecordingRule(file.Groups, e.Group, e.Index) {
+			e.Error = "recording rules cannot be written to provisioning files"
+		}
+		logger.Warnf("%s rule %d (%s) of group %s was not converted: %s\n", color.YellowString("!"), e.Index, e.Rule, e.Group, e.Error)
+	}
+
+	converted := make([]ngmodels.AlertRuleGroupWithFolderTitle, 0, len(groups))
+	// titles must be unique in the folder, so a group with the title of a rule of a previous group is left out.
+	titles := make(map[string]string)
+	for _, group := range groups {
+		if group.Err == nil {
+			for _, rule := range group.Rules {
+				if other, ok := titles[rule.Title]; ok {
+					group.Err = fmt.Errorf("rule %q has the same title as a rule of the group %s", rule.Title, other)
+					break
+				}
+			}
+		}
+		if group.Err != nil {
+			logger.Warnf("%s group %s was not converted: %s\n", color.YellowString("!"), group.Name, group.Err)
+			continue
+		}
+		rules := make([]ngmodels.AlertRule, 0, len(group.Rules))
+		for _, rule := range group.Rules {
+			titles[rule.Title] = group.Name
+			rule.UID = convertedRuleUID(folder, group.Name, rule.Title)
+			rules = append(rules, rule.AlertRule)
+		}
+		groupKey := ngmodels.AlertRuleGroupKey{OrgID: orgID, RuleGroup: group.Name}
+		converted = append(converted, ngmodels.NewAlertRuleGroupWithFolderTitle(groupKey, rules, folder))
+	}
+	if len(converted) == 0 {
+		return errors.New("none of the rule groups could be converted")
+	}
+
+	export, err := api.AlertingFileExportFromAlertRuleGroupWithFolderTitle(converted)
+	if err != nil {
+		return fmt.Errorf("failed to create the provisioning file: %w", err)
+	}
+	out, err := yaml.Marshal(export)
+	if err != nil {
+		return fmt.Errorf("failed to create the provisioning file: %w", err)
+	}
+	// nolint:gosec
+	if err := os.WriteFile(c.Args().Get(1), out, 0644); err != nil {
+		return fmt.Errorf("failed to write the provisioning file: %w", err)
+	}
+
+	logger.Infof("%s %d of %d rule groups converted\n", color.GreenString("✔"), len(converted), len(groups))
+	return nil
+}
+
+// isRecordingRule returns true if the rule at the index of the first group with the name is a recording rule.
+func isRecordingRule(groups []apimodels.PrometheusRuleGroup, group string, index int) bool {
+	for _, g := range groups {
+		if g.Name == group {
+			return index >= 0 && index < len(g.Rules) && g.Rules[index].Record != ""
+		}
+	}
+	return false
+}
+
+// convertedRuleUID returns the same UID every time a rule is converted, so that provisioning the converted file again updates the rules.
+func convertedRuleUID(folder, group, title string) string {
+	sum := sha256.Sum256([]byte(folder + "\x00" + group + "\x00" + title))
+	return fmt.Sprintf("%x", sum[:10])
+}
@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/ngalert/api"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/backtesting"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// testRulesCommand runs the unit tests of alert rule test files. The rules are evaluated against the input series of the
+// files, so neither a Grafana server nor the data sources of the rules are needed.
+func testRulesCommand(c utils.CommandLine) error {
+	if c.Args().Len() == 0 {
+		return errors.New("usage: test-rules <test file>...")
+	}
+	appURL, err := url.Parse(c.String("app-url"))
+	if err != nil {
+		return fmt.Errorf("invalid --app-url: %w", err)
+	}
+
+	tracer, err := tracing.ProvideService(setting.NewCfg())
+	if err != nil {
+		return err
+	}
+	engine := backtesting.NewEngine(appURL, nil, tracer)
+	exprService := expr.ProvideService(&setting.Cfg{ExpressionsEnabled: true}, nil, nil, featuremgmt.WithFeatures(), nil, tracer)
+	cfg := setting.UnifiedAlertingSettings{EvaluationTimeout: 30 * time.Second}
+
+	failed := 0
+	for _, path := range c.Args().Slice() {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read the test file %s: %w", path, err)
+		}
+		var tests apimodels.AlertRuleUnitTests
+		if err := yaml.Unmarshal(b, &tests); err != nil {
+			return fmt.Errorf("failed to parse the test file %s: %w", path, err)
+		}
+
+		results, err := api.RunAlertRuleUnitTests(context.Background(), engine, exprService, cfg, &user.SignedInUser{OrgID: 1}, tests)
+		if err != nil {
+			return fmt.Errorf("failed to run the tests of %s: %w", path, err)
+		}
+		for _, result := range results.Tests {
+			if len(result.Failures) == 0 {
+				logger.Infof("%s %s: %s\n", color.GreenString("✔"), path, result.Name)
+				continue
+			}
+			failed++
+			logger.Errorf("%s %s: %s\n", color.RedString("✗"), path, result.Name)
+			for _, failure := range result.Failures {
+				expected, _ := yaml.Marshal(failure.Expected)
+				got, _ := yaml.Marshal(failure.Got)
+				logger.Errorf("    at %s\n    expected:\n%s    got:\n%s", failure.EvalTime, indentLines(string(expected)), indentLines(string(got)))
+			}
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d tests failed", failed)
+	}
+	return nil
+}
+
+// indentLines indents the lines of s under the failure of a test.
+func indentLines(s string) string {
+	return "      " + strings.ReplaceAll(strings.TrimSuffix(s, "\n"), "\n", "\n      ") + "\n"
+}
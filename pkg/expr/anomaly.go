@@ -0,0 +1,395 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+// +enum
+type AnomalyAlgorithm string
+
+const (
+	// AnomalyAlgorithmMAD uses the median of the series as the baseline and the median absolute deviation as the width of the bands.
+	AnomalyAlgorithmMAD AnomalyAlgorithm = "mad"
+	// AnomalyAlgorithmHoltWinters uses the forecast of additive Holt-Winters (triple exponential smoothing) as the baseline
+	// and the standard deviation of the forecast errors as the width of the bands.
+	AnomalyAlgorithmHoltWinters AnomalyAlgorithm = "holt_winters"
+)
+
+// +enum
+type AnomalyOutput string
+
+const (
+	// AnomalyOutputAnomalous returns a number per series that is 1 if the last point of the series is outside the bands, and 0 otherwise.
+	AnomalyOutputAnomalous AnomalyOutput = "anomalous"
+	// AnomalyOutputBounds returns the baseline, lower and upper bound series for each series.
+	AnomalyOutputBounds AnomalyOutput = "bounds"
+)
+
+const (
+	// anomalyBoundLabel is the label that identifies the series returned by AnomalyOutputBounds.
+	anomalyBoundLabel = "bound"
+
+	defaultAnomalyDeviations = 3
+	defaultAnomalyAlpha      = 0.5
+	defaultAnomalyBeta       = 0.1
+	defaultAnomalyGamma      = 0.3
+)
+
+var (
+	supportedAnomalyAlgorithms = []string{
+		string(AnomalyAlgorithmMAD),
+		string(AnomalyAlgorithmHoltWinters),
+	}
+	supportedAnomalyOutputs = []string{
+		string(AnomalyOutputAnomalous),
+		string(AnomalyOutputBounds),
+	}
+)
+
+// AnomalyCommand is an expression that detects anomalies in time series. Unlike the outlier command of
+// Machine Learning, it runs locally and does not depend on an external service.
+type AnomalyCommand struct {
+	RefID        string
+	ReferenceVar string
+	Algorithm    AnomalyAlgorithm
+	// Deviations is the width of the bands as the number of deviations from the baseline.
+	Deviations float64
+	// Seasonality is the length of the season of Holt-Winters. Zero means no seasonality.
+	Seasonality time.Duration
+	Alpha       float64
+	Beta        float64
+	Gamma       float64
+	Output      AnomalyOutput
+}
+
+// NewAnomalyCommand creates a new AnomalyCommand with the default settings of the algorithm.
+func NewAnomalyCommand(refID, referenceVar string, algorithm AnomalyAlgorithm, output AnomalyOutput) (*AnomalyCommand, error) {
+	switch algorithm {
+	case AnomalyAlgorithmMAD, AnomalyAlgorithmHoltWinters:
+	default:
+		return nil, fmt.Errorf("expected anomaly algorithm to be one of [%s], got %s", strings.Join(supportedAnomalyAlgorithms, ", "), algorithm)
+	}
+	switch output {
+	case "":
+		output = AnomalyOutputAnomalous
+	case AnomalyOutputAnomalous, AnomalyOutputBounds:
+	default:
+		return nil, fmt.Errorf("expected anomaly output to be one of [%s], got %s", strings.Join(supportedAnomalyOutputs, ", "), output)
+	}
+	return &AnomalyCommand{
+		RefID:        refID,
+		ReferenceVar: referenceVar,
+		Algorithm:    algorithm,
+		Deviations:   defaultAnomalyDeviations,
+		Alpha:        defaultAnomalyAlpha,
+		Beta:         defaultAnomalyBeta,
+		Gamma:        defaultAnomalyGamma,
+		Output:       output,
+	}, nil
+}
+
+// newAnomalyCommandFromQuery creates a new AnomalyCommand from the properties of the query.
+func newAnomalyCommandFromQuery(refID, referenceVar string, q *AnomalyQuery) (*AnomalyCommand, error) {
+	cmd, err := NewAnomalyCommand(refID, referenceVar, q.Algorithm, q.Output)
+	if err != nil {
+		return nil, err
+	}
+	if q.Deviations != nil {
+		if *q.Deviations <= 0 {
+			return nil, fmt.Errorf("anomaly deviations must be greater than 0, got %v", *q.Deviations)
+		}
+		cmd.Deviations = *q.Deviations
+	}
+	if q.Seasonality != "" {
+		if cmd.Algorithm != AnomalyAlgorithmHoltWinters {
+			return nil, fmt.Errorf("seasonality is only supported by the %s algorithm", AnomalyAlgorithmHoltWinters)
+		}
+		cmd.Seasonality, err = gtime.ParseDuration(q.Seasonality)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse anomaly seasonality: %w", err)
+		}
+		if cmd.Seasonality <= 0 {
+			return nil, fmt.Errorf("anomaly seasonality must be greater than 0, got %s", q.Seasonality)
+		}
+	}
+	for _, p := range []struct {
+		name  string
+		value *float64
+		dst   *float64
+	}{{"alpha", q.Alpha, &cmd.Alpha}, {"beta", q.Beta, &cmd.Beta}, {"gamma", q.Gamma, &cmd.Gamma}} {
+		if p.value == nil {
+			continue
+		}
+		if *p.value < 0 || *p.value > 1 {
+			return nil, fmt.Errorf("anomaly smoothing factor %s must be between 0 and 1, got %v", p.name, *p.value)
+		}
+		*p.dst = *p.value
+	}
+	return cmd, nil
+}
+
+// UnmarshalAnomalyCommand creates an AnomalyCommand from Grafana's frontend query.
+func UnmarshalAnomalyCommand(rn *rawNode) (*AnomalyCommand, error) {
+	q := AnomalyQuery{}
+	if err := json.Unmarshal(rn.QueryRaw, &q); err != nil {
+		return nil, fmt.Errorf("failed to parse the anomaly command: %w", err)
+	}
+	referenceVar, err := getReferenceVar(q.Expression, rn.RefID)
+	if err != nil {
+		return nil, err
+	}
+	return newAnomalyCommandFromQuery(rn.RefID, referenceVar, &q)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (ac *AnomalyCommand) NeedsVars() []string {
+	return []string{ac.ReferenceVar}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (ac *AnomalyCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
+	_, span := tracer.Start(ctx, "SSE.ExecuteAnomaly")
+	defer span.End()
+
+	newRes := mathexp.Results{}
+	for _, val := range vars[ac.ReferenceVar].Values {
+		switch v := val.(type) {
+		case mathexp.Series:
+			b, err := ac.bands(v)
+			if err != nil {
+				return newRes, err
+			}
+			if ac.Output == AnomalyOutputBounds {
+				newRes.Values = append(newRes.Values, b.series(ac.RefID, v.GetLabels())...)
+				continue
+			}
+			n := mathexp.NewNumber(ac.RefID, v.GetLabels())
+			n.SetValue(b.anomalous())
+			newRes.Values = append(newRes.Values, n)
+		case mathexp.NoData:
+			newRes.Values = append(newRes.Values, mathexp.NewNoData())
+		default:
+			return newRes, fmt.Errorf("anomaly detection requires time series data, got %s", val.Type())
+		}
+	}
+	return newRes, nil
+}
+
+func (ac *AnomalyCommand) Type() string {
+	return TypeAnomaly.String()
+}
+
+// anomalyBands holds the points of a series without null and NaN values, the expected value of each point,
+// and the width of the bands around the expected values.
+type anomalyBands struct {
+	times    []time.Time
+	values   []float64
+	baseline []float64
+	width    float64
+	// ok is false if the series does not have enough points to calculate the bands.
+	ok bool
+}
+
+// bands calculates the bands of the series using the algorithm of the command. The last point of the series
+// is not used to calculate the width of the bands, so it can be checked against them.
+func (ac *AnomalyCommand) bands(s mathexp.Series) (anomalyBands, error) {
+	b := anomalyBands{}
+	for i := 0; i < s.Len(); i++ {
+		t, f := s.GetPoint(i)
+		if f == nil || math.IsNaN(*f) || math.IsInf(*f, 0) {
+			continue
+		}
+		b.times = append(b.times, t)
+		b.values = append(b.values, *f)
+	}
+	sort.Sort(&b)
+
+	switch ac.Algorithm {
+	case AnomalyAlgorithmHoltWinters:
+		season, err := seasonLength(b.times, ac.Seasonality)
+		if err != nil {
+			return b, err
+		}
+		b.holtWinters(season, ac.Alpha, ac.Beta, ac.Gamma, ac.Deviations)
+	default:
+		b.mad(ac.Deviations)
+	}
+	return b, nil
+}
+
+// mad uses the median of the values as the baseline, and the median absolute deviation as the width of the bands.
+func (b *anomalyBands) mad(deviations float64) {
+	// at least two points are needed for the deviation, plus the point to check.
+	if len(b.values) < 3 {
+		return
+	}
+	history := b.values[:len(b.values)-1]
+	median := medianOf(history)
+	absDeviations := make([]float64, 0, len(history))
+	for _, v := range history {
+		absDeviations = append(absDeviations, math.Abs(v-median))
+	}
+	// 1.4826 scales the median absolute deviation to the standard deviation of normally distributed data.
+	b.width = deviations * 1.4826 * medianOf(absDeviations)
+	b.baseline = make([]float64, len(b.values))
+	for i := range b.baseline {
+		b.baseline[i] = median
+	}
+	b.ok = true
+}
+
+// holtWinters uses the one-step-ahead forecast of additive Holt-Winters as the baseline, and the standard deviation
+// of the forecast errors as the width of the bands. If season is 0, the seasonal component is not used (Holt's linear trend).
+func (b *anomalyBands) holtWinters(season int, alpha, beta, gamma, deviations float64) {
+	n := len(b.values)
+	// The first season initializes the model. At least one forecast error is needed, plus the point to check.
+	start := season
+	if season == 0 {
+		start = 1
+	}
+	if n < start+2 || (season > 0 && n < 2*season+1) {
+		return
+	}
+
+	var level, trend float64
+	seasonal := make([]float64, n)
+	b.baseline = make([]float64, n)
+	if season == 0 {
+		level = b.values[0]
+		trend = b.values[1] - b.values[0]
+		b.baseline[0] = b.values[0]
+	} else {
+		first := meanOf(b.values[:season])
+		second := meanOf(b.values[season : 2*season])
+		level = first
+		trend = (second - first) / float64(season)
+		for i := 0; i < season; i++ {
+			seasonal[i] = b.values[i] - first
+			b.baseline[i] = b.values[i]
+		}
+	}
+
+	var sumSquares float64
+	for t := start; t < n; t++ {
+		var s float64
+		if season > 0 {
+			s = seasonal[t-season]
+		}
+		b.baseline[t] = level + trend + s
+		if t < n-1 {
+			e := b.values[t] - b.baseline[t]
+			sumSquares += e * e
+		}
+		prevLevel := level
+		level = alpha*(b.values[t]-s) + (1-alpha)*(level+trend)
+		trend = beta*(level-prevLevel) + (1-beta)*trend
+		if season > 0 {
+			seasonal[t] = gamma*(b.values[t]-level) + (1-gamma)*s
+		}
+	}
+	b.width = deviations * math.Sqrt(sumSquares/float64(n-1-start))
+	b.ok = true
+}
+
+// anomalous returns 1 if the last point is outside the bands, and 0 otherwise.
+// Returns nil if there are not enough points to calculate the bands.
+func (b *anomalyBands) anomalous() *float64 {
+	if !b.ok {
+		return nil
+	}
+	last := len(b.values) - 1
+	f := 0.0
+	if math.Abs(b.values[last]-b.baseline[last]) > b.width {
+		f = 1
+	}
+	return &f
+}
+
+// series returns the baseline, lower and upper bound series. The series have the labels of the input series
+// and the label "bound". If there are not enough points to calculate the bands, the series are empty.
+func (b *anomalyBands) series(refID string, labels data.Labels) []mathexp.Value {
+	bounds := []struct {
+		name   string
+		offset float64
+	}{{"baseline", 0}, {"lower", -b.width}, {"upper", b.width}}
+
+	result := make([]mathexp.Value, 0, len(bounds))
+	for _, bound := range bounds {
+		l := labels.Copy()
+		l[anomalyBoundLabel] = bound.name
+		size := 0
+		if b.ok {
+			size = len(b.times)
+		}
+		s := mathexp.NewSeries(refID, l, size)
+		for i := 0; i < size; i++ {
+			f := b.baseline[i] + bound.offset
+			s.SetPoint(i, b.times[i], &f)
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+// Len, Less and Swap sort the points of the bands by time.
+func (b *anomalyBands) Len() int { return len(b.times) }
+
+func (b *anomalyBands) Less(i, j int) bool { return b.times[i].Before(b.times[j]) }
+
+func (b *anomalyBands) Swap(i, j int) {
+	b.times[i], b.times[j] = b.times[j], b.times[i]
+	b.values[i], b.values[j] = b.values[j], b.values[i]
+}
+
+// seasonLength returns the number of points in a season, based on the median interval between the points.
+func seasonLength(times []time.Time, seasonality time.Duration) (int, error) {
+	if seasonality == 0 || len(times) < 2 {
+		return 0, nil
+	}
+	intervals := make([]float64, 0, len(times)-1)
+	for i := 1; i < len(times); i++ {
+		intervals = append(intervals, float64(times[i].Sub(times[i-1])))
+	}
+	interval := medianOf(intervals)
+	if interval <= 0 {
+		return 0, nil
+	}
+	season := int(math.Round(float64(seasonality) / interval))
+	if season < 2 {
+		return 0, fmt.Errorf("anomaly seasonality %s must be at least twice the interval of the series (%s)", seasonality, time.Duration(interval))
+	}
+	return season, nil
+}
+
+func medianOf(values []float64) float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+func meanOf(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
@@ -0,0 +1,231 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+func TestUnmarshalAnomalyCommand(t *testing.T) {
+	cases := []struct {
+		description   string
+		query         string
+		expectedError string
+		assert        func(*testing.T, *AnomalyCommand)
+	}{
+		{
+			description: "unmarshal with defaults",
+			query:       `{"expression": "$A", "type": "anomaly", "algorithm": "mad"}`,
+			assert: func(t *testing.T, cmd *AnomalyCommand) {
+				require.Equal(t, []string{"A"}, cmd.NeedsVars())
+				require.Equal(t, AnomalyAlgorithmMAD, cmd.Algorithm)
+				require.Equal(t, AnomalyOutputAnomalous, cmd.Output)
+				require.Equal(t, float64(defaultAnomalyDeviations), cmd.Deviations)
+				require.Zero(t, cmd.Seasonality)
+			},
+		},
+		{
+			description: "unmarshal holt-winters with settings",
+			query: `{
+				"expression": "B",
+				"type": "anomaly",
+				"algorithm": "holt_winters",
+				"output": "bounds",
+				"deviations": 2.5,
+				"seasonality": "1d",
+				"alpha": 0.2,
+				"beta": 0,
+				"gamma": 1
+			}`,
+			assert: func(t *testing.T, cmd *AnomalyCommand) {
+				require.Equal(t, []string{"B"}, cmd.NeedsVars())
+				require.Equal(t, AnomalyAlgorithmHoltWinters, cmd.Algorithm)
+				require.Equal(t, AnomalyOutputBounds, cmd.Output)
+				require.Equal(t, 2.5, cmd.Deviations)
+				require.Equal(t, 24*time.Hour, cmd.Seasonality)
+				require.Equal(t, 0.2, cmd.Alpha)
+				require.Equal(t, 0.0, cmd.Beta)
+				require.Equal(t, 1.0, cmd.Gamma)
+			},
+		},
+		{
+			description:   "unmarshal with unknown algorithm should error",
+			query:         `{"expression": "A", "type": "anomaly", "algorithm": "prophet"}`,
+			expectedError: "expected anomaly algorithm to be one of [mad, holt_winters], got prophet",
+		},
+		{
+			description:   "unmarshal with unknown output should error",
+			query:         `{"expression": "A", "type": "anomaly", "algorithm": "mad", "output": "score"}`,
+			expectedError: "expected anomaly output to be one of [anomalous, bounds], got score",
+		},
+		{
+			description:   "unmarshal without expression should error",
+			query:         `{"type": "anomaly", "algorithm": "mad"}`,
+			expectedError: "no variable specified to reference",
+		},
+		{
+			description:   "unmarshal with seasonality for mad should error",
+			query:         `{"expression": "A", "type": "anomaly", "algorithm": "mad", "seasonality": "1d"}`,
+			expectedError: "seasonality is only supported by the holt_winters algorithm",
+		},
+		{
+			description:   "unmarshal with negative deviations should error",
+			query:         `{"expression": "A", "type": "anomaly", "algorithm": "mad", "deviations": -1}`,
+			expectedError: "anomaly deviations must be greater than 0",
+		},
+		{
+			description:   "unmarshal with smoothing factor out of range should error",
+			query:         `{"expression": "A", "type": "anomaly", "algorithm": "holt_winters", "alpha": 1.5}`,
+			expectedError: "anomaly smoothing factor alpha must be between 0 and 1",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			var qmap = make(map[string]any)
+			require.NoError(t, json.Unmarshal([]byte(tc.query), &qmap))
+
+			cmd, err := UnmarshalAnomalyCommand(&rawNode{
+				RefID:    "C",
+				Query:    qmap,
+				QueryRaw: []byte(tc.query),
+			})
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			tc.assert(t, cmd)
+		})
+	}
+}
+
+func TestAnomalyCommandExecute(t *testing.T) {
+	start := time.Unix(0, 0)
+	makeSeries := func(labels data.Labels, values ...float64) mathexp.Series {
+		s := mathexp.NewSeries("A", labels, len(values))
+		for i := range values {
+			s.SetPoint(i, start.Add(time.Duration(i)*time.Minute), &values[i])
+		}
+		return s
+	}
+	seasonal := func(last float64) mathexp.Series {
+		var values []float64
+		for i := 0; i < 4; i++ {
+			values = append(values, 10, 20, 30, 20)
+		}
+		return makeSeries(nil, append(values, last)...)
+	}
+	ptr := func(f float64) *float64 { return &f }
+
+	cases := []struct {
+		description string
+		cmd         AnomalyCommand
+		input       mathexp.Value
+		expected    *float64
+	}{
+		{
+			description: "mad detects a spike",
+			cmd:         AnomalyCommand{Algorithm: AnomalyAlgorithmMAD, Deviations: 3},
+			input:       makeSeries(nil, 10, 11, 9, 10, 11, 10, 50),
+			expected:    ptr(1),
+		},
+		{
+			description: "mad ignores a small deviation",
+			cmd:         AnomalyCommand{Algorithm: AnomalyAlgorithmMAD, Deviations: 3},
+			input:       makeSeries(nil, 10, 11, 9, 10, 11, 10, 12),
+			expected:    ptr(0),
+		},
+		{
+			description: "mad without enough points returns no value",
+			cmd:         AnomalyCommand{Algorithm: AnomalyAlgorithmMAD, Deviations: 3},
+			input:       makeSeries(nil, 10, 11),
+			expected:    nil,
+		},
+		{
+			description: "holt-winters follows the season",
+			cmd:         AnomalyCommand{Algorithm: AnomalyAlgorithmHoltWinters, Deviations: 3, Seasonality: 4 * time.Minute, Alpha: 0.5, Beta: 0.1, Gamma: 0.3},
+			input:       seasonal(10),
+			expected:    ptr(0),
+		},
+		{
+			description: "holt-winters detects a value outside of the season",
+			cmd:         AnomalyCommand{Algorithm: AnomalyAlgorithmHoltWinters, Deviations: 3, Seasonality: 4 * time.Minute, Alpha: 0.5, Beta: 0.1, Gamma: 0.3},
+			input:       seasonal(30),
+			expected:    ptr(1),
+		},
+		{
+			description: "holt-winters without seasonality follows the trend",
+			cmd:         AnomalyCommand{Algorithm: AnomalyAlgorithmHoltWinters, Deviations: 3, Alpha: 0.5, Beta: 0.1},
+			input:       makeSeries(nil, 1, 2, 3, 4, 5, 6, 7),
+			expected:    ptr(0),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.cmd.RefID = "B"
+			tc.cmd.ReferenceVar = "A"
+			tc.cmd.Output = AnomalyOutputAnomalous
+			vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{tc.input}}}
+			res, err := tc.cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+			require.NoError(t, err)
+			require.Len(t, res.Values, 1)
+			require.IsType(t, mathexp.Number{}, res.Values[0])
+			require.Equal(t, tc.expected, res.Values[0].(mathexp.Number).GetFloat64Value())
+		})
+	}
+
+	t.Run("bounds output returns baseline, lower and upper series", func(t *testing.T) {
+		cmd := AnomalyCommand{RefID: "B", ReferenceVar: "A", Algorithm: AnomalyAlgorithmMAD, Deviations: 2, Output: AnomalyOutputBounds}
+		vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{makeSeries(data.Labels{"host": "a"}, 9, 11, 9, 11, 10)}}}
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, res.Values, 3)
+
+		// median is 10 and the median absolute deviation is 1
+		width := 2 * 1.4826
+		expected := map[string]float64{"baseline": 10, "lower": 10 - width, "upper": 10 + width}
+		for _, v := range res.Values {
+			s := v.(mathexp.Series)
+			bound := s.GetLabels()[anomalyBoundLabel]
+			require.Equal(t, data.Labels{"host": "a", anomalyBoundLabel: bound}, s.GetLabels())
+			require.Equal(t, 5, s.Len())
+			for i := 0; i < s.Len(); i++ {
+				require.InDelta(t, expected[bound], *s.GetValue(i), 1e-9)
+			}
+		}
+	})
+
+	t.Run("no data is returned as no data", func(t *testing.T) {
+		cmd := AnomalyCommand{RefID: "B", ReferenceVar: "A", Algorithm: AnomalyAlgorithmMAD, Deviations: 3, Output: AnomalyOutputAnomalous}
+		vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{mathexp.NewNoData()}}}
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		require.IsType(t, mathexp.NoData{}, res.Values[0])
+	})
+
+	t.Run("numbers should error", func(t *testing.T) {
+		cmd := AnomalyCommand{RefID: "B", ReferenceVar: "A", Algorithm: AnomalyAlgorithmMAD, Deviations: 3, Output: AnomalyOutputAnomalous}
+		n := mathexp.NewNumber("A", nil)
+		n.SetValue(ptr(1))
+		vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{n}}}
+		_, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.ErrorContains(t, err, "anomaly detection requires time series data")
+	})
+
+	t.Run("seasonality shorter than two points should error", func(t *testing.T) {
+		cmd := AnomalyCommand{RefID: "B", ReferenceVar: "A", Algorithm: AnomalyAlgorithmHoltWinters, Deviations: 3, Seasonality: time.Minute, Output: AnomalyOutputAnomalous}
+		vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{seasonal(10)}}}
+		_, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.ErrorContains(t, err, "must be at least twice the interval of the series")
+	})
+}
@@ -4,6 +4,8 @@ import (
 	"math"
 	"sort"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
 	"github.com/grafana/grafana/pkg/expr/mathexp"
 )
 
@@ -15,8 +17,11 @@ func (cr reducer) ValidReduceFunc() bool {
 		return true
 	case "diff", "diff_abs", "percent_diff", "percent_diff_abs", "count_non_null":
 		return true
+	case "stddev", "first", "delta", "rate":
+		return true
 	}
-	return false
+	_, ok := mathexp.ReducerID(cr).Percentile()
+	return ok
 }
 
 //nolint:gocyclo
@@ -144,6 +149,25 @@ func (cr reducer) Reduce(series mathexp.Series) mathexp.Number {
 		if value > 0 {
 			allNull = false
 		}
+	case "stddev":
+		allNull, value = reduceNonNull(ff, mathexp.StdDev)
+	case "first":
+		for i := 0; i < ff.Len(); i++ {
+			f := ff.GetValue(i)
+			if !nilOrNaN(f) {
+				value = *f
+				allNull = false
+				break
+			}
+		}
+	case "delta":
+		allNull, value = calculateDiff(ff, allNull, value, diff)
+	case "rate":
+		allNull, value = calculateRate(series)
+	default:
+		if p, ok := mathexp.ReducerID(cr).Percentile(); ok {
+			allNull, value = reduceNonNull(ff, mathexp.Percentile(p))
+		}
 	}
 
 	if allNull {
@@ -182,6 +206,46 @@ func calculateDiff(ff mathexp.Float64Field, allNull bool, value float64, fn func
 	return allNull, value
 }
 
+// reduceNonNull reduces the values that are neither null nor NaN using the provided function.
+// Returns true if there are no such values.
+func reduceNonNull(ff mathexp.Float64Field, fn mathexp.ReducerFunc) (bool, float64) {
+	values := make([]*float64, 0, ff.Len())
+	for i := 0; i < ff.Len(); i++ {
+		f := ff.GetValue(i)
+		if !nilOrNaN(f) {
+			values = append(values, f)
+		}
+	}
+	if len(values) == 0 {
+		return true, 0
+	}
+	nonNull := mathexp.Float64Field(*data.NewField("", nil, values))
+	return false, *fn(&nonNull)
+}
+
+// calculateRate returns the per-second rate of change between the oldest and the newest points that are neither null nor NaN.
+// Returns true if there are no such points.
+func calculateRate(series mathexp.Series) (bool, float64) {
+	first, last := -1, -1
+	for i := 0; i < series.Len(); i++ {
+		if nilOrNaN(series.GetValue(i)) {
+			continue
+		}
+		if first < 0 {
+			first = i
+		}
+		last = i
+	}
+	if first < 0 {
+		return true, 0
+	}
+	seconds := series.GetTime(last).Sub(series.GetTime(first)).Seconds()
+	if seconds <= 0 {
+		return false, 0
+	}
+	return false, (*series.GetValue(last) - *series.GetValue(first)) / seconds
+}
+
 func nilOrNaN(f *float64) bool {
 	return f == nil || math.IsNaN(*f)
 }
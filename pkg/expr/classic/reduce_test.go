@@ -115,6 +115,54 @@ func TestReducer(t *testing.T) {
 			inputSeries:    newSeries(nil, nil),
 			expectedNumber: newNumber(nil),
 		},
+		{
+			name:           "stddev",
+			reducer:        reducer("stddev"),
+			inputSeries:    newSeries(util.Pointer(2.0), nil, util.Pointer(4.0), util.Pointer(4.0), util.Pointer(4.0), util.Pointer(5.0), util.Pointer(5.0), util.Pointer(7.0), util.Pointer(9.0)),
+			expectedNumber: newNumber(util.Pointer(2.0)),
+		},
+		{
+			name:           "stddev with no values",
+			reducer:        reducer("stddev"),
+			inputSeries:    newSeries(nil, util.Pointer(math.NaN())),
+			expectedNumber: newNumber(nil),
+		},
+		{
+			name:           "first",
+			reducer:        reducer("first"),
+			inputSeries:    newSeries(nil, util.Pointer(math.NaN()), util.Pointer(3.0), util.Pointer(4.0)),
+			expectedNumber: newNumber(util.Pointer(3.0)),
+		},
+		{
+			name:           "delta",
+			reducer:        reducer("delta"),
+			inputSeries:    newSeries(util.Pointer(10.0), nil, util.Pointer(4.0)),
+			expectedNumber: newNumber(util.Pointer(-6.0)),
+		},
+		{
+			name:           "rate",
+			reducer:        reducer("rate"),
+			inputSeries:    newSeries(nil, util.Pointer(10.0), util.Pointer(12.0), util.Pointer(16.0), nil),
+			expectedNumber: newNumber(util.Pointer(3.0)),
+		},
+		{
+			name:           "rate with one value",
+			reducer:        reducer("rate"),
+			inputSeries:    newSeries(nil, util.Pointer(10.0)),
+			expectedNumber: newNumber(util.Pointer(0.0)),
+		},
+		{
+			name:           "p90",
+			reducer:        reducer("p90"),
+			inputSeries:    newSeries(util.Pointer(1.0), util.Pointer(2.0), nil, util.Pointer(3.0), util.Pointer(4.0), util.Pointer(5.0), util.Pointer(6.0), util.Pointer(7.0), util.Pointer(8.0), util.Pointer(9.0), util.Pointer(10.0), util.Pointer(11.0)),
+			expectedNumber: newNumber(util.Pointer(10.0)),
+		},
+		{
+			name:           "p50 is the same as median",
+			reducer:        reducer("p50"),
+			inputSeries:    newSeries(util.Pointer(1.0), util.Pointer(2.0), util.Pointer(3.0), util.Pointer(4.0)),
+			expectedNumber: newNumber(util.Pointer(2.5)),
+		},
 	}
 
 	for _, tt := range tests {
@@ -126,6 +174,15 @@ func TestReducer(t *testing.T) {
 	}
 }
 
+func TestValidReduceFunc(t *testing.T) {
+	for _, r := range []reducer{"p0", "p50", "p99.9", "p100"} {
+		require.Truef(t, r.ValidReduceFunc(), "reducer %s should be valid", r)
+	}
+	for _, r := range []reducer{"p", "p101", "p-1", "pNaN", "percentile", "foo"} {
+		require.Falsef(t, r.ValidReduceFunc(), "reducer %s should not be valid", r)
+	}
+}
+
 func TestDiffReducer(t *testing.T) {
 	var tests = []struct {
 		name           string
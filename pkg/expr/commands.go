@@ -90,7 +90,7 @@ type ReduceCommand struct {
 
 // NewReduceCommand creates a new ReduceCMD.
 func NewReduceCommand(refID string, reducer mathexp.ReducerID, varToReduce string, mapper mathexp.ReduceMapper) (*ReduceCommand, error) {
-	_, err := mathexp.GetReduceFunc(reducer)
+	_, err := mathexp.GetSeriesReduceFunc(reducer)
 	if err != nil {
 		return nil, err
 	}
@@ -343,6 +343,10 @@ const (
 	TypeThreshold
 	// TypeSQL is the CMDType for running SQL expressions
 	TypeSQL
+	// TypeAnomaly is the CMDType for detecting anomalies in time series
+	TypeAnomaly
+	// TypeLabels is the CMDType for changing the labels of series and numbers
+	TypeLabels
 )
 
 func (gt CommandType) String() string {
@@ -359,6 +363,10 @@ func (gt CommandType) String() string {
 		return "threshold"
 	case TypeSQL:
 		return "sql"
+	case TypeAnomaly:
+		return "anomaly"
+	case TypeLabels:
+		return "labels"
 	default:
 		return "unknown"
 	}
@@ -379,6 +387,10 @@ func ParseCommandType(s string) (CommandType, error) {
 		return TypeThreshold, nil
 	case "sql":
 		return TypeSQL, nil
+	case "anomaly":
+		return TypeAnomaly, nil
+	case "labels":
+		return TypeLabels, nil
 	default:
 		return TypeUnknown, fmt.Errorf("'%v' is not a recognized expression type", s)
 	}
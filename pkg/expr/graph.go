@@ -62,6 +62,7 @@ type DataPipeline []Node
 // map of the refId of the of each command
 func (dp *DataPipeline) execute(c context.Context, now time.Time, s *Service) (mathexp.Vars, error) {
 	vars := make(mathexp.Vars)
+	stats := executionStatsFromContext(c)
 
 	groupByDSFlag := s.features.IsEnabled(c, featuremgmt.FlagSseGroupByDatasource)
 	// Execute datasource nodes first, and grouped by datasource.
@@ -115,12 +116,14 @@ func (dp *DataPipeline) execute(c context.Context, now time.Time, s *Service) (m
 			return vars, makeUnexpectedNodeTypeError(node.RefID(), node.NodeType().String())
 		}
 
+		start := time.Now()
 		res, err := execNode.Execute(c, now, vars, s)
 		if err != nil {
 			res.Error = err
 		}
 
 		vars[node.RefID()] = res
+		stats.add(node.RefID(), node.NodeType(), time.Since(start), res)
 	}
 	return vars, nil
 }
@@ -0,0 +1,325 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+// +enum
+type LabelOperationType string
+
+const (
+	// LabelOperationReplace sets the destination label to the replacement if the regex matches the value of the source label.
+	LabelOperationReplace LabelOperationType = "replace"
+	// LabelOperationDrop removes the listed labels.
+	LabelOperationDrop LabelOperationType = "drop"
+	// LabelOperationKeep removes all labels but the listed ones.
+	LabelOperationKeep LabelOperationType = "keep"
+	// LabelOperationAggregate combines the values that have the same values of the listed labels using a reducer.
+	LabelOperationAggregate LabelOperationType = "aggregate"
+)
+
+var supportedLabelOperations = []string{
+	string(LabelOperationReplace),
+	string(LabelOperationDrop),
+	string(LabelOperationKeep),
+	string(LabelOperationAggregate),
+}
+
+// LabelOperation is a single step of a LabelsCommand.
+type LabelOperation struct {
+	Type LabelOperationType `json:"type"`
+
+	// The label to set, only valid for replace
+	Destination string `json:"destination,omitempty"`
+	// The label to match the regex against, only valid for replace
+	Source string `json:"source,omitempty"`
+	// The regex to match the value of the source label, anchored at both ends. Defaults to (.*)
+	Regex string `json:"regex,omitempty"`
+	// The new value of the destination label, can refer to the capture groups of the regex as $1 or ${name}
+	Replacement string `json:"replacement,omitempty"`
+
+	// The labels to drop or keep, or to aggregate by
+	Labels []string `json:"labels,omitempty"`
+	// The reducer to combine values with, only valid for aggregate
+	Reducer mathexp.ReducerID `json:"reducer,omitempty"`
+
+	regex  *regexp.Regexp
+	reduce mathexp.ReducerFunc
+}
+
+// LabelsCommand is an expression that changes the labels of the series or numbers of the referenced
+// variable. The operations are applied in order.
+type LabelsCommand struct {
+	RefID        string
+	ReferenceVar string
+	Operations   []LabelOperation
+}
+
+// NewLabelsCommand creates a new LabelsCommand and validates its operations.
+func NewLabelsCommand(refID, referenceVar string, operations []LabelOperation) (*LabelsCommand, error) {
+	if len(operations) == 0 {
+		return nil, fmt.Errorf("labels expression requires at least one operation")
+	}
+	ops := make([]LabelOperation, 0, len(operations))
+	for i, op := range operations {
+		if err := op.compile(); err != nil {
+			return nil, fmt.Errorf("invalid operation %d: %w", i+1, err)
+		}
+		ops = append(ops, op)
+	}
+	return &LabelsCommand{
+		RefID:        refID,
+		ReferenceVar: referenceVar,
+		Operations:   ops,
+	}, nil
+}
+
+// compile validates the operation and prepares its regex or reducer.
+func (op *LabelOperation) compile() error {
+	switch op.Type {
+	case LabelOperationReplace:
+		if op.Destination == "" {
+			return fmt.Errorf("replace requires a destination label")
+		}
+		regex := op.Regex
+		if regex == "" {
+			regex = "(.*)"
+		}
+		r, err := regexp.Compile("^(?:" + regex + ")$")
+		if err != nil {
+			return fmt.Errorf("failed to parse regex %q: %w", op.Regex, err)
+		}
+		op.regex = r
+	case LabelOperationDrop, LabelOperationKeep:
+		if len(op.Labels) == 0 {
+			return fmt.Errorf("%s requires at least one label", op.Type)
+		}
+	case LabelOperationAggregate:
+		reduce, err := mathexp.GetReduceFunc(op.Reducer)
+		if err != nil {
+			return err
+		}
+		op.reduce = reduce
+	default:
+		return fmt.Errorf("expected operation type to be one of [%s], got %s", strings.Join(supportedLabelOperations, ", "), op.Type)
+	}
+	return nil
+}
+
+// UnmarshalLabelsCommand creates a LabelsCommand from Grafana's frontend query.
+func UnmarshalLabelsCommand(rn *rawNode) (*LabelsCommand, error) {
+	q := LabelsQuery{}
+	if err := json.Unmarshal(rn.QueryRaw, &q); err != nil {
+		return nil, fmt.Errorf("failed to parse the labels command: %w", err)
+	}
+	referenceVar, err := getReferenceVar(q.Expression, rn.RefID)
+	if err != nil {
+		return nil, err
+	}
+	return NewLabelsCommand(rn.RefID, referenceVar, q.Operations)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (lc *LabelsCommand) NeedsVars() []string {
+	return []string{lc.ReferenceVar}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (lc *LabelsCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
+	_, span := tracer.Start(ctx, "SSE.ExecuteLabels")
+	defer span.End()
+
+	newRes := mathexp.Results{}
+	// The values of the referenced variable are copied because they can be used by other nodes.
+	for _, val := range vars[lc.ReferenceVar].Values {
+		switch v := val.(type) {
+		case mathexp.Series:
+			s := mathexp.NewSeries(lc.RefID, v.GetLabels().Copy(), v.Len())
+			for i := 0; i < v.Len(); i++ {
+				t, f := v.GetPoint(i)
+				s.SetPoint(i, t, f)
+			}
+			newRes.Values = append(newRes.Values, s)
+		case mathexp.Number:
+			n := mathexp.NewNumber(lc.RefID, v.GetLabels().Copy())
+			n.SetValue(v.GetFloat64Value())
+			newRes.Values = append(newRes.Values, n)
+		case mathexp.NoData:
+			newRes.Values = append(newRes.Values, mathexp.NewNoData())
+		default:
+			return newRes, fmt.Errorf("labels expression requires series or numbers, got %s", val.Type())
+		}
+	}
+	if newRes.IsNoData() {
+		return newRes, nil
+	}
+
+	for _, op := range lc.Operations {
+		if op.Type == LabelOperationAggregate {
+			values, err := aggregateByLabels(lc.RefID, newRes.Values, op.Labels, op.reduce)
+			if err != nil {
+				return newRes, err
+			}
+			newRes.Values = values
+			continue
+		}
+		for _, val := range newRes.Values {
+			if _, ok := val.(mathexp.NoData); ok {
+				continue
+			}
+			val.SetLabels(op.apply(val.GetLabels()))
+		}
+	}
+
+	seen := make(map[string]struct{}, len(newRes.Values))
+	for _, val := range newRes.Values {
+		if _, ok := val.(mathexp.NoData); ok {
+			continue
+		}
+		key := val.GetLabels().String()
+		if _, ok := seen[key]; ok {
+			return newRes, fmt.Errorf("labels expression results in multiple values with labels %s, use an aggregate operation to combine them", key)
+		}
+		seen[key] = struct{}{}
+	}
+	return newRes, nil
+}
+
+func (lc *LabelsCommand) Type() string {
+	return TypeLabels.String()
+}
+
+// apply returns the labels changed by the replace, drop or keep operation.
+func (op *LabelOperation) apply(labels data.Labels) data.Labels {
+	result := make(data.Labels, len(labels))
+	switch op.Type {
+	case LabelOperationReplace:
+		for k, v := range labels {
+			result[k] = v
+		}
+		value := labels[op.Source]
+		match := op.regex.FindStringSubmatchIndex(value)
+		if match == nil {
+			break
+		}
+		replaced := string(op.regex.ExpandString(nil, op.Replacement, value, match))
+		if replaced == "" {
+			delete(result, op.Destination)
+		} else {
+			result[op.Destination] = replaced
+		}
+	case LabelOperationDrop:
+		for k, v := range labels {
+			result[k] = v
+		}
+		for _, k := range op.Labels {
+			delete(result, k)
+		}
+	case LabelOperationKeep:
+		for _, k := range op.Labels {
+			if v, ok := labels[k]; ok {
+				result[k] = v
+			}
+		}
+	}
+	return result
+}
+
+// aggregateByLabels groups the values by the given labels and combines the values of each group with the reducer.
+// Numbers are combined into a single number, series are combined point by point. Null values are ignored.
+func aggregateByLabels(refID string, values mathexp.Values, by []string, reduce mathexp.ReducerFunc) (mathexp.Values, error) {
+	type group struct {
+		labels data.Labels
+		values mathexp.Values
+	}
+	var groups []*group
+	byKey := map[string]*group{}
+	var valueType string
+	for _, val := range values {
+		if _, ok := val.(mathexp.NoData); ok {
+			continue
+		}
+		if valueType == "" {
+			valueType = val.Type().String()
+		} else if valueType != val.Type().String() {
+			return nil, fmt.Errorf("cannot aggregate a mix of %s and %s", valueType, val.Type())
+		}
+		labels := make(data.Labels, len(by))
+		for _, k := range by {
+			if v, ok := val.GetLabels()[k]; ok {
+				labels[k] = v
+			}
+		}
+		key := labels.String()
+		g, ok := byKey[key]
+		if !ok {
+			g = &group{labels: labels}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		g.values = append(g.values, val)
+	}
+
+	result := make(mathexp.Values, 0, len(groups))
+	for _, g := range groups {
+		switch g.values[0].(type) {
+		case mathexp.Number:
+			var points []*float64
+			for _, val := range g.values {
+				if f := val.(mathexp.Number).GetFloat64Value(); f != nil {
+					points = append(points, f)
+				}
+			}
+			n := mathexp.NewNumber(refID, g.labels)
+			n.SetValue(reduceNonNull(points, reduce))
+			result = append(result, n)
+		case mathexp.Series:
+			// Points are keyed by instant, since equal times may differ in location or monotonic clock reading.
+			pointsByTime := map[int64][]*float64{}
+			var times []time.Time
+			for _, val := range g.values {
+				s := val.(mathexp.Series)
+				for i := 0; i < s.Len(); i++ {
+					t, f := s.GetPoint(i)
+					key := t.UnixNano()
+					if _, ok := pointsByTime[key]; !ok {
+						pointsByTime[key] = nil
+						times = append(times, t)
+					}
+					if f != nil {
+						pointsByTime[key] = append(pointsByTime[key], f)
+					}
+				}
+			}
+			sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+			s := mathexp.NewSeries(refID, g.labels, len(times))
+			for i, t := range times {
+				s.SetPoint(i, t, reduceNonNull(pointsByTime[t.UnixNano()], reduce))
+			}
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
+// reduceNonNull reduces the values with the reducer, or returns nil if there are no values.
+func reduceNonNull(values []*float64, reduce mathexp.ReducerFunc) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	field := mathexp.Float64Field(*data.NewField("", nil, values))
+	return reduce(&field)
+}
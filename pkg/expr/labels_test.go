@@ -0,0 +1,221 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+func TestUnmarshalLabelsCommand(t *testing.T) {
+	cases := []struct {
+		description   string
+		query         string
+		expectedError string
+	}{
+		{
+			description: "unmarshal all operations",
+			query: `{
+				"expression": "$A",
+				"type": "labels",
+				"operations": [
+					{"type": "replace", "destination": "service", "source": "job", "regex": "(.*)-prod", "replacement": "$1"},
+					{"type": "drop", "labels": ["pod"]},
+					{"type": "keep", "labels": ["service", "region"]},
+					{"type": "aggregate", "labels": ["service"], "reducer": "sum"}
+				]
+			}`,
+		},
+		{
+			description:   "unmarshal without operations should error",
+			query:         `{"expression": "$A", "type": "labels"}`,
+			expectedError: "labels expression requires at least one operation",
+		},
+		{
+			description:   "unmarshal with unknown operation should error",
+			query:         `{"expression": "$A", "type": "labels", "operations": [{"type": "rename"}]}`,
+			expectedError: "expected operation type to be one of [replace, drop, keep, aggregate], got rename",
+		},
+		{
+			description:   "unmarshal replace without destination should error",
+			query:         `{"expression": "$A", "type": "labels", "operations": [{"type": "replace", "source": "job"}]}`,
+			expectedError: "replace requires a destination label",
+		},
+		{
+			description:   "unmarshal replace with invalid regex should error",
+			query:         `{"expression": "$A", "type": "labels", "operations": [{"type": "replace", "destination": "a", "regex": "("}]}`,
+			expectedError: "failed to parse regex",
+		},
+		{
+			description:   "unmarshal drop without labels should error",
+			query:         `{"expression": "$A", "type": "labels", "operations": [{"type": "drop"}]}`,
+			expectedError: "drop requires at least one label",
+		},
+		{
+			description:   "unmarshal aggregate with unknown reducer should error",
+			query:         `{"expression": "$A", "type": "labels", "operations": [{"type": "aggregate", "reducer": "foo"}]}`,
+			expectedError: "reduction foo not implemented",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			var qmap = make(map[string]any)
+			require.NoError(t, json.Unmarshal([]byte(tc.query), &qmap))
+
+			cmd, err := UnmarshalLabelsCommand(&rawNode{
+				RefID:    "B",
+				Query:    qmap,
+				QueryRaw: []byte(tc.query),
+			})
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, []string{"A"}, cmd.NeedsVars())
+			require.Len(t, cmd.Operations, 4)
+		})
+	}
+}
+
+func TestLabelsCommandExecute(t *testing.T) {
+	start := time.Unix(0, 0)
+	ptr := func(f float64) *float64 { return &f }
+	makeNumber := func(labels data.Labels, f *float64) mathexp.Number {
+		n := mathexp.NewNumber("A", labels)
+		n.SetValue(f)
+		return n
+	}
+	makeSeries := func(labels data.Labels, values ...*float64) mathexp.Series {
+		s := mathexp.NewSeries("A", labels, len(values))
+		for i, v := range values {
+			s.SetPoint(i, start.Add(time.Duration(i)*time.Minute), v)
+		}
+		return s
+	}
+	execute := func(t *testing.T, ops []LabelOperation, values ...mathexp.Value) (mathexp.Results, error) {
+		t.Helper()
+		cmd, err := NewLabelsCommand("B", "A", ops)
+		require.NoError(t, err)
+		vars := mathexp.Vars{"A": mathexp.Results{Values: values}}
+		return cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+	}
+
+	t.Run("replace, drop and keep change the labels of numbers", func(t *testing.T) {
+		input := makeNumber(data.Labels{"job": "api-prod", "pod": "1", "region": "eu"}, ptr(1))
+		res, err := execute(t, []LabelOperation{
+			{Type: LabelOperationReplace, Destination: "service", Source: "job", Regex: "(.*)-prod", Replacement: "$1"},
+			{Type: LabelOperationDrop, Labels: []string{"pod"}},
+			{Type: LabelOperationKeep, Labels: []string{"service", "region"}},
+		}, input)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		require.Equal(t, data.Labels{"service": "api", "region": "eu"}, res.Values[0].GetLabels())
+		require.Equal(t, ptr(1), res.Values[0].(mathexp.Number).GetFloat64Value())
+		// the input must not be changed
+		require.Equal(t, data.Labels{"job": "api-prod", "pod": "1", "region": "eu"}, input.GetLabels())
+	})
+
+	t.Run("replace does nothing if the regex does not match", func(t *testing.T) {
+		res, err := execute(t, []LabelOperation{
+			{Type: LabelOperationReplace, Destination: "service", Source: "job", Regex: "(.*)-prod", Replacement: "$1"},
+		}, makeNumber(data.Labels{"job": "api-dev"}, ptr(1)))
+		require.NoError(t, err)
+		require.Equal(t, data.Labels{"job": "api-dev"}, res.Values[0].GetLabels())
+	})
+
+	t.Run("replace with an empty result removes the label", func(t *testing.T) {
+		res, err := execute(t, []LabelOperation{
+			{Type: LabelOperationReplace, Destination: "job", Source: "job", Regex: "api", Replacement: ""},
+		}, makeNumber(data.Labels{"job": "api", "pod": "1"}, ptr(1)))
+		require.NoError(t, err)
+		require.Equal(t, data.Labels{"pod": "1"}, res.Values[0].GetLabels())
+	})
+
+	t.Run("aggregate combines numbers by labels", func(t *testing.T) {
+		res, err := execute(t, []LabelOperation{
+			{Type: LabelOperationAggregate, Labels: []string{"service"}, Reducer: mathexp.ReducerSum},
+		},
+			makeNumber(data.Labels{"service": "api", "pod": "1"}, ptr(1)),
+			makeNumber(data.Labels{"service": "api", "pod": "2"}, ptr(2)),
+			makeNumber(data.Labels{"service": "api", "pod": "3"}, nil),
+			makeNumber(data.Labels{"service": "db", "pod": "1"}, ptr(5)),
+			makeNumber(data.Labels{"service": "db", "pod": "2"}, nil),
+			makeNumber(data.Labels{"pod": "4"}, nil),
+		)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 3)
+		require.Equal(t, data.Labels{"service": "api"}, res.Values[0].GetLabels())
+		require.Equal(t, ptr(3), res.Values[0].(mathexp.Number).GetFloat64Value())
+		require.Equal(t, data.Labels{"service": "db"}, res.Values[1].GetLabels())
+		require.Equal(t, ptr(5), res.Values[1].(mathexp.Number).GetFloat64Value())
+		require.Equal(t, data.Labels{}, res.Values[2].GetLabels())
+		require.Nil(t, res.Values[2].(mathexp.Number).GetFloat64Value())
+	})
+
+	t.Run("aggregate combines series point by point", func(t *testing.T) {
+		res, err := execute(t, []LabelOperation{
+			{Type: LabelOperationAggregate, Labels: []string{"service"}, Reducer: mathexp.ReducerMax},
+		},
+			makeSeries(data.Labels{"service": "api", "pod": "1"}, ptr(1), ptr(4), nil),
+			makeSeries(data.Labels{"service": "api", "pod": "2"}, ptr(3), ptr(2)),
+		)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		s := res.Values[0].(mathexp.Series)
+		require.Equal(t, data.Labels{"service": "api"}, s.GetLabels())
+		require.Equal(t, 3, s.Len())
+		require.Equal(t, ptr(3), s.GetValue(0))
+		require.Equal(t, ptr(4), s.GetValue(1))
+		require.Nil(t, s.GetValue(2))
+	})
+
+	t.Run("aggregate combines points at the same instant in different locations", func(t *testing.T) {
+		ts := time.Unix(1000, 0)
+		utc := mathexp.NewSeries("A", data.Labels{"service": "api", "pod": "1"}, 1)
+		utc.SetPoint(0, ts.UTC(), ptr(1))
+		local := mathexp.NewSeries("A", data.Labels{"service": "api", "pod": "2"}, 1)
+		local.SetPoint(0, ts.In(time.FixedZone("UTC+2", 2*60*60)), ptr(2))
+
+		res, err := execute(t, []LabelOperation{
+			{Type: LabelOperationAggregate, Labels: []string{"service"}, Reducer: mathexp.ReducerSum},
+		}, utc, local)
+		require.NoError(t, err)
+		s := res.Values[0].(mathexp.Series)
+		require.Equal(t, 1, s.Len())
+		require.Equal(t, ptr(3), s.GetValue(0))
+	})
+
+	t.Run("aggregate of series and numbers should error", func(t *testing.T) {
+		_, err := execute(t, []LabelOperation{
+			{Type: LabelOperationAggregate, Reducer: mathexp.ReducerSum},
+		}, makeSeries(nil, ptr(1)), makeNumber(data.Labels{"a": "b"}, ptr(1)))
+		require.ErrorContains(t, err, "cannot aggregate a mix of")
+	})
+
+	t.Run("duplicate labels after the operations should error", func(t *testing.T) {
+		_, err := execute(t, []LabelOperation{
+			{Type: LabelOperationDrop, Labels: []string{"pod"}},
+		},
+			makeNumber(data.Labels{"service": "api", "pod": "1"}, ptr(1)),
+			makeNumber(data.Labels{"service": "api", "pod": "2"}, ptr(2)),
+		)
+		require.ErrorContains(t, err, "use an aggregate operation to combine them")
+	})
+
+	t.Run("no data is returned as no data", func(t *testing.T) {
+		res, err := execute(t, []LabelOperation{
+			{Type: LabelOperationDrop, Labels: []string{"pod"}},
+		}, mathexp.NewNoData())
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		require.IsType(t, mathexp.NoData{}, res.Values[0])
+	})
+}
@@ -214,28 +214,8 @@ func (e *State) union(aResults, bResults Results, biNode *parse.BinaryNode) []*U
 	aMatched := make([]bool, len(aResults.Values))
 	bMatched := make([]bool, len(bResults.Values))
 	collectDrops := func() {
-		check := func(v string, matchArray []bool, r *Results) {
-			for i, b := range matchArray {
-				if b {
-					continue
-				}
-				if e.Drops == nil {
-					e.Drops = make(map[string]map[string][]data.Labels)
-				}
-				if e.Drops[biNode.String()] == nil {
-					e.Drops[biNode.String()] = make(map[string][]data.Labels)
-				}
-
-				if r.Values[i].Type() == parse.TypeNoData {
-					continue
-				}
-
-				e.DropCount++
-				e.Drops[biNode.String()][v] = append(e.Drops[biNode.String()][v], r.Values[i].GetLabels())
-			}
-		}
-		check(aVar, aMatched, &aResults)
-		check(bVar, bMatched, &bResults)
+		e.collectDrops(biNode, aVar, aMatched, &aResults)
+		e.collectDrops(biNode, bVar, bMatched, &bResults)
 	}
 
 	aValueLen := len(aResults.Values)
@@ -306,6 +286,138 @@ func (e *State) union(aResults, bResults Results, biNode *parse.BinaryNode) []*U
 	return unions
 }
 
+// collectDrops records the values of one side of a binary operation that are not part of any Union.
+func (e *State) collectDrops(biNode *parse.BinaryNode, v string, matchArray []bool, r *Results) {
+	for i, b := range matchArray {
+		if b {
+			continue
+		}
+		if e.Drops == nil {
+			e.Drops = make(map[string]map[string][]data.Labels)
+		}
+		if e.Drops[biNode.String()] == nil {
+			e.Drops[biNode.String()] = make(map[string][]data.Labels)
+		}
+
+		if r.Values[i].Type() == parse.TypeNoData {
+			continue
+		}
+
+		e.DropCount++
+		e.Drops[biNode.String()][v] = append(e.Drops[biNode.String()][v], r.Values[i].GetLabels())
+	}
+}
+
+// matchUnion creates Union objects by matching the labels of each Series or Number according to the
+// vector matching of the binary operation, e.g. $A / on(service) $B. Unlike union, matching is only done
+// on the selected labels and an error is returned if the matching is ambiguous.
+func (e *State) matchUnion(aResults, bResults Results, biNode *parse.BinaryNode) ([]*Union, error) {
+	m := biNode.Matching
+	// Scalars and no data have no labels to match, so they are combined like without vector matching.
+	for _, r := range []Results{aResults, bResults} {
+		if len(r.Values) == 1 && (r.Values[0].Type() == parse.TypeScalar || r.Values[0].Type() == parse.TypeNoData) {
+			return e.union(aResults, bResults, biNode), nil
+		}
+	}
+
+	// The "one" side of the matching must have at most one value per match group.
+	if _, err := matchGroups(aResults, m, m.Card != parse.CardManyToOne, biNode, "left"); err != nil {
+		return nil, err
+	}
+	bGroups, err := matchGroups(bResults, m, m.Card != parse.CardOneToMany, biNode, "right")
+	if err != nil {
+		return nil, err
+	}
+
+	unions := []*Union{}
+	aMatched := make([]bool, len(aResults.Values))
+	bMatched := make([]bool, len(bResults.Values))
+	seen := map[data.Fingerprint]struct{}{}
+	for iA, a := range aResults.Values {
+		for _, iB := range bGroups[matchLabels(a.GetLabels(), m).Fingerprint()] {
+			b := bResults.Values[iB]
+			labels := matchResultLabels(a.GetLabels(), b.GetLabels(), m)
+			if _, ok := seen[labels.Fingerprint()]; ok {
+				return nil, fmt.Errorf("multiple matches for labels {%s} in '%s': grouping labels must ensure unique matches", labels, biNode)
+			}
+			seen[labels.Fingerprint()] = struct{}{}
+			unions = append(unions, &Union{
+				Labels: labels,
+				A:      a,
+				B:      b,
+			})
+			aMatched[iA] = true
+			bMatched[iB] = true
+		}
+	}
+
+	e.collectDrops(biNode, biNode.Args[0].String(), aMatched, &aResults)
+	e.collectDrops(biNode, biNode.Args[1].String(), bMatched, &bResults)
+	return unions, nil
+}
+
+// matchGroups groups the indexes of the values by the labels they are matched on.
+// If unique is true, it returns an error if more than one value belongs to the same group.
+func matchGroups(r Results, m *parse.VectorMatching, unique bool, biNode *parse.BinaryNode, side string) (map[data.Fingerprint][]int, error) {
+	groups := make(map[data.Fingerprint][]int, len(r.Values))
+	for i, v := range r.Values {
+		labels := matchLabels(v.GetLabels(), m)
+		sig := labels.Fingerprint()
+		if unique && len(groups[sig]) > 0 {
+			return nil, fmt.Errorf("found duplicate series for the match group {%s} on the %s side of '%s': many-to-many matching not allowed, matching labels must be unique on one side", labels, side, biNode)
+		}
+		groups[sig] = append(groups[sig], i)
+	}
+	return groups, nil
+}
+
+// matchLabels returns the labels that are used for matching.
+func matchLabels(labels data.Labels, m *parse.VectorMatching) data.Labels {
+	result := data.Labels{}
+	if m.On {
+		for _, name := range m.Labels {
+			if v, ok := labels[name]; ok {
+				result[name] = v
+			}
+		}
+		return result
+	}
+	for name, v := range labels {
+		result[name] = v
+	}
+	for _, name := range m.Labels {
+		delete(result, name)
+	}
+	return result
+}
+
+// matchResultLabels returns the labels of the result of a matched binary operation. The result of a one-to-one
+// matching has the matching labels. The result of a many-to-one or one-to-many matching has the labels of the
+// "many" side and the included labels of the "one" side.
+func matchResultLabels(aLabels, bLabels data.Labels, m *parse.VectorMatching) data.Labels {
+	var many, one data.Labels
+	switch m.Card {
+	case parse.CardManyToOne:
+		many, one = aLabels, bLabels
+	case parse.CardOneToMany:
+		many, one = bLabels, aLabels
+	default:
+		return matchLabels(aLabels, m)
+	}
+	result := many.Copy()
+	if result == nil {
+		result = data.Labels{}
+	}
+	for _, name := range m.Include {
+		if v, ok := one[name]; ok {
+			result[name] = v
+		} else {
+			delete(result, name)
+		}
+	}
+	return result
+}
+
 func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 	res := Results{Values: Values{}}
 	ar, err := e.walk(node.Args[0])
@@ -316,7 +428,15 @@ func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 	if err != nil {
 		return res, err
 	}
-	unions := e.union(ar, br, node)
+	var unions []*Union
+	if node.Matching != nil {
+		unions, err = e.matchUnion(ar, br, node)
+		if err != nil {
+			return res, err
+		}
+	} else {
+		unions = e.union(ar, br, node)
+	}
 	for _, uni := range unions {
 		var value Value
 		switch at := uni.A.(type) {
@@ -564,6 +684,8 @@ func (e *State) walkFunc(node *parse.FuncNode) (Results, error) {
 		switch t := a.(type) {
 		case *parse.StringNode:
 			v = t.Text
+		case *parse.DurationNode:
+			v = t.Duration
 		case *parse.VarNode:
 			v = e.Vars[t.Name]
 		case *parse.ScalarNode:
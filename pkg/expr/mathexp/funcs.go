@@ -1,7 +1,9 @@
 package mathexp
 
 import (
+	"fmt"
 	"math"
+	"time"
 
 	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
 )
@@ -68,6 +70,52 @@ var builtins = map[string]parse.Func{
 		VariantReturn: true,
 		F:             floor,
 	},
+	"clamp_min": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar},
+		VariantReturn: true,
+		F:             clampMin,
+	},
+	"clamp_max": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar},
+		VariantReturn: true,
+		F:             clampMax,
+	},
+	"rate": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      rate,
+	},
+	"delta": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      delta,
+	},
+	"cumsum": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      cumsum,
+	},
+	"shift": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeDuration},
+		Return: parse.TypeSeriesSet,
+		F:      shift,
+	},
+	"moving_avg": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeDuration},
+		Return: parse.TypeSeriesSet,
+		F:      movingAvg,
+		Check:  checkPositiveDuration,
+	},
+	"hour": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      hour,
+	},
+	"day_of_week": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      dayOfWeek,
+	},
 }
 
 // abs returns the absolute value for each result in NumberSet, SeriesSet, or Scalar
@@ -307,3 +355,227 @@ func floor(e *State, varSet Results) (Results, error) {
 	}
 	return newRes, nil
 }
+
+// clampMin returns the greater of the value and min for each result in NumberSet, SeriesSet, or Scalar.
+func clampMin(e *State, varSet Results, minSet Results) (Results, error) {
+	minF := scalarArg(minSet)
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		newVal, err := perFloat(e, res, func(f float64) float64 {
+			return math.Max(f, minF)
+		})
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// clampMax returns the lesser of the value and max for each result in NumberSet, SeriesSet, or Scalar.
+func clampMax(e *State, varSet Results, maxSet Results) (Results, error) {
+	maxF := scalarArg(maxSet)
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		newVal, err := perFloat(e, res, func(f float64) float64 {
+			return math.Min(f, maxF)
+		})
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// rate returns the per-second rate of change between each point and the previous point of each series in SeriesSet.
+// The first point of each series is dropped since it has no previous point. If two points have the same
+// timestamp, the rate is NaN.
+func rate(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "rate", varSet, func(s Series) Series {
+		return perPointPair(e, s, func(prevT, t time.Time, prev, cur float64) float64 {
+			seconds := t.Sub(prevT).Seconds()
+			if seconds <= 0 {
+				return math.NaN()
+			}
+			return (cur - prev) / seconds
+		})
+	})
+}
+
+// delta returns the difference between each point and the previous point of each series in SeriesSet.
+// The first point of each series is dropped since it has no previous point.
+func delta(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "delta", varSet, func(s Series) Series {
+		return perPointPair(e, s, func(_, _ time.Time, prev, cur float64) float64 {
+			return cur - prev
+		})
+	})
+}
+
+// cumsum returns the cumulative sum of the values of each series in SeriesSet. Null values are kept as null
+// and do not contribute to the sum.
+func cumsum(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "cumsum", varSet, func(s Series) Series {
+		s = sortedCopy(s)
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		var sum float64
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			if f == nil {
+				newSeries.SetPoint(i, t, nil)
+				continue
+			}
+			sum += *f
+			nF := sum
+			newSeries.SetPoint(i, t, &nF)
+		}
+		return newSeries
+	})
+}
+
+// shift moves the timestamps of each series in SeriesSet forward by the duration, so that a series can be compared
+// with itself at an earlier time, for example `$A - shift($A, 1w)`.
+func shift(e *State, varSet Results, d time.Duration) (Results, error) {
+	return perSeries(e, "shift", varSet, func(s Series) Series {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			newSeries.SetPoint(i, t.Add(d), f)
+		}
+		return newSeries
+	})
+}
+
+// movingAvg returns the average of the values of each series in SeriesSet within the window that ends at each point.
+// Null and NaN values are ignored. If there are no values within the window, the point is null.
+func movingAvg(e *State, varSet Results, window time.Duration) (Results, error) {
+	return perSeries(e, "moving_avg", varSet, func(s Series) Series {
+		s = sortedCopy(s)
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		var sum float64
+		var count int
+		start := 0
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			if f != nil && !math.IsNaN(*f) {
+				sum += *f
+				count++
+			}
+			for ; !s.GetTime(start).After(t.Add(-window)); start++ {
+				if f := s.GetValue(start); f != nil && !math.IsNaN(*f) {
+					sum -= *f
+					count--
+				}
+			}
+			if count == 0 {
+				newSeries.SetPoint(i, t, nil)
+				continue
+			}
+			avg := sum / float64(count)
+			newSeries.SetPoint(i, t, &avg)
+		}
+		return newSeries
+	})
+}
+
+// hour returns the hour of the day (0-23) in UTC of the timestamp of each point of each series in SeriesSet.
+func hour(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "hour", varSet, func(s Series) Series {
+		return perTime(e, s, func(t time.Time) float64 {
+			return float64(t.UTC().Hour())
+		})
+	})
+}
+
+// dayOfWeek returns the day of the week (0-6, starting on Sunday) in UTC of the timestamp of each point of each series in SeriesSet.
+func dayOfWeek(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "day_of_week", varSet, func(s Series) Series {
+		return perTime(e, s, func(t time.Time) float64 {
+			return float64(t.UTC().Weekday())
+		})
+	})
+}
+
+// checkPositiveDuration checks that the duration argument of the function is greater than zero.
+func checkPositiveDuration(_ *parse.Tree, f *parse.FuncNode) error {
+	for _, arg := range f.Args {
+		if d, ok := arg.(*parse.DurationNode); ok && d.Duration <= 0 {
+			return fmt.Errorf("parse: duration for %s must be greater than zero, got %s", f.Name, d)
+		}
+	}
+	return nil
+}
+
+// scalarArg returns the value of a scalar argument. Null is returned as NaN.
+func scalarArg(res Results) float64 {
+	if len(res.Values) == 1 {
+		if s, ok := res.Values[0].(Scalar); ok {
+			if f := s.GetFloat64Value(); f != nil {
+				return *f
+			}
+		}
+	}
+	return math.NaN()
+}
+
+// perSeries passes each Series of a SeriesSet to seriesF. These functions depend on the timestamps of the points,
+// so other types return an error, except for no data which is returned as is.
+func perSeries(e *State, name string, varSet Results, seriesF func(s Series) Series) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		switch v := res.(type) {
+		case Series:
+			newRes.Values = append(newRes.Values, seriesF(v))
+		case NoData:
+			newRes.Values = append(newRes.Values, NewNoData())
+		default:
+			return newRes, fmt.Errorf("%s expects a series, got %v", name, res.Type())
+		}
+	}
+	return newRes, nil
+}
+
+// perPointPair passes the value of each point of the series and the value of the previous point to pairF,
+// in time order. If either of the values is null, the point is null.
+func perPointPair(e *State, s Series, pairF func(prevT, t time.Time, prev, cur float64) float64) Series {
+	s = sortedCopy(s)
+	size := s.Len() - 1
+	if size < 0 {
+		size = 0
+	}
+	newSeries := NewSeries(e.RefID, s.GetLabels(), size)
+	for i := 1; i < s.Len(); i++ {
+		prevT, prev := s.GetPoint(i - 1)
+		t, cur := s.GetPoint(i)
+		if prev == nil || cur == nil {
+			newSeries.SetPoint(i-1, t, nil)
+			continue
+		}
+		nF := pairF(prevT, t, *prev, *cur)
+		newSeries.SetPoint(i-1, t, &nF)
+	}
+	return newSeries
+}
+
+// perTime passes the timestamp of each point of the series to timeF.
+func perTime(e *State, s Series, timeF func(t time.Time) float64) Series {
+	newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+	for i := 0; i < s.Len(); i++ {
+		t := s.GetTime(i)
+		nF := timeF(t)
+		newSeries.SetPoint(i, t, &nF)
+	}
+	return newSeries
+}
+
+// sortedCopy returns a copy of the series sorted by time from oldest to newest, so the input is not modified.
+func sortedCopy(s Series) Series {
+	newSeries := NewSeries(s.GetName(), s.GetLabels(), s.Len())
+	for i := 0; i < s.Len(); i++ {
+		t, f := s.GetPoint(i)
+		newSeries.SetPoint(i, t, f)
+	}
+	newSeries.SortByTime(false)
+	return newSeries
+}
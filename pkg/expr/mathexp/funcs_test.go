@@ -140,3 +140,190 @@ func TestIsNumberFunc(t *testing.T) {
 		})
 	}
 }
+
+func TestSeriesFuncs(t *testing.T) {
+	monday := time.Date(2024, 1, 1, 13, 30, 0, 0, time.UTC)
+	series := resultValuesNoErr(
+		makeSeries("", nil,
+			tp{time.Unix(0, 0), float64Pointer(1)},
+			tp{time.Unix(10, 0), float64Pointer(3)},
+			tp{time.Unix(20, 0), float64Pointer(5)},
+			tp{time.Unix(30, 0), nil}),
+	)
+	var tests = []struct {
+		name      string
+		expr      string
+		vars      Vars
+		newErrIs  require.ErrorAssertionFunc
+		execErrIs require.ErrorAssertionFunc
+		results   Results
+	}{
+		{
+			name:      "rate on series",
+			expr:      "rate($A)",
+			vars:      Vars{"A": series},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(10, 0), float64Pointer(0.2)},
+					tp{time.Unix(20, 0), float64Pointer(0.2)},
+					tp{time.Unix(30, 0), nil}),
+			),
+		},
+		{
+			name: "delta on unsorted series",
+			expr: "delta($A)",
+			vars: Vars{"A": resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(10, 0), float64Pointer(3)},
+					tp{time.Unix(0, 0), float64Pointer(1)},
+					tp{time.Unix(20, 0), float64Pointer(2)}),
+			)},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(10, 0), float64Pointer(2)},
+					tp{time.Unix(20, 0), float64Pointer(-1)}),
+			),
+		},
+		{
+			name:      "cumsum on series",
+			expr:      "cumsum($A)",
+			vars:      Vars{"A": series},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), float64Pointer(1)},
+					tp{time.Unix(10, 0), float64Pointer(4)},
+					tp{time.Unix(20, 0), float64Pointer(9)},
+					tp{time.Unix(30, 0), nil}),
+			),
+		},
+		{
+			name:      "shift on series",
+			expr:      "shift($A, 1h)",
+			vars:      Vars{"A": series},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(3600, 0), float64Pointer(1)},
+					tp{time.Unix(3610, 0), float64Pointer(3)},
+					tp{time.Unix(3620, 0), float64Pointer(5)},
+					tp{time.Unix(3630, 0), nil}),
+			),
+		},
+		{
+			name: "week over week comparison",
+			expr: "$A - shift($A, 1w)",
+			vars: Vars{"A": resultValuesNoErr(
+				makeSeries("", nil,
+					tp{monday, float64Pointer(4)},
+					tp{monday.AddDate(0, 0, 7), float64Pointer(10)}),
+			)},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{monday.AddDate(0, 0, 7), float64Pointer(6)}),
+			),
+		},
+		{
+			name:      "moving_avg on series",
+			expr:      "moving_avg($A, 15s)",
+			vars:      Vars{"A": series},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), float64Pointer(1)},
+					tp{time.Unix(10, 0), float64Pointer(2)},
+					tp{time.Unix(20, 0), float64Pointer(4)},
+					tp{time.Unix(30, 0), float64Pointer(5)}),
+			),
+		},
+		{
+			name: "clamp_min on series",
+			expr: "clamp_min($A, 2)",
+			vars: Vars{"A": resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), float64Pointer(1)},
+					tp{time.Unix(10, 0), float64Pointer(3)}),
+			)},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), float64Pointer(2)},
+					tp{time.Unix(10, 0), float64Pointer(3)}),
+			),
+		},
+		{
+			name: "clamp_max on number",
+			expr: "clamp_max($A, 2)",
+			vars: Vars{
+				"A": resultValuesNoErr(makeNumber("", nil, float64Pointer(7))),
+			},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results:   resultValuesNoErr(makeNumber("", nil, float64Pointer(2))),
+		},
+		{
+			name: "hour and day_of_week on series",
+			expr: "hour($A) * 10 + day_of_week($A)",
+			vars: Vars{"A": resultValuesNoErr(
+				makeSeries("", nil, tp{monday, nil}),
+			)},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", nil, tp{monday, float64Pointer(131)}),
+			),
+		},
+		{
+			name: "rate on number should error",
+			expr: "rate($A)",
+			vars: Vars{
+				"A": resultValuesNoErr(makeNumber("", nil, float64Pointer(7))),
+			},
+			newErrIs:  require.NoError,
+			execErrIs: require.Error,
+		},
+		{
+			name:     "rate on scalar should error",
+			expr:     "rate(1)",
+			newErrIs: require.Error,
+		},
+		{
+			name:     "moving_avg with zero window should error",
+			expr:     "moving_avg($A, 0s)",
+			newErrIs: require.Error,
+		},
+		{
+			name:     "shift with invalid duration should error",
+			expr:     "shift($A, 1x)",
+			newErrIs: require.Error,
+		},
+		{
+			name:     "duration outside of function should error",
+			expr:     "$A + 1h",
+			newErrIs: require.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e != nil {
+				res, err := e.Execute("", tt.vars, tracing.InitializeTracerForTest())
+				tt.execErrIs(t, err)
+				if err == nil {
+					require.Equal(t, tt.results, res)
+				}
+			}
+		})
+	}
+}
@@ -56,8 +56,9 @@ const (
 	itemRightParen
 	itemString
 	itemFunc
-	itemVar // e.g. $A
-	itemPow // '**'
+	itemVar      // e.g. $A
+	itemPow      // '**'
+	itemDuration // e.g. 1h
 )
 
 const eof = -1
@@ -210,10 +211,28 @@ func lexNumber(l *lexer) stateFn {
 	if !l.scanNumber() {
 		return l.errorf("bad number syntax: %q", l.input[l.start:l.pos])
 	}
+	if unicode.IsLetter(l.peek()) {
+		return lexDuration
+	}
 	l.emit(itemNumber)
 	return lexItem
 }
 
+// lexDuration scans the unit of a duration such as 5m, 1h30m or 1w. The number
+// has already been scanned. The duration is validated by the parser.
+func lexDuration(l *lexer) stateFn {
+	for {
+		switch r := l.next(); {
+		case unicode.IsLetter(r) || isNumber(r):
+			// absorb
+		default:
+			l.backup()
+			l.emit(itemDuration)
+			return lexItem
+		}
+	}
+}
+
 func (l *lexer) scanNumber() bool {
 	// Is it hex?
 	digits := "0123456789"
@@ -276,7 +295,7 @@ func lexSymbol(l *lexer) stateFn {
 func lexFunc(l *lexer) stateFn {
 	for {
 		switch r := l.next(); {
-		case unicode.IsLetter(r) || r == '_':
+		case isVarchar(r):
 			// absorb
 		default:
 			l.backup()
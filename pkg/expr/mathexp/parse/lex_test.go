@@ -33,6 +33,9 @@ var itemName = map[itemType]string{
 	itemRightParen: ")",
 	itemString:     "string",
 	itemFunc:       "func",
+	itemVar:        "var",
+	itemPow:        "**",
+	itemDuration:   "duration",
 }
 
 func (i itemType) String() string {
@@ -97,6 +100,23 @@ var lexTests = []lexTest{
 		{itemNumber, 0, "1.2e-4"},
 		tEOF,
 	}},
+	{"durations", "1s 5m 1h30m 1.5h 2w", []item{
+		{itemDuration, 0, "1s"},
+		{itemDuration, 0, "5m"},
+		{itemDuration, 0, "1h30m"},
+		{itemDuration, 0, "1.5h"},
+		{itemDuration, 0, "2w"},
+		tEOF,
+	}},
+	{"func with duration", "shift($A, 1h)", []item{
+		{itemFunc, 0, "shift"},
+		{itemLeftParen, 0, "("},
+		{itemVar, 0, "$A"},
+		{itemComma, 0, ","},
+		{itemDuration, 0, "1h"},
+		{itemRightParen, 0, ")"},
+		tEOF,
+	}},
 	{"curly brace var", "${My Var}", []item{
 		{itemVar, 0, "${My Var}"},
 		tEOF,
@@ -9,6 +9,10 @@ package parse
 import (
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 )
 
 // A Node is an element in the parse tree. The interface is trivial.
@@ -62,6 +66,8 @@ const (
 	NodeNumber
 	// NodeVar is variable: $A
 	NodeVar
+	// NodeDuration is a duration constant: 1h
+	NodeDuration
 )
 
 // String returns the string representation of the NodeType
@@ -79,6 +85,8 @@ func (t NodeType) String() string {
 		return "NodeNumber"
 	case NodeVar:
 		return "NodeVar"
+	case NodeDuration:
+		return "NodeDuration"
 	default:
 		return "NodeUnknown"
 	}
@@ -293,6 +301,42 @@ func (s *StringNode) Return() ReturnType {
 	return TypeString
 }
 
+// DurationNode holds a duration constant such as 1h or 1w.
+type DurationNode struct {
+	NodeType
+	Pos
+	Duration time.Duration // The parsed duration.
+	Text     string        // The original textual representation from the input.
+}
+
+func newDuration(pos Pos, text string) (*DurationNode, error) {
+	d, err := gtime.ParseDuration(text)
+	if err != nil {
+		return nil, fmt.Errorf("illegal duration syntax: %q", text)
+	}
+	return &DurationNode{NodeType: NodeDuration, Pos: pos, Duration: d, Text: text}, nil
+}
+
+// String returns the string representation of the DurationNode so it fulfills the Node interface.
+func (d *DurationNode) String() string {
+	return d.Text
+}
+
+// StringAST returns the string representation of abstract syntax tree of the DurationNode so it fulfills the Node interface.
+func (d *DurationNode) StringAST() string {
+	return d.String()
+}
+
+// Check performs parse time checking on the DurationNode so it fulfills the Node interface.
+func (d *DurationNode) Check(*Tree) error {
+	return nil
+}
+
+// Return returns the result type of the DurationNode so it fulfills the Node interface.
+func (d *DurationNode) Return() ReturnType {
+	return TypeDuration
+}
+
 // BinaryNode holds two arguments and an operator.
 type BinaryNode struct {
 	NodeType
@@ -300,6 +344,7 @@ type BinaryNode struct {
 	Args     [2]Node
 	Operator item
 	OpStr    string
+	Matching *VectorMatching // nil if the labels of the arguments are matched by the default union.
 }
 
 func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
@@ -308,11 +353,17 @@ func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
 
 // String returns the string representation of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) String() string {
+	if b.Matching != nil {
+		return fmt.Sprintf("%s %s %s %s", b.Args[0], b.Operator.val, b.Matching, b.Args[1])
+	}
 	return fmt.Sprintf("%s %s %s", b.Args[0], b.Operator.val, b.Args[1])
 }
 
 // StringAST returns the string representation of abstract syntax tree of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) StringAST() string {
+	if b.Matching != nil {
+		return fmt.Sprintf("%s %s(%s, %s)", b.Operator.val, b.Matching, b.Args[0], b.Args[1])
+	}
 	return fmt.Sprintf("%s(%s, %s)", b.Operator.val, b.Args[0], b.Args[1])
 }
 
@@ -331,6 +382,60 @@ func (b *BinaryNode) Return() ReturnType {
 	return t0
 }
 
+// Keywords of the vector matching of a binary operator.
+const (
+	matchOn         = "on"
+	matchIgnoring   = "ignoring"
+	matchGroupLeft  = "group_left"
+	matchGroupRight = "group_right"
+)
+
+// MatchCardinality is the cardinality of the vector matching of a binary operator.
+type MatchCardinality int
+
+const (
+	// CardOneToOne matches each value on the left with at most one value on the right.
+	CardOneToOne MatchCardinality = iota
+	// CardManyToOne matches many values on the left with one value on the right (group_left).
+	CardManyToOne
+	// CardOneToMany matches one value on the left with many values on the right (group_right).
+	CardOneToMany
+)
+
+// VectorMatching describes how the labelled values on both sides of a binary operator are matched,
+// e.g. $A / on(service) group_left $B.
+type VectorMatching struct {
+	// On is true if only Labels are used for matching. Otherwise, all labels except Labels are used.
+	On     bool
+	Labels []string
+	Card   MatchCardinality
+	// Include is the list of labels copied from the "one" side to the result of a many-to-one or one-to-many matching.
+	Include []string
+}
+
+// String returns the string representation of the VectorMatching as written in the expression.
+func (m *VectorMatching) String() string {
+	list := func(labels []string) string {
+		return "(" + strings.Join(labels, ", ") + ")"
+	}
+	s := matchIgnoring + list(m.Labels)
+	if m.On {
+		s = matchOn + list(m.Labels)
+	}
+	switch m.Card {
+	case CardManyToOne:
+		s += " " + matchGroupLeft
+	case CardOneToMany:
+		s += " " + matchGroupRight
+	default:
+		return s
+	}
+	if len(m.Include) > 0 {
+		s += list(m.Include)
+	}
+	return s
+}
+
 // UnaryNode holds one argument and an operator.
 type UnaryNode struct {
 	NodeType
@@ -380,7 +485,7 @@ func Walk(n Node, f func(Node)) {
 		for _, a := range n.Args {
 			Walk(a, f)
 		}
-	case *ScalarNode, *StringNode:
+	case *ScalarNode, *StringNode, *DurationNode:
 		// Ignore since these node types have no sub nodes.
 	case *UnaryNode:
 		Walk(n.Arg, f)
@@ -407,6 +512,8 @@ const (
 	TypeNoData
 	// TypeTableData is a tabular data response.
 	TypeTableData
+	// TypeDuration is a single duration.
+	TypeDuration
 )
 
 // String returns a string representation of the ReturnType.
@@ -426,6 +533,8 @@ func (f ReturnType) String() string {
 		return "noData"
 	case TypeTableData:
 		return "tableData"
+	case TypeDuration:
+		return "duration"
 	default:
 		return "unknown"
 	}
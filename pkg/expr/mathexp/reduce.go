@@ -3,28 +3,44 @@ package mathexp
 import (
 	"fmt"
 	"math"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
 
 type ReducerFunc = func(fv *Float64Field) *float64
 
+// SeriesReducerFunc is a reduction function that, unlike ReducerFunc, also has access to the timestamps of the values.
+type SeriesReducerFunc = func(s Series) *float64
+
 // The reducer function
 // +enum
 type ReducerID string
 
 const (
-	ReducerSum   ReducerID = "sum"
-	ReducerMean  ReducerID = "mean"
-	ReducerMin   ReducerID = "min"
-	ReducerMax   ReducerID = "max"
-	ReducerCount ReducerID = "count"
-	ReducerLast  ReducerID = "last"
+	ReducerSum          ReducerID = "sum"
+	ReducerMean         ReducerID = "mean"
+	ReducerMin          ReducerID = "min"
+	ReducerMax          ReducerID = "max"
+	ReducerCount        ReducerID = "count"
+	ReducerLast         ReducerID = "last"
+	ReducerMedian       ReducerID = "median"
+	ReducerStdDev       ReducerID = "stddev"
+	ReducerFirst        ReducerID = "first"
+	ReducerDelta        ReducerID = "delta"
+	ReducerRate         ReducerID = "rate"
+	ReducerCountNonNull ReducerID = "count_non_null"
 )
 
-// GetSupportedReduceFuncs returns collection of supported function names
+// reducerPercentilePrefix is the prefix of percentile reducers. The prefix is followed by the percentile, e.g. p90 or p99.9.
+const reducerPercentilePrefix = "p"
+
+// GetSupportedReduceFuncs returns collection of supported function names.
+// Percentile reducers are not listed because any percentile between 0 and 100 is supported.
 func GetSupportedReduceFuncs() []ReducerID {
-	return []ReducerID{ReducerSum, ReducerMean, ReducerMin, ReducerMax, ReducerCount, ReducerLast}
+	return []ReducerID{ReducerSum, ReducerMean, ReducerMin, ReducerMax, ReducerCount, ReducerLast, ReducerMedian, ReducerStdDev, ReducerFirst, ReducerDelta, ReducerRate, ReducerCountNonNull}
 }
 
 func Sum(fv *Float64Field) *float64 {
@@ -98,6 +114,127 @@ func Last(fv *Float64Field) *float64 {
 	return fv.GetValue(fv.Len() - 1)
 }
 
+// First returns the first value. It is the counterpart of Last.
+func First(fv *Float64Field) *float64 {
+	var f float64
+	if fv.Len() == 0 {
+		f = math.NaN()
+		return &f
+	}
+	return fv.GetValue(0)
+}
+
+// Median returns the middle value of the sorted values, or the mean of the two middle values if the number of values is even.
+func Median(fv *Float64Field) *float64 {
+	return Percentile(50)(fv)
+}
+
+// Percentile returns a reduction function that calculates the p-th percentile of the values, where p is between 0 and 100.
+// The percentile is linearly interpolated between the closest ranks.
+func Percentile(p float64) ReducerFunc {
+	return func(fv *Float64Field) *float64 {
+		values, ok := numbers(fv)
+		if !ok || len(values) == 0 {
+			nan := math.NaN()
+			return &nan
+		}
+		sort.Float64s(values)
+		rank := p / 100 * float64(len(values)-1)
+		lower := int(math.Floor(rank))
+		upper := int(math.Ceil(rank))
+		f := values[lower] + (values[upper]-values[lower])*(rank-float64(lower))
+		return &f
+	}
+}
+
+// StdDev returns the population standard deviation of the values.
+func StdDev(fv *Float64Field) *float64 {
+	values, ok := numbers(fv)
+	if !ok || len(values) == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	var variance float64
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	f := math.Sqrt(variance / float64(len(values)))
+	return &f
+}
+
+// Delta returns the difference between the last and the first values.
+func Delta(fv *Float64Field) *float64 {
+	values, ok := numbers(fv)
+	if !ok || len(values) == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	f := values[len(values)-1] - values[0]
+	return &f
+}
+
+// CountNonNull returns the number of values that are neither null nor NaN.
+func CountNonNull(fv *Float64Field) *float64 {
+	var f float64
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v != nil && !math.IsNaN(*v) {
+			f++
+		}
+	}
+	return &f
+}
+
+// Rate returns the per-second rate of change between the first and the last points of the series.
+// It returns NaN if the series has less than two points or if all points have the same timestamp.
+func Rate(s Series) *float64 {
+	nan := math.NaN()
+	if s.Len() < 2 {
+		return &nan
+	}
+	fVec := s.Frame.Fields[seriesTypeValIdx]
+	floatField := Float64Field(*fVec)
+	delta := Delta(&floatField)
+	seconds := s.GetTime(s.Len() - 1).Sub(s.GetTime(0)).Seconds()
+	if math.IsNaN(*delta) || seconds <= 0 {
+		return &nan
+	}
+	f := *delta / seconds
+	return &f
+}
+
+// numbers returns the values of the field. Returns false if any of the values is either null or NaN.
+func numbers(fv *Float64Field) ([]float64, bool) {
+	result := make([]float64, 0, fv.Len())
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v == nil || math.IsNaN(*v) {
+			return nil, false
+		}
+		result = append(result, *v)
+	}
+	return result, true
+}
+
+// Percentile returns the percentile of a percentile reducer, e.g. 99.9 for p99.9.
+// Returns false if the reducer is not a percentile reducer.
+func (r ReducerID) Percentile() (float64, bool) {
+	s, ok := strings.CutPrefix(string(r), reducerPercentilePrefix)
+	if !ok {
+		return 0, false
+	}
+	p, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(p) || p < 0 || p > 100 {
+		return 0, false
+	}
+	return p, true
+}
+
 func GetReduceFunc(rFunc ReducerID) (ReducerFunc, error) {
 	switch rFunc {
 	case ReducerSum:
@@ -112,11 +249,41 @@ func GetReduceFunc(rFunc ReducerID) (ReducerFunc, error) {
 		return Count, nil
 	case ReducerLast:
 		return Last, nil
+	case ReducerMedian:
+		return Median, nil
+	case ReducerStdDev:
+		return StdDev, nil
+	case ReducerFirst:
+		return First, nil
+	case ReducerDelta:
+		return Delta, nil
+	case ReducerCountNonNull:
+		return CountNonNull, nil
 	default:
+		if p, ok := rFunc.Percentile(); ok {
+			return Percentile(p), nil
+		}
 		return nil, fmt.Errorf("reduction %v not implemented", rFunc)
 	}
 }
 
+// GetSeriesReduceFunc returns the reduction function for series. Unlike GetReduceFunc, it supports reducers that depend
+// on the timestamps of the values, such as rate.
+func GetSeriesReduceFunc(rFunc ReducerID) (SeriesReducerFunc, error) {
+	if rFunc == ReducerRate {
+		return Rate, nil
+	}
+	reduceFunc, err := GetReduceFunc(rFunc)
+	if err != nil {
+		return nil, err
+	}
+	return func(s Series) *float64 {
+		fVec := s.Frame.Fields[seriesTypeValIdx]
+		floatField := Float64Field(*fVec)
+		return reduceFunc(&floatField)
+	}, nil
+}
+
 // Reduce turns the Series into a Number based on the given reduction function
 // if ReduceMapper is defined it applies it to the provided series and performs reduction of the resulting series.
 // Otherwise, the reduction operation is done against the original series.
@@ -131,13 +298,11 @@ func (s Series) Reduce(refID string, rFunc ReducerID, mapper ReduceMapper) (Numb
 	if mapper != nil {
 		series = mapSeries(s, mapper)
 	}
-	fVec := series.Frame.Fields[seriesTypeValIdx]
-	floatField := Float64Field(*fVec)
-	reduceFunc, err := GetReduceFunc(rFunc)
+	reduceFunc, err := GetSeriesReduceFunc(rFunc)
 	if err != nil {
 		return number, fmt.Errorf("invalid expression '%s': %w", refID, err)
 	}
-	f = reduceFunc(&floatField)
+	f = reduceFunc(series)
 	if f != nil && mapper != nil {
 		f = mapper.MapOutput(f)
 	}
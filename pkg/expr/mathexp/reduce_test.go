@@ -179,6 +179,131 @@ func TestSeriesReduce(t *testing.T) {
 			resultsIs:   require.Equal,
 			results:     resultValuesNoErr(makeNumber("", nil, nil)),
 		},
+		{
+			name:        "median series",
+			red:         "median",
+			varToReduce: "A",
+			vars:        aSeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, float64Pointer(1.5))),
+		},
+		{
+			name:        "median series with a nil value",
+			red:         "median",
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, NaN)),
+		},
+		{
+			name:        "p90 series",
+			red:         "p90",
+			varToReduce: "A",
+			vars:        aSeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, float64Pointer(1.9))),
+		},
+		{
+			name:        "p0 series",
+			red:         "p0",
+			varToReduce: "A",
+			vars:        aSeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, float64Pointer(1))),
+		},
+		{
+			name:        "stddev series",
+			red:         "stddev",
+			varToReduce: "A",
+			vars:        aSeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, float64Pointer(0.5))),
+		},
+		{
+			name:        "stddev empty series",
+			red:         "stddev",
+			varToReduce: "A",
+			vars:        seriesEmpty,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, NaN)),
+		},
+		{
+			name:        "first series",
+			red:         "first",
+			varToReduce: "A",
+			vars:        aSeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, float64Pointer(2))),
+		},
+		{
+			name:        "first empty series",
+			red:         "first",
+			varToReduce: "A",
+			vars:        seriesEmpty,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, NaN)),
+		},
+		{
+			name:        "delta series",
+			red:         "delta",
+			varToReduce: "A",
+			vars:        aSeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, float64Pointer(-1))),
+		},
+		{
+			name:        "delta series with a nil value",
+			red:         "delta",
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, NaN)),
+		},
+		{
+			name:        "rate series",
+			red:         "rate",
+			varToReduce: "A",
+			vars:        aSeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, float64Pointer(-0.2))),
+		},
+		{
+			name:        "rate empty series",
+			red:         "rate",
+			varToReduce: "A",
+			vars:        seriesEmpty,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, NaN)),
+		},
+		{
+			name:        "count_non_null series with a nil value",
+			red:         "count_non_null",
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, float64Pointer(1))),
+		},
+		{
+			name:        "p101 reduction will error",
+			red:         "p101",
+			varToReduce: "A",
+			vars:        aSeries,
+			errIs:       require.Error,
+			resultsIs:   require.Equal,
+		},
 	}
 
 	for _, tt := range tests {
@@ -278,6 +403,34 @@ func TestSeriesReduceDropNN(t *testing.T) {
 			vars:        seriesWithNil,
 			results:     resultValuesNoErr(makeNumber("", nil, float64Pointer(1))),
 		},
+		{
+			name:        "DropNN: median series with a nil value",
+			red:         "median",
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			results:     resultValuesNoErr(makeNumber("", nil, float64Pointer(2))),
+		},
+		{
+			name:        "DropNN: stddev series with a nil value",
+			red:         "stddev",
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			results:     resultValuesNoErr(makeNumber("", nil, float64Pointer(0))),
+		},
+		{
+			name:        "DropNN: rate series with a nil value",
+			red:         "rate",
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			results:     resultValuesNoErr(makeNumber("", nil, nil)),
+		},
+		{
+			name:        "DropNN: count_non_null series that becomes empty after filtering non-number",
+			red:         "count_non_null",
+			varToReduce: "A",
+			vars:        seriesNonNumbers,
+			results:     resultValuesNoErr(makeNumber("", nil, float64Pointer(0))),
+		},
 	}
 
 	for _, tt := range tests {
@@ -3,8 +3,6 @@ package mathexp
 import (
 	"fmt"
 	"time"
-
-	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
 
 // The upsample function
@@ -29,12 +27,15 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler Reduc
 		return s, fmt.Errorf("the series cannot be sampled further; the time range is shorter than the interval")
 	}
 	resampled := NewSeries(refID, s.GetLabels(), newSeriesLength+1)
+	// the reducer is validated only when downsampling is needed.
+	reduceFunc, reduceErr := GetSeriesReduceFunc(downsampler)
 	bookmark := 0
 	var lastSeen *float64
 	idx := 0
 	t := from
 	for !t.After(to) && idx <= newSeriesLength {
 		vals := make([]*float64, 0)
+		times := make([]time.Time, 0)
 		sIdx := bookmark
 		for {
 			if sIdx == s.Len() {
@@ -48,6 +49,7 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler Reduc
 			sIdx++
 			lastSeen = v
 			vals = append(vals, v)
+			times = append(times, st)
 		}
 		var value *float64
 		if len(vals) == 0 { // upsampling
@@ -72,24 +74,14 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler Reduc
 		} else if len(vals) == 1 {
 			value = vals[0]
 		} else { // downsampling
-			fVec := data.NewField("", s.GetLabels(), vals)
-			ff := Float64Field(*fVec)
-			var tmp *float64
-			switch downsampler {
-			case ReducerSum:
-				tmp = Sum(&ff)
-			case ReducerMean:
-				tmp = Avg(&ff)
-			case ReducerMin:
-				tmp = Min(&ff)
-			case ReducerMax:
-				tmp = Max(&ff)
-			case ReducerLast:
-				tmp = Last(&ff)
-			default:
+			if reduceErr != nil {
 				return s, fmt.Errorf("downsampling %v not implemented", downsampler)
 			}
-			value = tmp
+			window := NewSeries("", s.GetLabels(), len(vals))
+			for i := range vals {
+				window.SetPoint(i, times[i], vals[i])
+			}
+			value = reduceFunc(window)
 		}
 		resampled.SetPoint(idx, t, value)
 		t = t.Add(interval)
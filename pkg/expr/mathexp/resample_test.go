@@ -277,6 +277,81 @@ func TestResampleSeries(t *testing.T) {
 				time.Unix(9, 0), float64Pointer(0),
 			}),
 		},
+		{
+			name:        "resample series: downsampling (rate / fillna)",
+			interval:    time.Second * 5,
+			downsampler: "rate",
+			upsampler:   "fillna",
+			timeRange: backend.TimeRange{
+				From: time.Unix(0, 0),
+				To:   time.Unix(16, 0),
+			},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(2, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(4, 0), float64Pointer(3),
+			}, tp{
+				time.Unix(7, 0), float64Pointer(4),
+			}, tp{
+				time.Unix(9, 0), float64Pointer(2),
+			}),
+			series: makeSeries("", nil, tp{
+				time.Unix(0, 0), nil,
+			}, tp{
+				time.Unix(5, 0), float64Pointer(0.5),
+			}, tp{
+				time.Unix(10, 0), float64Pointer(-1),
+			}, tp{
+				time.Unix(15, 0), nil,
+			}),
+		},
+		{
+			name:        "resample series: downsampling (p90 / fillna)",
+			interval:    time.Second * 5,
+			downsampler: "p90",
+			upsampler:   "fillna",
+			timeRange: backend.TimeRange{
+				From: time.Unix(0, 0),
+				To:   time.Unix(11, 0),
+			},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(1, 0), float64Pointer(1),
+			}, tp{
+				time.Unix(2, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(3, 0), float64Pointer(3),
+			}, tp{
+				time.Unix(4, 0), float64Pointer(4),
+			}, tp{
+				time.Unix(5, 0), float64Pointer(5),
+			}, tp{
+				time.Unix(7, 0), float64Pointer(4),
+			}, tp{
+				time.Unix(9, 0), float64Pointer(2),
+			}),
+			series: makeSeries("", nil, tp{
+				time.Unix(0, 0), nil,
+			}, tp{
+				time.Unix(5, 0), float64Pointer(4.6),
+			}, tp{
+				time.Unix(10, 0), float64Pointer(3.8),
+			}),
+		},
+		{
+			name:        "resample series: downsampling (unknown / fillna)",
+			interval:    time.Second * 5,
+			downsampler: "unknown",
+			upsampler:   "fillna",
+			timeRange: backend.TimeRange{
+				From: time.Unix(0, 0),
+				To:   time.Unix(11, 0),
+			},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(1, 0), float64Pointer(1),
+			}, tp{
+				time.Unix(2, 0), float64Pointer(2),
+			}),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
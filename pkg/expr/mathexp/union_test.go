@@ -5,7 +5,9 @@ import (
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_union(t *testing.T) {
@@ -300,3 +302,162 @@ func Test_union(t *testing.T) {
 		})
 	}
 }
+
+// matchedNumber is the labels and value of a Number resulting from a binary operation with vector matching.
+type matchedNumber struct {
+	labels data.Labels
+	value  float64
+}
+
+func TestVectorMatching(t *testing.T) {
+	requests := resultValuesNoErr(
+		makeNumber("", data.Labels{"service": "api", "region": "eu"}, float64Pointer(100)),
+		makeNumber("", data.Labels{"service": "web", "region": "eu"}, float64Pointer(40)),
+	)
+	errors := resultValuesNoErr(
+		makeNumber("", data.Labels{"service": "api", "code": "500"}, float64Pointer(5)),
+		makeNumber("", data.Labels{"service": "api", "code": "503"}, float64Pointer(10)),
+		makeNumber("", data.Labels{"service": "db", "code": "500"}, float64Pointer(1)),
+	)
+	var tests = []struct {
+		name      string
+		expr      string
+		vars      Vars
+		newErrIs  require.ErrorAssertionFunc
+		execErrIs require.ErrorAssertionFunc
+		results   []matchedNumber
+	}{
+		{
+			name: "on matches only the listed labels",
+			expr: "$A / on(service) $B",
+			vars: Vars{
+				"A": resultValuesNoErr(
+					makeNumber("", data.Labels{"service": "api", "instance": "1"}, float64Pointer(10)),
+					makeNumber("", data.Labels{"service": "web", "instance": "2"}, float64Pointer(4)),
+				),
+				"B": requests,
+			},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: []matchedNumber{
+				{data.Labels{"service": "api"}, 0.1},
+				{data.Labels{"service": "web"}, 0.1},
+			},
+		},
+		{
+			name: "ignoring matches all but the listed labels",
+			expr: `$A / ignoring(code, "region") $B`,
+			vars: Vars{
+				"A": resultValuesNoErr(
+					makeNumber("", data.Labels{"service": "api", "code": "500"}, float64Pointer(5)),
+				),
+				"B": requests,
+			},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: []matchedNumber{
+				{data.Labels{"service": "api"}, 0.05},
+			},
+		},
+		{
+			name:      "group_left matches many on the left with one on the right",
+			expr:      "$A / on(service) group_left $B",
+			vars:      Vars{"A": errors, "B": requests},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: []matchedNumber{
+				{data.Labels{"service": "api", "code": "500"}, 0.05},
+				{data.Labels{"service": "api", "code": "503"}, 0.1},
+			},
+		},
+		{
+			name:      "group_left copies the included labels from the right",
+			expr:      "$A / on(service) group_left(region) $B",
+			vars:      Vars{"A": errors, "B": requests},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: []matchedNumber{
+				{data.Labels{"service": "api", "code": "500", "region": "eu"}, 0.05},
+				{data.Labels{"service": "api", "code": "503", "region": "eu"}, 0.1},
+			},
+		},
+		{
+			name:      "group_right matches one on the left with many on the right",
+			expr:      "$B < on(service) group_right $A * 10",
+			vars:      Vars{"A": errors, "B": requests},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: []matchedNumber{
+				{data.Labels{"service": "api", "code": "500"}, 0},
+				{data.Labels{"service": "api", "code": "503"}, 0},
+			},
+		},
+		{
+			name:      "scalars are combined with all values",
+			expr:      "$A * on(service) 2",
+			vars:      Vars{"A": requests},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: []matchedNumber{
+				{data.Labels{"service": "api", "region": "eu"}, 200},
+				{data.Labels{"service": "web", "region": "eu"}, 80},
+			},
+		},
+		{
+			name:      "one-to-one matching with duplicates should error",
+			expr:      "$A / on(service) $B",
+			vars:      Vars{"A": errors, "B": requests},
+			newErrIs:  require.NoError,
+			execErrIs: require.Error,
+		},
+		{
+			name:      "group_left with duplicates on the right should error",
+			expr:      "$B / on(service) group_left $A",
+			vars:      Vars{"A": errors, "B": requests},
+			newErrIs:  require.NoError,
+			execErrIs: require.Error,
+		},
+		{
+			name:      "group_left with non-unique results should error",
+			expr:      "$A / ignoring(code) group_left(code) $B",
+			vars:      Vars{"A": errors, "B": resultValuesNoErr(makeNumber("", data.Labels{"service": "api"}, float64Pointer(1)))},
+			newErrIs:  require.NoError,
+			execErrIs: require.Error,
+		},
+		{
+			name:      "group_left without on or ignoring should error",
+			expr:      "$A / group_left $B",
+			newErrIs:  require.Error,
+			execErrIs: require.NoError,
+		},
+		{
+			name:      "unclosed label list should error",
+			expr:      "$A / on(service $B",
+			newErrIs:  require.Error,
+			execErrIs: require.NoError,
+		},
+		{
+			name:      "label in both on and group_left should error",
+			expr:      "$A / on(service) group_left(service) $B",
+			newErrIs:  require.Error,
+			execErrIs: require.NoError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e != nil {
+				res, err := e.Execute("", tt.vars, tracing.InitializeTracerForTest())
+				tt.execErrIs(t, err)
+				if err == nil {
+					actual := make([]matchedNumber, 0, len(res.Values))
+					for _, v := range res.Values {
+						actual = append(actual, matchedNumber{v.GetLabels(), *v.(Number).GetFloat64Value()})
+					}
+					require.Equal(t, tt.results, actual)
+				}
+			}
+		})
+	}
+}
@@ -27,6 +27,12 @@ const (
 
 	// SQL query via DuckDB
 	QueryTypeSQL QueryType = "sql"
+
+	// Detect anomalies in query results
+	QueryTypeAnomaly QueryType = "anomaly"
+
+	// Rename, drop or aggregate by labels
+	QueryTypeLabels QueryType = "labels"
 )
 
 type MathQuery struct {
@@ -77,6 +83,36 @@ type SQLExpression struct {
 	Expression string `json:"expression" jsonschema:"minLength=1,example=SELECT * FROM A LIMIT 1"`
 }
 
+type AnomalyQuery struct {
+	// Reference to single query result
+	Expression string `json:"expression" jsonschema:"minLength=1,example=$A"`
+
+	// The anomaly detection algorithm
+	Algorithm AnomalyAlgorithm `json:"algorithm"`
+
+	// The result of the expression, defaults to anomalous
+	Output AnomalyOutput `json:"output,omitempty"`
+
+	// The width of the bands as the number of deviations from the baseline, defaults to 3
+	Deviations *float64 `json:"deviations,omitempty"`
+
+	// The length of the season, only valid for holt_winters
+	Seasonality string `json:"seasonality,omitempty" jsonschema:"example=1d,example=1w"`
+
+	// The smoothing factors of holt_winters for the level, trend and season, between 0 and 1
+	Alpha *float64 `json:"alpha,omitempty"`
+	Beta  *float64 `json:"beta,omitempty"`
+	Gamma *float64 `json:"gamma,omitempty"`
+}
+
+type LabelsQuery struct {
+	// Reference to single query result
+	Expression string `json:"expression" jsonschema:"minLength=1,example=$A"`
+
+	// The operations to apply in order
+	Operations []LabelOperation `json:"operations"`
+}
+
 //-------------------------------
 // Non-query commands
 //-------------------------------
@@ -158,6 +158,10 @@ func buildCMDNode(rn *rawNode, toggles featuremgmt.FeatureToggles) (*CMDNode, er
 		node.Command, err = UnmarshalThresholdCommand(rn, toggles)
 	case TypeSQL:
 		node.Command, err = UnmarshalSQLCommand(rn)
+	case TypeAnomaly:
+		node.Command, err = UnmarshalAnomalyCommand(rn)
+	case TypeLabels:
+		node.Command, err = UnmarshalLabelsCommand(rn)
 	default:
 		return nil, fmt.Errorf("expression command type '%v' in expression '%v' not implemented", commandType, rn.RefID)
 	}
@@ -261,10 +265,17 @@ func executeDSNodesGrouped(ctx context.Context, now time.Time, vars mathexp.Vars
 		byDS[k] = append(byDS[k], node)
 	}
 
+	stats := executionStatsFromContext(ctx)
 	for _, nodeGroup := range byDS {
 		func() {
 			ctx, span := s.tracer.Start(ctx, "SSE.ExecuteDatasourceQuery")
 			defer span.End()
+			start := time.Now()
+			defer func() {
+				for _, dn := range nodeGroup {
+					stats.add(dn.refID, TypeDatasourceNode, time.Since(start), vars[dn.refID])
+				}
+			}()
 			firstNode := nodeGroup[0]
 			pCtx, err := s.pCtxProvider.GetWithDataSource(ctx, firstNode.datasource.Type, firstNode.request.User, firstNode.datasource)
 			if err != nil {
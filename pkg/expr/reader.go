@@ -131,6 +131,28 @@ func (h *ExpressionQueryReader) ReadQuery(
 			eq.Command, err = NewSQLCommand(common.RefID, q.Expression)
 		}
 
+	case QueryTypeAnomaly:
+		q := &AnomalyQuery{}
+		err = iter.ReadVal(q)
+		if err == nil {
+			referenceVar, err = getReferenceVar(q.Expression, common.RefID)
+		}
+		if err == nil {
+			eq.Properties = q
+			eq.Command, err = newAnomalyCommandFromQuery(common.RefID, referenceVar, q)
+		}
+
+	case QueryTypeLabels:
+		q := &LabelsQuery{}
+		err = iter.ReadVal(q)
+		if err == nil {
+			referenceVar, err = getReferenceVar(q.Expression, common.RefID)
+		}
+		if err == nil {
+			eq.Properties = q
+			eq.Command, err = NewLabelsCommand(common.RefID, referenceVar, q.Operations)
+		}
+
 	case QueryTypeThreshold:
 		q := &ThresholdQuery{}
 		err = iter.ReadVal(q)
@@ -0,0 +1,60 @@
+package expr
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+// NodeStats are the execution statistics of a node of a pipeline.
+type NodeStats struct {
+	RefID    string
+	NodeType NodeType
+	// Duration is the execution time of the node. The queries to the same data source are executed in one request when
+	// they are grouped by data source, and have the duration of the request.
+	Duration time.Duration
+	// Series is the number of series or numbers of the result of the node.
+	Series int
+}
+
+// ExecutionStats collects the statistics of the nodes executed by the pipelines of a context.
+type ExecutionStats struct {
+	mtx   sync.Mutex
+	nodes []NodeStats
+}
+
+type executionStatsContextKey struct{}
+
+// WithExecutionStats returns a context in which the pipelines add the statistics of their nodes to stats.
+func WithExecutionStats(ctx context.Context, stats *ExecutionStats) context.Context {
+	return context.WithValue(ctx, executionStatsContextKey{}, stats)
+}
+
+func executionStatsFromContext(ctx context.Context) *ExecutionStats {
+	stats, _ := ctx.Value(executionStatsContextKey{}).(*ExecutionStats)
+	return stats
+}
+
+// Nodes returns the statistics of the executed nodes, in the order of execution.
+func (s *ExecutionStats) Nodes() []NodeStats {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return append([]NodeStats(nil), s.nodes...)
+}
+
+func (s *ExecutionStats) add(refID string, nodeType NodeType, duration time.Duration, res mathexp.Results) {
+	if s == nil {
+		return
+	}
+	series := 0
+	for _, v := range res.Values {
+		if _, ok := v.(mathexp.NoData); !ok {
+			series++
+		}
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.nodes = append(s.nodes, NodeStats{RefID: refID, NodeType: nodeType, Duration: duration, Series: series})
+}
@@ -0,0 +1,130 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	datafakes "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginconfig"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugincontext"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestExecutionStats(t *testing.T) {
+	frame := func(values ...*float64) *data.Frame {
+		fields := data.Fields{data.NewField("time", nil, []time.Time{time.Unix(1, 0)})}
+		for i, v := range values {
+			fields = append(fields, data.NewField("value", data.Labels{"series": string(rune('a' + i))}, []*float64{v}))
+		}
+		return data.NewFrame("test", fields...)
+	}
+
+	me := &mockEndpoint{
+		Responses: map[string]backend.DataResponse{
+			"A": {Frames: data.Frames{frame(fp(1))}},
+			"B": {Frames: data.Frames{frame(fp(1), fp(2))}},
+		},
+	}
+
+	pCtxProvider := plugincontext.ProvideService(setting.NewCfg(), nil, &pluginstore.FakePluginStore{
+		PluginList: []pluginstore.Plugin{
+			{JSONData: plugins.JSONData{ID: "test"}},
+		},
+	}, &datafakes.FakeCacheService{}, &datafakes.FakeDataSourceService{}, nil, pluginconfig.NewFakePluginRequestConfigProvider())
+
+	dsQuery := func(refID string) Query {
+		return Query{
+			RefID: refID,
+			DataSource: &datasources.DataSource{
+				OrgID: 1,
+				UID:   "test",
+				Type:  "test",
+			},
+			JSON: json.RawMessage(`{ "datasource": { "uid": "test" }, "intervalMs": 1000, "maxDataPoints": 1000 }`),
+			TimeRange: AbsoluteTimeRange{
+				From: time.Time{},
+				To:   time.Time{},
+			},
+		}
+	}
+	queries := []Query{
+		dsQuery("A"),
+		dsQuery("B"),
+		{
+			RefID:      "C",
+			DataSource: dataSourceModel(),
+			JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "math", "expression": "$B * 2" }`),
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		features featuremgmt.FeatureToggles
+	}{
+		{
+			name:     "data source nodes executed one by one",
+			features: featuremgmt.WithFeatures(),
+		},
+		{
+			name:     "data source nodes grouped by data source",
+			features: featuremgmt.WithFeatures(featuremgmt.FlagSseGroupByDatasource),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := Service{
+				cfg:          setting.NewCfg(),
+				dataService:  me,
+				pCtxProvider: pCtxProvider,
+				features:     tc.features,
+				tracer:       tracing.InitializeTracerForTest(),
+				metrics:      newMetrics(nil),
+				converter: &ResultConverter{
+					Features: tc.features,
+					Tracer:   tracing.InitializeTracerForTest(),
+				},
+			}
+
+			pl, err := s.BuildPipeline(&Request{Queries: queries, User: &user.SignedInUser{}})
+			require.NoError(t, err)
+
+			stats := &ExecutionStats{}
+			_, err = s.ExecutePipeline(WithExecutionStats(context.Background(), stats), time.Now(), pl)
+			require.NoError(t, err)
+
+			nodes := stats.Nodes()
+			require.Len(t, nodes, 3)
+			require.Equal(t, "A", nodes[0].RefID)
+			require.Equal(t, TypeDatasourceNode, nodes[0].NodeType)
+			require.Equal(t, 1, nodes[0].Series)
+			require.Equal(t, "B", nodes[1].RefID)
+			require.Equal(t, TypeDatasourceNode, nodes[1].NodeType)
+			require.Equal(t, 2, nodes[1].Series)
+			require.Equal(t, "C", nodes[2].RefID)
+			require.Equal(t, TypeCMDNode, nodes[2].NodeType)
+			require.Equal(t, 2, nodes[2].Series)
+		})
+	}
+
+	t.Run("nothing is collected without stats in the context", func(t *testing.T) {
+		stats := executionStatsFromContext(context.Background())
+		require.Nil(t, stats)
+		require.NotPanics(t, func() {
+			stats.add("A", TypeDatasourceNode, time.Second, mathexp.Results{})
+		})
+	})
+}
@@ -3,6 +3,7 @@ package remotewrite
 import (
 	"fmt"
 	"hash/fnv"
+	"sort"
 	"strings"
 	"time"
 
@@ -190,6 +191,90 @@ func TimeSeriesFromFramesLabelsColumn(frames ...*data.Frame) []prompb.TimeSeries
 	return promTimeSeriesBatch
 }
 
+// TimeSeriesFromFramesWithMetricName converts frames to slice of Prometheus TimeSeries
+// where every numeric field becomes a series named metricName and labelled with the
+// field labels merged with extraLabels. Frames without a time field (i.e. instant
+// results such as numbers returned by server side expressions) are sampled at the
+// provided timestamp.
+func TimeSeriesFromFramesWithMetricName(metricName string, ts time.Time, extraLabels map[string]string, frames ...*data.Frame) ([]prompb.TimeSeries, error) {
+	name, ok := sanitizeMetricName(metricName)
+	if !ok {
+		return nil, fmt.Errorf("invalid metric name %q", metricName)
+	}
+
+	var entries = make(map[metricKey]prompb.TimeSeries)
+	var keys []metricKey // sorted keys.
+
+	for _, frame := range frames {
+		timeFieldIndex, hasTime := timeFieldIndex(frame)
+		for _, field := range frame.Fields {
+			if !field.Type().Numeric() {
+				continue
+			}
+
+			fieldLabels := make(map[string]string, len(field.Labels)+len(extraLabels))
+			for k, v := range field.Labels {
+				fieldLabels[k] = v
+			}
+			for k, v := range extraLabels {
+				fieldLabels[k] = v
+			}
+			labels := createLabels(fieldLabels)
+			sort.Slice(labels, func(i, j int) bool {
+				return labels[i].Name < labels[j].Name
+			})
+			key := makeMetricKey(name, labels)
+
+			var samples []prompb.Sample
+			for i := 0; i < field.Len(); i++ {
+				val, ok := field.ConcreteAt(i)
+				if !ok {
+					continue
+				}
+				value, ok := sampleValue(val)
+				if !ok {
+					continue
+				}
+				sampleTime := ts
+				if hasTime {
+					tm, ok := frame.Fields[timeFieldIndex].ConcreteAt(i)
+					if !ok {
+						continue
+					}
+					sampleTime = tm.(time.Time)
+				}
+				samples = append(samples, prompb.Sample{
+					// Timestamp is int milliseconds for remote write.
+					Timestamp: toSampleTime(sampleTime),
+					Value:     value,
+				})
+			}
+
+			if entry, ok := entries[key]; ok {
+				entry.Samples = append(entry.Samples, samples...)
+				entries[key] = entry
+				continue
+			}
+
+			labelsCopy := make([]prompb.Label, len(labels), len(labels)+1)
+			copy(labelsCopy, labels)
+			labelsCopy = append(labelsCopy, prompb.Label{
+				Name:  "__name__",
+				Value: name,
+			})
+			entries[key] = prompb.TimeSeries{Labels: labelsCopy, Samples: samples}
+			keys = append(keys, key)
+		}
+	}
+
+	var promTimeSeriesBatch = make([]prompb.TimeSeries, 0, len(entries))
+	for _, key := range keys {
+		promTimeSeriesBatch = append(promTimeSeriesBatch, entries[key])
+	}
+
+	return promTimeSeriesBatch, nil
+}
+
 func timeFieldIndex(frame *data.Frame) (int, bool) {
 	timeFieldIndex := -1
 	for i, field := range frame.Fields {
@@ -5,7 +5,10 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/util"
 )
 
 func TestTsFromFrames(t *testing.T) {
@@ -85,3 +88,40 @@ func TestSerialize(t *testing.T) {
 	_, err := Serialize(frame)
 	require.NoError(t, err)
 }
+
+func TestTsFromFramesWithMetricName(t *testing.T) {
+	t1 := time.Now()
+	t2 := time.Now().Add(time.Second)
+	now := time.Now().Add(time.Minute)
+	series := data.NewFrame("",
+		data.NewField("time", nil, []time.Time{t1, t2}),
+		data.NewField("value", map[string]string{"instance": "a"}, []float64{1.0, 2.0}),
+	)
+	number := data.NewFrame("",
+		data.NewField("value", map[string]string{"instance": "b"}, []*float64{util.Pointer(3.0)}),
+	)
+	ts, err := TimeSeriesFromFramesWithMetricName("recorded_metric", now, map[string]string{"rule": "test"}, series, number)
+	require.NoError(t, err)
+	require.Len(t, ts, 2)
+
+	require.Len(t, ts[0].Samples, 2)
+	require.Equal(t, toSampleTime(t1), ts[0].Samples[0].Timestamp)
+	require.Equal(t, toSampleTime(t2), ts[0].Samples[1].Timestamp)
+	require.Equal(t, []prompb.Label{
+		{Name: "instance", Value: "a"},
+		{Name: "rule", Value: "test"},
+		{Name: "__name__", Value: "recorded_metric"},
+	}, ts[0].Labels)
+
+	require.Len(t, ts[1].Samples, 1)
+	require.Equal(t, toSampleTime(now), ts[1].Samples[0].Timestamp)
+	require.Equal(t, 3.0, ts[1].Samples[0].Value)
+	require.Equal(t, []prompb.Label{
+		{Name: "instance", Value: "b"},
+		{Name: "rule", Value: "test"},
+		{Name: "__name__", Value: "recorded_metric"},
+	}, ts[1].Labels)
+
+	_, err = TimeSeriesFromFramesWithMetricName("!!!", now, nil, series)
+	require.Error(t, err)
+}
@@ -5,12 +5,15 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-openapi/strfmt"
 	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -361,6 +364,69 @@ func (srv AlertmanagerSrv) RoutePostTestTemplates(c *contextmodel.ReqContext, bo
 	return response.JSON(http.StatusOK, newTestTemplateResult(res))
 }
 
+// RoutePostTestRoutes returns the notification policies that the labels and the alerts of the request match, with the
+// time intervals that mute them and the silences that silence the alerts at the time of the request, or now if it is
+// not set. It uses the stored configuration of the organization, or the proposed configuration of the request if it is
+// set. The silences are the silences of the organization, which silence the alerts between their start and end time
+// regardless of their state. Nothing is sent to the Alertmanager or to the receivers.
+func (srv AlertmanagerSrv) RoutePostTestRoutes(c *contextmodel.ReqContext, body apimodels.TestRoutesConfigBodyParams) response.Response {
+	lsets := make([]model.LabelSet, 0, len(body.Alerts)+1)
+	if len(body.Labels) > 0 {
+		lsets = append(lsets, labelSetFromMap(body.Labels))
+	}
+	for _, alert := range body.Alerts {
+		if alert != nil {
+			lsets = append(lsets, labelSetFromMap(alert.Labels))
+		}
+	}
+	if len(lsets) == 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("labels or alerts are required"), "")
+	}
+	now := time.Now()
+	if body.Time != nil {
+		now = *body.Time
+	}
+
+	am, errResp := srv.AlertmanagerFor(c.SignedInUser.GetOrgID())
+	if errResp != nil {
+		return errResp
+	}
+	silences, err := am.ListSilences(c.Req.Context(), nil)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to list silences")
+	}
+
+	simulator, err := srv.mam.NewNotificationSimulator(c.Req.Context(), c.SignedInUser.GetOrgID(), body.AlertmanagerConfig)
+	if err != nil {
+		if body.AlertmanagerConfig != nil {
+			return ErrResp(http.StatusBadRequest, err, "invalid Alertmanager configuration")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the Alertmanager configuration")
+	}
+
+	result := apimodels.TestRoutesResult{Alerts: make([]apimodels.TestRoutesAlertResult, 0, len(lsets))}
+	for _, lset := range lsets {
+		silencedBy, err := notifier.SilencedBy(silences, lset, now)
+		if err != nil {
+			return ErrResp(http.StatusInternalServerError, err, "")
+		}
+		alert := apimodels.TestRoutesAlertResult{
+			Labels:     labelSetToMap(lset),
+			Routes:     []apimodels.TestRoutesRouteResult{},
+			SilencedBy: silencedBy,
+		}
+		for _, route := range simulator.Match(lset) {
+			mutedBy, err := simulator.Mutes(route, now)
+			if err != nil {
+				return ErrResp(http.StatusBadRequest, err, "invalid time intervals")
+			}
+			alert.Routes = append(alert.Routes, newTestRoutesRouteResult(route, simulator.Path(route), simulator.GroupLabels(route, lset), mutedBy))
+		}
+		result.Alerts = append(result.Alerts, alert)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
 // contextWithTimeoutFromRequest returns a context with a deadline set from the
 // Request-Timeout header in the HTTP request. If the header is absent then the
 // context will use the default timeout. The timeout in the Request-Timeout
@@ -470,6 +536,30 @@ func newTestTemplateResult(res *notifier.TestTemplatesResults) apimodels.TestTem
 	return apiRes
 }
 
+func newTestRoutesRouteResult(route *dispatch.Route, path []int, groupLabels model.LabelSet, mutedBy []string) apimodels.TestRoutesRouteResult {
+	groupBy := make([]string, 0, len(route.RouteOpts.GroupBy))
+	if route.RouteOpts.GroupByAll {
+		groupBy = append(groupBy, "...")
+	}
+	for ln := range route.RouteOpts.GroupBy {
+		groupBy = append(groupBy, string(ln))
+	}
+	sort.Strings(groupBy)
+	return apimodels.TestRoutesRouteResult{
+		Path:                path,
+		Policy:              route.Key(),
+		Receiver:            route.RouteOpts.Receiver,
+		GroupBy:             groupBy,
+		GroupLabels:         labelSetToMap(groupLabels),
+		GroupWait:           model.Duration(route.RouteOpts.GroupWait),
+		GroupInterval:       model.Duration(route.RouteOpts.GroupInterval),
+		RepeatInterval:      model.Duration(route.RouteOpts.RepeatInterval),
+		MuteTimeIntervals:   route.RouteOpts.MuteTimeIntervals,
+		ActiveTimeIntervals: route.RouteOpts.ActiveTimeIntervals,
+		MutedBy:             mutedBy,
+	}
+}
+
 func (srv AlertmanagerSrv) AlertmanagerFor(orgID int64) (notifier.Alertmanager, *response.NormalResponse) {
 	am, err := srv.mam.AlertmanagerFor(orgID)
 	if err == nil {
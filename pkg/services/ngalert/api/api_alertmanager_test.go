@@ -16,6 +16,7 @@ import (
 	amv2 "github.com/prometheus/alertmanager/api/v2/models"
 	"github.com/prometheus/alertmanager/pkg/labels"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/api/response"
@@ -577,6 +578,102 @@ func TestRoutePostTestTemplates(t *testing.T) {
 	})
 }
 
+func TestRoutePostTestRoutes(t *testing.T) {
+	sut := createSut(t)
+	// The silences created in the past start now.
+	now := time.Now().Add(time.Minute)
+
+	t.Run("assert 400 when there are no labels", func(t *testing.T) {
+		response := sut.RoutePostTestRoutes(createRequestCtxInOrg(1), apimodels.TestRoutesConfigBodyParams{})
+		require.Equal(t, http.StatusBadRequest, response.Status())
+	})
+
+	t.Run("assert 404 when no alertmanager found", func(t *testing.T) {
+		response := sut.RoutePostTestRoutes(createRequestCtxInOrg(10), apimodels.TestRoutesConfigBodyParams{
+			Labels: map[string]string{"alertname": "HighCPU"},
+		})
+		require.Equal(t, http.StatusNotFound, response.Status())
+	})
+
+	t.Run("routes the labels with the current configuration", func(t *testing.T) {
+		response := sut.RoutePostTestRoutes(createRequestCtxInOrg(1), apimodels.TestRoutesConfigBodyParams{
+			Labels: map[string]string{"alertname": "HighCPU"},
+		})
+		require.Equal(t, http.StatusOK, response.Status())
+
+		var result apimodels.TestRoutesResult
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Len(t, result.Alerts, 1)
+		require.Len(t, result.Alerts[0].Routes, 1)
+		require.Equal(t, "grafana-default-email", result.Alerts[0].Routes[0].Receiver)
+		require.Equal(t, []int{}, result.Alerts[0].Routes[0].Path)
+	})
+
+	t.Run("routes the alerts with the proposed configuration", func(t *testing.T) {
+		cfg := createAmConfigRequest(t, `{
+			"alertmanager_config": {
+				"route": {
+					"receiver": "default",
+					"group_by": ["alertname"],
+					"routes": [{
+						"receiver": "team",
+						"object_matchers": [["team", "=", "a"]],
+						"group_by": ["alertname", "instance"],
+						"group_wait": "1m",
+						"mute_time_intervals": ["always"]
+					}]
+				},
+				"time_intervals": [{
+					"name": "always",
+					"time_intervals": [{"times": [{"start_time": "00:00", "end_time": "24:00"}]}]
+				}],
+				"receivers": [{"name": "default"}, {"name": "team"}]
+			}
+		}`)
+		alertmanager, err := sut.mam.AlertmanagerFor(1)
+		require.NoError(t, err)
+		silence := silenceGen(withEmptyID)()
+		silence.Matchers = notifier.ToSilenceMatchers(labels.Matchers{{Type: labels.MatchEqual, Name: "team", Value: "a"}})
+		startsAt, endsAt := strfmt.DateTime(now.Add(-time.Hour)), strfmt.DateTime(now.Add(time.Hour))
+		silence.StartsAt, silence.EndsAt = &startsAt, &endsAt
+		silenceID, err := alertmanager.CreateSilence(context.Background(), &silence)
+		require.NoError(t, err)
+
+		response := sut.RoutePostTestRoutes(createRequestCtxInOrg(1), apimodels.TestRoutesConfigBodyParams{
+			Alerts: []*amv2.PostableAlert{
+				{Alert: amv2.Alert{Labels: amv2.LabelSet{"alertname": "HighCPU", "instance": "a", "team": "a"}}},
+				{Alert: amv2.Alert{Labels: amv2.LabelSet{"alertname": "HighCPU", "instance": "a"}}},
+			},
+			AlertmanagerConfig: &cfg,
+			Time:               &now,
+		})
+		require.Equal(t, http.StatusOK, response.Status())
+
+		var result apimodels.TestRoutesResult
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Len(t, result.Alerts, 2)
+
+		team := result.Alerts[0]
+		require.Equal(t, []string{silenceID}, team.SilencedBy)
+		require.Len(t, team.Routes, 1)
+		route := team.Routes[0]
+		require.Equal(t, []int{0}, route.Path)
+		require.Equal(t, `{}/{team="a"}`, route.Policy)
+		require.Equal(t, "team", route.Receiver)
+		require.Equal(t, []string{"alertname", "instance"}, route.GroupBy)
+		require.Equal(t, map[string]string{"alertname": "HighCPU", "instance": "a"}, route.GroupLabels)
+		require.Equal(t, model.Duration(time.Minute), route.GroupWait)
+		require.Equal(t, []string{"always"}, route.MutedBy)
+
+		other := result.Alerts[1]
+		require.Empty(t, other.SilencedBy)
+		require.Len(t, other.Routes, 1)
+		require.Equal(t, "default", other.Routes[0].Receiver)
+		require.Equal(t, map[string]string{"alertname": "HighCPU"}, other.Routes[0].GroupLabels)
+		require.Empty(t, other.Routes[0].MutedBy)
+	})
+}
+
 func TestSilenceCreate(t *testing.T) {
 	makeSilence := func(comment string, createdBy string,
 		startsAt, endsAt strfmt.DateTime, matchers amv2.Matchers) amv2.Silence {
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -14,24 +15,41 @@ import (
 	apiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
 
 	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/log"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/folder"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/util"
 )
 
+// RuleEvaluationStatsProvider returns the statistics of the last evaluations of the rules evaluated by this instance.
+type RuleEvaluationStatsProvider interface {
+	EvaluationStats(key ngmodels.AlertRuleKey) (schedule.EvaluationStats, bool)
+}
+
+// AlertInstanceAcknowledger acknowledges the firing alert instances of the rules evaluated by this instance.
+type AlertInstanceAcknowledger interface {
+	AcknowledgeState(ctx context.Context, rule *ngmodels.AlertRule, fingerprint string, ack ngmodels.AlertInstanceAcknowledgement) (*state.State, error)
+}
+
 type PrometheusSrv struct {
 	log     log.Logger
 	manager state.AlertInstanceManager
 	store   RuleStore
 	authz   RuleAccessControlService
+	stats   RuleEvaluationStatsProvider
+	acks    AlertInstanceAcknowledger
 }
 
-const queryIncludeInternalLabels = "includeInternalLabels"
+const (
+	queryIncludeInternalLabels = "includeInternalLabels"
+	queryEvalStats             = "eval_stats"
+)
 
 func (srv PrometheusSrv) RouteGetAlertStatuses(c *contextmodel.ReqContext) response.Response {
 	alertResponse := apimodels.AlertResponse{
@@ -62,15 +80,84 @@ func (srv PrometheusSrv) RouteGetAlertStatuses(c *contextmodel.ReqContext) respo
 
 			// TODO: or should we make this two fields? Using one field lets the
 			// frontend use the same logic for parsing text on annotations and this.
-			State:    state.FormatStateAndReason(alertState.State, alertState.StateReason),
-			ActiveAt: &startsAt,
-			Value:    valString,
+			State:           state.FormatStateAndReason(alertState.State, alertState.StateReason),
+			ActiveAt:        &startsAt,
+			Value:           valString,
+			Fingerprint:     alertState.Labels.Fingerprint().String(),
+			Acknowledgement: toAlertAcknowledgement(alertState.Acknowledgement),
 		})
 	}
 
 	return response.JSON(http.StatusOK, alertResponse)
 }
 
+// RoutePostAlertAcknowledgement acknowledges the firing alert of the rule whose labels have the given fingerprint.
+// Only the instance that evaluates the rule has its alerts.
+func (srv PrometheusSrv) RoutePostAlertAcknowledgement(c *contextmodel.ReqContext, body apimodels.PostableAlertAcknowledgement, ruleUID, fingerprint string) response.Response {
+	now := time.Now()
+	ack := ngmodels.AlertInstanceAcknowledgement{
+		By:                    c.SignedInUser.GetLogin(),
+		Comment:               body.Comment,
+		At:                    now,
+		SuppressNotifications: body.SuppressNotifications,
+	}
+	if body.ExpiresAt != nil {
+		if !body.ExpiresAt.After(now) {
+			return ErrResp(http.StatusBadRequest, errors.New("expiresAt must be in the future"), "")
+		}
+		ack.ExpiresAt = *body.ExpiresAt
+	}
+
+	group, err := srv.store.GetAlertRulesGroupByRuleUID(c.Req.Context(), &ngmodels.GetAlertRulesGroupByRuleUIDQuery{
+		UID:   ruleUID,
+		OrgID: c.SignedInUser.GetOrgID(),
+	})
+	if err != nil {
+		return errorToResponse(err)
+	}
+	var rule *ngmodels.AlertRule
+	for _, r := range group {
+		if r.UID == ruleUID {
+			rule = r
+			break
+		}
+	}
+	if rule == nil {
+		return ErrResp(http.StatusNotFound, fmt.Errorf("%w: rule UID %s", ngmodels.ErrAlertRuleNotFound, ruleUID), "")
+	}
+	if err := srv.authz.AuthorizeAccessToRuleGroup(c.Req.Context(), c.SignedInUser, group); err != nil {
+		return errorToResponse(err)
+	}
+
+	s, err := srv.acks.AcknowledgeState(c.Req.Context(), rule, fingerprint, ack)
+	if err != nil {
+		switch {
+		case errors.Is(err, state.ErrAlertInstanceNotFound):
+			return ErrResp(http.StatusNotFound, err, "")
+		case errors.Is(err, state.ErrAlertInstanceNotFiring):
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to acknowledge alert")
+	}
+	return response.JSON(http.StatusOK, toAlertAcknowledgement(s.Acknowledgement))
+}
+
+func toAlertAcknowledgement(ack *ngmodels.AlertInstanceAcknowledgement) *apimodels.AlertAcknowledgement {
+	if ack == nil {
+		return nil
+	}
+	result := &apimodels.AlertAcknowledgement{
+		By:                    ack.By,
+		Comment:               ack.Comment,
+		At:                    ack.At,
+		SuppressNotifications: ack.SuppressNotifications,
+	}
+	if !ack.ExpiresAt.IsZero() {
+		result.ExpiresAt = util.Pointer(ack.ExpiresAt)
+	}
+	return result
+}
+
 func formatValues(alertState *state.State) string {
 	var fv string
 	values := alertState.GetLastEvaluationValuesForCondition()
@@ -183,6 +270,7 @@ func (srv PrometheusSrv) RouteGetRuleStatuses(c *contextmodel.ReqContext) respon
 	if !c.QueryBoolWithDefault(queryIncludeInternalLabels, false) {
 		labelOptions = append(labelOptions, ngmodels.WithoutInternalLabels())
 	}
+	withEvalStats := c.QueryBoolWithDefault(queryEvalStats, false)
 
 	namespaceMap, err := srv.store.GetUserVisibleNamespaces(c.Req.Context(), c.SignedInUser.GetOrgID(), c.SignedInUser)
 	if err != nil {
@@ -242,7 +330,7 @@ func (srv PrometheusSrv) RouteGetRuleStatuses(c *contextmodel.ReqContext) respon
 		if !ok {
 			continue
 		}
-		ruleGroup, totals := srv.toRuleGroup(groupKey, folder, rules, limitAlertsPerRule, withStatesFast, matchers, labelOptions)
+		ruleGroup, totals := srv.toRuleGroup(groupKey, folder, rules, limitAlertsPerRule, withStatesFast, matchers, labelOptions, withEvalStats)
 		ruleGroup.Totals = totals
 		for k, v := range totals {
 			rulesTotals[k] += v
@@ -300,7 +388,7 @@ func matchersMatch(matchers []*labels.Matcher, labels map[string]string) bool {
 	return true
 }
 
-func (srv PrometheusSrv) toRuleGroup(groupKey ngmodels.AlertRuleGroupKey, folder *folder.Folder, rules []*ngmodels.AlertRule, limitAlerts int64, withStates map[eval.State]struct{}, matchers labels.Matchers, labelOptions []ngmodels.LabelOption) (*apimodels.RuleGroup, map[string]int64) {
+func (srv PrometheusSrv) toRuleGroup(groupKey ngmodels.AlertRuleGroupKey, folder *folder.Folder, rules []*ngmodels.AlertRule, limitAlerts int64, withStates map[eval.State]struct{}, matchers labels.Matchers, labelOptions []ngmodels.LabelOption, withEvalStats bool) (*apimodels.RuleGroup, map[string]int64) {
 	newGroup := &apimodels.RuleGroup{
 		Name: groupKey.RuleGroup,
 		// file is what Prometheus uses for provisioning, we replace it with namespace which is the folder in Grafana.
@@ -317,6 +405,7 @@ func (srv PrometheusSrv) toRuleGroup(groupKey ngmodels.AlertRuleGroupKey, folder
 			Query:       ruleToQuery(srv.log, rule),
 			Duration:    rule.For.Seconds(),
 			Annotations: rule.Annotations,
+			InhibitedBy: ApiRuleInhibitionsFromModelRuleInhibitions(rule.InhibitedBy),
 		}
 
 		newRule := apimodels.Rule{
@@ -326,6 +415,14 @@ func (srv PrometheusSrv) toRuleGroup(groupKey ngmodels.AlertRuleGroupKey, folder
 			Type:           apiv1.RuleTypeAlerting,
 			LastEvaluation: time.Time{},
 		}
+		if rule.Type() == ngmodels.RuleTypeRecording {
+			newRule.Type = apiv1.RuleTypeRecording
+		}
+		if withEvalStats && srv.stats != nil {
+			if stats, ok := srv.stats.EvaluationStats(rule.GetKey()); ok {
+				newRule.EvaluationStats = toRuleEvaluationStats(stats)
+			}
+		}
 
 		states := srv.manager.GetStatesForRuleUID(rule.OrgID, rule.UID)
 		totals := make(map[string]int64)
@@ -348,9 +445,11 @@ func (srv PrometheusSrv) toRuleGroup(groupKey ngmodels.AlertRuleGroupKey, folder
 
 				// TODO: or should we make this two fields? Using one field lets the
 				// frontend use the same logic for parsing text on annotations and this.
-				State:    state.FormatStateAndReason(alertState.State, alertState.StateReason),
-				ActiveAt: &activeAt,
-				Value:    valString,
+				State:           state.FormatStateAndReason(alertState.State, alertState.StateReason),
+				ActiveAt:        &activeAt,
+				Value:           valString,
+				Fingerprint:     alertState.Labels.Fingerprint().String(),
+				Acknowledgement: toAlertAcknowledgement(alertState.Acknowledgement),
 			}
 
 			if alertState.LastEvaluationTime.After(newRule.LastEvaluation) {
@@ -427,6 +526,48 @@ func (srv PrometheusSrv) toRuleGroup(groupKey ngmodels.AlertRuleGroupKey, folder
 	return newGroup, rulesTotals
 }
 
+func toRuleEvaluationStats(stats schedule.EvaluationStats) *apimodels.RuleEvaluationStats {
+	result := &apimodels.RuleEvaluationStats{
+		Evaluations:       make([]apimodels.RuleEvaluation, 0, len(stats.Evaluations)),
+		MissedEvaluations: stats.Missed,
+	}
+	for _, stat := range stats.Evaluations {
+		result.Evaluations = append(result.Evaluations, toRuleEvaluation(stat))
+	}
+	for _, stat := range stats.Errors {
+		result.Errors = append(result.Errors, toRuleEvaluation(stat))
+	}
+	if !stats.LastMissed.IsZero() {
+		result.LastMissedEvaluation = util.Pointer(stats.LastMissed)
+	}
+	return result
+}
+
+func toRuleEvaluation(stat schedule.EvaluationStat) apimodels.RuleEvaluation {
+	evaluation := apimodels.RuleEvaluation{
+		ScheduledAt:    stat.ScheduledAt,
+		Attempt:        stat.Attempt,
+		EvaluationTime: stat.Duration.Seconds(),
+		Series:         int64(stat.Series),
+	}
+	for _, node := range stat.Nodes {
+		nodeType := "query"
+		if node.NodeType == expr.TypeCMDNode {
+			nodeType = "expression"
+		}
+		evaluation.Queries = append(evaluation.Queries, apimodels.RuleEvaluationQuery{
+			RefID:          node.RefID,
+			Type:           nodeType,
+			EvaluationTime: node.Duration.Seconds(),
+			Series:         int64(node.Series),
+		})
+	}
+	if stat.Error != nil {
+		evaluation.Error = stat.Error.Error()
+	}
+	return evaluation
+}
+
 // ruleToQuery attempts to extract the datasource queries from the alert query model.
 // Returns the whole JSON model as a string if it fails to extract a minimum of 1 query.
 func ruleToQuery(logger log.Logger, rule *ngmodels.AlertRule) string {
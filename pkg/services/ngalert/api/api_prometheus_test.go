@@ -16,16 +16,20 @@ import (
 	alertingModels "github.com/grafana/alerting/models"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
+	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/ngalert/accesscontrol"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
 	"github.com/grafana/grafana/pkg/services/user"
@@ -137,7 +141,8 @@ func TestRouteGetAlertStatuses(t *testing.T) {
 			},
 			"state": "Normal",
 			"activeAt": "0001-01-01T00:00:00Z",
-			"value": ""
+			"value": "",
+			"fingerprint": "ebb686c9847c7989"
 		}, {
 			"labels": {
 				"alertname": "test_title_1",
@@ -149,7 +154,8 @@ func TestRouteGetAlertStatuses(t *testing.T) {
 			},
 			"state": "Normal",
 			"activeAt": "0001-01-01T00:00:00Z",
-			"value": ""
+			"value": "",
+			"fingerprint": "c83a086a64412367"
 		}]
 	}
 }`, string(r.Body()))
@@ -179,7 +185,8 @@ func TestRouteGetAlertStatuses(t *testing.T) {
 			},
 			"state": "Alerting",
 			"activeAt": "0001-01-01T00:00:00Z",
-			"value": "1.1e+00"
+			"value": "1.1e+00",
+			"fingerprint": "ebb686c9847c7989"
 		}, {
 			"labels": {
 				"alertname": "test_title_1",
@@ -191,7 +198,8 @@ func TestRouteGetAlertStatuses(t *testing.T) {
 			},
 			"state": "Alerting",
 			"activeAt": "0001-01-01T00:00:00Z",
-			"value": "1.1e+00"
+			"value": "1.1e+00",
+			"fingerprint": "c83a086a64412367"
 		}]
 	}
 }`, string(r.Body()))
@@ -223,7 +231,8 @@ func TestRouteGetAlertStatuses(t *testing.T) {
 			},
 			"state": "Normal",
 			"activeAt": "0001-01-01T00:00:00Z",
-			"value": ""
+			"value": "",
+			"fingerprint": "ebb686c9847c7989"
 		}, {
 			"labels": {
 				"__alert_rule_namespace_uid__": "test_namespace_uid",
@@ -237,7 +246,8 @@ func TestRouteGetAlertStatuses(t *testing.T) {
 			},
 			"state": "Normal",
 			"activeAt": "0001-01-01T00:00:00Z",
-			"value": ""
+			"value": "",
+			"fingerprint": "c83a086a64412367"
 		}]
 	}
 }`, string(r.Body()))
@@ -1252,6 +1262,168 @@ func TestRouteGetRuleStatuses(t *testing.T) {
 	})
 }
 
+func TestRouteGetRuleStatusesEvaluationStats(t *testing.T) {
+	orgID := int64(1)
+	queryPermissions := map[int64]map[string][]string{1: {datasources.ActionQuery: {datasources.ScopeAll}}}
+	fakeStore, fakeAIM, api := setupAPI(t)
+	generateRuleAndInstanceWithQuery(t, orgID, fakeAIM, fakeStore, withClassicConditionSingleQuery())
+	rule := fakeStore.Rules[orgID][0]
+
+	scheduledAt := time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)
+	api.stats = fakeRuleEvaluationStats{
+		rule.GetKey(): {
+			Evaluations: []schedule.EvaluationStat{
+				{
+					ScheduledAt: scheduledAt,
+					Attempt:     1,
+					Duration:    2 * time.Second,
+					Series:      3,
+					Nodes: []expr.NodeStats{
+						{RefID: "A", NodeType: expr.TypeDatasourceNode, Duration: 1500 * time.Millisecond, Series: 3},
+						{RefID: "B", NodeType: expr.TypeCMDNode, Duration: 500 * time.Millisecond, Series: 3},
+					},
+					Error: errors.New("failed"),
+				},
+			},
+			Missed:     2,
+			LastMissed: scheduledAt,
+		},
+	}
+
+	getRule := func(t *testing.T, url string) apimodels.AlertingRule {
+		t.Helper()
+		req, err := http.NewRequest("GET", url, nil)
+		require.NoError(t, err)
+		c := &contextmodel.ReqContext{Context: &web.Context{Req: req}, SignedInUser: &user.SignedInUser{OrgID: orgID, Permissions: queryPermissions}}
+
+		r := api.RouteGetRuleStatuses(c)
+		require.Equal(t, http.StatusOK, r.Status())
+		var res apimodels.RuleResponse
+		require.NoError(t, json.Unmarshal(r.Body(), &res))
+		require.Len(t, res.Data.RuleGroups, 1)
+		require.Len(t, res.Data.RuleGroups[0].Rules, 1)
+		return res.Data.RuleGroups[0].Rules[0]
+	}
+
+	t.Run("without eval_stats", func(t *testing.T) {
+		require.Nil(t, getRule(t, "/api/v1/rules").EvaluationStats)
+	})
+
+	t.Run("with eval_stats", func(t *testing.T) {
+		stats := getRule(t, "/api/v1/rules?eval_stats=true").EvaluationStats
+		require.NotNil(t, stats)
+		require.Equal(t, &apimodels.RuleEvaluationStats{
+			Evaluations: []apimodels.RuleEvaluation{
+				{
+					ScheduledAt:    scheduledAt,
+					Attempt:        1,
+					EvaluationTime: 2,
+					Series:         3,
+					Queries: []apimodels.RuleEvaluationQuery{
+						{RefID: "A", Type: "query", EvaluationTime: 1.5, Series: 3},
+						{RefID: "B", Type: "expression", EvaluationTime: 0.5, Series: 3},
+					},
+					Error: "failed",
+				},
+			},
+			MissedEvaluations:    2,
+			LastMissedEvaluation: &scheduledAt,
+		}, stats)
+	})
+}
+
+type fakeRuleEvaluationStats map[ngmodels.AlertRuleKey]schedule.EvaluationStats
+
+func (f fakeRuleEvaluationStats) EvaluationStats(key ngmodels.AlertRuleKey) (schedule.EvaluationStats, bool) {
+	stats, ok := f[key]
+	return stats, ok
+}
+
+func TestRoutePostAlertAcknowledgement(t *testing.T) {
+	orgID := int64(1)
+	permissions := map[int64]map[string][]string{1: {
+		datasources.ActionQuery:         {datasources.ScopeAll},
+		ac.ActionAlertingRuleRead:       {dashboards.ScopeFoldersAll},
+		ac.ActionAlertingInstanceUpdate: nil,
+	}}
+	fakeStore, fakeAIM, api := setupAPI(t)
+	generateRuleAndInstanceWithQuery(t, orgID, fakeAIM, fakeStore, withClassicConditionSingleQuery())
+	rule := fakeStore.Rules[orgID][0]
+	acks := &fakeAlertInstanceAcknowledger{}
+	api.acks = acks
+
+	post := func(t *testing.T, ruleUID string, body apimodels.PostableAlertAcknowledgement, perms map[int64]map[string][]string) response.Response {
+		t.Helper()
+		req, err := http.NewRequest("POST", "/api/v1/rule/"+ruleUID+"/alerts/abc/acknowledge", nil)
+		require.NoError(t, err)
+		c := &contextmodel.ReqContext{Context: &web.Context{Req: req}, SignedInUser: &user.SignedInUser{OrgID: orgID, Login: "editor", Permissions: perms}}
+		return api.RoutePostAlertAcknowledgement(c, body, ruleUID, "abc")
+	}
+
+	t.Run("acknowledges the alert", func(t *testing.T) {
+		expiresAt := time.Now().Add(time.Hour).UTC()
+		r := post(t, rule.UID, apimodels.PostableAlertAcknowledgement{Comment: "on it", ExpiresAt: &expiresAt, SuppressNotifications: true}, permissions)
+		require.Equal(t, http.StatusOK, r.Status())
+
+		require.Equal(t, rule.UID, acks.rule.UID)
+		require.Equal(t, "abc", acks.fingerprint)
+		require.Equal(t, "editor", acks.ack.By)
+		require.Equal(t, "on it", acks.ack.Comment)
+		require.Equal(t, expiresAt, acks.ack.ExpiresAt)
+		require.True(t, acks.ack.SuppressNotifications)
+
+		var res apimodels.AlertAcknowledgement
+		require.NoError(t, json.Unmarshal(r.Body(), &res))
+		require.Equal(t, "editor", res.By)
+		require.Equal(t, "on it", res.Comment)
+		require.True(t, expiresAt.Equal(*res.ExpiresAt))
+	})
+
+	t.Run("returns 400 if the expiry is in the past", func(t *testing.T) {
+		expiresAt := time.Now().Add(-time.Hour)
+		r := post(t, rule.UID, apimodels.PostableAlertAcknowledgement{ExpiresAt: &expiresAt}, permissions)
+		require.Equal(t, http.StatusBadRequest, r.Status())
+	})
+
+	t.Run("returns 404 if the rule does not exist", func(t *testing.T) {
+		r := post(t, "unknown", apimodels.PostableAlertAcknowledgement{}, permissions)
+		require.Equal(t, http.StatusNotFound, r.Status())
+	})
+
+	t.Run("returns 403 if the user cannot query the data sources of the rule", func(t *testing.T) {
+		perms := map[int64]map[string][]string{1: {ac.ActionAlertingRuleRead: {dashboards.ScopeFoldersAll}}}
+		r := post(t, rule.UID, apimodels.PostableAlertAcknowledgement{}, perms)
+		require.Equal(t, http.StatusForbidden, r.Status())
+	})
+
+	t.Run("returns 404 if the alert does not exist", func(t *testing.T) {
+		acks.err = state.ErrAlertInstanceNotFound
+		r := post(t, rule.UID, apimodels.PostableAlertAcknowledgement{}, permissions)
+		require.Equal(t, http.StatusNotFound, r.Status())
+	})
+
+	t.Run("returns 400 if the alert is not firing", func(t *testing.T) {
+		acks.err = state.ErrAlertInstanceNotFiring
+		r := post(t, rule.UID, apimodels.PostableAlertAcknowledgement{}, permissions)
+		require.Equal(t, http.StatusBadRequest, r.Status())
+	})
+}
+
+type fakeAlertInstanceAcknowledger struct {
+	err         error
+	rule        *ngmodels.AlertRule
+	fingerprint string
+	ack         ngmodels.AlertInstanceAcknowledgement
+}
+
+func (f *fakeAlertInstanceAcknowledger) AcknowledgeState(_ context.Context, rule *ngmodels.AlertRule, fingerprint string, ack ngmodels.AlertInstanceAcknowledgement) (*state.State, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.rule, f.fingerprint, f.ack = rule, fingerprint, ack
+	return &state.State{Acknowledgement: &ack}, nil
+}
+
 func setupAPI(t *testing.T) (*fakes.RuleStore, *fakeAlertInstanceManager, PrometheusSrv) {
 	fakeStore := fakes.NewRuleStore(t)
 	fakeAIM := NewFakeAlertInstanceManager(t)
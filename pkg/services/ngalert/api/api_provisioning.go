@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/hcl"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	alerting_models "github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -28,6 +29,9 @@ type ProvisioningSrv struct {
 	templates           TemplateService
 	muteTimings         MuteTimingService
 	alertRules          AlertRuleService
+	silences            SilenceService
+	namespaces          NamespaceService
+	xact                provisioning.TransactionManager
 }
 
 type ContactPointService interface {
@@ -57,6 +61,18 @@ type MuteTimingService interface {
 	DeleteMuteTiming(ctx context.Context, name string, orgID int64) error
 }
 
+type SilenceService interface {
+	GetSilences(ctx context.Context, orgID int64) ([]alerting_models.ProvisionedSilence, map[string]alerting_models.Provenance, error)
+	GetSilence(ctx context.Context, orgID int64, uid string) (alerting_models.ProvisionedSilence, alerting_models.Provenance, error)
+	CreateSilence(ctx context.Context, silence alerting_models.ProvisionedSilence, provenance alerting_models.Provenance) (alerting_models.ProvisionedSilence, error)
+	UpdateSilence(ctx context.Context, silence alerting_models.ProvisionedSilence, provenance alerting_models.Provenance) (alerting_models.ProvisionedSilence, error)
+	DeleteSilence(ctx context.Context, orgID int64, uid string, provenance alerting_models.Provenance) error
+}
+
+type NamespaceService interface {
+	GetUserVisibleNamespaces(ctx context.Context, orgID int64, user identity.Requester) (map[string]*folder.Folder, error)
+}
+
 type AlertRuleService interface {
 	GetAlertRules(ctx context.Context, user identity.Requester) ([]*alerting_models.AlertRule, map[string]alerting_models.Provenance, error)
 	GetAlertRule(ctx context.Context, user identity.Requester, ruleUID string) (alerting_models.AlertRule, alerting_models.Provenance, error)
@@ -308,6 +324,62 @@ func (srv *ProvisioningSrv) RouteDeleteMuteTiming(c *contextmodel.ReqContext, na
 	return response.JSON(http.StatusNoContent, nil)
 }
 
+func (srv *ProvisioningSrv) RouteGetProvisionedSilences(c *contextmodel.ReqContext) response.Response {
+	silences, provenances, err := srv.silences.GetSilences(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get provisioned silences", err)
+	}
+	result := make(definitions.ProvisionedSilences, 0, len(silences))
+	for _, s := range silences {
+		result = append(result, ApiProvisionedSilenceFromProvisionedSilence(s, provenances[s.UID]))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv *ProvisioningSrv) RouteGetProvisionedSilence(c *contextmodel.ReqContext, UID string) response.Response {
+	silence, provenance, err := srv.silences.GetSilence(c.Req.Context(), c.SignedInUser.GetOrgID(), UID)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get provisioned silence", err)
+	}
+	return response.JSON(http.StatusOK, ApiProvisionedSilenceFromProvisionedSilence(silence, provenance))
+}
+
+func (srv *ProvisioningSrv) RoutePostProvisionedSilence(c *contextmodel.ReqContext, s definitions.ProvisionedSilence) response.Response {
+	silence, err := ProvisionedSilenceFromApiProvisionedSilence(c.SignedInUser.GetOrgID(), s)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	provenance := alerting_models.Provenance(determineProvenance(c))
+	created, err := srv.silences.CreateSilence(c.Req.Context(), silence, provenance)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to create provisioned silence", err)
+	}
+	return response.JSON(http.StatusCreated, ApiProvisionedSilenceFromProvisionedSilence(created, provenance))
+}
+
+func (srv *ProvisioningSrv) RoutePutProvisionedSilence(c *contextmodel.ReqContext, s definitions.ProvisionedSilence, UID string) response.Response {
+	s.UID = UID
+	silence, err := ProvisionedSilenceFromApiProvisionedSilence(c.SignedInUser.GetOrgID(), s)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	provenance := alerting_models.Provenance(determineProvenance(c))
+	updated, err := srv.silences.UpdateSilence(c.Req.Context(), silence, provenance)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to update provisioned silence", err)
+	}
+	return response.JSON(http.StatusAccepted, ApiProvisionedSilenceFromProvisionedSilence(updated, provenance))
+}
+
+func (srv *ProvisioningSrv) RouteDeleteProvisionedSilence(c *contextmodel.ReqContext, UID string) response.Response {
+	provenance := alerting_models.Provenance(determineProvenance(c))
+	err := srv.silences.DeleteSilence(c.Req.Context(), c.SignedInUser.GetOrgID(), UID, provenance)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to delete provisioned silence", err)
+	}
+	return response.JSON(http.StatusNoContent, nil)
+}
+
 func (srv *ProvisioningSrv) RouteGetAlertRules(c *contextmodel.ReqContext) response.Response {
 	rules, provenances, err := srv.alertRules.GetAlertRules(c.Req.Context(), c.SignedInUser)
 	if err != nil {
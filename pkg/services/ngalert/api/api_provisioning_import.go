@@ -0,0 +1,590 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/hcl"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	alerting_models "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels_config"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// importStep is the change of a resource of an imported file. apply is nil if the resource is not changed.
+type importStep struct {
+	change definitions.ProvisioningImportChange
+	apply  func(ctx context.Context) error
+}
+
+// RoutePostProvisioningImport imports the resources of a file in one of the export formats. The mute timings and the
+// contact points are imported first, because the notification policies and the rules can refer to them. All the
+// changes are applied in one transaction, so either all of them or none of them are applied.
+func (srv *ProvisioningSrv) RoutePostProvisioningImport(c *contextmodel.ReqContext) response.Response {
+	body, err := io.ReadAll(c.Req.Body)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to read the file")
+	}
+	file, err := parseImportedFile(extractImportFormat(c), body, c.SignedInUser.GetOrgID())
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to parse the file")
+	}
+	provenance := alerting_models.Provenance(determineProvenance(c))
+
+	var steps []importStep
+	for _, plan := range []func(*contextmodel.ReqContext, definitions.AlertingFileExport, alerting_models.Provenance) ([]importStep, error){
+		srv.planMuteTimingsImport,
+		srv.planContactPointsImport,
+		srv.planPoliciesImport,
+		srv.planRuleGroupsImport,
+	} {
+		s, err := plan(c, file, provenance)
+		if err != nil {
+			return importErrorResponse(err, "failed to import the file")
+		}
+		steps = append(steps, s...)
+	}
+
+	dryRun := c.QueryBoolWithDefault("dryRun", false)
+	result := definitions.ProvisioningImportResponse{
+		DryRun:  dryRun,
+		Changes: make([]definitions.ProvisioningImportChange, 0, len(steps)),
+	}
+	for _, step := range steps {
+		result.Changes = append(result.Changes, step.change)
+	}
+	if dryRun {
+		return response.JSON(http.StatusOK, result)
+	}
+
+	var failed *importStep
+	err = srv.xact.InTransaction(c.Req.Context(), func(ctx context.Context) error {
+		for i := range steps {
+			if steps[i].apply == nil {
+				continue
+			}
+			if err := steps[i].apply(ctx); err != nil {
+				failed = &steps[i]
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		msg := "failed to import the file, no changes were applied"
+		if failed != nil {
+			msg = fmt.Sprintf("failed to import %s", failed.change.Kind)
+			if failed.change.Name != "" {
+				msg = fmt.Sprintf("%s '%s'", msg, failed.change.Name)
+			}
+			msg += ", no changes were applied"
+		}
+		return importErrorResponse(err, msg)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func importErrorResponse(err error, msg string) response.Response {
+	if errors.Is(err, provisioning.ErrValidation) ||
+		errors.Is(err, alerting_models.ErrAlertRuleFailedValidation) ||
+		errors.Is(err, alerting_models.ErrAlertRuleUniqueConstraintViolation) {
+		return ErrResp(http.StatusBadRequest, err, msg)
+	}
+	if errors.Is(err, store.ErrOptimisticLock) {
+		return ErrResp(http.StatusConflict, err, msg)
+	}
+	if errors.Is(err, alerting_models.ErrQuotaReached) {
+		return ErrResp(http.StatusForbidden, err, msg)
+	}
+	return response.ErrOrFallback(http.StatusInternalServerError, msg, err)
+}
+
+func extractImportFormat(c *contextmodel.ReqContext) string {
+	format := "yaml"
+	contentType := c.Req.Header.Get("Content-Type")
+	if strings.Contains(contentType, "json") {
+		format = "json"
+	}
+	if strings.Contains(contentType, "hcl") || strings.Contains(contentType, "terraform") {
+		format = "hcl"
+	}
+	queryFormat := c.Query("format")
+	if queryFormat == "yaml" || queryFormat == "json" || queryFormat == "hcl" {
+		format = queryFormat
+	}
+	return format
+}
+
+func parseImportedFile(format string, body []byte, orgID int64) (definitions.AlertingFileExport, error) {
+	var file definitions.AlertingFileExport
+	var err error
+	switch format {
+	case "hcl":
+		file, err = decodeHcl(body, orgID)
+	case "json":
+		err = json.Unmarshal(body, &file)
+	default:
+		err = yaml.Unmarshal(body, &file)
+	}
+	if err != nil {
+		return definitions.AlertingFileExport{}, err
+	}
+	if len(file.Groups) == 0 && len(file.ContactPoints) == 0 && len(file.Policies) == 0 && len(file.MuteTimings) == 0 {
+		return definitions.AlertingFileExport{}, errors.New("the file has no resources")
+	}
+	return file, nil
+}
+
+// decodeHcl is the inverse of exportHcl.
+func decodeHcl(body []byte, orgID int64) (definitions.AlertingFileExport, error) {
+	resources, err := hcl.Decode(body, func(resourceType string) (interface{}, error) {
+		switch resourceType {
+		case "grafana_rule_group":
+			return &definitions.AlertRuleGroupExport{}, nil
+		case "grafana_contact_point":
+			return &definitions.ContactPoint{}, nil
+		case "grafana_notification_policy":
+			return &definitions.RouteExport{}, nil
+		case "grafana_mute_timing":
+			return &definitions.MuteTimeIntervalExportHcl{}, nil
+		}
+		return nil, fmt.Errorf("unsupported resource type %s", resourceType)
+	})
+	if err != nil {
+		return definitions.AlertingFileExport{}, err
+	}
+
+	file := definitions.AlertingFileExport{APIVersion: 1}
+	for _, resource := range resources {
+		switch body := resource.Body.(type) {
+		case *definitions.AlertRuleGroupExport:
+			file.Groups = append(file.Groups, *body)
+		case *definitions.ContactPoint:
+			cp, err := ContactPointExportFromContactPoint(orgID, *body)
+			if err != nil {
+				return definitions.AlertingFileExport{}, fmt.Errorf("failed to convert contact point '%s': %w", body.Name, err)
+			}
+			file.ContactPoints = append(file.ContactPoints, cp)
+		case *definitions.RouteExport:
+			file.Policies = append(file.Policies, definitions.NotificationPolicyExport{OrgID: orgID, RouteExport: body})
+		case *definitions.MuteTimeIntervalExportHcl:
+			mt, err := MuteTimeIntervalExportFromMuteTimeIntervalHclExport(orgID, *body)
+			if err != nil {
+				return definitions.AlertingFileExport{}, fmt.Errorf("failed to convert mute timing '%s': %w", body.Name, err)
+			}
+			file.MuteTimings = append(file.MuteTimings, mt)
+		}
+	}
+	return file, nil
+}
+
+func (srv *ProvisioningSrv) planMuteTimingsImport(c *contextmodel.ReqContext, file definitions.AlertingFileExport, provenance alerting_models.Provenance) ([]importStep, error) {
+	if len(file.MuteTimings) == 0 {
+		return nil, nil
+	}
+	orgID := c.SignedInUser.GetOrgID()
+	existing, err := srv.muteTimings.GetMuteTimings(c.Req.Context(), orgID)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]definitions.MuteTimeInterval, len(existing))
+	for _, mt := range existing {
+		byName[mt.Name] = mt
+	}
+
+	steps := make([]importStep, 0, len(file.MuteTimings))
+	imported := make(map[string]struct{}, len(file.MuteTimings))
+	for _, export := range file.MuteTimings {
+		mt := definitions.MuteTimeInterval{MuteTimeInterval: export.MuteTimeInterval, Provenance: definitions.Provenance(provenance)}
+		if _, ok := imported[mt.Name]; ok {
+			return nil, provisioning.MakeErrTimeIntervalInvalid(fmt.Errorf("mute timing '%s' is defined more than once", mt.Name))
+		}
+		imported[mt.Name] = struct{}{}
+		if err := mt.Validate(); err != nil {
+			return nil, provisioning.MakeErrTimeIntervalInvalid(err)
+		}
+
+		step := importStep{change: definitions.ProvisioningImportChange{Kind: definitions.ProvisioningImportKindMuteTiming, Name: mt.Name}}
+		current, ok := byName[mt.Name]
+		if !ok {
+			step.change.Action = definitions.ProvisioningImportActionCreate
+			step.apply = func(ctx context.Context) error {
+				_, err := srv.muteTimings.CreateMuteTiming(ctx, mt, orgID)
+				return err
+			}
+		} else if step.change.Diff, err = diffExports(current.MuteTimeInterval, mt.MuteTimeInterval); err != nil {
+			return nil, err
+		} else if len(step.change.Diff) > 0 {
+			step.change.Action = definitions.ProvisioningImportActionUpdate
+			step.apply = func(ctx context.Context) error {
+				_, err := srv.muteTimings.UpdateMuteTiming(ctx, mt, orgID)
+				return err
+			}
+		} else {
+			step.change.Action = definitions.ProvisioningImportActionNone
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// planContactPointsImport plans the import of the contact points. The integrations are matched with the existing
+// integrations by UID. The integrations that have no UID, like the ones exported to HCL, are matched with the existing
+// integrations of the same type. The existing integrations that are not matched are deleted.
+func (srv *ProvisioningSrv) planContactPointsImport(c *contextmodel.ReqContext, file definitions.AlertingFileExport, provenance alerting_models.Provenance) ([]importStep, error) {
+	if len(file.ContactPoints) == 0 {
+		return nil, nil
+	}
+	orgID := c.SignedInUser.GetOrgID()
+	existing, err := srv.contactPointService.GetContactPoints(c.Req.Context(), provisioning.ContactPointQuery{OrgID: orgID}, c.SignedInUser)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string][]definitions.EmbeddedContactPoint)
+	uids := make(map[string]struct{}, len(existing))
+	for _, cp := range existing {
+		byName[cp.Name] = append(byName[cp.Name], cp)
+		uids[cp.UID] = struct{}{}
+	}
+
+	steps := make([]importStep, 0, len(file.ContactPoints))
+	imported := make(map[string]struct{}, len(file.ContactPoints))
+	for _, export := range file.ContactPoints {
+		if _, ok := imported[export.Name]; ok {
+			return nil, fmt.Errorf("%w: contact point '%s' is defined more than once", provisioning.ErrValidation, export.Name)
+		}
+		imported[export.Name] = struct{}{}
+		integrations, err := EmbeddedContactPointsFromContactPointExport(export)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", provisioning.ErrValidation, err.Error())
+		}
+		if len(integrations) == 0 {
+			return nil, fmt.Errorf("%w: contact point '%s' has no integrations", provisioning.ErrValidation, export.Name)
+		}
+
+		current := byName[export.Name]
+		matched := make(map[string]struct{}, len(current))
+		for _, i := range integrations {
+			if i.UID != "" {
+				matched[i.UID] = struct{}{}
+			}
+		}
+		for idx := range integrations {
+			if integrations[idx].UID != "" {
+				continue
+			}
+			for _, cp := range current {
+				if _, ok := matched[cp.UID]; !ok && cp.Type == integrations[idx].Type {
+					integrations[idx].UID = cp.UID
+					matched[cp.UID] = struct{}{}
+					break
+				}
+			}
+		}
+		var deleted []string
+		for _, cp := range current {
+			if _, ok := matched[cp.UID]; !ok {
+				deleted = append(deleted, cp.UID)
+			}
+		}
+		for _, i := range integrations {
+			if _, ok := uids[i.UID]; !ok {
+				if err := checkNoRedactedSecrets(i); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		step := importStep{change: definitions.ProvisioningImportChange{Kind: definitions.ProvisioningImportKindContactPoint, Name: export.Name}}
+		if len(current) == 0 {
+			step.change.Action = definitions.ProvisioningImportActionCreate
+		} else {
+			if step.change.Diff, err = diffContactPoints(orgID, current, integrations); err != nil {
+				return nil, err
+			}
+			step.change.Action = definitions.ProvisioningImportActionUpdate
+			if len(step.change.Diff) == 0 {
+				step.change.Action = definitions.ProvisioningImportActionNone
+				steps = append(steps, step)
+				continue
+			}
+		}
+		step.apply = func(ctx context.Context) error {
+			for _, i := range integrations {
+				if _, ok := uids[i.UID]; ok {
+					if err := srv.contactPointService.UpdateContactPoint(ctx, orgID, i, provenance); err != nil {
+						return err
+					}
+					continue
+				}
+				if _, err := srv.contactPointService.CreateContactPoint(ctx, orgID, i, provenance); err != nil {
+					return err
+				}
+			}
+			for _, uid := range deleted {
+				if err := srv.contactPointService.DeleteContactPoint(ctx, orgID, uid); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// checkNoRedactedSecrets returns an error if a new integration has redacted secure settings, because there are no values to keep.
+func checkNoRedactedSecrets(cp definitions.EmbeddedContactPoint) error {
+	secretKeys, err := channels_config.GetSecretKeysForContactPointType(cp.Type)
+	if err != nil {
+		return fmt.Errorf("%w: %s", provisioning.ErrValidation, err.Error())
+	}
+	for _, key := range secretKeys {
+		if cp.Settings.Get(key).MustString() == definitions.RedactedValue {
+			return fmt.Errorf("%w: setting '%s' of the new %s integration of contact point '%s' is redacted, export the contact point with decrypted secure settings",
+				provisioning.ErrValidation, key, cp.Type, cp.Name)
+		}
+	}
+	return nil
+}
+
+// diffContactPoints compares the exports of the integrations, ordered by UID like the existing integrations.
+// The secure settings of the existing integrations are redacted, so the secure settings that are not redacted in the
+// file are reported as changed.
+func diffContactPoints(orgID int64, current, imported []definitions.EmbeddedContactPoint) ([]string, error) {
+	sorted := make([]definitions.EmbeddedContactPoint, len(imported))
+	copy(sorted, imported)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].UID < sorted[j].UID
+	})
+	currentExport, err := AlertingFileExportFromEmbeddedContactPoints(orgID, current)
+	if err != nil {
+		return nil, err
+	}
+	importedExport, err := AlertingFileExportFromEmbeddedContactPoints(orgID, sorted)
+	if err != nil {
+		return nil, err
+	}
+	return diffExports(currentExport.ContactPoints[0], importedExport.ContactPoints[0])
+}
+
+func (srv *ProvisioningSrv) planPoliciesImport(c *contextmodel.ReqContext, file definitions.AlertingFileExport, provenance alerting_models.Provenance) ([]importStep, error) {
+	if len(file.Policies) == 0 {
+		return nil, nil
+	}
+	if len(file.Policies) > 1 {
+		return nil, fmt.Errorf("%w: the file has more than one notification policy tree", provisioning.ErrValidation)
+	}
+	if file.Policies[0].RouteExport == nil {
+		return nil, fmt.Errorf("%w: the notification policy tree is empty", provisioning.ErrValidation)
+	}
+	tree, err := RouteFromRouteExport(file.Policies[0].RouteExport)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", provisioning.ErrValidation, err.Error())
+	}
+	if err := tree.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s", provisioning.ErrValidation, err.Error())
+	}
+
+	orgID := c.SignedInUser.GetOrgID()
+	current, err := srv.policies.GetPolicyTree(c.Req.Context(), orgID)
+	if err != nil {
+		return nil, err
+	}
+	step := importStep{change: definitions.ProvisioningImportChange{Kind: definitions.ProvisioningImportKindPolicies}}
+	if step.change.Diff, err = diffExports(RouteExportFromRoute(&current), RouteExportFromRoute(tree)); err != nil {
+		return nil, err
+	}
+	step.change.Action = definitions.ProvisioningImportActionNone
+	if len(step.change.Diff) > 0 {
+		step.change.Action = definitions.ProvisioningImportActionUpdate
+		step.apply = func(ctx context.Context) error {
+			return srv.policies.UpdatePolicyTree(ctx, orgID, *tree, provenance)
+		}
+	}
+	return []importStep{step}, nil
+}
+
+// planRuleGroupsImport plans the import of the rule groups. The folders are not created: the folder of a rule group is
+// identified by UID in HCL, and by title in the other formats. The rules that have no UID, like the ones exported to
+// HCL, get the UID of the rule of the group with the same title.
+func (srv *ProvisioningSrv) planRuleGroupsImport(c *contextmodel.ReqContext, file definitions.AlertingFileExport, provenance alerting_models.Provenance) ([]importStep, error) {
+	if len(file.Groups) == 0 {
+		return nil, nil
+	}
+	orgID := c.SignedInUser.GetOrgID()
+	folders, err := srv.namespaces.GetUserVisibleNamespaces(c.Req.Context(), orgID, c.SignedInUser)
+	if err != nil {
+		return nil, err
+	}
+	byTitle := make(map[string][]string, len(folders))
+	for uid, f := range folders {
+		byTitle[f.Title] = append(byTitle[f.Title], uid)
+	}
+
+	groups := make([]alerting_models.AlertRuleGroupWithFolderTitle, 0, len(file.Groups))
+	folderUIDs := make([]string, 0, len(file.Groups))
+	imported := make(map[alerting_models.AlertRuleGroupKey]struct{}, len(file.Groups))
+	for _, export := range file.Groups {
+		group, err := AlertRuleGroupFromAlertRuleGroupExport(export)
+		if err != nil {
+			return nil, fmt.Errorf("%w: rule group '%s': %s", alerting_models.ErrAlertRuleFailedValidation, export.Name, err.Error())
+		}
+		if group.FolderUID == "" {
+			uids := byTitle[export.Folder]
+			if len(uids) != 1 {
+				return nil, fmt.Errorf("%w: rule group '%s': expected one folder with title '%s', found %d", alerting_models.ErrAlertRuleFailedValidation, export.Name, export.Folder, len(uids))
+			}
+			group.FolderUID = uids[0]
+		}
+		f, ok := folders[group.FolderUID]
+		if !ok {
+			return nil, fmt.Errorf("%w: rule group '%s': folder '%s' does not exist", alerting_models.ErrAlertRuleFailedValidation, export.Name, group.FolderUID)
+		}
+		key := alerting_models.AlertRuleGroupKey{OrgID: orgID, NamespaceUID: group.FolderUID, RuleGroup: group.Title}
+		if _, ok := imported[key]; ok {
+			return nil, fmt.Errorf("%w: rule group '%s' of folder '%s' is defined more than once", alerting_models.ErrAlertRuleFailedValidation, group.Title, f.Title)
+		}
+		imported[key] = struct{}{}
+		groups = append(groups, alerting_models.AlertRuleGroupWithFolderTitle{AlertRuleGroup: &group, OrgID: orgID, FolderTitle: f.Title})
+		folderUIDs = append(folderUIDs, group.FolderUID)
+	}
+
+	existing, err := srv.alertRules.GetAlertGroupsWithFolderTitle(c.Req.Context(), c.SignedInUser, folderUIDs)
+	if err != nil {
+		return nil, err
+	}
+	existingByKey := make(map[alerting_models.AlertRuleGroupKey]alerting_models.AlertRuleGroupWithFolderTitle, len(existing))
+	for _, g := range existing {
+		existingByKey[alerting_models.AlertRuleGroupKey{OrgID: orgID, NamespaceUID: g.FolderUID, RuleGroup: g.Title}] = g
+	}
+
+	steps := make([]importStep, 0, len(groups))
+	for _, group := range groups {
+		step := importStep{change: definitions.ProvisioningImportChange{
+			Kind:   definitions.ProvisioningImportKindRuleGroup,
+			Name:   group.Title,
+			Folder: group.FolderTitle,
+		}}
+		current, ok := existingByKey[alerting_models.AlertRuleGroupKey{OrgID: orgID, NamespaceUID: group.FolderUID, RuleGroup: group.Title}]
+		if !ok {
+			step.change.Action = definitions.ProvisioningImportActionCreate
+		} else {
+			uids := make(map[string]string, len(current.Rules))
+			for _, r := range current.Rules {
+				uids[r.Title] = r.UID
+			}
+			for i := range group.Rules {
+				if group.Rules[i].UID == "" {
+					group.Rules[i].UID = uids[group.Rules[i].Title]
+				}
+			}
+			if step.change.Diff, err = diffRuleGroups(current, group); err != nil {
+				return nil, err
+			}
+			step.change.Action = definitions.ProvisioningImportActionUpdate
+			if len(step.change.Diff) == 0 {
+				step.change.Action = definitions.ProvisioningImportActionNone
+				steps = append(steps, step)
+				continue
+			}
+		}
+		ruleGroup := *group.AlertRuleGroup
+		step.apply = func(ctx context.Context) error {
+			return srv.alertRules.ReplaceRuleGroup(ctx, c.SignedInUser, ruleGroup, provenance)
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+func diffRuleGroups(current, imported alerting_models.AlertRuleGroupWithFolderTitle) ([]string, error) {
+	currentExport, err := AlertRuleGroupExportFromAlertRuleGroupWithFolderTitle(current)
+	if err != nil {
+		return nil, err
+	}
+	importedExport, err := AlertRuleGroupExportFromAlertRuleGroupWithFolderTitle(imported)
+	if err != nil {
+		return nil, err
+	}
+	return diffExports(currentExport, importedExport)
+}
+
+// diffExports returns the paths of the fields that are different in the JSON exports of the resources.
+func diffExports(current, imported any) ([]string, error) {
+	toGeneric := func(v any) (any, error) {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		var result any
+		return result, json.Unmarshal(b, &result)
+	}
+	c, err := toGeneric(current)
+	if err != nil {
+		return nil, err
+	}
+	i, err := toGeneric(imported)
+	if err != nil {
+		return nil, err
+	}
+	return diffValues("", c, i, nil), nil
+}
+
+func diffValues(path string, current, imported any, diff []string) []string {
+	switch c := current.(type) {
+	case map[string]any:
+		i, ok := imported.(map[string]any)
+		if !ok {
+			return append(diff, path)
+		}
+		keys := make([]string, 0, len(c)+len(i))
+		for k := range c {
+			keys = append(keys, k)
+		}
+		for k := range i {
+			if _, ok := c[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			diff = diffValues(p, c[k], i[k], diff)
+		}
+		return diff
+	case []any:
+		i, ok := imported.([]any)
+		if !ok {
+			return append(diff, path)
+		}
+		for idx := 0; idx < max(len(c), len(i)); idx++ {
+			p := fmt.Sprintf("%s[%d]", path, idx)
+			if idx >= len(c) || idx >= len(i) {
+				diff = append(diff, p)
+				continue
+			}
+			diff = diffValues(p, c[idx], i[idx], diff)
+		}
+		return diff
+	}
+	if !reflect.DeepEqual(current, imported) {
+		diff = append(diff, path)
+	}
+	return diff
+}
@@ -0,0 +1,244 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestProvisioningApiImport(t *testing.T) {
+	exports := map[string]func(srv *ProvisioningSrv, c *contextmodel.ReqContext) response.Response{
+		"rule group": func(srv *ProvisioningSrv, c *contextmodel.ReqContext) response.Response {
+			return srv.RouteGetAlertRuleGroupExport(c, "folder-uid", "my-cool-group")
+		},
+		"contact points": func(srv *ProvisioningSrv, c *contextmodel.ReqContext) response.Response {
+			return srv.RouteGetContactPointsExport(c)
+		},
+		"notification policies": func(srv *ProvisioningSrv, c *contextmodel.ReqContext) response.Response {
+			return srv.RouteGetPolicyTreeExport(c)
+		},
+		"mute timings": func(srv *ProvisioningSrv, c *contextmodel.ReqContext) response.Response {
+			return srv.RouteGetMuteTimingsExport(c)
+		},
+	}
+
+	for _, format := range []string{"yaml", "json", "hcl"} {
+		for name, export := range exports {
+			t.Run(name+" exported to "+format+" are not changed by the import", func(t *testing.T) {
+				sut := createProvisioningSrvSut(t)
+				policies := createFakeNotificationPolicyService()
+				if format == "hcl" {
+					// HCL has only the object matchers.
+					policies.tree.Routes[0].Matchers = nil
+				}
+				sut.policies = policies
+				insertRule(t, sut, createTestImportRule("rule1"))
+				insertRule(t, sut, createTestImportRule("rule2"))
+
+				rc := createTestRequestCtx()
+				rc.Context.Req.Form.Set("format", format)
+				exported := export(&sut, &rc)
+				require.Equal(t, 200, exported.Status())
+
+				result := importFile(t, &sut, format, exported.Body(), false)
+
+				require.NotEmpty(t, result.Changes)
+				for _, change := range result.Changes {
+					require.Equalf(t, definitions.ProvisioningImportActionNone, change.Action, "%s '%s' is changed: %v", change.Kind, change.Name, change.Diff)
+				}
+			})
+		}
+	}
+
+	t.Run("dry run reports the changes without applying them", func(t *testing.T) {
+		sut := createProvisioningSrvSut(t)
+		insertRule(t, sut, createTestImportRule("rule1"))
+		file := exportRuleGroup(t, &sut)
+		file.Groups[0].Rules[0].Annotations = &map[string]string{"summary": "changed"}
+		newGroup := file.Groups[0]
+		newGroup.Name = "my-new-group"
+		newGroup.Rules = []definitions.AlertRuleExport{newGroup.Rules[0]}
+		newGroup.Rules[0].UID = "new-rule"
+		file.Groups = append(file.Groups, newGroup)
+
+		result := importFile(t, &sut, "json", marshalFile(t, file), true)
+
+		require.True(t, result.DryRun)
+		require.Equal(t, []definitions.ProvisioningImportChange{
+			{
+				Kind:   definitions.ProvisioningImportKindRuleGroup,
+				Name:   "my-cool-group",
+				Folder: "Folder Title",
+				Action: definitions.ProvisioningImportActionUpdate,
+				Diff:   []string{"rules[0].annotations"},
+			},
+			{
+				Kind:   definitions.ProvisioningImportKindRuleGroup,
+				Name:   "my-new-group",
+				Folder: "Folder Title",
+				Action: definitions.ProvisioningImportActionCreate,
+			},
+		}, result.Changes)
+		require.Empty(t, exportRuleGroup(t, &sut).Groups[0].Rules[0].Annotations)
+	})
+
+	t.Run("import applies the changes", func(t *testing.T) {
+		sut := createProvisioningSrvSut(t)
+		sut.policies = createFakeNotificationPolicyService()
+		insertRule(t, sut, createTestImportRule("rule1"))
+		file := exportRuleGroup(t, &sut)
+		file.Groups[0].Rules[0].Annotations = &map[string]string{"summary": "changed"}
+		file.Policies = []definitions.NotificationPolicyExport{{
+			OrgID:       1,
+			RouteExport: &definitions.RouteExport{Receiver: "email-receiver"},
+		}}
+
+		result := importFile(t, &sut, "json", marshalFile(t, file), false)
+
+		require.False(t, result.DryRun)
+		require.Len(t, result.Changes, 2)
+		require.Equal(t, definitions.ProvisioningImportActionUpdate, result.Changes[0].Action)
+		require.Equal(t, definitions.ProvisioningImportKindPolicies, result.Changes[0].Kind)
+		require.Equal(t, definitions.ProvisioningImportActionUpdate, result.Changes[1].Action)
+		require.Equal(t, definitions.ProvisioningImportKindRuleGroup, result.Changes[1].Kind)
+		require.Equal(t, map[string]string{"summary": "changed"}, *exportRuleGroup(t, &sut).Groups[0].Rules[0].Annotations)
+		require.Equal(t, "email-receiver", sut.policies.(*fakeNotificationPolicyService).tree.Receiver)
+	})
+
+	t.Run("import applies the changes in one transaction", func(t *testing.T) {
+		sut := createProvisioningSrvSut(t)
+		xact := &recordingTransactionManager{}
+		sut.xact = xact
+		policies := &failingNotificationPolicyService{fakeNotificationPolicyService: createFakeNotificationPolicyService()}
+		sut.policies = policies
+		insertRule(t, sut, createTestImportRule("rule1"))
+		file := exportRuleGroup(t, &sut)
+		file.Groups[0].Rules[0].Annotations = &map[string]string{"summary": "changed"}
+		file.Policies = []definitions.NotificationPolicyExport{{
+			OrgID:       1,
+			RouteExport: &definitions.RouteExport{Receiver: "email-receiver"},
+		}}
+
+		response := postImport(&sut, "json", marshalFile(t, file), false)
+
+		require.Equal(t, 500, response.Status())
+		require.Contains(t, string(response.Body()), "failed to import policies, no changes were applied")
+		require.Equal(t, 1, xact.transactions)
+		require.True(t, policies.inTransaction, "the notification policies must be updated in the transaction of the import")
+	})
+
+	t.Run("rule group in unknown folder, POST returns 400", func(t *testing.T) {
+		sut := createProvisioningSrvSut(t)
+		insertRule(t, sut, createTestImportRule("rule1"))
+		file := exportRuleGroup(t, &sut)
+		file.Groups[0].Folder = "does not exist"
+
+		response := postImport(&sut, "json", marshalFile(t, file), false)
+
+		require.Equal(t, 400, response.Status())
+		require.Contains(t, string(response.Body()), "expected one folder with title 'does not exist'")
+	})
+
+	t.Run("file without resources, POST returns 400", func(t *testing.T) {
+		sut := createProvisioningSrvSut(t)
+
+		response := postImport(&sut, "yaml", []byte("apiVersion: 1\n"), false)
+
+		require.Equal(t, 400, response.Status())
+		require.Contains(t, string(response.Body()), "the file has no resources")
+	})
+
+	t.Run("unsupported HCL resource, POST returns 400", func(t *testing.T) {
+		sut := createProvisioningSrvSut(t)
+
+		response := postImport(&sut, "hcl", []byte(`resource "grafana_folder" "folder" {}`), false)
+
+		require.Equal(t, 400, response.Status())
+		require.Contains(t, string(response.Body()), "unsupported resource type grafana_folder")
+	})
+}
+
+// createTestImportRule returns a test rule with a relative time range that is valid after the round trip through an export.
+func createTestImportRule(title string) definitions.ProvisionedAlertRule {
+	rule := createTestAlertRule(title, 1)
+	rule.Data[0].RelativeTimeRange.From = definitions.Duration(time.Minute)
+	return rule
+}
+
+type recordingTransactionManager struct {
+	transactions int
+}
+
+type recordingTransactionKey struct{}
+
+func (m *recordingTransactionManager) InTransaction(ctx context.Context, work func(ctx context.Context) error) error {
+	if ctx.Value(recordingTransactionKey{}) != nil {
+		return work(ctx)
+	}
+	m.transactions++
+	return work(context.WithValue(ctx, recordingTransactionKey{}, struct{}{}))
+}
+
+// failingNotificationPolicyService fails to update the policy tree.
+type failingNotificationPolicyService struct {
+	*fakeNotificationPolicyService
+	inTransaction bool
+}
+
+func (f *failingNotificationPolicyService) UpdatePolicyTree(ctx context.Context, _ int64, _ definitions.Route, _ models.Provenance) error {
+	f.inTransaction = ctx.Value(recordingTransactionKey{}) != nil
+	return errors.New("failed to save the configuration")
+}
+
+func postImport(srv *ProvisioningSrv, format string, body []byte, dryRun bool) response.Response {
+	rc := createTestRequestCtx()
+	rc.Context.Req.Body = io.NopCloser(bytes.NewReader(body))
+	rc.Context.Req.Form.Set("format", format)
+	if dryRun {
+		rc.Context.Req.Form.Set("dryRun", "true")
+	}
+	return srv.RoutePostProvisioningImport(&rc)
+}
+
+func importFile(t *testing.T, srv *ProvisioningSrv, format string, body []byte, dryRun bool) definitions.ProvisioningImportResponse {
+	t.Helper()
+
+	resp := postImport(srv, format, body, dryRun)
+	require.Equalf(t, http.StatusOK, resp.Status(), "unexpected response: %s", strings.TrimSpace(string(resp.Body())))
+	var result definitions.ProvisioningImportResponse
+	require.NoError(t, json.Unmarshal(resp.Body(), &result))
+	return result
+}
+
+func exportRuleGroup(t *testing.T, srv *ProvisioningSrv) definitions.AlertingFileExport {
+	t.Helper()
+
+	rc := createTestRequestCtx()
+	rc.Context.Req.Form.Set("format", "json")
+	resp := srv.RouteGetAlertRuleGroupExport(&rc, "folder-uid", "my-cool-group")
+	require.Equal(t, http.StatusOK, resp.Status())
+	var file definitions.AlertingFileExport
+	require.NoError(t, json.Unmarshal(resp.Body(), &file))
+	return file
+}
+
+func marshalFile(t *testing.T, file definitions.AlertingFileExport) []byte {
+	t.Helper()
+
+	b, err := json.Marshal(file)
+	require.NoError(t, err)
+	return b
+}
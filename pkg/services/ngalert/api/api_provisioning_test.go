@@ -24,6 +24,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/log/logtest"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/folder"
@@ -1706,9 +1707,19 @@ func createProvisioningSrvSutFromEnv(t *testing.T, env *testEnvironment) Provisi
 		templates:           provisioning.NewTemplateService(env.configs, env.prov, env.xact, env.log),
 		muteTimings:         provisioning.NewMuteTimingService(env.configs, env.prov, env.xact, env.log),
 		alertRules:          provisioning.NewAlertRuleService(env.store, env.prov, env.folderService, env.dashboardService, env.quotas, env.xact, 60, 10, 100, env.log, &provisioning.NotificationSettingsValidatorProviderFake{}),
+		namespaces: fakeNamespaceService{
+			"folder-uid": {UID: "folder-uid", Title: "Folder Title"},
+		},
+		xact: env.xact,
 	}
 }
 
+type fakeNamespaceService map[string]*folder.Folder
+
+func (f fakeNamespaceService) GetUserVisibleNamespaces(context.Context, int64, identity.Requester) (map[string]*folder.Folder, error) {
+	return f, nil
+}
+
 func createTestRequestCtx() contextmodel.ReqContext {
 	return contextmodel.ReqContext{
 		Context: &web.Context{
@@ -301,9 +301,18 @@ func (srv RulerSrv) checkGroupLimits(group apimodels.PostableRuleGroupConfig) er
 
 // updateAlertRulesInGroup calculates changes (rules to add,update,delete), verifies that the user is authorized to do the calculated changes and updates database.
 // All operations are performed in a single transaction
+func (srv RulerSrv) updateAlertRulesInGroup(c *contextmodel.ReqContext, groupKey ngmodels.AlertRuleGroupKey, rules []*ngmodels.AlertRuleWithOptionals) response.Response {
+	finalChanges, err := srv.updateRuleGroup(c, groupKey, rules)
+	if err != nil {
+		return updateRuleGroupErrorResponse(err)
+	}
+	return changesToResponse(finalChanges)
+}
+
+// updateRuleGroup does the work of updateAlertRulesInGroup and returns the changes that were made.
 //
 //nolint:gocyclo
-func (srv RulerSrv) updateAlertRulesInGroup(c *contextmodel.ReqContext, groupKey ngmodels.AlertRuleGroupKey, rules []*ngmodels.AlertRuleWithOptionals) response.Response {
+func (srv RulerSrv) updateRuleGroup(c *contextmodel.ReqContext, groupKey ngmodels.AlertRuleGroupKey, rules []*ngmodels.AlertRuleWithOptionals) (*store.GroupDelta, error) {
 	var finalChanges *store.GroupDelta
 	var dbConfig *ngmodels.AlertConfiguration
 	err := srv.xactManager.InTransaction(c.Req.Context(), func(tranCtx context.Context) error {
@@ -418,18 +427,7 @@ func (srv RulerSrv) updateAlertRulesInGroup(c *contextmodel.ReqContext, groupKey
 	})
 
 	if err != nil {
-		if errors.As(err, &errutil.Error{}) {
-			return response.Err(err)
-		} else if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
-			return ErrResp(http.StatusNotFound, err, "failed to update rule group")
-		} else if errors.Is(err, ngmodels.ErrAlertRuleFailedValidation) || errors.Is(err, errProvisionedResource) {
-			return ErrResp(http.StatusBadRequest, err, "failed to update rule group")
-		} else if errors.Is(err, ngmodels.ErrQuotaReached) {
-			return ErrResp(http.StatusForbidden, err, "")
-		} else if errors.Is(err, store.ErrOptimisticLock) {
-			return ErrResp(http.StatusConflict, err, "")
-		}
-		return ErrResp(http.StatusInternalServerError, err, "failed to update rule group")
+		return nil, err
 	}
 
 	if srv.featureManager.IsEnabled(c.Req.Context(), featuremgmt.FlagAlertingSimplifiedRouting) && dbConfig != nil {
@@ -440,7 +438,22 @@ func (srv RulerSrv) updateAlertRulesInGroup(c *contextmodel.ReqContext, groupKey
 		}
 	}
 
-	return changesToResponse(finalChanges)
+	return finalChanges, nil
+}
+
+func updateRuleGroupErrorResponse(err error) response.Response {
+	if errors.As(err, &errutil.Error{}) {
+		return response.Err(err)
+	} else if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+		return ErrResp(http.StatusNotFound, err, "failed to update rule group")
+	} else if errors.Is(err, ngmodels.ErrAlertRuleFailedValidation) || errors.Is(err, errProvisionedResource) {
+		return ErrResp(http.StatusBadRequest, err, "failed to update rule group")
+	} else if errors.Is(err, ngmodels.ErrQuotaReached) {
+		return ErrResp(http.StatusForbidden, err, "")
+	} else if errors.Is(err, store.ErrOptimisticLock) {
+		return ErrResp(http.StatusConflict, err, "")
+	}
+	return ErrResp(http.StatusInternalServerError, err, "failed to update rule group")
 }
 
 func changesToResponse(finalChanges *store.GroupDelta) response.Response {
@@ -507,6 +520,8 @@ func toGettableExtendedRuleNode(r ngmodels.AlertRule, provenanceRecords map[stri
 			Provenance:           apimodels.Provenance(provenance),
 			IsPaused:             r.IsPaused,
 			NotificationSettings: AlertRuleNotificationSettingsFromNotificationSettings(r.NotificationSettings),
+			Record:               ApiRecordFromModelRecord(r.Record),
+			InhibitedBy:          ApiRuleInhibitionsFromModelRuleInhibitions(r.InhibitedBy),
 		},
 	}
 	forDuration := model.Duration(r.For)
@@ -515,6 +530,10 @@ func toGettableExtendedRuleNode(r ngmodels.AlertRule, provenanceRecords map[stri
 		Annotations: r.Annotations,
 		Labels:      r.Labels,
 	}
+	if r.KeepFiringFor > 0 {
+		keepFiringFor := model.Duration(r.KeepFiringFor)
+		gettableExtendedRuleNode.ApiRuleNode.KeepFiringFor = &keepFiringFor
+	}
 	return gettableExtendedRuleNode
 }
 
@@ -0,0 +1,202 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/prom"
+)
+
+// ConvertedRuleGroup is a Prometheus rule group converted to Grafana alert rules.
+type ConvertedRuleGroup struct {
+	Name  string
+	Rules []*ngmodels.AlertRuleWithOptionals
+	// Err is set if the group could not be converted.
+	Err error
+}
+
+// ConvertPrometheusRuleGroups converts Prometheus rule groups to Grafana alert rules of the folder, and validates them
+// like the rule groups posted to the ruler API. The rules that cannot be converted are left out of their group and
+// returned as errors. A group that cannot be converted has an error and no rules. The rules with the same name in a
+// group get unique titles, see prom.UniqueTitles.
+func ConvertPrometheusRuleGroups(
+	groups []apimodels.PrometheusRuleGroup,
+	cfg prom.Config,
+	orgID int64,
+	namespaceUID string,
+	limits RuleLimits) ([]ConvertedRuleGroup, []apimodels.PrometheusRuleImportError, error) {
+	converter, err := prom.NewConverter(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := make([]ConvertedRuleGroup, 0, len(groups))
+	var ruleErrors []apimodels.PrometheusRuleImportError
+	names := make(map[string]struct{}, len(groups))
+	for _, group := range groups {
+		converted := ConvertedRuleGroup{Name: group.Name}
+		if _, ok := names[group.Name]; ok {
+			converted.Err = errors.New("rule group is defined more than once")
+			result = append(result, converted)
+			continue
+		}
+		names[group.Name] = struct{}{}
+		if err := converter.CheckGroup(group); err != nil {
+			converted.Err = err
+			result = append(result, converted)
+			continue
+		}
+
+		interval := time.Duration(group.Interval)
+		if interval == 0 {
+			interval = limits.DefaultRuleEvaluationInterval
+		}
+		config := apimodels.PostableRuleGroupConfig{
+			Name:     group.Name,
+			Interval: group.Interval,
+			Rules:    make([]apimodels.PostableExtendedRuleNode, 0, len(group.Rules)),
+		}
+		for idx, rule := range group.Rules {
+			node, err := converter.ConvertRule(rule)
+			if err == nil {
+				_, err = validateRuleNode(&node, group.Name, interval, orgID, namespaceUID, limits)
+			}
+			if err != nil {
+				name := rule.Alert
+				if name == "" {
+					name = rule.Record
+				}
+				ruleErrors = append(ruleErrors, apimodels.PrometheusRuleImportError{
+					Group: group.Name,
+					Index: idx,
+					Rule:  name,
+					Error: err.Error(),
+				})
+				continue
+			}
+			config.Rules = append(config.Rules, node)
+		}
+		if len(config.Rules) == 0 {
+			converted.Err = errors.New("none of the rules of the group could be converted")
+			result = append(result, converted)
+			continue
+		}
+		// the rules that cannot be converted are left out before the titles are made unique, so that they do not
+		// change the titles, and therefore the UIDs, of the other rules.
+		prom.UniqueTitles(config.Rules)
+		converted.Rules, converted.Err = ValidateRuleGroup(&config, orgID, namespaceUID, limits)
+		result = append(result, converted)
+	}
+	return result, ruleErrors, nil
+}
+
+// RoutePostPrometheusRulesImport converts the rule groups of a Prometheus rule file to Grafana alert rules and saves them in the folder.
+// Each rule group replaces the rule group with the same name in the folder. The rules keep the UID of the rule with the
+// same title in the group, so importing the same file again updates the rules instead of creating new ones.
+func (srv RulerSrv) RoutePostPrometheusRulesImport(c *contextmodel.ReqContext, namespaceUID string) response.Response {
+	datasourceUID := c.Query("datasourceUid")
+	if datasourceUID == "" {
+		return ErrResp(http.StatusBadRequest, errors.New("datasourceUid must be specified"), "")
+	}
+
+	body, err := io.ReadAll(c.Req.Body)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to read the rule file")
+	}
+	// YAML is a superset of JSON, so both formats are parsed with the YAML parser.
+	var file apimodels.PrometheusRuleFile
+	if err := yaml.Unmarshal(body, &file); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to parse the rule file")
+	}
+	if len(file.Groups) == 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("rule file has no rule groups"), "")
+	}
+
+	namespace, err := srv.store.GetNamespaceByUID(c.Req.Context(), namespaceUID, c.SignedInUser.GetOrgID(), c.SignedInUser)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	groups, ruleErrors, err := ConvertPrometheusRuleGroups(file.Groups, prom.Config{DatasourceUID: datasourceUID}, c.SignedInUser.GetOrgID(), namespace.UID, RuleLimitsFromConfig(srv.cfg))
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	res := apimodels.PrometheusRulesImportResponse{
+		Groups: make([]apimodels.PrometheusRuleGroupImportResult, 0, len(groups)),
+		Errors: ruleErrors,
+	}
+	imported := 0
+	for _, group := range groups {
+		result := apimodels.PrometheusRuleGroupImportResult{Name: group.Name}
+		if group.Err == nil {
+			groupKey := ngmodels.AlertRuleGroupKey{
+				OrgID:        c.SignedInUser.GetOrgID(),
+				NamespaceUID: namespace.UID,
+				RuleGroup:    group.Name,
+			}
+			group.Err = srv.importRuleGroup(c, groupKey, group.Rules, &result)
+		}
+		if group.Err != nil {
+			srv.log.Warn("Failed to import Prometheus rule group", "namespace_uid", namespace.UID, "group", group.Name, "error", group.Err)
+			result.Error = group.Err.Error()
+		} else {
+			imported++
+		}
+		res.Groups = append(res.Groups, result)
+	}
+	res.Message = fmt.Sprintf("%d of %d rule groups imported", imported, len(groups))
+	return response.JSON(http.StatusAccepted, res)
+}
+
+// importRuleGroup replaces the rule group with the converted rules. The converted rules get the UID of the existing rule
+// of the group with the same title. The group is not imported if a converted rule has the title of a rule of another
+// group of the folder, because titles must be unique in a folder.
+func (srv RulerSrv) importRuleGroup(c *contextmodel.ReqContext, groupKey ngmodels.AlertRuleGroupKey, rules []*ngmodels.AlertRuleWithOptionals, result *apimodels.PrometheusRuleGroupImportResult) error {
+	existing, err := srv.store.ListAlertRules(c.Req.Context(), &ngmodels.ListAlertRulesQuery{
+		OrgID:         groupKey.OrgID,
+		NamespaceUIDs: []string{groupKey.NamespaceUID},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get the rules of the folder: %w", err)
+	}
+	uids := make(map[string]string, len(existing))
+	otherGroups := make(map[string]string, len(existing))
+	for _, rule := range existing {
+		if rule.RuleGroup == groupKey.RuleGroup {
+			uids[rule.Title] = rule.UID
+		} else {
+			otherGroups[rule.Title] = rule.RuleGroup
+		}
+	}
+	for _, rule := range rules {
+		if group, ok := otherGroups[rule.Title]; ok {
+			return fmt.Errorf("rule %q has the same title as a rule of the group %q in the folder", rule.Title, group)
+		}
+		rule.UID = uids[rule.Title]
+	}
+
+	changes, err := srv.updateRuleGroup(c, groupKey, rules)
+	if err != nil {
+		return err
+	}
+	for _, r := range changes.New {
+		result.Created = append(result.Created, r.UID)
+	}
+	for _, r := range changes.Update {
+		result.Updated = append(result.Updated, r.Existing.UID)
+	}
+	for _, r := range changes.Delete {
+		result.Deleted = append(result.Deleted, r.UID)
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/prom"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+)
+
+const prometheusRuleFile = `
+groups:
+  - name: node
+    interval: 30s
+    rules:
+      - alert: HostDown
+        expr: up == 0
+        for: 5m
+        labels:
+          severity: warning
+        annotations:
+          summary: '{{ $labels.instance }} is down ({{ $value }})'
+      - alert: HostDown
+        expr: up == 0
+        for: 15m
+        labels:
+          severity: critical
+      - record: instance:up:sum
+        expr: sum by (instance) (up)
+      - alert: Broken
+        expr: up ==
+  - name: limited
+    limit: 10
+    rules:
+      - alert: Limited
+        expr: up == 0
+`
+
+func TestRoutePostPrometheusRulesImport(t *testing.T) {
+	orgID := rand.Int63()
+	folder := randFolder()
+	permissions := map[int64]map[string][]string{
+		orgID: {
+			datasources.ActionQuery:     []string{datasources.ScopeAll},
+			ac.ActionAlertingRuleCreate: []string{dashboards.ScopeFoldersAll},
+			ac.ActionAlertingRuleUpdate: []string{dashboards.ScopeFoldersAll},
+			ac.ActionAlertingRuleDelete: []string{dashboards.ScopeFoldersAll},
+			ac.ActionAlertingRuleRead:   []string{dashboards.ScopeFoldersAll},
+		},
+	}
+
+	createRequest := func(query, body string) *contextmodel.ReqContext {
+		req := createRequestContextWithPerms(orgID, permissions, nil)
+		req.Req.Form, _ = url.ParseQuery(query)
+		req.Req.Body = io.NopCloser(strings.NewReader(body))
+		return req
+	}
+	createService := func(ruleStore *fakes.RuleStore) *RulerSrv {
+		svc := createService(ruleStore)
+		svc.conditionValidator = &recordingConditionValidator{}
+		svc.QuotaService = quotatest.New(false, nil)
+		svc.cfg.DefaultRuleEvaluationInterval = time.Minute
+		return svc
+	}
+	parseResponse := func(t *testing.T, resp response.Response) apimodels.PrometheusRulesImportResponse {
+		t.Helper()
+		require.Equal(t, http.StatusAccepted, resp.Status(), string(resp.Body()))
+		var result apimodels.PrometheusRulesImportResponse
+		require.NoError(t, json.Unmarshal(resp.Body(), &result))
+		return result
+	}
+
+	t.Run("should import the rules that can be converted and report the others", func(t *testing.T) {
+		ruleStore := fakes.NewRuleStore(t)
+		ruleStore.Folders[orgID] = append(ruleStore.Folders[orgID], folder)
+		svc := createService(ruleStore)
+
+		result := parseResponse(t, svc.RoutePostPrometheusRulesImport(createRequest("datasourceUid=prom", prometheusRuleFile), folder.UID))
+
+		require.Equal(t, "1 of 2 rule groups imported", result.Message)
+		require.Len(t, result.Groups, 2)
+		require.Equal(t, "node", result.Groups[0].Name)
+		require.Empty(t, result.Groups[0].Error)
+		require.Len(t, result.Groups[0].Created, 2)
+		require.Equal(t, "limited", result.Groups[1].Name)
+		require.Equal(t, "limit is not supported", result.Groups[1].Error)
+
+		require.Len(t, result.Errors, 2)
+		require.Equal(t, "node", result.Errors[0].Group)
+		require.Equal(t, 2, result.Errors[0].Index)
+		require.Equal(t, "instance:up:sum", result.Errors[0].Rule)
+		require.Contains(t, result.Errors[0].Error, "recording rules are not enabled")
+		require.Equal(t, 3, result.Errors[1].Index)
+		require.Equal(t, "Broken", result.Errors[1].Rule)
+		require.Contains(t, result.Errors[1].Error, "invalid expression")
+
+		inserts := ruleStore.GetRecordedCommands(func(cmd any) (any, bool) {
+			c, ok := cmd.([]models.AlertRule)
+			return c, ok
+		})
+		require.Len(t, inserts, 1)
+		rules := inserts[0].([]models.AlertRule)
+		require.Len(t, rules, 2)
+		require.Equal(t, "HostDown", rules[0].Title)
+		require.Equal(t, "HostDown (2)", rules[1].Title)
+		require.Equal(t, folder.UID, rules[0].NamespaceUID)
+		require.Equal(t, "node", rules[0].RuleGroup)
+		require.EqualValues(t, 30, rules[0].IntervalSeconds)
+		require.Equal(t, "C", rules[0].Condition)
+		require.Equal(t, "prom", rules[0].Data[0].DatasourceUID)
+		require.Equal(t, models.OK, rules[0].NoDataState)
+		require.Equal(t, "{{ $labels.instance }} is down ({{ $values.A.Value }})", rules[0].Annotations["summary"])
+		require.Equal(t, "critical", rules[1].Labels["severity"])
+	})
+
+	t.Run("should keep the UID of the rules with the same title", func(t *testing.T) {
+		ruleStore := fakes.NewRuleStore(t)
+		ruleStore.Folders[orgID] = append(ruleStore.Folders[orgID], folder)
+		groupKey := models.AlertRuleGroupKey{OrgID: orgID, NamespaceUID: folder.UID, RuleGroup: "node"}
+		gen := models.AlertRuleGen(withGroupKey(groupKey), models.WithNoNotificationSettings(), models.WithUniqueGroupIndex())
+		existing := gen()
+		existing.Title = "HostDown"
+		obsolete := gen()
+		ruleStore.PutRule(context.Background(), existing, obsolete)
+		svc := createService(ruleStore)
+
+		result := parseResponse(t, svc.RoutePostPrometheusRulesImport(createRequest("datasourceUid=prom", prometheusRuleFile), folder.UID))
+
+		require.Equal(t, []string{existing.UID}, result.Groups[0].Updated)
+		require.Equal(t, []string{obsolete.UID}, result.Groups[0].Deleted)
+		require.Len(t, result.Groups[0].Created, 1)
+	})
+
+	t.Run("should not import a group whose rules have the title of a rule of another group of the folder", func(t *testing.T) {
+		ruleStore := fakes.NewRuleStore(t)
+		ruleStore.Folders[orgID] = append(ruleStore.Folders[orgID], folder)
+		groupKey := models.AlertRuleGroupKey{OrgID: orgID, NamespaceUID: folder.UID, RuleGroup: "other"}
+		existing := models.AlertRuleGen(withGroupKey(groupKey), models.WithNoNotificationSettings())()
+		existing.Title = "HostDown (2)"
+		ruleStore.PutRule(context.Background(), existing)
+		svc := createService(ruleStore)
+
+		result := parseResponse(t, svc.RoutePostPrometheusRulesImport(createRequest("datasourceUid=prom", prometheusRuleFile), folder.UID))
+
+		require.Equal(t, "0 of 2 rule groups imported", result.Message)
+		require.Equal(t, `rule "HostDown (2)" has the same title as a rule of the group "other" in the folder`, result.Groups[0].Error)
+		require.Empty(t, result.Groups[0].Created)
+	})
+
+	t.Run("should accept JSON", func(t *testing.T) {
+		ruleStore := fakes.NewRuleStore(t)
+		ruleStore.Folders[orgID] = append(ruleStore.Folders[orgID], folder)
+		svc := createService(ruleStore)
+		body := `{"groups":[{"name":"node","rules":[{"alert":"HostDown","expr":"up == 0","for":"1m"}]}]}`
+
+		result := parseResponse(t, svc.RoutePostPrometheusRulesImport(createRequest("datasourceUid=prom", body), folder.UID))
+
+		require.Equal(t, "1 of 1 rule groups imported", result.Message)
+		require.Empty(t, result.Errors)
+	})
+
+	t.Run("should return BadRequest if the data source is not specified", func(t *testing.T) {
+		svc := createService(fakes.NewRuleStore(t))
+		resp := svc.RoutePostPrometheusRulesImport(createRequest("", prometheusRuleFile), folder.UID)
+		require.Equal(t, http.StatusBadRequest, resp.Status())
+	})
+
+	t.Run("should return BadRequest if the rule file is invalid", func(t *testing.T) {
+		svc := createService(fakes.NewRuleStore(t))
+		resp := svc.RoutePostPrometheusRulesImport(createRequest("datasourceUid=prom", "groups: {"), folder.UID)
+		require.Equal(t, http.StatusBadRequest, resp.Status())
+	})
+}
+
+func TestConvertPrometheusRuleGroups(t *testing.T) {
+	limits := RuleLimits{DefaultRuleEvaluationInterval: time.Minute, BaseInterval: 10 * time.Second}
+	convert := func(t *testing.T, groups ...apimodels.PrometheusRuleGroup) map[string][]string {
+		t.Helper()
+		converted, _, err := ConvertPrometheusRuleGroups(groups, prom.Config{DatasourceUID: "prom"}, 1, "folder", limits)
+		require.NoError(t, err)
+		titles := map[string][]string{}
+		for _, g := range converted {
+			require.NoError(t, g.Err)
+			for _, r := range g.Rules {
+				titles[g.Name] = append(titles[g.Name], r.Title)
+			}
+		}
+		return titles
+	}
+	hostDown := apimodels.ApiRuleNode{Alert: "HostDown", Expr: "up == 0"}
+	broken := apimodels.ApiRuleNode{Alert: "HostDown", Expr: "up =="}
+
+	t.Run("should make the titles unique within each group", func(t *testing.T) {
+		titles := convert(t,
+			apimodels.PrometheusRuleGroup{Name: "a", Rules: []apimodels.ApiRuleNode{hostDown, hostDown}},
+			apimodels.PrometheusRuleGroup{Name: "b", Rules: []apimodels.ApiRuleNode{hostDown}},
+		)
+		require.Equal(t, map[string][]string{"a": {"HostDown", "HostDown (2)"}, "b": {"HostDown"}}, titles)
+	})
+
+	t.Run("should not number the rules that cannot be converted", func(t *testing.T) {
+		titles := convert(t, apimodels.PrometheusRuleGroup{Name: "a", Rules: []apimodels.ApiRuleNode{broken, hostDown, hostDown}})
+		require.Equal(t, map[string][]string{"a": {"HostDown", "HostDown (2)"}}, titles)
+	})
+
+	t.Run("should convert the first of the groups with the same name", func(t *testing.T) {
+		groups := []apimodels.PrometheusRuleGroup{
+			{Name: "a", Rules: []apimodels.ApiRuleNode{hostDown}},
+			{Name: "a", Rules: []apimodels.ApiRuleNode{{Alert: "Other", Expr: "up == 0"}}},
+		}
+		converted, _, err := ConvertPrometheusRuleGroups(groups, prom.Config{DatasourceUID: "prom"}, 1, "folder", limits)
+		require.NoError(t, err)
+		require.Len(t, converted, 2)
+		require.NoError(t, converted[0].Err)
+		require.Equal(t, "HostDown", converted[0].Rules[0].Title)
+		require.EqualError(t, converted[1].Err, "rule group is defined more than once")
+	})
+}
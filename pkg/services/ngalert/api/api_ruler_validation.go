@@ -18,12 +18,15 @@ type RuleLimits struct {
 	DefaultRuleEvaluationInterval time.Duration
 	// All intervals must be an integer multiple of this duration.
 	BaseInterval time.Duration
+	// Whether recording rules are allowed.
+	RecordingRulesAllowed bool
 }
 
 func RuleLimitsFromConfig(cfg *setting.UnifiedAlertingSettings) RuleLimits {
 	return RuleLimits{
 		DefaultRuleEvaluationInterval: cfg.DefaultRuleEvaluationInterval,
 		BaseInterval:                  cfg.BaseInterval,
+		RecordingRulesAllowed:         cfg.RecordingRules.Enabled,
 	}
 }
 
@@ -88,8 +91,26 @@ func validateRuleNode(
 		} else {
 			return nil, fmt.Errorf("%w: no queries or expressions are found", ngmodels.ErrAlertRuleFailedValidation)
 		}
-	} else {
-		err = validateCondition(ruleNode.GrafanaManagedAlert.Condition, ruleNode.GrafanaManagedAlert.Data)
+	}
+
+	condition := ruleNode.GrafanaManagedAlert.Condition
+	record := ModelRecordFromApiRecord(ruleNode.GrafanaManagedAlert.Record)
+	if record != nil {
+		if !limits.RecordingRulesAllowed {
+			return nil, fmt.Errorf("%w: recording rules are not enabled", ngmodels.ErrAlertRuleFailedValidation)
+		}
+		if ruleNode.GrafanaManagedAlert.NotificationSettings != nil {
+			return nil, fmt.Errorf("%w: recording rules cannot have notification settings", ngmodels.ErrAlertRuleFailedValidation)
+		}
+		// the condition of a recording rule is always the recorded query or expression.
+		if condition != "" && condition != record.From {
+			return nil, fmt.Errorf("%w: condition of a recording rule must be the same as the recorded query or expression '%s'", ngmodels.ErrAlertRuleFailedValidation, record.From)
+		}
+		condition = record.From
+	}
+
+	if len(ruleNode.GrafanaManagedAlert.Data) > 0 {
+		err = validateCondition(condition, ruleNode.GrafanaManagedAlert.Data)
 		if err != nil {
 			return nil, fmt.Errorf("%w: %s", ngmodels.ErrAlertRuleFailedValidation, err.Error())
 		}
@@ -97,10 +118,17 @@ func validateRuleNode(
 
 	queries := AlertQueriesFromApiAlertQueries(ruleNode.GrafanaManagedAlert.Data)
 
+	// if queries are not specified, the record is validated after the rule is patched with the queries of the current version.
+	if record != nil && len(queries) > 0 {
+		if err := record.Validate(queries); err != nil {
+			return nil, fmt.Errorf("%w: invalid record: %s", ngmodels.ErrAlertRuleFailedValidation, err.Error())
+		}
+	}
+
 	newAlertRule := ngmodels.AlertRule{
 		OrgID:           orgId,
 		Title:           ruleNode.GrafanaManagedAlert.Title,
-		Condition:       ruleNode.GrafanaManagedAlert.Condition,
+		Condition:       condition,
 		Data:            queries,
 		UID:             ruleNode.GrafanaManagedAlert.UID,
 		IntervalSeconds: intervalSeconds,
@@ -108,6 +136,7 @@ func validateRuleNode(
 		RuleGroup:       groupName,
 		NoDataState:     noDataState,
 		ExecErrState:    errorState,
+		Record:          record,
 	}
 
 	if ruleNode.GrafanaManagedAlert.NotificationSettings != nil {
@@ -117,11 +146,26 @@ func validateRuleNode(
 		}
 	}
 
+	if len(ruleNode.GrafanaManagedAlert.InhibitedBy) > 0 {
+		if record != nil {
+			return nil, fmt.Errorf("%w: recording rules cannot be inhibited", ngmodels.ErrAlertRuleFailedValidation)
+		}
+		newAlertRule.InhibitedBy, err = validateInhibitions(newAlertRule.UID, ruleNode.GrafanaManagedAlert.InhibitedBy)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	newAlertRule.For, err = validateForInterval(ruleNode)
 	if err != nil {
 		return nil, err
 	}
 
+	newAlertRule.KeepFiringFor, err = validateKeepFiringForInterval(ruleNode)
+	if err != nil {
+		return nil, err
+	}
+
 	if ruleNode.ApiRuleNode != nil {
 		newAlertRule.Annotations = ruleNode.ApiRuleNode.Annotations
 		err = validateLabels(ruleNode.Labels)
@@ -206,6 +250,21 @@ func validateForInterval(ruleNode *apimodels.PostableExtendedRuleNode) (time.Dur
 	return duration, nil
 }
 
+// validateKeepFiringForInterval validates ApiRuleNode.KeepFiringFor and converts it to time.Duration. If the field is not specified returns 0 if GrafanaManagedAlert.UID is empty and -1 if it is not.
+func validateKeepFiringForInterval(ruleNode *apimodels.PostableExtendedRuleNode) (time.Duration, error) {
+	if ruleNode.ApiRuleNode == nil || ruleNode.ApiRuleNode.KeepFiringFor == nil {
+		if ruleNode.GrafanaManagedAlert.UID != "" {
+			return -1, nil // will be patched later with the real value of the current version of the rule
+		}
+		return 0, nil // if it's a new rule, use the 0 as the default
+	}
+	duration := time.Duration(*ruleNode.ApiRuleNode.KeepFiringFor)
+	if duration < 0 {
+		return 0, fmt.Errorf("field `keep_firing_for` cannot be negative [%v]. 0 or any positive duration are allowed", *ruleNode.ApiRuleNode.KeepFiringFor)
+	}
+	return duration, nil
+}
+
 // ValidateRuleGroup validates API model (definitions.PostableRuleGroupConfig) and converts it to a collection of models.AlertRule.
 // Returns a slice that contains all rules described by API model or error if either group specification or an alert definition is not valid.
 // It also returns a map containing current existing alerts that don't contain the is_paused field in the body of the call.
@@ -286,3 +345,20 @@ func validateNotificationSettings(n *apimodels.AlertRuleNotificationSettings) ([
 		s,
 	}, nil
 }
+
+// validateInhibitions validates the inhibitions of the rule with the given UID and converts them to models.RuleInhibition.
+// It does not check that the inhibiting rules exist. An inhibition by a rule that does not exist has no effect.
+func validateInhibitions(ruleUID string, inhibitions []apimodels.RuleInhibition) ([]ngmodels.RuleInhibition, error) {
+	result := ModelRuleInhibitionsFromApiRuleInhibitions(inhibitions)
+	seen := make(map[string]struct{}, len(result))
+	for _, inhibition := range result {
+		if err := inhibition.Validate(ruleUID); err != nil {
+			return nil, fmt.Errorf("%w: invalid inhibition: %s", ngmodels.ErrAlertRuleFailedValidation, err.Error())
+		}
+		if _, ok := seen[inhibition.RuleUID]; ok {
+			return nil, fmt.Errorf("%w: rule %s is specified more than once in inhibitions", ngmodels.ErrAlertRuleFailedValidation, inhibition.RuleUID)
+		}
+		seen[inhibition.RuleUID] = struct{}{}
+	}
+	return result, nil
+}
@@ -44,9 +44,11 @@ func config(t *testing.T) *setting.UnifiedAlertingSettings {
 
 func validRule() apimodels.PostableExtendedRuleNode {
 	forDuration := model.Duration(rand.Int63n(1000))
+	keepFiringFor := model.Duration(rand.Int63n(1000))
 	return apimodels.PostableExtendedRuleNode{
 		ApiRuleNode: &apimodels.ApiRuleNode{
-			For: &forDuration,
+			For:           &forDuration,
+			KeepFiringFor: &keepFiringFor,
 			Labels: map[string]string{
 				"test-label": "data",
 			},
@@ -336,6 +338,7 @@ func TestValidateRuleNode_NoUID(t *testing.T) {
 				require.Equal(t, models.NoDataState(api.GrafanaManagedAlert.NoDataState), alert.NoDataState)
 				require.Equal(t, models.ExecutionErrorState(api.GrafanaManagedAlert.ExecErrState), alert.ExecErrState)
 				require.Equal(t, time.Duration(*api.ApiRuleNode.For), alert.For)
+				require.Equal(t, time.Duration(*api.ApiRuleNode.KeepFiringFor), alert.KeepFiringFor)
 				require.Equal(t, api.ApiRuleNode.Annotations, alert.Annotations)
 				require.Equal(t, api.ApiRuleNode.Labels, alert.Labels)
 			},
@@ -349,6 +352,7 @@ func TestValidateRuleNode_NoUID(t *testing.T) {
 			},
 			assert: func(t *testing.T, api *apimodels.PostableExtendedRuleNode, alert *models.AlertRule) {
 				require.Equal(t, time.Duration(0), alert.For)
+				require.Equal(t, time.Duration(0), alert.KeepFiringFor)
 				require.Nil(t, alert.Annotations)
 				require.Nil(t, alert.Labels)
 			},
@@ -451,6 +455,15 @@ func TestValidateRuleNodeFailures_NoUID(t *testing.T) {
 				return &r
 			},
 		},
+		{
+			name: "fail if keep_firing_for is negative",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				keepFiringFor := model.Duration(-time.Minute)
+				r.ApiRuleNode.KeepFiringFor = &keepFiringFor
+				return &r
+			},
+		},
 		{
 			name: "fail if NoDataState is not known",
 			rule: func() *apimodels.PostableExtendedRuleNode {
@@ -907,3 +920,148 @@ func TestValidateRuleNodeReservedLabels(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateRuleNodeRecord(t *testing.T) {
+	cfg := config(t)
+	cfg.RecordingRules.Enabled = true
+
+	t.Run("should use the recorded query as condition", func(t *testing.T) {
+		r := validRule()
+		r.GrafanaManagedAlert.Condition = ""
+		r.GrafanaManagedAlert.Record = &apimodels.Record{Metric: "test_metric", From: "A"}
+
+		alert, err := validateRuleNode(&r, util.GenerateShortUID(), cfg.BaseInterval, rand.Int63(), randFolder().UID, RuleLimitsFromConfig(cfg))
+		require.NoError(t, err)
+		require.Equal(t, "A", alert.Condition)
+		require.Equal(t, &models.Record{Metric: "test_metric", From: "A"}, alert.Record)
+		require.Equal(t, models.RuleTypeRecording, alert.Type())
+	})
+
+	testCases := []struct {
+		name             string
+		mutate           func(r *apimodels.PostableExtendedRuleNode)
+		limits           func(l *RuleLimits)
+		expErrorContains string
+	}{
+		{
+			name:             "recording rules are disabled",
+			limits:           func(l *RuleLimits) { l.RecordingRulesAllowed = false },
+			expErrorContains: "not enabled",
+		},
+		{
+			name: "metric name is empty",
+			mutate: func(r *apimodels.PostableExtendedRuleNode) {
+				r.GrafanaManagedAlert.Record.Metric = ""
+			},
+			expErrorContains: "metric",
+		},
+		{
+			name: "metric name is invalid",
+			mutate: func(r *apimodels.PostableExtendedRuleNode) {
+				r.GrafanaManagedAlert.Record.Metric = "invalid metric"
+			},
+			expErrorContains: "metric",
+		},
+		{
+			name: "recorded query does not exist",
+			mutate: func(r *apimodels.PostableExtendedRuleNode) {
+				r.GrafanaManagedAlert.Record.From = "B"
+				r.GrafanaManagedAlert.Condition = ""
+			},
+			expErrorContains: "B",
+		},
+		{
+			name: "condition differs from the recorded query",
+			mutate: func(r *apimodels.PostableExtendedRuleNode) {
+				r.GrafanaManagedAlert.Condition = "B"
+			},
+			expErrorContains: "condition",
+		},
+		{
+			name: "notification settings are specified",
+			mutate: func(r *apimodels.PostableExtendedRuleNode) {
+				r.GrafanaManagedAlert.NotificationSettings = AlertRuleNotificationSettingsFromNotificationSettings([]models.NotificationSettings{models.NotificationSettingsGen()()})
+			},
+			expErrorContains: "notification settings",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			r := validRule()
+			r.GrafanaManagedAlert.Record = &apimodels.Record{Metric: "test_metric", From: "A"}
+			if tt.mutate != nil {
+				tt.mutate(&r)
+			}
+			limits := RuleLimitsFromConfig(cfg)
+			if tt.limits != nil {
+				tt.limits(&limits)
+			}
+			_, err := validateRuleNode(&r, util.GenerateShortUID(), cfg.BaseInterval, rand.Int63(), randFolder().UID, limits)
+			require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+			require.ErrorContains(t, err, tt.expErrorContains)
+		})
+	}
+}
+
+func TestValidateRuleNodeInhibitedBy(t *testing.T) {
+	cfg := config(t)
+
+	t.Run("should convert inhibitions", func(t *testing.T) {
+		r := validRule()
+		r.GrafanaManagedAlert.InhibitedBy = []apimodels.RuleInhibition{{RuleUID: "other", Equal: []string{"datacenter"}}}
+
+		alert, err := validateRuleNode(&r, util.GenerateShortUID(), cfg.BaseInterval, rand.Int63(), randFolder().UID, RuleLimitsFromConfig(cfg))
+		require.NoError(t, err)
+		require.Equal(t, []models.RuleInhibition{{RuleUID: "other", Equal: []string{"datacenter"}}}, alert.InhibitedBy)
+	})
+
+	testCases := []struct {
+		name             string
+		inhibitedBy      []apimodels.RuleInhibition
+		expErrorContains string
+	}{
+		{
+			name:             "rule UID is empty",
+			inhibitedBy:      []apimodels.RuleInhibition{{Equal: []string{"datacenter"}}},
+			expErrorContains: "cannot be empty",
+		},
+		{
+			name:             "rule inhibits itself",
+			inhibitedBy:      []apimodels.RuleInhibition{{RuleUID: "rule-uid"}},
+			expErrorContains: "itself",
+		},
+		{
+			name:             "rule is specified twice",
+			inhibitedBy:      []apimodels.RuleInhibition{{RuleUID: "other"}, {RuleUID: "other", Equal: []string{"datacenter"}}},
+			expErrorContains: "more than once",
+		},
+		{
+			name:             "label name is invalid",
+			inhibitedBy:      []apimodels.RuleInhibition{{RuleUID: "other", Equal: []string{"data center"}}},
+			expErrorContains: "label name",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			r := validRule()
+			r.GrafanaManagedAlert.UID = "rule-uid"
+			r.GrafanaManagedAlert.InhibitedBy = tt.inhibitedBy
+			_, err := validateRuleNode(&r, util.GenerateShortUID(), cfg.BaseInterval, rand.Int63(), randFolder().UID, RuleLimitsFromConfig(cfg))
+			require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+			require.ErrorContains(t, err, tt.expErrorContains)
+		})
+	}
+
+	t.Run("recording rules cannot be inhibited", func(t *testing.T) {
+		cfg := config(t)
+		cfg.RecordingRules.Enabled = true
+		r := validRule()
+		r.GrafanaManagedAlert.Record = &apimodels.Record{Metric: "test_metric", From: "A"}
+		r.GrafanaManagedAlert.InhibitedBy = []apimodels.RuleInhibition{{RuleUID: "other"}}
+		_, err := validateRuleNode(&r, util.GenerateShortUID(), cfg.BaseInterval, rand.Int63(), randFolder().UID, RuleLimitsFromConfig(cfg))
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+		require.ErrorContains(t, err, "cannot be inhibited")
+	})
+}
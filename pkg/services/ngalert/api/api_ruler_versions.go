@@ -0,0 +1,236 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/util/cmputil"
+)
+
+// ruleVersionDiffSections maps the top-level fields of models.AlertRule to the sections reported in apimodels.RuleVersionChange.
+var ruleVersionDiffSections = map[string]string{
+	"Title":                "title",
+	"Condition":            "condition",
+	"Data":                 "data",
+	"IntervalSeconds":      "interval_seconds",
+	"NamespaceUID":         "namespace_uid",
+	"RuleGroup":            "rule_group",
+	"RuleGroupIndex":       "rule_group_index",
+	"NoDataState":          "no_data_state",
+	"ExecErrState":         "exec_err_state",
+	"For":                  "for",
+	"KeepFiringFor":        "keep_firing_for",
+	"Annotations":          "annotations",
+	"Labels":               "labels",
+	"IsPaused":             "is_paused",
+	"NotificationSettings": "notification_settings",
+	"Record":               "record",
+	"InhibitedBy":          "inhibited_by",
+	"DashboardUID":         "dashboard_uid",
+	"PanelID":              "panel_id",
+}
+
+// RouteGetRuleVersionsByUID returns all versions of the rule, newest first.
+// Versions that use data sources the user does not have access to are omitted.
+func (srv RulerSrv) RouteGetRuleVersionsByUID(c *contextmodel.ReqContext, ruleUID string) response.Response {
+	if _, _, err := srv.getAuthorizedRuleByUID(c.Req.Context(), c, ruleUID); err != nil {
+		return toRuleVersionErrorResponse(err)
+	}
+	versions, err := srv.getAuthorizedRuleVersions(c.Req.Context(), c, ruleUID)
+	if err != nil {
+		return toRuleVersionErrorResponse(err)
+	}
+
+	result := make(apimodels.GettableRuleVersions, 0, len(versions))
+	for _, v := range versions {
+		result = append(result, toGettableExtendedRuleNode(v.AlertRule(), nil))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// RouteGetRuleVersionsDiff returns the changes of the rule definition between two versions.
+// If the query parameter "to" is not specified, the version "from" is compared to the current version of the rule.
+// It responds with Forbidden if the user cannot read either version, and NotFound if a version does not exist.
+func (srv RulerSrv) RouteGetRuleVersionsDiff(c *contextmodel.ReqContext, ruleUID string) response.Response {
+	from := c.QueryInt64("from")
+	to := c.QueryInt64("to")
+	if from <= 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("query parameter 'from' must be a positive version number"), "")
+	}
+
+	rule, _, err := srv.getAuthorizedRuleByUID(c.Req.Context(), c, ruleUID)
+	if err != nil {
+		return toRuleVersionErrorResponse(err)
+	}
+	versions, err := srv.store.GetAlertRuleVersions(c.Req.Context(), c.SignedInUser.GetOrgID(), ruleUID)
+	if err != nil {
+		return toRuleVersionErrorResponse(err)
+	}
+
+	fromRule, err := srv.getAuthorizedRuleVersion(c.Req.Context(), c, versions, from)
+	if err != nil {
+		return toRuleVersionErrorResponse(err)
+	}
+	// The current definition was authorized with the rule.
+	toRule := *rule
+	if to <= 0 {
+		to = rule.Version
+	} else if to != rule.Version {
+		toRule, err = srv.getAuthorizedRuleVersion(c.Req.Context(), c, versions, to)
+		if err != nil {
+			return toRuleVersionErrorResponse(err)
+		}
+	}
+
+	return response.JSON(http.StatusOK, apimodels.RuleVersionDiff{
+		RuleUID: ruleUID,
+		From:    from,
+		To:      to,
+		Changes: toRuleVersionChanges(fromRule.Diff(&toRule, store.AlertRuleFieldsToIgnoreInDiff[:]...)),
+	})
+}
+
+// RoutePostRestoreRuleVersion replaces the definition of the rule with the one it had at the specified version.
+// The rule stays in its current folder and group, and keeps its pause status. The restored rule is saved as a new version
+// and goes through the same validation and authorization as any other update of the rule group.
+func (srv RulerSrv) RoutePostRestoreRuleVersion(c *contextmodel.ReqContext, ruleUID string, version int64) response.Response {
+	rule, group, err := srv.getAuthorizedRuleByUID(c.Req.Context(), c, ruleUID)
+	if err != nil {
+		return toRuleVersionErrorResponse(err)
+	}
+	versions, err := srv.getAuthorizedRuleVersions(c.Req.Context(), c, ruleUID)
+	if err != nil {
+		return toRuleVersionErrorResponse(err)
+	}
+	v := findRuleVersion(versions, version)
+	if v == nil {
+		return ErrResp(http.StatusNotFound, fmt.Errorf("%w: version %d", ngmodels.ErrAlertRuleNotFound, version), "")
+	}
+
+	restored := v.AlertRule()
+	restored.ID = rule.ID
+	restored.Version = rule.Version
+	restored.NamespaceUID = rule.NamespaceUID
+	restored.RuleGroup = rule.RuleGroup
+	restored.RuleGroupIndex = rule.RuleGroupIndex
+	restored.IntervalSeconds = rule.IntervalSeconds
+
+	rules := make([]*ngmodels.AlertRuleWithOptionals, 0, len(group))
+	for _, r := range group {
+		if r.UID == rule.UID {
+			rules = append(rules, &ngmodels.AlertRuleWithOptionals{AlertRule: restored})
+			continue
+		}
+		rules = append(rules, &ngmodels.AlertRuleWithOptionals{AlertRule: *r, HasPause: true})
+	}
+	return srv.updateAlertRulesInGroup(c, rule.GetGroupKey(), rules)
+}
+
+// getAuthorizedRuleByUID fetches the rule and the group it belongs to, and validates that the user is authorized to access the group.
+// Returns models.ErrAlertRuleNotFound if the rule does not exist.
+func (srv RulerSrv) getAuthorizedRuleByUID(ctx context.Context, c *contextmodel.ReqContext, ruleUID string) (*ngmodels.AlertRule, ngmodels.RulesGroup, error) {
+	group, err := srv.store.GetAlertRulesGroupByRuleUID(ctx, &ngmodels.GetAlertRulesGroupByRuleUIDQuery{
+		UID:   ruleUID,
+		OrgID: c.SignedInUser.GetOrgID(),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	var rule *ngmodels.AlertRule
+	for _, r := range group {
+		if r.UID == ruleUID {
+			rule = r
+			break
+		}
+	}
+	if rule == nil {
+		return nil, nil, fmt.Errorf("%w: rule UID %s", ngmodels.ErrAlertRuleNotFound, ruleUID)
+	}
+	if err := srv.authz.AuthorizeAccessToRuleGroup(ctx, c.SignedInUser, group); err != nil {
+		return nil, nil, err
+	}
+	return rule, group, nil
+}
+
+// getAuthorizedRuleVersions fetches versions of the rule, newest first. Versions that use data sources the user cannot query are omitted.
+// It does not check access to the current group of the rule, use getAuthorizedRuleByUID for that.
+func (srv RulerSrv) getAuthorizedRuleVersions(ctx context.Context, c *contextmodel.ReqContext, ruleUID string) ([]*ngmodels.AlertRuleVersion, error) {
+	versions, err := srv.store.GetAlertRuleVersions(ctx, c.SignedInUser.GetOrgID(), ruleUID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*ngmodels.AlertRuleVersion, 0, len(versions))
+	for _, v := range versions {
+		rule := v.AlertRule()
+		ok, err := srv.authz.HasAccessToRuleGroup(ctx, c.SignedInUser, ngmodels.RulesGroup{&rule})
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			result = append(result, v)
+		}
+	}
+	return result, nil
+}
+
+// getAuthorizedRuleVersion returns the definition of the rule at the version.
+// Returns models.ErrAlertRuleNotFound if the version does not exist, or an authorization error if it uses data sources the user cannot query.
+func (srv RulerSrv) getAuthorizedRuleVersion(ctx context.Context, c *contextmodel.ReqContext, versions []*ngmodels.AlertRuleVersion, version int64) (ngmodels.AlertRule, error) {
+	v := findRuleVersion(versions, version)
+	if v == nil {
+		return ngmodels.AlertRule{}, fmt.Errorf("%w: version %d", ngmodels.ErrAlertRuleNotFound, version)
+	}
+	rule := v.AlertRule()
+	if err := srv.authz.AuthorizeAccessToRuleGroup(ctx, c.SignedInUser, ngmodels.RulesGroup{&rule}); err != nil {
+		return ngmodels.AlertRule{}, err
+	}
+	return rule, nil
+}
+
+func findRuleVersion(versions []*ngmodels.AlertRuleVersion, version int64) *ngmodels.AlertRuleVersion {
+	for _, v := range versions {
+		if v.Version == version {
+			return v
+		}
+	}
+	return nil
+}
+
+func toRuleVersionChanges(diff cmputil.DiffReport) []apimodels.RuleVersionChange {
+	result := make([]apimodels.RuleVersionChange, 0, len(diff))
+	for _, d := range diff {
+		field, _, _ := strings.Cut(d.Path, ".")
+		field, _, _ = strings.Cut(field, "[")
+		section, ok := ruleVersionDiffSections[field]
+		if !ok {
+			section = strings.ToLower(field)
+		}
+		change := apimodels.RuleVersionChange{
+			Section: section,
+			Path:    d.Path,
+		}
+		if d.Left.IsValid() && d.Left.CanInterface() {
+			change.Old = d.Left.Interface()
+		}
+		if d.Right.IsValid() && d.Right.CanInterface() {
+			change.New = d.Right.Interface()
+		}
+		result = append(result, change)
+	}
+	return result
+}
+
+func toRuleVersionErrorResponse(err error) response.Response {
+	if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+		return ErrResp(http.StatusNotFound, err, "")
+	}
+	return errorToResponse(err)
+}
@@ -0,0 +1,250 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+)
+
+func TestRouteGetRuleVersionsByUID(t *testing.T) {
+	orgID := rand.Int63()
+	folder := randFolder()
+	ruleStore := fakes.NewRuleStore(t)
+	ruleStore.Folders[orgID] = append(ruleStore.Folders[orgID], folder)
+	groupKey := models.GenerateGroupKey(orgID)
+	groupKey.NamespaceUID = folder.UID
+
+	rule := models.AlertRuleGen(withGroupKey(groupKey), models.WithNoNotificationSettings())()
+	rule.Version = 3
+	ruleStore.PutRule(context.Background(), rule)
+	ruleStore.PutRuleVersion(generateRuleVersions(rule)...)
+
+	t.Run("should return versions newest first", func(t *testing.T) {
+		req := createRequestContext(orgID, nil)
+		response := createService(ruleStore).RouteGetRuleVersionsByUID(req, rule.UID)
+		require.Equal(t, http.StatusOK, response.Status())
+
+		var result apimodels.GettableRuleVersions
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Len(t, result, 3)
+		for i, v := range result {
+			require.Equal(t, rule.UID, v.GrafanaManagedAlert.UID)
+			require.Equal(t, int64(3-i), v.GrafanaManagedAlert.Version)
+		}
+	})
+
+	t.Run("should return Forbidden if user does not have access to the rule", func(t *testing.T) {
+		req := createRequestContextWithPerms(orgID, map[int64]map[string][]string{}, nil)
+		response := createService(ruleStore).RouteGetRuleVersionsByUID(req, rule.UID)
+		require.Equal(t, http.StatusForbidden, response.Status())
+	})
+
+	t.Run("should return NotFound if rule does not exist", func(t *testing.T) {
+		req := createRequestContext(orgID, nil)
+		response := createService(ruleStore).RouteGetRuleVersionsByUID(req, "unknown")
+		require.Equal(t, http.StatusNotFound, response.Status())
+	})
+}
+
+func TestRouteGetRuleVersionsDiff(t *testing.T) {
+	orgID := rand.Int63()
+	folder := randFolder()
+	ruleStore := fakes.NewRuleStore(t)
+	ruleStore.Folders[orgID] = append(ruleStore.Folders[orgID], folder)
+	groupKey := models.GenerateGroupKey(orgID)
+	groupKey.NamespaceUID = folder.UID
+
+	rule := models.AlertRuleGen(withGroupKey(groupKey), models.WithNoNotificationSettings())()
+	rule.Version = 3
+	withDashboardAnnotations(rule)
+	ruleStore.PutRule(context.Background(), rule)
+	versions := generateRuleVersions(rule)
+	ruleStore.PutRuleVersion(versions...)
+
+	t.Run("should return changes between versions", func(t *testing.T) {
+		req := createRequestContext(orgID, nil)
+		req.Req.Form = url.Values{"from": []string{"1"}, "to": []string{"2"}}
+		response := createService(ruleStore).RouteGetRuleVersionsDiff(req, rule.UID)
+		require.Equal(t, http.StatusOK, response.Status())
+
+		var result apimodels.RuleVersionDiff
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Equal(t, int64(1), result.From)
+		require.Equal(t, int64(2), result.To)
+		require.Len(t, result.Changes, 2)
+		sections := map[string]apimodels.RuleVersionChange{}
+		for _, c := range result.Changes {
+			sections[c.Section] = c
+		}
+		require.Equal(t, versions[0].Title, sections["title"].Old)
+		require.Equal(t, versions[1].Title, sections["title"].New)
+		require.Equal(t, "Labels[changed]", sections["labels"].Path)
+		require.Nil(t, sections["labels"].Old)
+		require.Equal(t, "v2", sections["labels"].New)
+	})
+
+	t.Run("should compare with the current version if 'to' is not specified", func(t *testing.T) {
+		req := createRequestContext(orgID, nil)
+		req.Req.Form = url.Values{"from": []string{"2"}}
+		response := createService(ruleStore).RouteGetRuleVersionsDiff(req, rule.UID)
+		require.Equal(t, http.StatusOK, response.Status())
+
+		var result apimodels.RuleVersionDiff
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Equal(t, int64(3), result.To)
+		sections := map[string]apimodels.RuleVersionChange{}
+		for _, c := range result.Changes {
+			sections[c.Section] = c
+		}
+		require.Equal(t, versions[1].Title, sections["title"].Old)
+		require.Equal(t, rule.Title, sections["title"].New)
+		// the dashboard and panel of the versions are set from their annotations.
+		require.NotContains(t, sections, "dashboard_uid")
+		require.NotContains(t, sections, "panel_id")
+	})
+
+	t.Run("should return Forbidden if user does not have access to the current version", func(t *testing.T) {
+		req := createRequestContextWithPerms(orgID, map[int64]map[string][]string{}, nil)
+		req.Req.Form = url.Values{"from": []string{"1"}}
+		response := createService(ruleStore).RouteGetRuleVersionsDiff(req, rule.UID)
+		require.Equal(t, http.StatusForbidden, response.Status())
+	})
+
+	t.Run("should return BadRequest if 'from' is not specified", func(t *testing.T) {
+		req := createRequestContext(orgID, nil)
+		response := createService(ruleStore).RouteGetRuleVersionsDiff(req, rule.UID)
+		require.Equal(t, http.StatusBadRequest, response.Status())
+	})
+
+	t.Run("should return NotFound if version does not exist", func(t *testing.T) {
+		req := createRequestContext(orgID, nil)
+		req.Req.Form = url.Values{"from": []string{"10"}}
+		response := createService(ruleStore).RouteGetRuleVersionsDiff(req, rule.UID)
+		require.Equal(t, http.StatusNotFound, response.Status())
+	})
+}
+
+func TestRoutePostRestoreRuleVersion(t *testing.T) {
+	orgID := rand.Int63()
+	folder := randFolder()
+	ruleStore := fakes.NewRuleStore(t)
+	ruleStore.Folders[orgID] = append(ruleStore.Folders[orgID], folder)
+	groupKey := models.GenerateGroupKey(orgID)
+	groupKey.NamespaceUID = folder.UID
+
+	gen := models.AlertRuleGen(withGroupKey(groupKey), models.WithNoNotificationSettings(), models.WithUniqueGroupIndex())
+	rule := gen()
+	rule.Version = 3
+	withDashboardAnnotations(rule)
+	other := gen()
+	ruleStore.PutRule(context.Background(), rule, other)
+	versions := generateRuleVersions(rule)
+	ruleStore.PutRuleVersion(versions...)
+
+	permissions := map[int64]map[string][]string{
+		orgID: {
+			datasources.ActionQuery:     []string{datasources.ScopeAll},
+			ac.ActionAlertingRuleUpdate: []string{dashboards.ScopeFoldersAll},
+		},
+	}
+
+	t.Run("should update the rule with the definition of the version", func(t *testing.T) {
+		svc := createService(ruleStore)
+		svc.conditionValidator = &recordingConditionValidator{}
+		req := createRequestContextWithPerms(orgID, permissions, nil)
+
+		response := svc.RoutePostRestoreRuleVersion(req, rule.UID, 1)
+		require.Equal(t, http.StatusAccepted, response.Status())
+
+		updates := ruleStore.GetRecordedCommands(func(cmd any) (any, bool) {
+			u, ok := cmd.([]models.UpdateRule)
+			return u, ok
+		})
+		require.Len(t, updates, 1)
+		var restored *models.AlertRule
+		for _, u := range updates[0].([]models.UpdateRule) {
+			if u.New.UID == rule.UID {
+				restored = models.CopyRule(&u.New)
+			} else {
+				require.Equal(t, *other, u.New)
+			}
+		}
+		require.NotNil(t, restored)
+		require.Equal(t, versions[0].Title, restored.Title)
+		require.Equal(t, versions[0].Labels, restored.Labels)
+		require.Equal(t, rule.RuleGroup, restored.RuleGroup)
+		require.Equal(t, rule.IntervalSeconds, restored.IntervalSeconds)
+		require.Equal(t, rule.IsPaused, restored.IsPaused)
+		require.Equal(t, rule.DashboardUID, restored.DashboardUID)
+		require.Equal(t, rule.PanelID, restored.PanelID)
+	})
+
+	t.Run("should return NotFound if version does not exist", func(t *testing.T) {
+		svc := createService(ruleStore)
+		svc.conditionValidator = &recordingConditionValidator{}
+		req := createRequestContextWithPerms(orgID, permissions, nil)
+
+		response := svc.RoutePostRestoreRuleVersion(req, rule.UID, 10)
+		require.Equal(t, http.StatusNotFound, response.Status())
+	})
+}
+
+// withDashboardAnnotations links the rule to a dashboard panel, with the annotations that store the link.
+func withDashboardAnnotations(rule *models.AlertRule) {
+	dashboardUID, panelID := "dashboard", int64(1)
+	rule.DashboardUID, rule.PanelID = &dashboardUID, &panelID
+	annotations := make(map[string]string, len(rule.Annotations)+2)
+	for k, v := range rule.Annotations {
+		annotations[k] = v
+	}
+	annotations[models.DashboardUIDAnnotation] = dashboardUID
+	annotations[models.PanelIDAnnotation] = "1"
+	rule.Annotations = annotations
+}
+
+// generateRuleVersions generates versions of the rule from 1 to rule.Version. Every version has a unique title
+// and the versions starting from 2 have the label "changed" with the value of the version.
+func generateRuleVersions(rule *models.AlertRule) []*models.AlertRuleVersion {
+	result := make([]*models.AlertRuleVersion, 0, rule.Version)
+	for v := int64(1); v <= rule.Version; v++ {
+		labels := make(map[string]string, len(rule.Labels)+1)
+		for k, val := range rule.Labels {
+			labels[k] = val
+		}
+		if v > 1 {
+			labels["changed"] = "v" + string(rune('0'+v))
+		}
+		result = append(result, &models.AlertRuleVersion{
+			RuleOrgID:        rule.OrgID,
+			RuleUID:          rule.UID,
+			RuleNamespaceUID: rule.NamespaceUID,
+			RuleGroup:        rule.RuleGroup,
+			RuleGroupIndex:   rule.RuleGroupIndex,
+			ParentVersion:    v - 1,
+			Version:          v,
+			Created:          rule.Updated,
+			Title:            rule.Title + "-v" + string(rune('0'+v)),
+			Condition:        rule.Condition,
+			Data:             rule.Data,
+			IntervalSeconds:  rule.IntervalSeconds,
+			NoDataState:      rule.NoDataState,
+			ExecErrState:     rule.ExecErrState,
+			For:              rule.For,
+			Annotations:      rule.Annotations,
+			Labels:           labels,
+		})
+	}
+	return result
+}
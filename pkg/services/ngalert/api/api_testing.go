@@ -17,6 +17,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
 	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
@@ -29,6 +30,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/backtesting"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/setting"
@@ -39,6 +41,10 @@ type folderService interface {
 	GetNamespaceByUID(ctx context.Context, uid string, orgID int64, user identity.Requester) (*folder.Folder, error)
 }
 
+type notificationSimulators interface {
+	NewNotificationSimulator(ctx context.Context, org int64, cfg *apimodels.PostableUserConfig, rules ...*ngmodels.AlertRule) (*notifier.NotificationSimulator, error)
+}
+
 type TestingApiSrv struct {
 	*AlertingProxy
 	DatasourceCache datasources.CacheService
@@ -47,10 +53,12 @@ type TestingApiSrv struct {
 	evaluator       eval.EvaluatorFactory
 	cfg             *setting.UnifiedAlertingSettings
 	backtesting     *backtesting.Engine
+	exprService     *expr.Service
 	featureManager  featuremgmt.FeatureToggles
 	appUrl          *url.URL
 	tracer          tracing.Tracer
 	folderService   folderService
+	simulators      notificationSimulators
 }
 
 // RouteTestGrafanaRuleConfig returns a list of potential alerts for a given rule configuration. This is intended to be
@@ -285,3 +293,76 @@ func (srv TestingApiSrv) BacktestAlertRule(c *contextmodel.ReqContext, cmd apimo
 	}
 	return response.JSON(http.StatusOK, body)
 }
+
+// BacktestAlertRuleGroup evaluates the rules of a rule group over a time range, and simulates the notifications of their
+// alerts with the notification policies and mute timings of the organization or of the given configuration.
+func (srv TestingApiSrv) BacktestAlertRuleGroup(c *contextmodel.ReqContext, cmd apimodels.BacktestRuleGroupConfig) response.Response {
+	if !srv.featureManager.IsEnabled(c.Req.Context(), featuremgmt.FlagAlertingBacktesting) {
+		return ErrResp(http.StatusNotFound, nil, "Backtesting API is not enabled")
+	}
+
+	if !cmd.From.Before(cmd.To) {
+		return ErrResp(http.StatusBadRequest, nil, "From must be before To")
+	}
+
+	namespace, err := srv.folderService.GetNamespaceByUID(c.Req.Context(), cmd.FolderUID, c.SignedInUser.GetOrgID(), c.SignedInUser)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	rulesWithOptionals, err := ValidateRuleGroup(&cmd.Group, c.SignedInUser.GetOrgID(), namespace.UID, RuleLimitsFromConfig(srv.cfg))
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	rules := make(ngmodels.RulesGroup, 0, len(rulesWithOptionals))
+	for _, r := range rulesWithOptionals {
+		if r.Type() == ngmodels.RuleTypeRecording {
+			continue
+		}
+		rule := r.AlertRule
+		if rule.UID == "" {
+			// prefix backtesting- is to distinguish between executions of regular rule and backtesting in logs
+			rule.UID = "backtesting-" + util.GenerateShortUID()
+		}
+		rules = append(rules, &rule)
+	}
+	if len(rules) == 0 {
+		return ErrResp(http.StatusBadRequest, nil, "The rule group has no alert rules")
+	}
+
+	if err := srv.authz.AuthorizeAccessToRuleGroup(c.Req.Context(), c.SignedInUser, rules); err != nil {
+		return errorToResponse(err)
+	}
+
+	simulator, err := srv.simulators.NewNotificationSimulator(c.Req.Context(), c.SignedInUser.GetOrgID(), cmd.AlertmanagerConfig, rules...)
+	if err != nil {
+		if cmd.AlertmanagerConfig != nil {
+			return ErrResp(http.StatusBadRequest, err, "Invalid Alertmanager configuration")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "Failed to get the Alertmanager configuration")
+	}
+
+	includeFolder := !srv.cfg.ReservedLabels.IsReservedLabelDisabled(ngmodels.FolderTitleLabel)
+	result, err := srv.backtesting.TestGroup(c.Req.Context(), c.SignedInUser, rules, namespace.Title, includeFolder, cmd.From, cmd.To, simulator)
+	if err != nil {
+		if errors.Is(err, backtesting.ErrInvalidInputData) {
+			return ErrResp(http.StatusBadRequest, err, "Failed to evaluate")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "Failed to evaluate")
+	}
+
+	return response.JSON(http.StatusOK, BacktestRuleGroupResultFromGroupResult(result))
+}
+
+// RunAlertRuleUnitTests runs the unit tests of an alert rule. The data sources are not queried, so no additional
+// authorization is needed.
+func (srv TestingApiSrv) RunAlertRuleUnitTests(c *contextmodel.ReqContext, cmd apimodels.AlertRuleUnitTests) response.Response {
+	result, err := RunAlertRuleUnitTests(c.Req.Context(), srv.backtesting, srv.exprService, *srv.cfg, c.SignedInUser, cmd)
+	if err != nil {
+		if errors.Is(err, backtesting.ErrInvalidInputData) {
+			return ErrResp(400, err, "Failed to run the unit tests")
+		}
+		return ErrResp(500, err, "Failed to run the unit tests")
+	}
+	return response.JSON(http.StatusOK, result)
+}
@@ -402,3 +402,31 @@ func createTestingApiSrv(t *testing.T, ds *fakes.FakeCacheService, ac *acMock.Mo
 		folderService:   ruleStore,
 	}
 }
+
+func TestBacktestAlertRuleGroup(t *testing.T) {
+	rc := &contextmodel.ReqContext{
+		Context: &web.Context{
+			Req: &http.Request{},
+		},
+		SignedInUser: &user.SignedInUser{
+			OrgID: 1,
+		},
+	}
+	from := time.Now()
+
+	t.Run("should return NotFound if backtesting is not enabled", func(t *testing.T) {
+		srv := &TestingApiSrv{featureManager: featuremgmt.WithFeatures()}
+
+		response := srv.BacktestAlertRuleGroup(rc, definitions.BacktestRuleGroupConfig{From: from, To: from.Add(time.Hour)})
+
+		require.Equal(t, http.StatusNotFound, response.Status())
+	})
+
+	t.Run("should return BadRequest if from is not before to", func(t *testing.T) {
+		srv := &TestingApiSrv{featureManager: featuremgmt.WithFeatures(featuremgmt.FlagAlertingBacktesting)}
+
+		response := srv.BacktestAlertRuleGroup(rc, definitions.BacktestRuleGroupConfig{From: from, To: from})
+
+		require.Equal(t, http.StatusBadRequest, response.Status())
+	})
+}
@@ -2,13 +2,18 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"time"
 
 	jsoniter "github.com/json-iterator/go"
+	"github.com/prometheus/alertmanager/pkg/labels"
 	"github.com/prometheus/common/model"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/backtesting"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -27,6 +32,7 @@ func AlertRuleFromProvisionedAlertRule(a definitions.ProvisionedAlertRule) (mode
 		NoDataState:          models.NoDataState(a.NoDataState),          // TODO there must be a validation
 		ExecErrState:         models.ExecutionErrorState(a.ExecErrState), // TODO there must be a validation
 		For:                  time.Duration(a.For),
+		KeepFiringFor:        time.Duration(a.KeepFiringFor),
 		Annotations:          a.Annotations,
 		Labels:               a.Labels,
 		IsPaused:             a.IsPaused,
@@ -44,6 +50,7 @@ func ProvisionedAlertRuleFromAlertRule(rule models.AlertRule, provenance models.
 		RuleGroup:            rule.RuleGroup,
 		Title:                rule.Title,
 		For:                  model.Duration(rule.For),
+		KeepFiringFor:        model.Duration(rule.KeepFiringFor),
 		Condition:            rule.Condition,
 		Data:                 ApiAlertQueriesFromAlertQueries(rule.Data),
 		Updated:              rule.Updated,
@@ -180,6 +187,7 @@ func AlertRuleExportFromAlertRule(rule models.AlertRule) (definitions.AlertRuleE
 		UID:                  rule.UID,
 		Title:                rule.Title,
 		For:                  model.Duration(rule.For),
+		KeepFiringFor:        model.Duration(rule.KeepFiringFor),
 		Condition:            rule.Condition,
 		Data:                 data,
 		DashboardUID:         rule.DashboardUID,
@@ -192,6 +200,9 @@ func AlertRuleExportFromAlertRule(rule models.AlertRule) (definitions.AlertRuleE
 	if rule.For.Seconds() > 0 {
 		result.ForString = util.Pointer(model.Duration(rule.For).String())
 	}
+	if rule.KeepFiringFor.Seconds() > 0 {
+		result.KeepFiringForString = util.Pointer(model.Duration(rule.KeepFiringFor).String())
+	}
 	if rule.Annotations != nil {
 		result.Annotations = &rule.Annotations
 	}
@@ -377,6 +388,283 @@ func MuteTimingIntervalToMuteTimeIntervalHclExport(m definitions.MuteTimeInterva
 	return result, err
 }
 
+// MuteTimeIntervalExportFromMuteTimeIntervalHclExport converts definitions.MuteTimeIntervalExportHcl to definitions.MuteTimeIntervalExport using JSON marshalling.
+// It is the inverse of MuteTimingIntervalToMuteTimeIntervalHclExport.
+func MuteTimeIntervalExportFromMuteTimeIntervalHclExport(orgID int64, m definitions.MuteTimeIntervalExportHcl) (definitions.MuteTimeIntervalExport, error) {
+	result := definitions.MuteTimeIntervalExport{OrgID: orgID}
+	j := jsoniter.ConfigCompatibleWithStandardLibrary
+	mdata, err := j.Marshal(m)
+	if err != nil {
+		return result, err
+	}
+	err = j.Unmarshal(mdata, &result.MuteTimeInterval)
+	return result, err
+}
+
+// AlertRuleGroupFromAlertRuleGroupExport creates a models.AlertRuleGroup from definitions.AlertRuleGroupExport.
+// The folder UID is only set when the export comes from HCL, the other formats have the title of the folder.
+func AlertRuleGroupFromAlertRuleGroupExport(d definitions.AlertRuleGroupExport) (models.AlertRuleGroup, error) {
+	interval := int64(time.Duration(d.Interval).Seconds())
+	if interval == 0 {
+		interval = d.IntervalSeconds
+	}
+	rules := make([]models.AlertRule, 0, len(d.Rules))
+	for _, r := range d.Rules {
+		rule, err := AlertRuleFromAlertRuleExport(r)
+		if err != nil {
+			return models.AlertRuleGroup{}, fmt.Errorf("failed to convert rule '%s': %w", r.Title, err)
+		}
+		rules = append(rules, rule)
+	}
+	return models.AlertRuleGroup{
+		Title:     d.Name,
+		FolderUID: d.FolderUID,
+		Interval:  interval,
+		Rules:     rules,
+	}, nil
+}
+
+// AlertRuleFromAlertRuleExport creates a models.AlertRule from definitions.AlertRuleExport.
+// The fields that are only exported to HCL are used when their counterparts are empty.
+func AlertRuleFromAlertRuleExport(rule definitions.AlertRuleExport) (models.AlertRule, error) {
+	data := make([]models.AlertQuery, 0, len(rule.Data))
+	for _, q := range rule.Data {
+		query, err := AlertQueryFromAlertQueryExport(q)
+		if err != nil {
+			return models.AlertRule{}, err
+		}
+		data = append(data, query)
+	}
+	forDuration, err := durationOrString(rule.For, rule.ForString)
+	if err != nil {
+		return models.AlertRule{}, fmt.Errorf("failed to parse for: %w", err)
+	}
+	keepFiringFor, err := durationOrString(rule.KeepFiringFor, rule.KeepFiringForString)
+	if err != nil {
+		return models.AlertRule{}, fmt.Errorf("failed to parse keep_firing_for: %w", err)
+	}
+	noDataState, err := models.NoDataStateFromString(string(rule.NoDataState))
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+	execErrState, err := models.ErrStateFromString(string(rule.ExecErrState))
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+	ns, err := NotificationSettingsFromAlertRuleNotificationSettingsExport(rule.NotificationSettings)
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+
+	result := models.AlertRule{
+		UID:                  rule.UID,
+		Title:                rule.Title,
+		Condition:            rule.Condition,
+		Data:                 data,
+		DashboardUID:         rule.DashboardUID,
+		PanelID:              rule.PanelID,
+		NoDataState:          noDataState,
+		ExecErrState:         execErrState,
+		For:                  forDuration,
+		KeepFiringFor:        keepFiringFor,
+		IsPaused:             rule.IsPaused,
+		NotificationSettings: ns,
+	}
+	if rule.Annotations != nil {
+		result.Annotations = *rule.Annotations
+	}
+	if rule.Labels != nil {
+		result.Labels = *rule.Labels
+	}
+	return result, nil
+}
+
+func durationOrString(d model.Duration, s *string) (time.Duration, error) {
+	if d != 0 || s == nil {
+		return time.Duration(d), nil
+	}
+	parsed, err := model.ParseDuration(*s)
+	return time.Duration(parsed), err
+}
+
+// AlertQueryFromAlertQueryExport creates a models.AlertQuery from definitions.AlertQueryExport.
+func AlertQueryFromAlertQueryExport(query definitions.AlertQueryExport) (models.AlertQuery, error) {
+	mdl := json.RawMessage(query.ModelString)
+	if query.Model != nil {
+		var err error
+		if mdl, err = json.Marshal(query.Model); err != nil {
+			return models.AlertQuery{}, err
+		}
+	}
+	if len(mdl) == 0 {
+		return models.AlertQuery{}, fmt.Errorf("query '%s' has no model", query.RefID)
+	}
+	var queryType string
+	if query.QueryType != nil {
+		queryType = *query.QueryType
+	}
+	return models.AlertQuery{
+		RefID:     query.RefID,
+		QueryType: queryType,
+		RelativeTimeRange: models.RelativeTimeRange{
+			From: models.Duration(time.Duration(query.RelativeTimeRange.FromSeconds) * time.Second),
+			To:   models.Duration(time.Duration(query.RelativeTimeRange.ToSeconds) * time.Second),
+		},
+		DatasourceUID: query.DatasourceUID,
+		Model:         mdl,
+	}, nil
+}
+
+// NotificationSettingsFromAlertRuleNotificationSettingsExport converts definitions.AlertRuleNotificationSettingsExport to []models.NotificationSettings
+func NotificationSettingsFromAlertRuleNotificationSettingsExport(ns *definitions.AlertRuleNotificationSettingsExport) ([]models.NotificationSettings, error) {
+	if ns == nil {
+		return nil, nil
+	}
+	parseIfNotNil := func(s *string) (*model.Duration, error) {
+		if s == nil {
+			return nil, nil
+		}
+		d, err := model.ParseDuration(*s)
+		if err != nil {
+			return nil, err
+		}
+		return &d, nil
+	}
+	groupWait, err := parseIfNotNil(ns.GroupWait)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse group_wait: %w", err)
+	}
+	groupInterval, err := parseIfNotNil(ns.GroupInterval)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse group_interval: %w", err)
+	}
+	repeatInterval, err := parseIfNotNil(ns.RepeatInterval)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse repeat_interval: %w", err)
+	}
+	return []models.NotificationSettings{
+		{
+			Receiver:          ns.Receiver,
+			GroupBy:           ns.GroupBy,
+			GroupWait:         groupWait,
+			GroupInterval:     groupInterval,
+			RepeatInterval:    repeatInterval,
+			MuteTimeIntervals: ns.MuteTimeIntervals,
+		},
+	}, nil
+}
+
+// EmbeddedContactPointsFromContactPointExport creates a definitions.EmbeddedContactPoint for each receiver of definitions.ContactPointExport.
+func EmbeddedContactPointsFromContactPointExport(cp definitions.ContactPointExport) ([]definitions.EmbeddedContactPoint, error) {
+	result := make([]definitions.EmbeddedContactPoint, 0, len(cp.Receivers))
+	for _, r := range cp.Receivers {
+		settings, err := simplejson.NewJson(r.Settings)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse settings of %s integration (uid:%s): %w", r.Type, r.UID, err)
+		}
+		result = append(result, definitions.EmbeddedContactPoint{
+			UID:                   r.UID,
+			Name:                  cp.Name,
+			Type:                  r.Type,
+			Settings:              settings,
+			DisableResolveMessage: r.DisableResolveMessage,
+		})
+	}
+	return result, nil
+}
+
+// ContactPointExportFromContactPoint creates a definitions.ContactPointExport from the strongly typed definitions.ContactPoint.
+// The integrations of definitions.ContactPoint have no UID.
+func ContactPointExportFromContactPoint(orgID int64, cp definitions.ContactPoint) (definitions.ContactPointExport, error) {
+	receiver, err := ContactPointToContactPointExport(cp)
+	if err != nil {
+		return definitions.ContactPointExport{}, err
+	}
+	result := definitions.ContactPointExport{
+		OrgID:     orgID,
+		Name:      cp.Name,
+		Receivers: make([]definitions.ReceiverExport, 0, len(receiver.Integrations)),
+	}
+	for _, i := range receiver.Integrations {
+		result.Receivers = append(result.Receivers, definitions.ReceiverExport{
+			UID:                   i.UID,
+			Type:                  i.Type,
+			Settings:              definitions.RawMessage(i.Settings),
+			DisableResolveMessage: i.DisableResolveMessage,
+		})
+	}
+	return result, nil
+}
+
+// RouteFromRouteExport creates a definitions.Route from definitions.RouteExport. It is the inverse of RouteExportFromRoute.
+func RouteFromRouteExport(export *definitions.RouteExport) (*definitions.Route, error) {
+	parseIfNotNil := func(s *string) (*model.Duration, error) {
+		if s == nil {
+			return nil, nil
+		}
+		d, err := model.ParseDuration(*s)
+		if err != nil {
+			return nil, err
+		}
+		return &d, nil
+	}
+
+	route := definitions.Route{
+		Receiver:       export.Receiver,
+		Match:          export.Match,
+		MatchRE:        export.MatchRE,
+		Matchers:       export.Matchers,
+		ObjectMatchers: export.ObjectMatchers,
+	}
+	if export.GroupByStr != nil {
+		route.GroupByStr = *export.GroupByStr
+	}
+	if export.MuteTimeIntervals != nil {
+		route.MuteTimeIntervals = *export.MuteTimeIntervals
+	}
+	if export.Continue != nil {
+		route.Continue = *export.Continue
+	}
+	// HCL has only the slice of object matchers.
+	if len(route.ObjectMatchers) == 0 {
+		for _, m := range export.ObjectMatchersSlice {
+			matcher, err := matcherFromMatcherExport(m)
+			if err != nil {
+				return nil, err
+			}
+			route.ObjectMatchers = append(route.ObjectMatchers, matcher)
+		}
+	}
+	var err error
+	if route.GroupWait, err = parseIfNotNil(export.GroupWait); err != nil {
+		return nil, fmt.Errorf("failed to parse group_wait: %w", err)
+	}
+	if route.GroupInterval, err = parseIfNotNil(export.GroupInterval); err != nil {
+		return nil, fmt.Errorf("failed to parse group_interval: %w", err)
+	}
+	if route.RepeatInterval, err = parseIfNotNil(export.RepeatInterval); err != nil {
+		return nil, fmt.Errorf("failed to parse repeat_interval: %w", err)
+	}
+
+	for _, r := range export.Routes {
+		child, err := RouteFromRouteExport(r)
+		if err != nil {
+			return nil, err
+		}
+		route.Routes = append(route.Routes, child)
+	}
+	return &route, nil
+}
+
+func matcherFromMatcherExport(m *definitions.MatcherExport) (*labels.Matcher, error) {
+	for _, t := range []labels.MatchType{labels.MatchEqual, labels.MatchNotEqual, labels.MatchRegexp, labels.MatchNotRegexp} {
+		if t.String() == m.Match {
+			return labels.NewMatcher(t, m.Label, m.Value)
+		}
+	}
+	return nil, fmt.Errorf("invalid match type '%s' of matcher '%s'", m.Match, m.Label)
+}
+
 // AlertRuleNotificationSettingsFromNotificationSettings converts []models.NotificationSettings to definitions.AlertRuleNotificationSettings
 func AlertRuleNotificationSettingsFromNotificationSettings(ns []models.NotificationSettings) *definitions.AlertRuleNotificationSettings {
 	if len(ns) == 0 {
@@ -434,3 +722,153 @@ func NotificationSettingsFromAlertRuleNotificationSettings(ns *definitions.Alert
 		},
 	}
 }
+
+// ApiRecordFromModelRecord converts models.Record to definitions.Record
+func ApiRecordFromModelRecord(r *models.Record) *definitions.Record {
+	if r == nil {
+		return nil
+	}
+	return &definitions.Record{
+		Metric: r.Metric,
+		From:   r.From,
+	}
+}
+
+// ModelRecordFromApiRecord converts definitions.Record to models.Record
+func ModelRecordFromApiRecord(r *definitions.Record) *models.Record {
+	if r == nil {
+		return nil
+	}
+	return &models.Record{
+		Metric: r.Metric,
+		From:   r.From,
+	}
+}
+
+// ApiRuleInhibitionsFromModelRuleInhibitions converts []models.RuleInhibition to []definitions.RuleInhibition
+func ApiRuleInhibitionsFromModelRuleInhibitions(inhibitions []models.RuleInhibition) []definitions.RuleInhibition {
+	if len(inhibitions) == 0 {
+		return nil
+	}
+	result := make([]definitions.RuleInhibition, 0, len(inhibitions))
+	for _, i := range inhibitions {
+		result = append(result, definitions.RuleInhibition{
+			RuleUID: i.RuleUID,
+			Equal:   i.Equal,
+		})
+	}
+	return result
+}
+
+// ModelRuleInhibitionsFromApiRuleInhibitions converts []definitions.RuleInhibition to []models.RuleInhibition
+func ModelRuleInhibitionsFromApiRuleInhibitions(inhibitions []definitions.RuleInhibition) []models.RuleInhibition {
+	if len(inhibitions) == 0 {
+		return nil
+	}
+	result := make([]models.RuleInhibition, 0, len(inhibitions))
+	for _, i := range inhibitions {
+		result = append(result, models.RuleInhibition{
+			RuleUID: i.RuleUID,
+			Equal:   i.Equal,
+		})
+	}
+	return result
+}
+
+// ProvisionedSilenceFromApiProvisionedSilence converts definitions.ProvisionedSilence to models.ProvisionedSilence
+func ProvisionedSilenceFromApiProvisionedSilence(orgID int64, s definitions.ProvisionedSilence) (models.ProvisionedSilence, error) {
+	matchers, err := notifier.FromSilenceMatchers(s.Matchers)
+	if err != nil {
+		return models.ProvisionedSilence{}, err
+	}
+	result := models.ProvisionedSilence{
+		OrgID:     orgID,
+		UID:       s.UID,
+		Matchers:  matchers,
+		Comment:   s.Comment,
+		CreatedBy: s.CreatedBy,
+	}
+	if s.StartsAt != nil {
+		result.StartsAt = *s.StartsAt
+	}
+	if s.EndsAt != nil {
+		result.EndsAt = *s.EndsAt
+	}
+	if s.Schedule != nil {
+		result.Schedule = &models.SilenceSchedule{
+			Cron:     s.Schedule.Cron,
+			Duration: time.Duration(s.Schedule.Duration),
+			Location: s.Schedule.Location,
+		}
+	}
+	return result, nil
+}
+
+// ApiProvisionedSilenceFromProvisionedSilence converts models.ProvisionedSilence to definitions.ProvisionedSilence and sets provided provenance status
+func ApiProvisionedSilenceFromProvisionedSilence(s models.ProvisionedSilence, provenance models.Provenance) definitions.ProvisionedSilence {
+	result := definitions.ProvisionedSilence{
+		UID:        s.UID,
+		Matchers:   notifier.ToSilenceMatchers(s.Matchers),
+		Comment:    s.Comment,
+		CreatedBy:  s.CreatedBy,
+		Provenance: definitions.Provenance(provenance),
+	}
+	if s.Schedule != nil {
+		result.Schedule = &definitions.SilenceSchedule{
+			Cron:     s.Schedule.Cron,
+			Duration: model.Duration(s.Schedule.Duration),
+			Location: s.Schedule.Location,
+		}
+	} else {
+		startsAt, endsAt := s.StartsAt, s.EndsAt
+		result.StartsAt, result.EndsAt = &startsAt, &endsAt
+	}
+	return result
+}
+
+// BacktestRuleGroupResultFromGroupResult converts the result of the backtesting of a rule group to the API model.
+func BacktestRuleGroupResultFromGroupResult(r *backtesting.GroupResult) definitions.BacktestRuleGroupResult {
+	result := definitions.BacktestRuleGroupResult{
+		Rules:         r.Rules,
+		Notifications: make([]definitions.BacktestNotification, 0, len(r.Notifications)),
+		ContactPoints: make(map[string]int),
+	}
+	toMaps := func(sets []model.LabelSet) []map[string]string {
+		maps := make([]map[string]string, 0, len(sets))
+		for _, set := range sets {
+			maps = append(maps, labelSetToMap(set))
+		}
+		return maps
+	}
+	for _, n := range r.Notifications {
+		result.Notifications = append(result.Notifications, definitions.BacktestNotification{
+			Time:        n.Time,
+			Receiver:    n.Receiver,
+			Policy:      n.Route,
+			GroupLabels: labelSetToMap(n.GroupLabels),
+			Firing:      toMaps(n.Firing),
+			Resolved:    toMaps(n.Resolved),
+			MutedBy:     n.MutedBy,
+		})
+		if len(n.MutedBy) == 0 {
+			result.ContactPoints[n.Receiver]++
+		}
+	}
+	return result
+}
+
+func labelSetToMap(set model.LabelSet) map[string]string {
+	m := make(map[string]string, len(set))
+	for k, v := range set {
+		m[string(k)] = string(v)
+	}
+	return m
+}
+
+func labelSetFromMap(m map[string]string) model.LabelSet {
+	set := make(model.LabelSet, len(m))
+	for k, v := range m {
+		set[model.LabelName(k)] = model.LabelValue(v)
+	}
+	return set
+}
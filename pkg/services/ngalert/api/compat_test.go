@@ -2,10 +2,14 @@ package api
 
 import (
 	"testing"
+	"time"
 
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util"
 )
 
 func TestToModel(t *testing.T) {
@@ -35,3 +39,31 @@ func TestToModel(t *testing.T) {
 		require.Len(t, tm.Rules, 1)
 	})
 }
+
+func TestAlertRuleExportFromAlertRule(t *testing.T) {
+	t.Run("keep_firing_for should be exported only if it is set", func(t *testing.T) {
+		rule := models.AlertRuleGen(models.WithKeepFiringFor(5 * time.Minute))()
+		export, err := AlertRuleExportFromAlertRule(*rule)
+		require.NoError(t, err)
+		require.Equal(t, model.Duration(5*time.Minute), export.KeepFiringFor)
+		require.Equal(t, util.Pointer("5m"), export.KeepFiringForString)
+
+		rule.KeepFiringFor = 0
+		export, err = AlertRuleExportFromAlertRule(*rule)
+		require.NoError(t, err)
+		require.Zero(t, export.KeepFiringFor)
+		require.Nil(t, export.KeepFiringForString)
+	})
+}
+
+func TestProvisionedAlertRuleFromAlertRule(t *testing.T) {
+	t.Run("should convert keep_firing_for back and forth", func(t *testing.T) {
+		rule := models.AlertRuleGen(models.WithKeepFiringFor(5 * time.Minute))()
+		provisioned := ProvisionedAlertRuleFromAlertRule(*rule, models.ProvenanceNone)
+		require.Equal(t, model.Duration(5*time.Minute), provisioned.KeepFiringFor)
+
+		converted, err := AlertRuleFromProvisionedAlertRule(provisioned)
+		require.NoError(t, err)
+		require.Equal(t, rule.KeepFiringFor, converted.KeepFiringFor)
+	})
+}
@@ -1,6 +1,9 @@
 package api
 
 import (
+	"net/http"
+	"strconv"
+
 	"github.com/grafana/grafana/pkg/api/response"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/datasources"
@@ -109,10 +112,30 @@ func (f *RulerApiHandler) handleRoutePostRulesGroupForExport(ctx *contextmodel.R
 	return f.GrafanaRuler.ExportFromPayload(ctx, conf, namespace)
 }
 
+func (f *RulerApiHandler) handleRoutePostPrometheusRulesImport(ctx *contextmodel.ReqContext, namespace string) response.Response {
+	return f.GrafanaRuler.RoutePostPrometheusRulesImport(ctx, namespace)
+}
+
 func (f *RulerApiHandler) handleRouteGetRulesForExport(ctx *contextmodel.ReqContext) response.Response {
 	return f.GrafanaRuler.ExportRules(ctx)
 }
 
+func (f *RulerApiHandler) handleRouteGetRuleVersionsByUID(ctx *contextmodel.ReqContext, ruleUID string) response.Response {
+	return f.GrafanaRuler.RouteGetRuleVersionsByUID(ctx, ruleUID)
+}
+
+func (f *RulerApiHandler) handleRouteGetRuleVersionsDiff(ctx *contextmodel.ReqContext, ruleUID string) response.Response {
+	return f.GrafanaRuler.RouteGetRuleVersionsDiff(ctx, ruleUID)
+}
+
+func (f *RulerApiHandler) handleRoutePostRestoreRuleVersion(ctx *contextmodel.ReqContext, ruleUID, version string) response.Response {
+	v, err := strconv.ParseInt(version, 10, 64)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "version must be a number")
+	}
+	return f.GrafanaRuler.RoutePostRestoreRuleVersion(ctx, ruleUID, v)
+}
+
 func (f *RulerApiHandler) getService(ctx *contextmodel.ReqContext) (*LotexRuler, error) {
 	_, err := getDatasourceByUID(ctx, f.DatasourceCache, apimodels.LoTexRulerBackend)
 	if err != nil {
@@ -24,6 +24,7 @@ type ProvisioningApi interface {
 	RouteDeleteAlertRuleGroup(*contextmodel.ReqContext) response.Response
 	RouteDeleteContactpoints(*contextmodel.ReqContext) response.Response
 	RouteDeleteMuteTiming(*contextmodel.ReqContext) response.Response
+	RouteDeleteProvisionedSilence(*contextmodel.ReqContext) response.Response
 	RouteDeleteTemplate(*contextmodel.ReqContext) response.Response
 	RouteExportMuteTiming(*contextmodel.ReqContext) response.Response
 	RouteExportMuteTimings(*contextmodel.ReqContext) response.Response
@@ -39,16 +40,21 @@ type ProvisioningApi interface {
 	RouteGetMuteTimings(*contextmodel.ReqContext) response.Response
 	RouteGetPolicyTree(*contextmodel.ReqContext) response.Response
 	RouteGetPolicyTreeExport(*contextmodel.ReqContext) response.Response
+	RouteGetProvisionedSilence(*contextmodel.ReqContext) response.Response
+	RouteGetProvisionedSilences(*contextmodel.ReqContext) response.Response
 	RouteGetTemplate(*contextmodel.ReqContext) response.Response
 	RouteGetTemplates(*contextmodel.ReqContext) response.Response
 	RoutePostAlertRule(*contextmodel.ReqContext) response.Response
 	RoutePostContactpoints(*contextmodel.ReqContext) response.Response
 	RoutePostMuteTiming(*contextmodel.ReqContext) response.Response
+	RoutePostProvisionedSilence(*contextmodel.ReqContext) response.Response
+	RoutePostProvisioningImport(*contextmodel.ReqContext) response.Response
 	RoutePutAlertRule(*contextmodel.ReqContext) response.Response
 	RoutePutAlertRuleGroup(*contextmodel.ReqContext) response.Response
 	RoutePutContactpoint(*contextmodel.ReqContext) response.Response
 	RoutePutMuteTiming(*contextmodel.ReqContext) response.Response
 	RoutePutPolicyTree(*contextmodel.ReqContext) response.Response
+	RoutePutProvisionedSilence(*contextmodel.ReqContext) response.Response
 	RoutePutTemplate(*contextmodel.ReqContext) response.Response
 	RouteResetPolicyTree(*contextmodel.ReqContext) response.Response
 }
@@ -74,6 +80,11 @@ func (f *ProvisioningApiHandler) RouteDeleteMuteTiming(ctx *contextmodel.ReqCont
 	nameParam := web.Params(ctx.Req)[":name"]
 	return f.handleRouteDeleteMuteTiming(ctx, nameParam)
 }
+func (f *ProvisioningApiHandler) RouteDeleteProvisionedSilence(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Path Parameters
+	uIDParam := web.Params(ctx.Req)[":UID"]
+	return f.handleRouteDeleteProvisionedSilence(ctx, uIDParam)
+}
 func (f *ProvisioningApiHandler) RouteDeleteTemplate(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Path Parameters
 	nameParam := web.Params(ctx.Req)[":name"]
@@ -135,6 +146,14 @@ func (f *ProvisioningApiHandler) RouteGetPolicyTree(ctx *contextmodel.ReqContext
 func (f *ProvisioningApiHandler) RouteGetPolicyTreeExport(ctx *contextmodel.ReqContext) response.Response {
 	return f.handleRouteGetPolicyTreeExport(ctx)
 }
+func (f *ProvisioningApiHandler) RouteGetProvisionedSilence(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Path Parameters
+	uIDParam := web.Params(ctx.Req)[":UID"]
+	return f.handleRouteGetProvisionedSilence(ctx, uIDParam)
+}
+func (f *ProvisioningApiHandler) RouteGetProvisionedSilences(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRouteGetProvisionedSilences(ctx)
+}
 func (f *ProvisioningApiHandler) RouteGetTemplate(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Path Parameters
 	nameParam := web.Params(ctx.Req)[":name"]
@@ -167,6 +186,17 @@ func (f *ProvisioningApiHandler) RoutePostMuteTiming(ctx *contextmodel.ReqContex
 	}
 	return f.handleRoutePostMuteTiming(ctx, conf)
 }
+func (f *ProvisioningApiHandler) RoutePostProvisionedSilence(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Request Body
+	conf := apimodels.ProvisionedSilence{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleRoutePostProvisionedSilence(ctx, conf)
+}
+func (f *ProvisioningApiHandler) RoutePostProvisioningImport(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRoutePostProvisioningImport(ctx)
+}
 func (f *ProvisioningApiHandler) RoutePutAlertRule(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Path Parameters
 	uIDParam := web.Params(ctx.Req)[":UID"]
@@ -216,6 +246,16 @@ func (f *ProvisioningApiHandler) RoutePutPolicyTree(ctx *contextmodel.ReqContext
 	}
 	return f.handleRoutePutPolicyTree(ctx, conf)
 }
+func (f *ProvisioningApiHandler) RoutePutProvisionedSilence(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Path Parameters
+	uIDParam := web.Params(ctx.Req)[":UID"]
+	// Parse Request Body
+	conf := apimodels.ProvisionedSilence{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleRoutePutProvisionedSilence(ctx, conf, uIDParam)
+}
 func (f *ProvisioningApiHandler) RoutePutTemplate(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Path Parameters
 	nameParam := web.Params(ctx.Req)[":name"]
@@ -280,6 +320,18 @@ func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApi, m *metrics
 				m,
 			),
 		)
+		group.Delete(
+			toMacaronPath("/api/v1/provisioning/silences/{UID}"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodDelete, "/api/v1/provisioning/silences/{UID}"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/v1/provisioning/silences/{UID}",
+				api.Hooks.Wrap(srv.RouteDeleteProvisionedSilence),
+				m,
+			),
+		)
 		group.Delete(
 			toMacaronPath("/api/v1/provisioning/templates/{name}"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
@@ -460,6 +512,30 @@ func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApi, m *metrics
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/silences/{UID}"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/silences/{UID}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/silences/{UID}",
+				api.Hooks.Wrap(srv.RouteGetProvisionedSilence),
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/silences"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/silences"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/silences",
+				api.Hooks.Wrap(srv.RouteGetProvisionedSilences),
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/v1/provisioning/templates/{name}"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
@@ -520,6 +596,30 @@ func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApi, m *metrics
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/v1/provisioning/silences"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodPost, "/api/v1/provisioning/silences"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/provisioning/silences",
+				api.Hooks.Wrap(srv.RoutePostProvisionedSilence),
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/provisioning/import"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodPost, "/api/v1/provisioning/import"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/provisioning/import",
+				api.Hooks.Wrap(srv.RoutePostProvisioningImport),
+				m,
+			),
+		)
 		group.Put(
 			toMacaronPath("/api/v1/provisioning/alert-rules/{UID}"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
@@ -580,6 +680,18 @@ func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApi, m *metrics
 				m,
 			),
 		)
+		group.Put(
+			toMacaronPath("/api/v1/provisioning/silences/{UID}"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodPut, "/api/v1/provisioning/silences/{UID}"),
+			metrics.Instrument(
+				http.MethodPut,
+				"/api/v1/provisioning/silences/{UID}",
+				api.Hooks.Wrap(srv.RoutePutProvisionedSilence),
+				m,
+			),
+		)
 		group.Put(
 			toMacaronPath("/api/v1/provisioning/templates/{name}"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
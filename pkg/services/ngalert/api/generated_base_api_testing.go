@@ -21,7 +21,9 @@ import (
 
 type TestingApi interface {
 	BacktestConfig(*contextmodel.ReqContext) response.Response
+	BacktestRuleGroupConfig(*contextmodel.ReqContext) response.Response
 	RouteEvalQueries(*contextmodel.ReqContext) response.Response
+	RouteRunAlertRuleUnitTests(*contextmodel.ReqContext) response.Response
 	RouteTestRuleConfig(*contextmodel.ReqContext) response.Response
 	RouteTestRuleGrafanaConfig(*contextmodel.ReqContext) response.Response
 }
@@ -34,6 +36,14 @@ func (f *TestingApiHandler) BacktestConfig(ctx *contextmodel.ReqContext) respons
 	}
 	return f.handleBacktestConfig(ctx, conf)
 }
+func (f *TestingApiHandler) BacktestRuleGroupConfig(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Request Body
+	conf := apimodels.BacktestRuleGroupConfig{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleBacktestRuleGroupConfig(ctx, conf)
+}
 func (f *TestingApiHandler) RouteEvalQueries(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Request Body
 	conf := apimodels.EvalQueriesPayload{}
@@ -42,6 +52,14 @@ func (f *TestingApiHandler) RouteEvalQueries(ctx *contextmodel.ReqContext) respo
 	}
 	return f.handleRouteEvalQueries(ctx, conf)
 }
+func (f *TestingApiHandler) RouteRunAlertRuleUnitTests(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Request Body
+	conf := apimodels.AlertRuleUnitTests{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleRouteRunAlertRuleUnitTests(ctx, conf)
+}
 func (f *TestingApiHandler) RouteTestRuleConfig(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Path Parameters
 	datasourceUIDParam := web.Params(ctx.Req)[":DatasourceUID"]
@@ -75,6 +93,18 @@ func (api *API) RegisterTestingApiEndpoints(srv TestingApi, m *metrics.API) {
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/v1/rule/backtest/group"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodPost, "/api/v1/rule/backtest/group"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/rule/backtest/group",
+				api.Hooks.Wrap(srv.BacktestRuleGroupConfig),
+				m,
+			),
+		)
 		group.Post(
 			toMacaronPath("/api/v1/eval"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
@@ -87,6 +117,18 @@ func (api *API) RegisterTestingApiEndpoints(srv TestingApi, m *metrics.API) {
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/v1/rule/unit-test"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodPost, "/api/v1/rule/unit-test"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/rule/unit-test",
+				api.Hooks.Wrap(srv.RouteRunAlertRuleUnitTests),
+				m,
+			),
+		)
 		group.Post(
 			toMacaronPath("/api/v1/rule/test/{DatasourceUID}"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
@@ -2,8 +2,12 @@ package hcl
 
 import (
 	"fmt"
+	"reflect"
+	"strings"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 )
 
@@ -28,3 +32,113 @@ func Encode(resources ...Resource) (data []byte, err error) {
 	}
 	return f.Bytes(), nil
 }
+
+// Decode decodes the resources encoded by Encode. newBody returns a pointer to the struct that the body of a resource
+// of the given type is decoded into, or an error if the type is not supported.
+// Encode omits the nil attributes, so unlike gohcl.DecodeBody, Decode leaves the fields of missing attributes empty.
+func Decode(data []byte, newBody func(resourceType string) (interface{}, error)) ([]Resource, error) {
+	f, diags := hclsyntax.ParseConfig(data, "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	body, ok := f.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, fmt.Errorf("unexpected HCL body %T", f.Body)
+	}
+	for name, attr := range body.Attributes {
+		return nil, fmt.Errorf("%s: unexpected attribute %s", attr.SrcRange, name)
+	}
+
+	resources := make([]Resource, 0, len(body.Blocks))
+	for _, block := range body.Blocks {
+		if block.Type != "resource" || len(block.Labels) != 2 {
+			return nil, fmt.Errorf("%s: expected a resource block with a type and a name", block.DefRange())
+		}
+		target, err := newBody(block.Labels[0])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", block.DefRange(), err)
+		}
+		if err := decodeBody(block.Body, reflect.ValueOf(target)); err != nil {
+			return nil, err
+		}
+		resources = append(resources, Resource{Type: block.Labels[0], Name: block.Labels[1], Body: target})
+	}
+	return resources, nil
+}
+
+func decodeBody(body *hclsyntax.Body, v reflect.Value) error {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("cannot decode HCL block into %s", v.Type())
+	}
+
+	attributes := make(map[string]struct{}, len(body.Attributes))
+	blocks := make(map[string]struct{})
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		tag, ok := t.Field(i).Tag.Lookup("hcl")
+		if !ok {
+			continue
+		}
+		name, kind, _ := strings.Cut(tag, ",")
+		switch kind {
+		case "block":
+			blocks[name] = struct{}{}
+			if err := decodeBlocks(body, name, v.Field(i)); err != nil {
+				return err
+			}
+		case "", "optional":
+			attributes[name] = struct{}{}
+			attr, ok := body.Attributes[name]
+			if !ok {
+				continue
+			}
+			if diags := gohcl.DecodeExpression(attr.Expr, nil, v.Field(i).Addr().Interface()); diags.HasErrors() {
+				return diags
+			}
+		}
+	}
+
+	for name, attr := range body.Attributes {
+		if _, ok := attributes[name]; !ok {
+			return fmt.Errorf("%s: unsupported attribute %s", attr.SrcRange, name)
+		}
+	}
+	for _, block := range body.Blocks {
+		if _, ok := blocks[block.Type]; !ok {
+			return fmt.Errorf("%s: unsupported block %s", block.DefRange(), block.Type)
+		}
+	}
+	return nil
+}
+
+func decodeBlocks(body *hclsyntax.Body, name string, field reflect.Value) error {
+	var blocks []*hclsyntax.Block
+	for _, block := range body.Blocks {
+		if block.Type == name {
+			blocks = append(blocks, block)
+		}
+	}
+	if len(blocks) == 0 {
+		return nil
+	}
+	if field.Kind() != reflect.Slice {
+		if len(blocks) > 1 {
+			return fmt.Errorf("%s: only one %s block is allowed", blocks[1].DefRange(), name)
+		}
+		return decodeBody(blocks[0].Body, field)
+	}
+	slice := reflect.MakeSlice(field.Type(), len(blocks), len(blocks))
+	for i, block := range blocks {
+		if err := decodeBody(block.Body, slice.Index(i)); err != nil {
+			return err
+		}
+	}
+	field.Set(slice)
+	return nil
+}
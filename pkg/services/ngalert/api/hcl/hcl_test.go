@@ -1,6 +1,7 @@
 package hcl
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -73,3 +74,98 @@ func TestEncode(t *testing.T) {
 }
 `, string(encoded))
 }
+
+func TestDecode(t *testing.T) {
+	type data struct {
+		Name      string             `hcl:"name"`
+		Number    float64            `hcl:"number"`
+		NumberRef *float64           `hcl:"numberRef"`
+		Bool      bool               `hcl:"bul"`
+		BoolRef   *bool              `hcl:"bulRef,optional"`
+		Strings   *[]string          `hcl:"strings"`
+		Labels    *map[string]string `hcl:"labels"`
+		Ignored   string
+		Blocks    []data `hcl:"blocks,block"`
+		SubData   *data  `hcl:"sub,block"`
+	}
+	newBody := func(resourceType string) (interface{}, error) {
+		if resourceType != "grafana_test" {
+			return nil, fmt.Errorf("unsupported resource type %s", resourceType)
+		}
+		return &data{}, nil
+	}
+
+	t.Run("should decode what Encode encodes", func(t *testing.T) {
+		expected := Resource{
+			Type: "grafana_test",
+			Name: "test-01",
+			Body: &data{
+				Name:      "test",
+				Number:    123,
+				NumberRef: func(f float64) *float64 { return &f }(1333),
+				BoolRef:   func(f bool) *bool { return &f }(true),
+				Strings:   &[]string{"a", "b"},
+				Labels:    &map[string]string{"team": "alerting", "with space": "value"},
+				Blocks: []data{
+					{Name: "el-0", Number: 1},
+					{Name: "el-1", Number: 2, Bool: true},
+				},
+				SubData: &data{Name: "sub-data", Number: 123123},
+			},
+		}
+		encoded, err := Encode(expected, Resource{Type: "grafana_test", Name: "test-02", Body: &data{Name: "empty"}})
+		require.NoError(t, err)
+
+		resources, err := Decode(encoded, newBody)
+		require.NoError(t, err)
+		require.Equal(t, []Resource{expected, {Type: "grafana_test", Name: "test-02", Body: &data{Name: "empty"}}}, resources)
+	})
+
+	testCases := []struct {
+		name     string
+		hcl      string
+		expected string
+	}{
+		{
+			name:     "unsupported resource type",
+			hcl:      `resource "grafana_other" "test" {}`,
+			expected: "unsupported resource type grafana_other",
+		},
+		{
+			name:     "not a resource",
+			hcl:      `provider "grafana" {}`,
+			expected: "expected a resource block with a type and a name",
+		},
+		{
+			name:     "unsupported attribute",
+			hcl:      "resource \"grafana_test\" \"test\" {\n  other = 1\n}",
+			expected: "unsupported attribute other",
+		},
+		{
+			name:     "unsupported block",
+			hcl:      "resource \"grafana_test\" \"test\" {\n  other {}\n}",
+			expected: "unsupported block other",
+		},
+		{
+			name:     "more than one single block",
+			hcl:      "resource \"grafana_test\" \"test\" {\n  sub {}\n  sub {}\n}",
+			expected: "only one sub block is allowed",
+		},
+		{
+			name:     "wrong attribute type",
+			hcl:      "resource \"grafana_test\" \"test\" {\n  number = \"abc\"\n}",
+			expected: "a number is required",
+		},
+		{
+			name:     "invalid syntax",
+			hcl:      `resource "grafana_test" "test" {`,
+			expected: "Unclosed configuration block",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Decode([]byte(tc.hcl), newBody)
+			require.ErrorContains(t, err, tc.expected)
+		})
+	}
+}
@@ -17,6 +17,8 @@ type RuleStore interface {
 
 	GetAlertRulesGroupByRuleUID(ctx context.Context, query *ngmodels.GetAlertRulesGroupByRuleUIDQuery) ([]*ngmodels.AlertRule, error)
 	ListAlertRules(ctx context.Context, query *ngmodels.ListAlertRulesQuery) (ngmodels.RulesGroup, error)
+	// GetAlertRuleVersions returns all versions of the alert rule, ordered from the newest to the oldest.
+	GetAlertRuleVersions(ctx context.Context, orgID int64, ruleUID string) ([]*ngmodels.AlertRuleVersion, error)
 
 	// InsertAlertRules will insert all alert rules passed into the function
 	// and return the map of uuid to id.
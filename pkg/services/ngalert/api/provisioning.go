@@ -128,6 +128,26 @@ func (f *ProvisioningApiHandler) handleRoutePutAlertRuleGroup(ctx *contextmodel.
 	return f.svc.RoutePutAlertRuleGroup(ctx, ag, folder, group)
 }
 
+func (f *ProvisioningApiHandler) handleRouteGetProvisionedSilences(ctx *contextmodel.ReqContext) response.Response {
+	return f.svc.RouteGetProvisionedSilences(ctx)
+}
+
+func (f *ProvisioningApiHandler) handleRouteGetProvisionedSilence(ctx *contextmodel.ReqContext, UID string) response.Response {
+	return f.svc.RouteGetProvisionedSilence(ctx, UID)
+}
+
+func (f *ProvisioningApiHandler) handleRoutePostProvisionedSilence(ctx *contextmodel.ReqContext, s apimodels.ProvisionedSilence) response.Response {
+	return f.svc.RoutePostProvisionedSilence(ctx, s)
+}
+
+func (f *ProvisioningApiHandler) handleRoutePutProvisionedSilence(ctx *contextmodel.ReqContext, s apimodels.ProvisionedSilence, UID string) response.Response {
+	return f.svc.RoutePutProvisionedSilence(ctx, s, UID)
+}
+
+func (f *ProvisioningApiHandler) handleRouteDeleteProvisionedSilence(ctx *contextmodel.ReqContext, UID string) response.Response {
+	return f.svc.RouteDeleteProvisionedSilence(ctx, UID)
+}
+
 func (f *ProvisioningApiHandler) handleRouteExportMuteTiming(ctx *contextmodel.ReqContext, name string) response.Response {
 	return f.svc.RouteGetMuteTimingExport(ctx, name)
 }
@@ -139,3 +159,7 @@ func (f *ProvisioningApiHandler) handleRouteExportMuteTimings(ctx *contextmodel.
 func (f *ProvisioningApiHandler) handleRouteDeleteAlertRuleGroup(ctx *contextmodel.ReqContext, folderUID, group string) response.Response {
 	return f.svc.RouteDeleteAlertRuleGroup(ctx, folderUID, group)
 }
+
+func (f *ProvisioningApiHandler) handleRoutePostProvisioningImport(ctx *contextmodel.ReqContext) response.Response {
+	return f.svc.RoutePostProvisioningImport(ctx)
+}
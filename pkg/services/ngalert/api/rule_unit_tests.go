@@ -0,0 +1,307 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/promql/parser"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/backtesting"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	defaultRuleUnitTestEvaluationInterval = time.Minute
+	// maxRuleUnitTestEvaluations limits the number of evaluations of a unit test.
+	maxRuleUnitTestEvaluations = 10000
+)
+
+// ruleUnitTestStart is the time of the first values of the input series and of the first evaluation of the rule.
+var ruleUnitTestStart = time.Unix(0, 0).UTC()
+
+// ruleUnitTestSeries is an input series of a unit test, with a value for every interval of the test from ruleUnitTestStart.
+type ruleUnitTestSeries struct {
+	labels data.Labels
+	values []*float64
+}
+
+// RunAlertRuleUnitTests runs the unit tests of an alert rule. Every test replays the evaluations of the rule with the
+// backtesting engine, from the first values of the input series to the last evaluation time of the test. The data
+// sources are not queried: the results of the data queries are the input series, and only the expressions are executed
+// by expressionService. Errors in the tests themselves wrap backtesting.ErrInvalidInputData.
+func RunAlertRuleUnitTests(ctx context.Context, engine *backtesting.Engine, expressionService *expr.Service, cfg setting.UnifiedAlertingSettings, user identity.Requester, tests definitions.AlertRuleUnitTests) (definitions.AlertRuleUnitTestResults, error) {
+	rule, err := AlertRuleFromAlertRuleExport(tests.Rule)
+	if err != nil {
+		return definitions.AlertRuleUnitTestResults{}, fmt.Errorf("%w: invalid rule: %s", backtesting.ErrInvalidInputData, err.Error())
+	}
+	if rule.Title == "" {
+		return definitions.AlertRuleUnitTestResults{}, fmt.Errorf("%w: the rule must have a title", backtesting.ErrInvalidInputData)
+	}
+	interval := time.Duration(tests.EvaluationInterval)
+	if interval == 0 {
+		interval = defaultRuleUnitTestEvaluationInterval
+	}
+	if interval < time.Second || interval%time.Second != 0 {
+		return definitions.AlertRuleUnitTestResults{}, fmt.Errorf("%w: the evaluation interval must be a multiple of one second", backtesting.ErrInvalidInputData)
+	}
+	rule.OrgID = user.GetOrgID()
+	rule.IntervalSeconds = int64(interval.Seconds())
+	if rule.UID == "" {
+		// prefix unit-test- is to distinguish between executions of regular rules and unit tests in logs
+		rule.UID = "unit-test-" + util.GenerateShortUID()
+	}
+
+	var dataQueries []string
+	for _, q := range rule.Data {
+		isExpr, err := q.IsExpression()
+		if err != nil {
+			return definitions.AlertRuleUnitTestResults{}, fmt.Errorf("%w: invalid query %s: %s", backtesting.ErrInvalidInputData, q.RefID, err.Error())
+		}
+		if !isExpr {
+			dataQueries = append(dataQueries, q.RefID)
+		}
+	}
+
+	results := definitions.AlertRuleUnitTestResults{Tests: make([]definitions.AlertRuleUnitTestResult, 0, len(tests.Tests))}
+	for i, test := range tests.Tests {
+		name := test.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		failures, err := runAlertRuleUnitTest(ctx, engine, expressionService, cfg, user, rule, dataQueries, test)
+		if err != nil {
+			return definitions.AlertRuleUnitTestResults{}, fmt.Errorf("test %s: %w", name, err)
+		}
+		results.Tests = append(results.Tests, definitions.AlertRuleUnitTestResult{Name: test.Name, Failures: failures})
+	}
+	return results, nil
+}
+
+func runAlertRuleUnitTest(ctx context.Context, engine *backtesting.Engine, expressionService *expr.Service, cfg setting.UnifiedAlertingSettings, user identity.Requester, rule ngmodels.AlertRule, dataQueries []string, test definitions.AlertRuleUnitTest) ([]definitions.AlertRuleUnitTestFailure, error) {
+	evalInterval := time.Duration(rule.IntervalSeconds) * time.Second
+	seriesInterval := time.Duration(test.Interval)
+	if seriesInterval == 0 {
+		seriesInterval = evalInterval
+	}
+	if seriesInterval < 0 {
+		return nil, fmt.Errorf("%w: the interval must be positive", backtesting.ErrInvalidInputData)
+	}
+	series, err := parseRuleUnitTestSeries(test.InputSeries, dataQueries)
+	if err != nil {
+		return nil, err
+	}
+
+	cases := make(map[int][]definitions.AlertRuleUnitTestCase, len(test.AlertRuleTest))
+	evaluations := 0
+	for _, c := range test.AlertRuleTest {
+		if c.EvalTime < 0 {
+			return nil, fmt.Errorf("%w: eval_time %s is negative", backtesting.ErrInvalidInputData, c.EvalTime)
+		}
+		// the alerts at the evaluation time are the ones of the last evaluation before it
+		idx := int(time.Duration(c.EvalTime) / evalInterval)
+		cases[idx] = append(cases[idx], c)
+		evaluations = max(evaluations, idx+1)
+	}
+	if evaluations == 0 {
+		return nil, nil
+	}
+	if evaluations > maxRuleUnitTestEvaluations {
+		return nil, fmt.Errorf("%w: the test needs %d evaluations, the maximum is %d", backtesting.ErrInvalidInputData, evaluations, maxRuleUnitTestEvaluations)
+	}
+
+	input := func(query ngmodels.AlertQuery, now time.Time) (mathexp.Results, error) {
+		return ruleUnitTestQueryResult(query, series[query.RefID], seriesInterval, now)
+	}
+	factory := eval.NewInputEvaluatorFactory(cfg, expressionService, input)
+
+	var failures []definitions.AlertRuleUnitTestFailure
+	extraLabels := state.GetRuleExtraLabels(log.New("ngalert.unit-tests"), &rule, "", false)
+	err = engine.Replay(ctx, user, &rule, factory, extraLabels, ruleUnitTestStart, evaluations, func(now time.Time, states []*state.State) error {
+		got := ruleUnitTestAlerts(states)
+		for _, c := range cases[int(now.Sub(ruleUnitTestStart)/evalInterval)] {
+			expected := normalizeRuleUnitTestAlerts(c.ExpAlerts)
+			if !reflect.DeepEqual(expected, got) {
+				failures = append(failures, definitions.AlertRuleUnitTestFailure{EvalTime: c.EvalTime, Expected: expected, Got: got})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(failures, func(i, j int) bool {
+		return failures[i].EvalTime < failures[j].EvalTime
+	})
+	return failures, nil
+}
+
+// parseRuleUnitTestSeries parses the input series of a test, and returns them by the RefID of their data query.
+func parseRuleUnitTestSeries(inputSeries []definitions.AlertRuleUnitTestInputSeries, dataQueries []string) (map[string][]ruleUnitTestSeries, error) {
+	result := make(map[string][]ruleUnitTestSeries, len(dataQueries))
+	for _, is := range inputSeries {
+		refID := is.RefID
+		if refID == "" {
+			if len(dataQueries) != 1 {
+				return nil, fmt.Errorf("%w: series %s must have the ref_id of one of the data queries %v", backtesting.ErrInvalidInputData, is.Series, dataQueries)
+			}
+			refID = dataQueries[0]
+		}
+		if !slices.Contains(dataQueries, refID) {
+			return nil, fmt.Errorf("%w: series %s has the ref_id %s that is not one of the data queries %v", backtesting.ErrInvalidInputData, is.Series, refID, dataQueries)
+		}
+		lbls, values, err := parser.ParseSeriesDesc(is.Series + " " + is.Values)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to parse series %s: %s", backtesting.ErrInvalidInputData, is.Series, err.Error())
+		}
+		s := ruleUnitTestSeries{labels: make(data.Labels, lbls.Len()), values: make([]*float64, len(values))}
+		lbls.Range(func(l labels.Label) {
+			s.labels[l.Name] = l.Value
+		})
+		for i, v := range values {
+			if v.Histogram != nil {
+				return nil, fmt.Errorf("%w: series %s has histogram values, which are not supported", backtesting.ErrInvalidInputData, is.Series)
+			}
+			if v.Omitted || value.IsStaleNaN(v.Value) {
+				continue
+			}
+			s.values[i] = util.Pointer(v.Value)
+		}
+		result[refID] = append(result[refID], s)
+	}
+	return result, nil
+}
+
+// ruleUnitTestQueryResult returns the values of the series in the time range of the query. The result of an instant
+// query is the last value of every series.
+func ruleUnitTestQueryResult(query ngmodels.AlertQuery, series []ruleUnitTestSeries, interval time.Duration, now time.Time) (mathexp.Results, error) {
+	instant, err := isInstantQuery(query)
+	if err != nil {
+		return mathexp.Results{}, err
+	}
+	tr := query.RelativeTimeRange.ToTimeRange().AbsoluteTime(now)
+
+	values := make(mathexp.Values, 0, len(series))
+	for _, s := range series {
+		var times []time.Time
+		var points []*float64
+		for i, v := range s.values {
+			t := ruleUnitTestStart.Add(time.Duration(i) * interval)
+			if v == nil || t.Before(tr.From) || t.After(tr.To) {
+				continue
+			}
+			times = append(times, t)
+			points = append(points, v)
+		}
+		if len(points) == 0 {
+			continue
+		}
+		if instant {
+			n := mathexp.NewNumber(query.RefID, s.labels.Copy())
+			n.SetValue(points[len(points)-1])
+			values = append(values, n)
+			continue
+		}
+		result := mathexp.NewSeries(query.RefID, s.labels.Copy(), len(points))
+		for i := range points {
+			result.SetPoint(i, times[i], points[i])
+		}
+		values = append(values, result)
+	}
+	if len(values) == 0 {
+		values = append(values, mathexp.NoData{}.New())
+	}
+	return mathexp.Results{Values: values}, nil
+}
+
+// isInstantQuery returns true if the query is an instant query of the Prometheus or the Loki data source.
+func isInstantQuery(query ngmodels.AlertQuery) (bool, error) {
+	if query.QueryType == "instant" {
+		return true, nil
+	}
+	var model struct {
+		Instant   bool   `json:"instant"`
+		Range     bool   `json:"range"`
+		QueryType string `json:"queryType"`
+	}
+	if err := json.Unmarshal(query.Model, &model); err != nil {
+		return false, fmt.Errorf("failed to parse the model of query %s: %w", query.RefID, err)
+	}
+	return (model.Instant && !model.Range) || model.QueryType == "instant", nil
+}
+
+// ruleUnitTestAlerts returns the alert instances that are not Normal, without their private labels and annotations,
+// in the order of normalizeRuleUnitTestAlerts.
+func ruleUnitTestAlerts(states []*state.State) []definitions.AlertRuleUnitTestAlert {
+	alerts := make([]definitions.AlertRuleUnitTestAlert, 0, len(states))
+	for _, s := range states {
+		if s.State == eval.Normal {
+			continue
+		}
+		st := s.State.String()
+		if s.StateReason != "" {
+			st += " (" + s.StateReason + ")"
+		}
+		alerts = append(alerts, definitions.AlertRuleUnitTestAlert{
+			ExpState:       st,
+			ExpLabels:      withoutPrivateKeys(s.Labels),
+			ExpAnnotations: withoutPrivateKeys(s.Annotations),
+		})
+	}
+	return normalizeRuleUnitTestAlerts(alerts)
+}
+
+// normalizeRuleUnitTestAlerts sets the default state of the expected alerts and sorts the alerts, so that they can be compared.
+func normalizeRuleUnitTestAlerts(alerts []definitions.AlertRuleUnitTestAlert) []definitions.AlertRuleUnitTestAlert {
+	result := make([]definitions.AlertRuleUnitTestAlert, 0, len(alerts))
+	for _, a := range alerts {
+		if a.ExpState == "" {
+			a.ExpState = eval.Alerting.String()
+		}
+		if len(a.ExpLabels) == 0 {
+			a.ExpLabels = nil
+		}
+		if len(a.ExpAnnotations) == 0 {
+			a.ExpAnnotations = nil
+		}
+		result = append(result, a)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return ruleUnitTestAlertKey(result[i]) < ruleUnitTestAlertKey(result[j])
+	})
+	return result
+}
+
+func ruleUnitTestAlertKey(a definitions.AlertRuleUnitTestAlert) string {
+	// fmt prints the maps sorted by key
+	return fmt.Sprintf("%v %s %v", a.ExpLabels, a.ExpState, a.ExpAnnotations)
+}
+
+// withoutPrivateKeys returns a copy of the labels or annotations without the keys like __alert_rule_uid__.
+func withoutPrivateKeys(m map[string]string) map[string]string {
+	result := make(map[string]string, len(m))
+	for k, v := range m {
+		if strings.HasPrefix(k, "__") && strings.HasSuffix(k, "__") {
+			continue
+		}
+		result[k] = v
+	}
+	return result
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/backtesting"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const testRuleUnitTests = `
+rule:
+  title: HighCPU
+  condition: C
+  for: 2m
+  noDataState: NoData
+  execErrState: Error
+  labels:
+    severity: critical
+  annotations:
+    summary: 'CPU of {{ $labels.instance }} is {{ $values.B }}'
+  data:
+    - refId: A
+      datasourceUid: prometheus
+      relativeTimeRange:
+        from: 600
+        to: 0
+      model:
+        expr: cpu_usage
+    - refId: B
+      datasourceUid: __expr__
+      model:
+        type: reduce
+        expression: A
+        reducer: last
+    - refId: C
+      datasourceUid: __expr__
+      model:
+        type: threshold
+        expression: B
+        conditions:
+          - evaluator:
+              type: gt
+              params: [0.5]
+evaluation_interval: 1m
+tests:
+  - name: cpu
+    input_series:
+      - series: 'cpu_usage{instance="a"}'
+        values: '0 0 1x4'
+      - series: 'cpu_usage{instance="b"}'
+        values: '0x5'
+    alert_rule_test:
+      - eval_time: 1m
+        exp_alerts: []
+      - eval_time: 2m30s
+        exp_alerts:
+          - exp_state: Pending
+            exp_labels:
+              alertname: HighCPU
+              instance: a
+              severity: critical
+            exp_annotations:
+              summary: CPU of a is 1
+      - eval_time: 4m
+        exp_alerts:
+          - exp_labels:
+              alertname: HighCPU
+              instance: a
+              severity: critical
+            exp_annotations:
+              summary: CPU of a is 1
+`
+
+func TestRunAlertRuleUnitTests(t *testing.T) {
+	run := func(t *testing.T, tests definitions.AlertRuleUnitTests) (definitions.AlertRuleUnitTestResults, error) {
+		t.Helper()
+		tracer := tracing.InitializeTracerForTest()
+		engine := backtesting.NewEngine(&url.URL{Scheme: "http", Host: "localhost:3000"}, nil, tracer)
+		exprService := expr.ProvideService(&setting.Cfg{ExpressionsEnabled: true}, nil, nil, featuremgmt.WithFeatures(), nil, tracer)
+		return RunAlertRuleUnitTests(context.Background(), engine, exprService, setting.UnifiedAlertingSettings{EvaluationTimeout: 10 * time.Second}, &user.SignedInUser{OrgID: 1}, tests)
+	}
+	parse := func(t *testing.T) definitions.AlertRuleUnitTests {
+		t.Helper()
+		var tests definitions.AlertRuleUnitTests
+		require.NoError(t, yaml.Unmarshal([]byte(testRuleUnitTests), &tests))
+		return tests
+	}
+
+	t.Run("passing test has no failures", func(t *testing.T) {
+		results, err := run(t, parse(t))
+
+		require.NoError(t, err)
+		require.Equal(t, definitions.AlertRuleUnitTestResults{Tests: []definitions.AlertRuleUnitTestResult{{Name: "cpu"}}}, results)
+	})
+
+	t.Run("failing test has the expected and actual alerts", func(t *testing.T) {
+		tests := parse(t)
+		tests.Tests[0].AlertRuleTest = append(tests.Tests[0].AlertRuleTest, definitions.AlertRuleUnitTestCase{
+			EvalTime: model.Duration(3 * time.Minute),
+		})
+
+		results, err := run(t, tests)
+
+		require.NoError(t, err)
+		require.Len(t, results.Tests, 1)
+		require.Equal(t, []definitions.AlertRuleUnitTestFailure{{
+			EvalTime: model.Duration(3 * time.Minute),
+			Expected: []definitions.AlertRuleUnitTestAlert{},
+			Got: []definitions.AlertRuleUnitTestAlert{{
+				ExpState:       "Pending",
+				ExpLabels:      map[string]string{"alertname": "HighCPU", "instance": "a", "severity": "critical"},
+				ExpAnnotations: map[string]string{"summary": "CPU of a is 1"},
+			}},
+		}}, results.Tests[0].Failures)
+	})
+
+	t.Run("alerts of an instant query", func(t *testing.T) {
+		tests := parse(t)
+		tests.Rule.Data[0].Model["instant"] = true
+		tests.Rule.Data = []definitions.AlertQueryExport{tests.Rule.Data[0], tests.Rule.Data[2]}
+		tests.Rule.Data[1].Model["expression"] = "A"
+		tests.Rule.Condition = "C"
+		tests.Rule.Annotations = nil
+		tests.Tests[0].AlertRuleTest = []definitions.AlertRuleUnitTestCase{{
+			EvalTime:  model.Duration(2 * time.Minute),
+			ExpAlerts: []definitions.AlertRuleUnitTestAlert{{ExpState: "Pending", ExpLabels: map[string]string{"alertname": "HighCPU", "instance": "a", "severity": "critical"}}},
+		}}
+
+		results, err := run(t, tests)
+
+		require.NoError(t, err)
+		require.Empty(t, results.Tests[0].Failures)
+	})
+
+	t.Run("series of unknown query is invalid", func(t *testing.T) {
+		tests := parse(t)
+		tests.Tests[0].InputSeries[0].RefID = "B"
+
+		_, err := run(t, tests)
+
+		require.ErrorIs(t, err, backtesting.ErrInvalidInputData)
+		require.ErrorContains(t, err, "not one of the data queries [A]")
+	})
+
+	t.Run("invalid values are invalid", func(t *testing.T) {
+		tests := parse(t)
+		tests.Tests[0].InputSeries[0].Values = "1 a"
+
+		_, err := run(t, tests)
+
+		require.ErrorIs(t, err, backtesting.ErrInvalidInputData)
+	})
+}
@@ -32,3 +32,11 @@ func (f *TestingApiHandler) handleRouteEvalQueries(c *contextmodel.ReqContext, b
 func (f *TestingApiHandler) handleBacktestConfig(ctx *contextmodel.ReqContext, conf apimodels.BacktestConfig) response.Response {
 	return f.svc.BacktestAlertRule(ctx, conf)
 }
+
+func (f *TestingApiHandler) handleBacktestRuleGroupConfig(ctx *contextmodel.ReqContext, conf apimodels.BacktestRuleGroupConfig) response.Response {
+	return f.svc.BacktestAlertRuleGroup(ctx, conf)
+}
+
+func (f *TestingApiHandler) handleRouteRunAlertRuleUnitTests(ctx *contextmodel.ReqContext, conf apimodels.AlertRuleUnitTests) response.Response {
+	return f.svc.RunAlertRuleUnitTests(ctx, conf)
+}
@@ -177,6 +177,19 @@ import (
 //       403: PermissionDenied
 //       409: AlertManagerNotReady
 
+// swagger:route POST /alertmanager/grafana/config/api/v1/routes/test alertmanager RoutePostTestGrafanaRoutes
+//
+// Test the notification policies of the Grafana Alertmanager with the labels of alerts, without sending notifications.
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//
+//       200: TestRoutesResult
+//       400: ValidationError
+//       403: PermissionDenied
+//       404: NotFound
+
 // swagger:route GET /alertmanager/grafana/api/v2/silences alertmanager RouteGetGrafanaSilences
 //
 // get silences
@@ -373,6 +386,70 @@ const (
 	ExecutionError  TemplateErrorKind = "execution_error"
 )
 
+// swagger:parameters RoutePostTestGrafanaRoutes
+type TestRoutesConfigParams struct {
+	// in:body
+	Body TestRoutesConfigBodyParams
+}
+
+type TestRoutesConfigBodyParams struct {
+	// Labels of an alert to route.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Alerts to route, like the current alert instances of a rule.
+	Alerts []*amv2.PostableAlert `json:"alerts,omitempty"`
+
+	// Configuration to route the alerts with. The current configuration is used if it is not set.
+	AlertmanagerConfig *PostableUserConfig `json:"alertmanager_config,omitempty"`
+
+	// Time at which the mute timings and the silences are evaluated. It defaults to now.
+	Time *time.Time `json:"time,omitempty"`
+}
+
+// swagger:model
+type TestRoutesResult struct {
+	Alerts []TestRoutesAlertResult `json:"alerts"`
+}
+
+type TestRoutesAlertResult struct {
+	Labels map[string]string `json:"labels"`
+
+	// Notification policies that the labels match, in the order the Alertmanager notifies them.
+	Routes []TestRoutesRouteResult `json:"routes"`
+
+	// IDs of the silences that silence the alert at the time.
+	SilencedBy []string `json:"silenced_by,omitempty"`
+}
+
+type TestRoutesRouteResult struct {
+	// Indexes of the nested notification policies from the default notification policy. It is empty for the default
+	// notification policy, and null for the notification policies autogenerated from the notification settings of rules.
+	Path []int `json:"path"`
+
+	// Key of the notification policy, like the key of its aggregation groups.
+	// example: {}/{severity="critical"}
+	Policy string `json:"policy"`
+
+	// Name of the contact point.
+	Receiver string `json:"receiver"`
+
+	// Effective labels to group by, or ["..."] to group by all labels.
+	GroupBy []string `json:"group_by"`
+
+	// Labels of the aggregation group of the alert.
+	GroupLabels map[string]string `json:"group_labels"`
+
+	GroupWait      model.Duration `json:"group_wait"`
+	GroupInterval  model.Duration `json:"group_interval"`
+	RepeatInterval model.Duration `json:"repeat_interval"`
+
+	MuteTimeIntervals   []string `json:"mute_time_intervals,omitempty"`
+	ActiveTimeIntervals []string `json:"active_time_intervals,omitempty"`
+
+	// Time intervals that mute the notifications of the notification policy at the time.
+	MutedBy []string `json:"muted_by,omitempty"`
+}
+
 // swagger:parameters RouteCreateSilence RouteCreateGrafanaSilence
 type CreateSilenceParams struct {
 	// in:body
@@ -164,6 +164,41 @@ import (
 //       403: ForbiddenError
 //       404: NotFound
 
+// swagger:route Get /ruler/grafana/api/v1/rule/{RuleUID}/versions ruler RouteGetRuleVersionsByUID
+//
+// List all versions of a rule, newest first
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GettableRuleVersions
+//       403: ForbiddenError
+//       404: NotFound
+
+// swagger:route Get /ruler/grafana/api/v1/rule/{RuleUID}/versions/diff ruler RouteGetRuleVersionsDiff
+//
+// Compare two versions of a rule
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: RuleVersionDiff
+//       400: ValidationError
+//       403: ForbiddenError
+//       404: NotFound
+
+// swagger:route POST /ruler/grafana/api/v1/rule/{RuleUID}/versions/{Version}/restore ruler RoutePostRestoreRuleVersion
+//
+// Restore the definition of a rule from one of its previous versions
+//
+//     Responses:
+//       202: UpdateRuleGroupResponse
+//       400: ValidationError
+//       403: ForbiddenError
+//       404: NotFound
+
 // swagger:parameters RoutePostNameRulesConfig RoutePostNameGrafanaRulesConfig RoutePostRulesGroupForExport
 type NamespaceConfig struct {
 	// The UID of the rule folder
@@ -189,6 +224,34 @@ type PathRouleGroupConfig struct {
 	Groupname string
 }
 
+// swagger:parameters RouteGetRuleVersionsByUID RouteGetRuleVersionsDiff
+type PathRuleUID struct {
+	// The UID of the rule
+	// in: path
+	RuleUID string
+}
+
+// swagger:parameters RouteGetRuleVersionsDiff
+type RuleVersionsDiffParams struct {
+	// The version to compare from
+	// in: query
+	// required: true
+	From int64 `json:"from"`
+	// The version to compare to. Defaults to the current version of the rule.
+	// in: query
+	To int64 `json:"to"`
+}
+
+// swagger:parameters RoutePostRestoreRuleVersion
+type RestoreRuleVersionParams struct {
+	// The UID of the rule
+	// in: path
+	RuleUID string
+	// The version to restore
+	// in: path
+	Version int64
+}
+
 // swagger:parameters RouteGetRulesConfig RouteGetGrafanaRulesConfig
 type PathGetRulesParams struct {
 	// in: query
@@ -450,6 +513,33 @@ type AlertRuleNotificationSettings struct {
 	MuteTimeIntervals []string `json:"mute_time_intervals,omitempty"`
 }
 
+// Record defines how the result of a recording rule is written.
+// swagger:model
+type Record struct {
+	// Name of the recorded metric.
+	// required: true
+	// example: grafana_alerts_ratio
+	Metric string `json:"metric" yaml:"metric"`
+	// RefID of the query or expression whose result is recorded.
+	// required: true
+	// example: A
+	From string `json:"from" yaml:"from"`
+}
+
+// RuleInhibition makes the alert instances of a rule inhibited while an alert instance of another rule is firing.
+// Inhibited alert instances are not sent to the Alertmanager.
+// swagger:model
+type RuleInhibition struct {
+	// UID of the rule whose firing alert instances inhibit the rule.
+	// required: true
+	// example: datacenter-down
+	RuleUID string `json:"rule_uid" yaml:"rule_uid"`
+	// Labels that must have the same value in the firing alert instance and in the inhibited alert instance.
+	// If empty, any firing alert instance of the rule inhibits all alert instances.
+	// example: ["datacenter"]
+	Equal []string `json:"equal,omitempty" yaml:"equal,omitempty"`
+}
+
 // swagger:model
 type PostableGrafanaRule struct {
 	Title                string                         `json:"title" yaml:"title"`
@@ -460,6 +550,8 @@ type PostableGrafanaRule struct {
 	ExecErrState         ExecutionErrorState            `json:"exec_err_state" yaml:"exec_err_state"`
 	IsPaused             *bool                          `json:"is_paused" yaml:"is_paused"`
 	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings" yaml:"notification_settings"`
+	Record               *Record                        `json:"record" yaml:"record"`
+	InhibitedBy          []RuleInhibition               `json:"inhibited_by,omitempty" yaml:"inhibited_by,omitempty"`
 }
 
 // swagger:model
@@ -480,6 +572,8 @@ type GettableGrafanaRule struct {
 	Provenance           Provenance                     `json:"provenance,omitempty" yaml:"provenance,omitempty"`
 	IsPaused             bool                           `json:"is_paused" yaml:"is_paused"`
 	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings,omitempty" yaml:"notification_settings,omitempty"`
+	Record               *Record                        `json:"record,omitempty" yaml:"record,omitempty"`
+	InhibitedBy          []RuleInhibition               `json:"inhibited_by,omitempty" yaml:"inhibited_by,omitempty"`
 }
 
 // AlertQuery represents a single query associated with an alert definition.
@@ -549,6 +643,32 @@ func (d *Duration) UnmarshalYAML(unmarshal func(any) error) error {
 	}
 }
 
+// swagger:model
+type GettableRuleVersions []GettableExtendedRuleNode
+
+// swagger:model
+type RuleVersionDiff struct {
+	RuleUID string `json:"rule_uid"`
+	From    int64  `json:"from"`
+	To      int64  `json:"to"`
+	// Changes between the versions. Empty if the rule definition did not change.
+	Changes []RuleVersionChange `json:"changes"`
+}
+
+// RuleVersionChange describes a change of a single field of a rule between two versions.
+type RuleVersionChange struct {
+	// Section of the rule the field belongs to, e.g. data, labels, annotations or notification_settings.
+	// example: labels
+	Section string `json:"section"`
+	// Path to the changed field. Keys of maps and indices of lists are designated by square brackets.
+	// example: Labels[severity]
+	Path string `json:"path"`
+	// Value of the field in the older version. Absent if the field was added.
+	Old any `json:"old,omitempty"`
+	// Value of the field in the newer version. Absent if the field was removed.
+	New any `json:"new,omitempty"`
+}
+
 // swagger:model
 type UpdateRuleGroupResponse struct {
 	Message string   `json:"message"`
@@ -39,6 +39,53 @@ import (
 //       200: AlertResponse
 //       404: NotFound
 
+// swagger:route POST /prometheus/grafana/api/v1/rule/{RuleUID}/alerts/{Fingerprint}/acknowledge prometheus RoutePostGrafanaAlertAcknowledgement
+//
+// acknowledges a firing alert of a Grafana-managed rule
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       200: AlertAcknowledgement
+//       400: ValidationError
+//       403: ForbiddenError
+//       404: NotFound
+
+// swagger:parameters RoutePostGrafanaAlertAcknowledgement
+type AlertAcknowledgementParams struct {
+	// in:path
+	RuleUID string
+	// The fingerprint of the labels of the alert.
+	// in:path
+	Fingerprint string
+	// in:body
+	Body PostableAlertAcknowledgement
+}
+
+// swagger:model
+type PostableAlertAcknowledgement struct {
+	Comment string `json:"comment,omitempty"`
+	// The time when the acknowledgement expires. If it is not set, the acknowledgement is kept until the state of the
+	// alert changes.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	// Do not send the alert to the Alertmanager again while it is acknowledged.
+	SuppressNotifications bool `json:"suppressNotifications,omitempty"`
+}
+
+// swagger:model
+type AlertAcknowledgement struct {
+	// The login of the user who acknowledged the alert.
+	// required: true
+	By      string `json:"by"`
+	Comment string `json:"comment,omitempty"`
+	// required: true
+	At        time.Time  `json:"at"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	// required: true
+	SuppressNotifications bool `json:"suppressNotifications"`
+}
+
 // swagger:model
 type RuleResponse struct {
 	// in: body
@@ -138,6 +185,8 @@ type AlertingRule struct {
 	Alerts         []Alert          `json:"alerts,omitempty"`
 	Totals         map[string]int64 `json:"totals,omitempty"`
 	TotalsFiltered map[string]int64 `json:"totalsFiltered,omitempty"`
+	// Rules that inhibit the alerts of this rule while they are firing.
+	InhibitedBy []RuleInhibition `json:"inhibitedBy,omitempty"`
 	Rule
 }
 
@@ -156,6 +205,58 @@ type Rule struct {
 	Type           v1.RuleType `json:"type"`
 	LastEvaluation time.Time   `json:"lastEvaluation"`
 	EvaluationTime float64     `json:"evaluationTime"`
+
+	// The statistics of the last evaluations of the rule. They are only returned with eval_stats=true, by the instance
+	// that evaluates the rule.
+	EvaluationStats *RuleEvaluationStats `json:"evaluationStats,omitempty"`
+}
+
+// RuleEvaluationStats are the statistics of the last evaluations of a rule, kept in memory by the instance that evaluates
+// the rule.
+// swagger:model
+type RuleEvaluationStats struct {
+	// The last evaluations of the rule, the latest first. Every attempt of an evaluation is an evaluation.
+	// required: true
+	Evaluations []RuleEvaluation `json:"evaluations"`
+	// The last failed evaluations of the rule, the latest first.
+	Errors []RuleEvaluation `json:"errors,omitempty"`
+	// The number of evaluations that were missed because the previous evaluation was too slow.
+	// required: true
+	MissedEvaluations    int64      `json:"missedEvaluations"`
+	LastMissedEvaluation *time.Time `json:"lastMissedEvaluation,omitempty"`
+}
+
+// swagger:model
+type RuleEvaluation struct {
+	// required: true
+	ScheduledAt time.Time `json:"scheduledAt"`
+	// required: true
+	Attempt int64 `json:"attempt"`
+	// The duration of the evaluation in seconds.
+	// required: true
+	EvaluationTime float64 `json:"evaluationTime"`
+	// The number of series of the result of the condition.
+	// required: true
+	Series int64 `json:"series"`
+	// The queries and expressions of the rule, in the order of execution.
+	Queries []RuleEvaluationQuery `json:"queries,omitempty"`
+	Error   string                `json:"error,omitempty"`
+}
+
+// swagger:model
+type RuleEvaluationQuery struct {
+	// required: true
+	RefID string `json:"refId"`
+	// The type of the node, "query" for a query to a data source and "expression" for an expression.
+	// required: true
+	Type string `json:"type"`
+	// The duration of the query or the expression in seconds. The queries to the same data source can be sent in one
+	// request, and have the duration of the request.
+	// required: true
+	EvaluationTime float64 `json:"evaluationTime"`
+	// The number of series of the result.
+	// required: true
+	Series int64 `json:"series"`
 }
 
 // Alert has info for an alert.
@@ -170,6 +271,9 @@ type Alert struct {
 	ActiveAt *time.Time `json:"activeAt"`
 	// required: true
 	Value string `json:"value"`
+	// The fingerprint of the labels of the alert. It is only set for the alerts of Grafana-managed rules.
+	Fingerprint     string                `json:"fingerprint,omitempty"`
+	Acknowledgement *AlertAcknowledgement `json:"acknowledgement,omitempty"`
 }
 
 type StateByImportance int
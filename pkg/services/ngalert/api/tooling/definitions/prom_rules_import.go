@@ -0,0 +1,80 @@
+package definitions
+
+import (
+	"github.com/prometheus/common/model"
+)
+
+// swagger:route POST /ruler/grafana/api/v1/import/prometheus/{Namespace} ruler RoutePostPrometheusRulesImport
+//
+// Converts the rule groups of a Prometheus rule file to Grafana-managed alert rules and saves them in the folder.
+// A rule group that already exists in the folder is replaced, the rules with the same title keep their UID.
+// The rules that cannot be converted are reported and left out of their group.
+//
+//     Consumes:
+//     - application/json
+//     - application/yaml
+//
+//     Responses:
+//       202: PrometheusRulesImportResponse
+//       400: ValidationError
+//       403: ForbiddenError
+//       404: description: Not found.
+
+// swagger:parameters RoutePostPrometheusRulesImport
+type PrometheusRulesImportParams struct {
+	// The UID of the rule folder
+	// in:path
+	Namespace string
+	// The UID of the Prometheus data source that the queries of the rules use
+	// in:query
+	// required: true
+	DatasourceUID string `json:"datasourceUid"`
+	// in:body
+	Body PrometheusRuleFile
+}
+
+// PrometheusRuleFile is a rule file in the Prometheus format.
+// swagger:model
+type PrometheusRuleFile struct {
+	Groups []PrometheusRuleGroup `yaml:"groups" json:"groups"`
+}
+
+// PrometheusRuleGroup is a rule group in the Prometheus format.
+// swagger:model
+type PrometheusRuleGroup struct {
+	Name     string         `yaml:"name" json:"name"`
+	Interval model.Duration `yaml:"interval,omitempty" json:"interval,omitempty"`
+	// Limit is not supported, the group is not imported if it is set.
+	Limit int           `yaml:"limit,omitempty" json:"limit,omitempty"`
+	Rules []ApiRuleNode `yaml:"rules" json:"rules"`
+}
+
+// swagger:model
+type PrometheusRulesImportResponse struct {
+	Message string `json:"message"`
+	// Groups are the results of the import of the rule groups.
+	Groups []PrometheusRuleGroupImportResult `json:"groups"`
+	// Errors are the rules that could not be converted.
+	Errors []PrometheusRuleImportError `json:"errors,omitempty"`
+}
+
+type PrometheusRuleGroupImportResult struct {
+	// example: node-exporter
+	Name    string   `json:"name"`
+	Created []string `json:"created,omitempty"`
+	Updated []string `json:"updated,omitempty"`
+	Deleted []string `json:"deleted,omitempty"`
+	// Error is set if the group was not imported.
+	Error string `json:"error,omitempty"`
+}
+
+type PrometheusRuleImportError struct {
+	// example: node-exporter
+	Group string `json:"group"`
+	// Index is the position of the rule in the group.
+	Index int `json:"index"`
+	// Rule is the name of the alert or of the recorded metric.
+	// example: HostOutOfMemory
+	Rule  string `json:"rule"`
+	Error string `json:"error"`
+}
@@ -147,7 +147,8 @@ type ProvisionedAlertRule struct {
 	// required: true
 	ExecErrState ExecutionErrorState `json:"execErrState"`
 	// required: true
-	For model.Duration `json:"for"`
+	For           model.Duration `json:"for"`
+	KeepFiringFor model.Duration `json:"keepFiringFor,omitempty"`
 	// example: {"runbook_url": "https://supercoolrunbook.com/page/13"}
 	Annotations map[string]string `json:"annotations,omitempty"`
 	// example: {"team": "sre-team-1"}
@@ -257,7 +258,12 @@ type AlertRuleExport struct {
 	// ForString is used to:
 	// - Only export the for field for HCL if it is non-zero.
 	// - Format the Prometheus model.Duration type properly for HCL.
-	ForString            *string                              `json:"-" yaml:"-" hcl:"for"`
+	ForString     *string        `json:"-" yaml:"-" hcl:"for"`
+	KeepFiringFor model.Duration `json:"keepFiringFor,omitempty" yaml:"keepFiringFor,omitempty"`
+	// KeepFiringForString is used to:
+	// - Only export the keep_firing_for field for HCL if it is non-zero.
+	// - Format the Prometheus model.Duration type properly for HCL.
+	KeepFiringForString  *string                              `json:"-" yaml:"-" hcl:"keep_firing_for"`
 	Annotations          *map[string]string                   `json:"annotations,omitempty" yaml:"annotations,omitempty" hcl:"annotations"`
 	Labels               *map[string]string                   `json:"labels,omitempty" yaml:"labels,omitempty" hcl:"labels"`
 	IsPaused             bool                                 `json:"isPaused" yaml:"isPaused" hcl:"is_paused"`
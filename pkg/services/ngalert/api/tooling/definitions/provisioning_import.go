@@ -0,0 +1,76 @@
+package definitions
+
+// swagger:route POST /v1/provisioning/import provisioning stable RoutePostProvisioningImport
+//
+// Import alert rule groups, contact points, notification policies and mute timings from a file in one of the export formats.
+//
+// The resources of the file replace the resources with the same identity: alert rule groups are identified by folder
+// and name, contact points and mute timings by name. The resources that are not in the file are not changed.
+// With dryRun, the changes are calculated but not applied.
+//
+//     Consumes:
+//     - application/json
+//     - application/yaml
+//     - text/hcl
+//
+//     Responses:
+//       200: ProvisioningImportResponse
+//       400: ValidationError
+
+// swagger:parameters RoutePostProvisioningImport
+type ProvisioningImportParams struct {
+	// Format of the file, either yaml, json or hcl. The Content-Type header can also be used, but the query parameter will take precedence.
+	// in: query
+	// required: false
+	// default: yaml
+	Format string `json:"format"`
+	// Whether to only calculate the changes without applying them.
+	// in: query
+	// required: false
+	// default: false
+	DryRun bool `json:"dryRun"`
+	// A file exported by the export endpoints.
+	// in:body
+	Body AlertingFileExport
+	// in:header
+	XDisableProvenance string `json:"X-Disable-Provenance"`
+}
+
+// The actions of the changes of an import.
+const (
+	ProvisioningImportActionCreate = "create"
+	ProvisioningImportActionUpdate = "update"
+	ProvisioningImportActionNone   = "none"
+)
+
+// The kinds of the resources of an import.
+const (
+	ProvisioningImportKindRuleGroup    = "ruleGroup"
+	ProvisioningImportKindContactPoint = "contactPoint"
+	ProvisioningImportKindPolicies     = "policies"
+	ProvisioningImportKindMuteTiming   = "muteTiming"
+)
+
+// swagger:model
+type ProvisioningImportResponse struct {
+	// DryRun is true if the changes were not applied.
+	DryRun  bool                       `json:"dryRun"`
+	Changes []ProvisioningImportChange `json:"changes"`
+}
+
+// ProvisioningImportChange is the change of a resource of the imported file.
+type ProvisioningImportChange struct {
+	// example: ruleGroup
+	// enum: ruleGroup, contactPoint, policies, muteTiming
+	Kind string `json:"kind"`
+	// Name of the resource. It is empty for the notification policies.
+	Name string `json:"name,omitempty"`
+	// Folder is the title of the folder of a rule group.
+	Folder string `json:"folder,omitempty"`
+	// example: update
+	// enum: create, update, none
+	Action string `json:"action"`
+	// Diff is the paths of the fields of the exported resource that are changed by the import.
+	// example: ["rules[0].title","rules[1]"]
+	Diff []string `json:"diff,omitempty"`
+}
@@ -0,0 +1,112 @@
+package definitions
+
+import (
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/common/model"
+)
+
+// swagger:route GET /v1/provisioning/silences provisioning stable RouteGetProvisionedSilences
+//
+// Get all the provisioned silences.
+//
+//     Responses:
+//       200: ProvisionedSilences
+
+// swagger:route GET /v1/provisioning/silences/{UID} provisioning stable RouteGetProvisionedSilence
+//
+// Get a provisioned silence.
+//
+//     Responses:
+//       200: ProvisionedSilence
+//       404: description: Not found.
+
+// swagger:route POST /v1/provisioning/silences provisioning stable RoutePostProvisionedSilence
+//
+// Create a new provisioned silence.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       201: ProvisionedSilence
+//       400: ValidationError
+
+// swagger:route PUT /v1/provisioning/silences/{UID} provisioning stable RoutePutProvisionedSilence
+//
+// Replace an existing provisioned silence.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       202: ProvisionedSilence
+//       400: ValidationError
+//       404: description: Not found.
+
+// swagger:route DELETE /v1/provisioning/silences/{UID} provisioning stable RouteDeleteProvisionedSilence
+//
+// Delete a provisioned silence and expire its silence.
+//
+//     Responses:
+//       204: description: The provisioned silence was deleted successfully.
+
+// swagger:parameters RouteGetProvisionedSilence RoutePutProvisionedSilence RouteDeleteProvisionedSilence
+type ProvisionedSilenceUIDParam struct {
+	// Provisioned silence UID
+	// in:path
+	UID string
+}
+
+// swagger:parameters RoutePostProvisionedSilence RoutePutProvisionedSilence
+type ProvisionedSilencePayload struct {
+	// in:body
+	Body ProvisionedSilence
+}
+
+// swagger:parameters RoutePostProvisionedSilence RoutePutProvisionedSilence RouteDeleteProvisionedSilence
+type ProvisionedSilenceHeaders struct {
+	// in:header
+	XDisableProvenance string `json:"X-Disable-Provenance"`
+}
+
+// swagger:model
+type ProvisionedSilences []ProvisionedSilence
+
+// ProvisionedSilence is a silence that Grafana creates in the Alertmanager and keeps in sync with its definition.
+// It is either a one-off silence with a start and an end, or a silence repeated on a schedule.
+// swagger:model
+type ProvisionedSilence struct {
+	// example: maintenance-window
+	UID string `json:"uid"`
+	// required: true
+	Matchers amv2.Matchers `json:"matchers"`
+	// required: true
+	// example: Weekly database maintenance
+	Comment string `json:"comment"`
+	// example: ops-team
+	CreatedBy string `json:"createdBy,omitempty"`
+	// StartsAt and EndsAt are the start and the end of a one-off silence. They must not be set if the silence has a schedule.
+	StartsAt *time.Time `json:"startsAt,omitempty"`
+	EndsAt   *time.Time `json:"endsAt,omitempty"`
+	// Schedule repeats the silence.
+	Schedule *SilenceSchedule `json:"schedule,omitempty"`
+	// readonly: true
+	Provenance Provenance `json:"provenance,omitempty"`
+}
+
+// swagger:model
+type SilenceSchedule struct {
+	// Cron is a standard cron expression of the starts of the silences.
+	// required: true
+	// example: 0 2 * * SUN
+	Cron string `json:"cron"`
+	// Duration is how long each silence lasts.
+	// required: true
+	// example: 2h
+	Duration model.Duration `json:"duration"`
+	// Location is the time zone of the cron expression. Defaults to UTC.
+	// example: Europe/Berlin
+	Location string `json:"location,omitempty"`
+}
@@ -68,6 +68,23 @@ import (
 //     Responses:
 //       200: BacktestResult
 
+// swagger:route Post /v1/rule/backtest/group testing BacktestRuleGroupConfig
+//
+// Test rule group and simulate its notifications
+//
+// The rules of the group are evaluated over the time range, and their alerts are routed through the notification
+// policies and mute timings of the organization, or of the given Alertmanager configuration, without sending anything.
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: BacktestRuleGroupResult
+//       400: ValidationError
+
 // swagger:parameters RouteTestReceiverConfig
 type TestReceiverRequest struct {
 	// in:body
@@ -234,3 +251,50 @@ type BacktestConfig struct {
 
 // swagger:model
 type BacktestResult data.Frame
+
+// swagger:parameters BacktestRuleGroupConfig
+type BacktestRuleGroupConfigRequest struct {
+	// in:body
+	Body BacktestRuleGroupConfig
+}
+
+// swagger:model
+type BacktestRuleGroupConfig struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+
+	// The UID of the folder of the rule group.
+	FolderUID string `json:"folder_uid"`
+	// The rule group in the format of the ruler API. Recording rules are not evaluated.
+	Group PostableRuleGroupConfig `json:"group"`
+	// The Alertmanager configuration that routes the alerts. It defaults to the configuration of the organization.
+	AlertmanagerConfig *PostableUserConfig `json:"alertmanager_config,omitempty"`
+}
+
+// swagger:model
+type BacktestRuleGroupResult struct {
+	// The states of the alert instances of each rule, in the format of BacktestResult. The name of a frame is the title
+	// of its rule.
+	Rules []*data.Frame `json:"rules"`
+	// The notifications of the alerts of the rules, in chronological order.
+	Notifications []BacktestNotification `json:"notifications"`
+	// The number of notifications sent to each contact point. Muted notifications are not counted.
+	ContactPoints map[string]int `json:"contact_points"`
+}
+
+// BacktestNotification is a notification of an aggregation group that would have been sent to a contact point.
+type BacktestNotification struct {
+	Time time.Time `json:"time"`
+	// The name of the contact point.
+	Receiver string `json:"receiver"`
+	// The key of the notification policy of the aggregation group.
+	// example: {}/{severity="critical"}
+	Policy      string            `json:"policy"`
+	GroupLabels map[string]string `json:"group_labels"`
+	// The labels of the firing alerts of the notification.
+	Firing []map[string]string `json:"firing"`
+	// The labels of the resolved alerts of the notification.
+	Resolved []map[string]string `json:"resolved"`
+	// The time intervals that muted the notification. The notification is not sent if it is not empty.
+	MutedBy []string `json:"muted_by,omitempty"`
+}
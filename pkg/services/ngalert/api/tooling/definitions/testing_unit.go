@@ -0,0 +1,92 @@
+package definitions
+
+import (
+	"github.com/prometheus/common/model"
+)
+
+// swagger:route Post /v1/rule/unit-test testing RouteRunAlertRuleUnitTests
+//
+// Run the unit tests of an alert rule. The rule is evaluated against the input series of each test instead of
+// querying the data sources, and the alerts are compared with the expected alerts at the evaluation times.
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: AlertRuleUnitTestResults
+//       400: ValidationError
+
+// swagger:parameters RouteRunAlertRuleUnitTests
+type AlertRuleUnitTestsRequest struct {
+	// in:body
+	Body AlertRuleUnitTests
+}
+
+// AlertRuleUnitTests are the unit tests of an alert rule, in the format of the rule test files of the CLI.
+// swagger:model
+type AlertRuleUnitTests struct {
+	// The alert rule in the format of the provisioning export. Its data queries are not executed: their results
+	// are the input series of the tests.
+	Rule AlertRuleExport `json:"rule" yaml:"rule"`
+	// The interval between the evaluations of the rule.
+	// default: 1m
+	EvaluationInterval model.Duration      `json:"evaluation_interval,omitempty" yaml:"evaluation_interval,omitempty"`
+	Tests              []AlertRuleUnitTest `json:"tests" yaml:"tests"`
+}
+
+type AlertRuleUnitTest struct {
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// The interval between the values of the input series. It defaults to the evaluation interval.
+	Interval      model.Duration                 `json:"interval,omitempty" yaml:"interval,omitempty"`
+	InputSeries   []AlertRuleUnitTestInputSeries `json:"input_series" yaml:"input_series"`
+	AlertRuleTest []AlertRuleUnitTestCase        `json:"alert_rule_test" yaml:"alert_rule_test"`
+}
+
+// AlertRuleUnitTestInputSeries is a series of the result of a data query, in the notation of the Prometheus unit tests.
+type AlertRuleUnitTestInputSeries struct {
+	// The RefID of the data query. It can be omitted if the rule has one data query.
+	RefID string `json:"ref_id,omitempty" yaml:"ref_id,omitempty"`
+	// example: cpu_usage{instance="a"}
+	Series string `json:"series" yaml:"series"`
+	// The values in the expanding notation, where '_' is a missing value.
+	// example: 0 0 1+1x5 _ 10
+	Values string `json:"values" yaml:"values"`
+}
+
+type AlertRuleUnitTestCase struct {
+	// The time of the evaluation, relative to the first values of the input series.
+	EvalTime model.Duration `json:"eval_time" yaml:"eval_time"`
+	// The alert instances that are not Normal at the time of the evaluation.
+	ExpAlerts []AlertRuleUnitTestAlert `json:"exp_alerts" yaml:"exp_alerts"`
+}
+
+type AlertRuleUnitTestAlert struct {
+	// The state of the alert instance, followed by the reason of the state in parentheses if there is one.
+	// default: Alerting
+	// example: Alerting (NoData)
+	ExpState string `json:"exp_state,omitempty" yaml:"exp_state,omitempty"`
+	// The labels of the alert instance, without the private labels like __alert_rule_uid__.
+	ExpLabels map[string]string `json:"exp_labels,omitempty" yaml:"exp_labels,omitempty"`
+	// The rendered annotations of the alert instance.
+	ExpAnnotations map[string]string `json:"exp_annotations,omitempty" yaml:"exp_annotations,omitempty"`
+}
+
+// swagger:model
+type AlertRuleUnitTestResults struct {
+	Tests []AlertRuleUnitTestResult `json:"tests"`
+}
+
+type AlertRuleUnitTestResult struct {
+	Name string `json:"name,omitempty"`
+	// The evaluations where the alerts are not the expected alerts. The test passed if it is empty.
+	Failures []AlertRuleUnitTestFailure `json:"failures,omitempty"`
+}
+
+type AlertRuleUnitTestFailure struct {
+	EvalTime model.Duration           `json:"eval_time"`
+	Expected []AlertRuleUnitTestAlert `json:"expected"`
+	Got      []AlertRuleUnitTestAlert `json:"got"`
+}
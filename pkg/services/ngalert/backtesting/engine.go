@@ -11,12 +11,14 @@ import (
 	"github.com/benbjohnson/clock"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
 	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 )
@@ -75,59 +77,194 @@ func (e *Engine) Test(ctx context.Context, user identity.Requester, rule *models
 	}
 	length := int(to.Sub(from).Seconds()) / int(rule.IntervalSeconds)
 
-	stateManager := e.createStateManager()
+	logger.Info("Start testing alert rule", "from", from, "to", to, "interval", rule.IntervalSeconds, "evaluations", length)
 
-	evaluator, err := backtestingEvaluatorFactory(ruleCtx, e.evalFactory, user, rule.GetEvalCondition(), &schedule.AlertingResultsFromRuleState{
-		Manager: stateManager,
-		Rule:    rule,
+	start := time.Now()
+
+	timeline := newStateTimeline(length)
+	err := e.run(ruleCtx, user, rule, e.evalFactory, nil, from, length, func(idx int, currentTime time.Time, states []state.StateTransition, _ stateManager) error {
+		timeline.add(idx, currentTime, states)
+		return nil
 	})
 	if err != nil {
-		return nil, errors.Join(ErrInvalidInputData, err)
+		return nil, err
 	}
+	logger.Info("Rule testing finished successfully", "duration", time.Since(start))
+	return timeline.frame("Testing results"), nil
+}
 
-	logger.Info("Start testing alert rule", "from", from, "to", to, "interval", rule.IntervalSeconds, "evaluations", length)
+// GroupResult is the result of the backtesting of a rule group.
+type GroupResult struct {
+	// Rules are the states of the alert instances of each rule of the group, in the format of the result of Test. The
+	// name of a frame is the title of its rule.
+	Rules []*data.Frame
+	// Notifications are the notifications of the alerts of the rules, computed by the notification simulator.
+	Notifications []notifier.SimulatedNotification
+}
 
-	start := time.Now()
+// TestGroup evaluates the rules of a rule group over the interval [from, to) at the interval of the group, and sends
+// the alerts of the rules to the simulator like the scheduler sends them to the Alertmanager. The labels that the
+// scheduler adds to the alerts of the rules are added with the folder title if includeFolder is true.
+func (e *Engine) TestGroup(ctx context.Context, user identity.Requester, rules []*models.AlertRule, folderTitle string, includeFolder bool, from, to time.Time, simulator *notifier.NotificationSimulator) (*GroupResult, error) {
+	logger := logger.FromContext(ctx)
 
-	tsField := data.NewField("Time", nil, make([]time.Time, length))
-	valueFields := make(map[string]*data.Field)
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("%w: the rule group has no rules", ErrInvalidInputData)
+	}
+	intervalSeconds := rules[0].IntervalSeconds
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: invalid interval of the backtesting [%d,%d]", ErrInvalidInputData, from.Unix(), to.Unix())
+	}
+	if to.Sub(from).Seconds() < float64(intervalSeconds) {
+		return nil, fmt.Errorf("%w: interval of the backtesting [%d,%d] is less than evaluation interval [%ds]", ErrInvalidInputData, from.Unix(), to.Unix(), intervalSeconds)
+	}
+	length := int(to.Sub(from).Seconds()) / int(intervalSeconds)
+
+	logger.Info("Start testing alert rule group", "from", from, "to", to, "interval", intervalSeconds, "evaluations", length, "rules", len(rules))
 
-	err = evaluator.Eval(ruleCtx, from, time.Duration(rule.IntervalSeconds)*time.Second, length, func(idx int, currentTime time.Time, results eval.Results) error {
-		if idx >= length {
-			logger.Info("Unexpected evaluation. Skipping", "from", from, "to", to, "interval", rule.IntervalSeconds, "evaluationTime", currentTime, "evaluationIndex", idx, "expectedEvaluations", length)
+	start := time.Now()
+
+	result := &GroupResult{Rules: make([]*data.Frame, 0, len(rules))}
+	times := make([]time.Time, length)
+	alerts := make([][]notifier.SimulatedAlert, length)
+	for _, rule := range rules {
+		if rule.IntervalSeconds != intervalSeconds {
+			return nil, fmt.Errorf("%w: rule %s has a different evaluation interval than the rule group", ErrInvalidInputData, rule.Title)
+		}
+		ruleCtx := models.WithRuleKey(ctx, rule.GetKey())
+		extraLabels := state.GetRuleExtraLabels(logger, rule, folderTitle, includeFolder)
+		timeline := newStateTimeline(length)
+		err := e.run(ruleCtx, user, rule, e.evalFactory, extraLabels, from, length, func(idx int, currentTime time.Time, states []state.StateTransition, _ stateManager) error {
+			timeline.add(idx, currentTime, states)
+			times[idx] = currentTime
+			alerts[idx] = append(alerts[idx], simulatedAlerts(currentTime, states)...)
 			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to test rule %s: %w", rule.Title, err)
 		}
-		states := stateManager.ProcessEvalResults(ruleCtx, currentTime, rule, results, nil)
-		tsField.Set(idx, currentTime)
-		for _, s := range states {
-			field, ok := valueFields[s.CacheID]
-			if !ok {
-				field = data.NewField("", s.Labels, make([]*string, length))
-				valueFields[s.CacheID] = field
-			}
-			if s.State.State != eval.NoData { // set nil if NoData
-				value := s.State.State.String()
-				if s.StateReason != "" {
-					value += " (" + s.StateReason + ")"
-				}
-				field.Set(idx, &value)
+		result.Rules = append(result.Rules, timeline.frame(rule.Title))
+	}
+
+	for idx, currentTime := range times {
+		if err := simulator.Put(currentTime, alerts[idx]...); err != nil {
+			return nil, err
+		}
+	}
+	if err := simulator.Advance(to); err != nil {
+		return nil, err
+	}
+	result.Notifications = simulator.Notifications()
+
+	logger.Info("Rule group testing finished successfully", "duration", time.Since(start), "notifications", len(result.Notifications))
+	return result, nil
+}
+
+// simulatedAlerts returns the alerts that the scheduler sends to the Alertmanager for the state transitions of an
+// evaluation: the alerts of the firing states, and the resolved alerts of the states that stopped firing.
+func simulatedAlerts(now time.Time, transitions []state.StateTransition) []notifier.SimulatedAlert {
+	result := make([]notifier.SimulatedAlert, 0, len(transitions))
+	for _, t := range transitions {
+		switch {
+		case t.State.State == eval.Normal && t.State.Resolved:
+		case t.State.State == eval.Alerting || t.State.State == eval.NoData || t.State.State == eval.Error:
+			if t.State.InhibitedBy != "" {
 				continue
 			}
+		default:
+			continue
 		}
-		return nil
-	})
-	fields := make([]*data.Field, 0, len(valueFields)+1)
-	fields = append(fields, tsField)
-	for _, f := range valueFields {
+		alert := state.StateToPostableAlert(t, nil)
+		lset := make(model.LabelSet, len(alert.Labels))
+		for k, v := range alert.Labels {
+			lset[model.LabelName(k)] = model.LabelValue(v)
+		}
+		// Firing alerts expire at EndsAt if they are not sent again, like in the Alertmanager.
+		simulated := notifier.SimulatedAlert{Labels: lset, StartsAt: t.State.StartsAt, EndsAt: t.State.EndsAt}
+		if t.State.Resolved {
+			simulated.EndsAt = now
+		}
+		result = append(result, simulated)
+	}
+	return result
+}
+
+// stateTimeline collects the states of the alert instances of a rule over the evaluations into a data frame.
+type stateTimeline struct {
+	length      int
+	tsField     *data.Field
+	valueFields map[string]*data.Field
+}
+
+func newStateTimeline(length int) *stateTimeline {
+	return &stateTimeline{
+		length:      length,
+		tsField:     data.NewField("Time", nil, make([]time.Time, length)),
+		valueFields: make(map[string]*data.Field),
+	}
+}
+
+func (t *stateTimeline) add(idx int, currentTime time.Time, states []state.StateTransition) {
+	t.tsField.Set(idx, currentTime)
+	for _, s := range states {
+		field, ok := t.valueFields[s.CacheID]
+		if !ok {
+			field = data.NewField("", s.Labels, make([]*string, t.length))
+			t.valueFields[s.CacheID] = field
+		}
+		if s.State.State != eval.NoData { // set nil if NoData
+			value := s.State.State.String()
+			if s.StateReason != "" {
+				value += " (" + s.StateReason + ")"
+			}
+			field.Set(idx, &value)
+			continue
+		}
+	}
+}
+
+func (t *stateTimeline) frame(name string) *data.Frame {
+	fields := make([]*data.Field, 0, len(t.valueFields)+1)
+	fields = append(fields, t.tsField)
+	for _, f := range t.valueFields {
 		fields = append(fields, f)
 	}
-	result := data.NewFrame("Testing results", fields...)
+	return data.NewFrame(name, fields...)
+}
 
+// Replay evaluates the rule the given number of times from `from`, with the evaluators created by evalFactory instead of
+// the evaluator factory of the engine. The extra labels are added to the alert instances like the scheduler does. After
+// each evaluation, callback is called with the current states of the alert instances of the rule.
+func (e *Engine) Replay(ctx context.Context, user identity.Requester, rule *models.AlertRule, evalFactory eval.EvaluatorFactory, extraLabels data.Labels, from time.Time, evaluations int, callback func(now time.Time, states []*state.State) error) error {
+	if evaluations <= 0 {
+		return fmt.Errorf("%w: the number of evaluations must be positive", ErrInvalidInputData)
+	}
+	ruleCtx := models.WithRuleKey(ctx, rule.GetKey())
+	return e.run(ruleCtx, user, rule, evalFactory, extraLabels, from, evaluations, func(_ int, now time.Time, _ []state.StateTransition, manager stateManager) error {
+		return callback(now, manager.GetStatesForRuleUID(rule.OrgID, rule.UID))
+	})
+}
+
+// run evaluates the rule with a new state manager and calls callback with the state transitions of each evaluation.
+func (e *Engine) run(ctx context.Context, user identity.Requester, rule *models.AlertRule, evalFactory eval.EvaluatorFactory, extraLabels data.Labels, from time.Time, evaluations int, callback func(idx int, now time.Time, states []state.StateTransition, manager stateManager) error) error {
+	stateManager := e.createStateManager()
+
+	evaluator, err := backtestingEvaluatorFactory(ctx, evalFactory, user, rule.GetEvalCondition(), &schedule.AlertingResultsFromRuleState{
+		Manager: stateManager,
+		Rule:    rule,
+	})
 	if err != nil {
-		return nil, err
+		return errors.Join(ErrInvalidInputData, err)
 	}
-	logger.Info("Rule testing finished successfully", "duration", time.Since(start))
-	return result, nil
+
+	return evaluator.Eval(ctx, from, time.Duration(rule.IntervalSeconds)*time.Second, evaluations, func(idx int, currentTime time.Time, results eval.Results) error {
+		if idx >= evaluations {
+			logger.FromContext(ctx).Info("Unexpected evaluation. Skipping", "from", from, "interval", rule.IntervalSeconds, "evaluationTime", currentTime, "evaluationIndex", idx, "expectedEvaluations", evaluations)
+			return nil
+		}
+		states := stateManager.ProcessEvalResults(ctx, currentTime, rule, results, extraLabels)
+		return callback(idx, currentTime, states, stateManager)
+	})
 }
 
 func newBacktestingEvaluator(ctx context.Context, evalFactory eval.EvaluatorFactory, user identity.Requester, condition models.Condition, reader eval.AlertingResultsReader) (backtestingEvaluator, error) {
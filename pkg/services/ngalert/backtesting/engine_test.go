@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
@@ -17,6 +18,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval/eval_mocks"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -378,6 +380,93 @@ func TestEvaluatorTest(t *testing.T) {
 	})
 }
 
+func TestEngineTestGroup(t *testing.T) {
+	evaluator := &fakeBacktestingEvaluator{
+		evalCallback: func(now time.Time) (eval.Results, error) {
+			return eval.Results{}, nil
+		},
+	}
+	backtestingEvaluatorFactory = func(ctx context.Context, evalFactory eval.EvaluatorFactory, user identity.Requester, condition models.Condition, r eval.AlertingResultsReader) (backtestingEvaluator, error) {
+		return evaluator, nil
+	}
+	t.Cleanup(func() {
+		backtestingEvaluatorFactory = newBacktestingEvaluator
+	})
+
+	from := time.Unix(0, 0).UTC()
+	interval := time.Minute
+	// The alert is firing from the second evaluation, and is resolved at the fifth.
+	manager := &fakeStateManager{
+		stateCallback: func(now time.Time) []state.StateTransition {
+			s := &state.State{
+				CacheID:  "state-1",
+				Labels:   data.Labels{"alertname": "HighCPU", "instance": "a"},
+				State:    eval.Normal,
+				StartsAt: from.Add(interval),
+			}
+			switch idx := int(now.Sub(from) / interval); {
+			case idx >= 1 && idx < 4:
+				s.State = eval.Alerting
+			case idx == 4:
+				s.Resolved = true
+			}
+			return []state.StateTransition{{State: s}}
+		},
+	}
+	engine := &Engine{
+		createStateManager: func() stateManager {
+			return manager
+		},
+	}
+	rules := []*models.AlertRule{
+		models.AlertRuleGen(models.WithInterval(interval), models.WithTitle("rule-1"))(),
+		models.AlertRuleGen(models.WithInterval(interval), models.WithTitle("rule-2"))(),
+	}
+	newSimulator := func(t *testing.T) *notifier.NotificationSimulator {
+		cfg, err := notifier.Load([]byte(`{"alertmanager_config": {"route": {"receiver": "default", "group_by": ["alertname"], "group_wait": "30s", "group_interval": "2m"}, "receivers": [{"name": "default"}]}}`))
+		require.NoError(t, err)
+		simulator, err := notifier.NewNotificationSimulator(cfg.AlertmanagerConfig.Config)
+		require.NoError(t, err)
+		return simulator
+	}
+
+	t.Run("should return the states of each rule and the notifications of their alerts", func(t *testing.T) {
+		result, err := engine.TestGroup(context.Background(), nil, rules, "folder", true, from, from.Add(10*interval), newSimulator(t))
+
+		require.NoError(t, err)
+		require.Len(t, result.Rules, 2)
+		require.Equal(t, "rule-1", result.Rules[0].Name)
+		require.Equal(t, "rule-2", result.Rules[1].Name)
+		require.Equal(t, 10, result.Rules[0].Rows())
+
+		labels := model.LabelSet{"alertname": "HighCPU", "instance": "a"}
+		require.Equal(t, []notifier.SimulatedNotification{
+			{
+				Time:        from.Add(interval + 30*time.Second),
+				Receiver:    "default",
+				Route:       "{}",
+				GroupLabels: model.LabelSet{"alertname": "HighCPU"},
+				Firing:      []model.LabelSet{labels},
+			},
+			{
+				Time:        from.Add(5*interval + 30*time.Second),
+				Receiver:    "default",
+				Route:       "{}",
+				GroupLabels: model.LabelSet{"alertname": "HighCPU"},
+				Resolved:    []model.LabelSet{labels},
+			},
+		}, result.Notifications)
+	})
+
+	t.Run("should fail if the rules have different intervals", func(t *testing.T) {
+		other := models.AlertRuleGen(models.WithInterval(2 * interval))()
+
+		_, err := engine.TestGroup(context.Background(), nil, append(rules, other), "folder", true, from, from.Add(10*interval), newSimulator(t))
+
+		require.ErrorIs(t, err, ErrInvalidInputData)
+	})
+}
+
 type fakeStateManager struct {
 	stateCallback func(now time.Time) []state.StateTransition
 }
@@ -92,6 +92,8 @@ type evaluatorImpl struct {
 	dataSourceCache   datasources.CacheService
 	expressionService *expr.Service
 	pluginsStore      pluginstore.Store
+	// input returns the results of the data queries if they are not queried from the data sources.
+	input InputData
 }
 
 func NewEvaluatorFactory(
@@ -772,7 +774,7 @@ func (e *evaluatorImpl) Validate(ctx EvaluationContext, condition models.Conditi
 		return err
 	}
 	for _, query := range req.Queries {
-		if query.DataSource == nil {
+		if query.DataSource == nil || e.input != nil {
 			continue
 		}
 		switch expr.NodeTypeFromDatasourceUID(query.DataSource.UID) {
@@ -815,12 +817,16 @@ func (e *evaluatorImpl) create(condition models.Condition, req *expr.Request) (C
 	if err != nil {
 		return nil, err
 	}
+	var service expressionService = e.expressionService
+	if e.input != nil {
+		service = newInputExpressionService(e.expressionService, condition, e.input)
+	}
 	conditions := make([]string, 0, len(pipeline))
 	for _, node := range pipeline {
 		if node.RefID() == condition.Condition {
 			return &conditionEvaluator{
 				pipeline:          pipeline,
-				expressionService: e.expressionService,
+				expressionService: service,
 				condition:         condition,
 				evalTimeout:       e.evaluationTimeout,
 			}, nil
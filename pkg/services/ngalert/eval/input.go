@@ -0,0 +1,109 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// InputDatasourceType is the type of the data sources of the queries evaluated by the evaluators of NewInputEvaluatorFactory.
+const InputDatasourceType = "__input__"
+
+// InputData returns the result of a data query evaluated at the time now.
+type InputData func(query models.AlertQuery, now time.Time) (mathexp.Results, error)
+
+// NewInputEvaluatorFactory returns an EvaluatorFactory whose evaluators do not query the data sources: the results of
+// the data queries are returned by input, and only the expressions are executed.
+func NewInputEvaluatorFactory(cfg setting.UnifiedAlertingSettings, expressionService *expr.Service, input InputData) EvaluatorFactory {
+	return &evaluatorImpl{
+		evaluationTimeout: cfg.EvaluationTimeout,
+		dataSourceCache:   inputDatasourceCache{},
+		expressionService: expressionService,
+		input:             input,
+	}
+}
+
+// inputDatasourceCache returns a data source of type InputDatasourceType for every UID.
+type inputDatasourceCache struct{}
+
+func (inputDatasourceCache) GetDatasource(_ context.Context, _ int64, _ identity.Requester, _ bool) (*datasources.DataSource, error) {
+	return nil, errors.New("data sources are not available when the data is an input")
+}
+
+func (inputDatasourceCache) GetDatasourceByUID(_ context.Context, uid string, _ identity.Requester, _ bool) (*datasources.DataSource, error) {
+	return &datasources.DataSource{UID: uid, Type: InputDatasourceType}, nil
+}
+
+// inputExpressionService executes a pipeline like expr.Service, but takes the results of the data queries from input.
+type inputExpressionService struct {
+	service *expr.Service
+	queries map[string]models.AlertQuery
+	input   InputData
+}
+
+func newInputExpressionService(service *expr.Service, condition models.Condition, input InputData) *inputExpressionService {
+	queries := make(map[string]models.AlertQuery, len(condition.Data))
+	for _, q := range condition.Data {
+		queries[q.RefID] = q
+	}
+	return &inputExpressionService{service: service, queries: queries, input: input}
+}
+
+func (s *inputExpressionService) ExecutePipeline(ctx context.Context, now time.Time, pipeline expr.DataPipeline) (*backend.QueryDataResponse, error) {
+	vars := make(mathexp.Vars, len(pipeline))
+	for _, node := range pipeline {
+		if node.NodeType() != expr.TypeCMDNode {
+			query, ok := s.queries[node.RefID()]
+			if !ok {
+				return nil, fmt.Errorf("query %s does not exist", node.RefID())
+			}
+			res, err := s.input(query, now)
+			if err != nil {
+				res.Error = err
+			}
+			vars[node.RefID()] = res
+			continue
+		}
+
+		var depErr error
+		for _, neededVar := range node.NeedsVars() {
+			if res, ok := vars[neededVar]; ok && res.Error != nil {
+				depErr = fmt.Errorf("failed to execute %s: dependency %s failed", node.RefID(), neededVar)
+				break
+			}
+		}
+		if depErr != nil {
+			vars[node.RefID()] = mathexp.Results{Error: depErr}
+			continue
+		}
+
+		execNode, ok := node.(expr.ExecutableNode)
+		if !ok {
+			return nil, fmt.Errorf("node %s of type %s cannot be executed", node.RefID(), node.NodeType())
+		}
+		res, err := execNode.Execute(ctx, now, vars, s.service)
+		if err != nil {
+			res.Error = err
+		}
+		vars[node.RefID()] = res
+	}
+
+	resp := backend.NewQueryDataResponse()
+	for refID, val := range vars {
+		resp.Responses[refID] = backend.DataResponse{
+			Frames: val.Values.AsDataFrames(refID),
+			Error:  val.Error,
+		}
+	}
+	return resp, nil
+}
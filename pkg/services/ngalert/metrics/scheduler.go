@@ -29,6 +29,8 @@ type Scheduler struct {
 	UpdateSchedulableAlertRulesDuration prometheus.Histogram
 	Ticker                              *ticker.Metrics
 	EvaluationMissed                    *prometheus.CounterVec
+	ShardMembers                        prometheus.Gauge
+	ShardOwnedAlertRules                prometheus.Gauge
 }
 
 func NewSchedulerMetrics(r prometheus.Registerer) *Scheduler {
@@ -163,5 +165,21 @@ func NewSchedulerMetrics(r prometheus.Registerer) *Scheduler {
 			},
 			[]string{"org", "name"},
 		),
+		ShardMembers: promauto.With(r).NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "schedule_shard_members",
+				Help:      "The number of Grafana instances that the evaluation of alert rules is sharded across.",
+			},
+		),
+		ShardOwnedAlertRules: promauto.With(r).NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "schedule_shard_owned_alert_rules",
+				Help:      "The number of alert rules that are evaluated by this Grafana instance when the evaluation is sharded.",
+			},
+		),
 	}
 }
@@ -13,6 +13,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	alertingModels "github.com/grafana/alerting/models"
+	"github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/setting"
@@ -145,6 +146,9 @@ const (
 	StateReasonUpdated       = "Updated"
 	StateReasonRuleDeleted   = "RuleDeleted"
 	StateReasonKeepLast      = "KeepLast"
+	StateReasonKeepFiring    = "KeepFiring"
+	StateReasonInhibited     = "Inhibited"
+	StateReasonAcknowledged  = "Acknowledged"
 )
 
 func ConcatReasons(reasons ...string) string {
@@ -171,6 +175,73 @@ var (
 	}
 )
 
+// RuleType is the kind of work a rule performs when it is evaluated.
+type RuleType string
+
+const (
+	// RuleTypeAlerting is a rule whose results are used to produce alert instances.
+	RuleTypeAlerting RuleType = "alerting"
+	// RuleTypeRecording is a rule whose results are written to a time series database as a new metric.
+	RuleTypeRecording RuleType = "recording"
+)
+
+func (r RuleType) String() string {
+	return string(r)
+}
+
+// Record contains the settings of a recording rule.
+type Record struct {
+	// Metric is the name of the metric the result of the rule is written to.
+	Metric string `json:"metric"`
+	// From is the RefID of the query or expression whose result is recorded.
+	From string `json:"from"`
+}
+
+// Validate checks that the record refers to a valid metric name and to one of the provided queries.
+func (r *Record) Validate(queries []AlertQuery) error {
+	if r.Metric == "" {
+		return errors.New("metric name cannot be empty")
+	}
+	if !model.IsValidMetricName(model.LabelValue(r.Metric)) {
+		return fmt.Errorf("metric name %q is not a valid Prometheus metric name", r.Metric)
+	}
+	if r.From == "" {
+		return errors.New("refID of the recorded query or expression cannot be empty")
+	}
+	for _, q := range queries {
+		if q.RefID == r.From {
+			return nil
+		}
+	}
+	return fmt.Errorf("recorded query or expression %s does not exist", r.From)
+}
+
+// RuleInhibition makes the alert instances of a rule inhibited while an alert instance of another rule is firing.
+// Inhibited instances are not sent to the Alertmanager.
+type RuleInhibition struct {
+	// RuleUID is the UID of the rule whose firing alert instances inhibit the rule.
+	RuleUID string `json:"rule_uid"`
+	// Equal is the list of labels that must have the same value in the firing instance and in the inhibited instance.
+	// If it is empty, any firing instance of the rule inhibits all instances.
+	Equal []string `json:"equal,omitempty"`
+}
+
+// Validate checks that the inhibition refers to a rule other than the one with the given UID, and that the labels are valid.
+func (i RuleInhibition) Validate(ruleUID string) error {
+	if i.RuleUID == "" {
+		return errors.New("UID of the inhibiting rule cannot be empty")
+	}
+	if i.RuleUID == ruleUID {
+		return errors.New("rule cannot inhibit itself")
+	}
+	for _, l := range i.Equal {
+		if !model.LabelName(l).IsValid() {
+			return fmt.Errorf("label name %q is not valid", l)
+		}
+	}
+	return nil
+}
+
 // AlertRuleGroup is the base model for a rule group in unified alerting.
 type AlertRuleGroup struct {
 	Title      string
@@ -244,11 +315,17 @@ type AlertRule struct {
 	ExecErrState    ExecutionErrorState
 	// ideally this field should have been apimodels.ApiDuration
 	// but this is currently not possible because of circular dependencies
-	For                  time.Duration
+	For time.Duration
+	// KeepFiringFor is how long an alert instance stays Alerting after its condition stops being met.
+	KeepFiringFor        time.Duration
 	Annotations          map[string]string
 	Labels               map[string]string
 	IsPaused             bool
 	NotificationSettings []NotificationSettings `xorm:"notification_settings"` // we use slice to workaround xorm mapping that does not serialize a struct to JSON unless it's a slice
+	// Record is set only for recording rules. See Type.
+	Record *Record `xorm:"'record' JSON"`
+	// InhibitedBy is the list of rules that inhibit the alert instances of this rule while they are firing.
+	InhibitedBy []RuleInhibition `xorm:"inhibited_by"`
 }
 
 // AlertRuleWithOptionals This is to avoid having to pass in additional arguments deep in the call stack. Alert rule
@@ -332,7 +409,22 @@ func (alertRule *AlertRule) GetLabels(opts ...LabelOption) map[string]string {
 	return labels
 }
 
+// Type returns the type of the rule. Rules that have the Record settings are recording rules.
+func (alertRule *AlertRule) Type() RuleType {
+	if alertRule.Record != nil {
+		return RuleTypeRecording
+	}
+	return RuleTypeAlerting
+}
+
+// GetEvalCondition returns the condition to evaluate. For recording rules, it is the recorded query or expression.
 func (alertRule *AlertRule) GetEvalCondition() Condition {
+	if alertRule.Type() == RuleTypeRecording {
+		return Condition{
+			Condition: alertRule.Record.From,
+			Data:      alertRule.Data,
+		}
+	}
 	return Condition{
 		Condition: alertRule.Condition,
 		Data:      alertRule.Data,
@@ -502,6 +594,10 @@ func (alertRule *AlertRule) ValidateAlertRule(cfg setting.UnifiedAlertingSetting
 		return fmt.Errorf("%w: field `for` cannot be negative", ErrAlertRuleFailedValidation)
 	}
 
+	if alertRule.KeepFiringFor < 0 {
+		return fmt.Errorf("%w: field `keep_firing_for` cannot be negative", ErrAlertRuleFailedValidation)
+	}
+
 	if len(alertRule.Labels) > 0 {
 		for label := range alertRule.Labels {
 			if _, ok := LabelsUserCannotSpecify[label]; ok {
@@ -510,6 +606,29 @@ func (alertRule *AlertRule) ValidateAlertRule(cfg setting.UnifiedAlertingSetting
 		}
 	}
 
+	if alertRule.Type() == RuleTypeRecording {
+		if err := alertRule.Record.Validate(alertRule.Data); err != nil {
+			return fmt.Errorf("%w: invalid record: %s", ErrAlertRuleFailedValidation, err.Error())
+		}
+		if len(alertRule.NotificationSettings) > 0 {
+			return fmt.Errorf("%w: recording rules cannot have notification settings", ErrAlertRuleFailedValidation)
+		}
+		if len(alertRule.InhibitedBy) > 0 {
+			return fmt.Errorf("%w: recording rules cannot be inhibited", ErrAlertRuleFailedValidation)
+		}
+	}
+
+	inhibitingRules := make(map[string]struct{}, len(alertRule.InhibitedBy))
+	for _, inhibition := range alertRule.InhibitedBy {
+		if err := inhibition.Validate(alertRule.UID); err != nil {
+			return fmt.Errorf("%w: invalid inhibition: %s", ErrAlertRuleFailedValidation, err.Error())
+		}
+		if _, ok := inhibitingRules[inhibition.RuleUID]; ok {
+			return fmt.Errorf("%w: rule %s is specified more than once in inhibitions", ErrAlertRuleFailedValidation, inhibition.RuleUID)
+		}
+		inhibitingRules[inhibition.RuleUID] = struct{}{}
+	}
+
 	if len(alertRule.NotificationSettings) > 0 {
 		if len(alertRule.NotificationSettings) != 1 {
 			return fmt.Errorf("%w: only one notification settings entry is allowed", ErrAlertRuleFailedValidation)
@@ -562,10 +681,45 @@ type AlertRuleVersion struct {
 	// ideally this field should have been apimodels.ApiDuration
 	// but this is currently not possible because of circular dependencies
 	For                  time.Duration
+	KeepFiringFor        time.Duration
 	Annotations          map[string]string
 	Labels               map[string]string
 	IsPaused             bool
 	NotificationSettings []NotificationSettings `xorm:"notification_settings"` // we use slice to workaround xorm mapping that does not serialize a struct to JSON unless it's a slice
+	Record               *Record                `xorm:"'record' JSON"`
+	InhibitedBy          []RuleInhibition       `xorm:"inhibited_by"`
+}
+
+// AlertRule returns the alert rule as it was defined at this version.
+// Fields that are not stored in the version, such as ID, DashboardUID and PanelID, are not set.
+func (v AlertRuleVersion) AlertRule() AlertRule {
+	rule := AlertRule{
+		OrgID:                v.RuleOrgID,
+		UID:                  v.RuleUID,
+		NamespaceUID:         v.RuleNamespaceUID,
+		RuleGroup:            v.RuleGroup,
+		RuleGroupIndex:       v.RuleGroupIndex,
+		Version:              v.Version,
+		Updated:              v.Created,
+		Title:                v.Title,
+		Condition:            v.Condition,
+		Data:                 v.Data,
+		IntervalSeconds:      v.IntervalSeconds,
+		NoDataState:          v.NoDataState,
+		ExecErrState:         v.ExecErrState,
+		For:                  v.For,
+		KeepFiringFor:        v.KeepFiringFor,
+		Annotations:          v.Annotations,
+		Labels:               v.Labels,
+		IsPaused:             v.IsPaused,
+		NotificationSettings: v.NotificationSettings,
+		Record:               v.Record,
+		InhibitedBy:          v.InhibitedBy,
+	}
+	// The dashboard and panel are not stored in the version. They are set from the annotations, which were validated
+	// when the version was saved, like when the rule is saved.
+	_ = rule.SetDashboardAndPanelFromAnnotations()
+	return rule
 }
 
 // GetAlertRuleByUIDQuery is the query for retrieving/deleting an alert rule by UID and organisation ID.
@@ -693,6 +847,9 @@ func PatchPartialAlertRule(existingRule *AlertRule, ruleToPatch *AlertRuleWithOp
 	if ruleToPatch.For == -1 {
 		ruleToPatch.For = existingRule.For
 	}
+	if ruleToPatch.KeepFiringFor == -1 {
+		ruleToPatch.KeepFiringFor = existingRule.KeepFiringFor
+	}
 	if !ruleToPatch.HasPause {
 		ruleToPatch.IsPaused = existingRule.IsPaused
 	}
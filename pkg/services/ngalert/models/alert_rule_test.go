@@ -248,6 +248,12 @@ func TestPatchPartialAlertRule(t *testing.T) {
 					r.For = -1
 				},
 			},
+			{
+				name: "KeepFiringFor is -1",
+				mutator: func(r *AlertRuleWithOptionals) {
+					r.KeepFiringFor = -1
+				},
+			},
 			{
 				name: "IsPaused did not come in request",
 				mutator: func(r *AlertRuleWithOptionals) {
@@ -262,6 +268,7 @@ func TestPatchPartialAlertRule(t *testing.T) {
 				for {
 					rule := AlertRuleGen(func(rule *AlertRule) {
 						rule.For = time.Duration(rand.Int63n(1000) + 1)
+						rule.KeepFiringFor = time.Duration(rand.Int63n(1000) + 1)
 					})()
 					existing = &AlertRuleWithOptionals{AlertRule: *rule}
 					cloned := *existing
@@ -844,3 +851,103 @@ func TestTimeRangeYAML(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, yamlRaw, string(serialized))
 }
+
+func TestRecordValidate(t *testing.T) {
+	queries := []AlertQuery{{RefID: "A"}, {RefID: "B"}}
+
+	testCases := []struct {
+		name   string
+		record Record
+		expErr string
+	}{
+		{
+			name:   "valid record",
+			record: Record{Metric: "test_metric:rate5m", From: "B"},
+		},
+		{
+			name:   "empty metric",
+			record: Record{From: "A"},
+			expErr: "metric name cannot be empty",
+		},
+		{
+			name:   "invalid metric",
+			record: Record{Metric: "1metric", From: "A"},
+			expErr: "not a valid Prometheus metric name",
+		},
+		{
+			name:   "empty from",
+			record: Record{Metric: "test_metric"},
+			expErr: "cannot be empty",
+		},
+		{
+			name:   "unknown from",
+			record: Record{Metric: "test_metric", From: "C"},
+			expErr: "C does not exist",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.record.Validate(queries)
+			if tc.expErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tc.expErr)
+		})
+	}
+}
+
+func TestAlertRuleType(t *testing.T) {
+	rule := AlertRuleGen()()
+	require.Equal(t, RuleTypeAlerting, rule.Type())
+	require.Equal(t, rule.Condition, rule.GetEvalCondition().Condition)
+
+	rule = CopyRule(rule)
+	WithRecord("test_metric", rule.Data[0].RefID)(rule)
+	require.Equal(t, RuleTypeRecording, rule.Type())
+	require.Equal(t, rule.Data[0].RefID, rule.GetEvalCondition().Condition)
+}
+
+func TestRuleInhibitionValidate(t *testing.T) {
+	testCases := []struct {
+		name       string
+		inhibition RuleInhibition
+		expErr     string
+	}{
+		{
+			name:       "valid inhibition",
+			inhibition: RuleInhibition{RuleUID: "other", Equal: []string{"datacenter", "cluster"}},
+		},
+		{
+			name:       "valid inhibition without labels",
+			inhibition: RuleInhibition{RuleUID: "other"},
+		},
+		{
+			name:       "empty rule UID",
+			inhibition: RuleInhibition{Equal: []string{"datacenter"}},
+			expErr:     "cannot be empty",
+		},
+		{
+			name:       "inhibited by itself",
+			inhibition: RuleInhibition{RuleUID: "rule"},
+			expErr:     "rule cannot inhibit itself",
+		},
+		{
+			name:       "invalid label name",
+			inhibition: RuleInhibition{RuleUID: "other", Equal: []string{"data center"}},
+			expErr:     "label name \"data center\" is not valid",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.inhibition.Validate("rule")
+			if tc.expErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tc.expErr)
+		})
+	}
+}
@@ -16,5 +16,6 @@ type HistoryQuery struct {
 	From         time.Time
 	To           time.Time
 	Limit        int
+	Offset       int
 	SignedInUser identity.Requester
 }
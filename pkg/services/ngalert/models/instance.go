@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"fmt"
 	"time"
 )
@@ -15,6 +16,7 @@ type AlertInstance struct {
 	CurrentStateEnd   time.Time
 	LastEvalTime      time.Time
 	ResultFingerprint string
+	Acknowledgement   *AlertInstanceAcknowledgement
 }
 
 type AlertInstanceKey struct {
@@ -23,6 +25,39 @@ type AlertInstanceKey struct {
 	LabelsHash string
 }
 
+// AlertInstanceAcknowledgement is the acknowledgement of a firing alert instance by a user.
+// It is kept until the state of the instance changes or it expires.
+type AlertInstanceAcknowledgement struct {
+	// By is the login of the user who acknowledged the alert instance.
+	By      string    `json:"by"`
+	Comment string    `json:"comment,omitempty"`
+	At      time.Time `json:"at"`
+	// ExpiresAt is the time when the acknowledgement expires. It does not expire if ExpiresAt is zero.
+	ExpiresAt time.Time `json:"expiresAt,omitempty"`
+	// SuppressNotifications stops the repeated notifications of the alert instance while it is acknowledged.
+	SuppressNotifications bool `json:"suppressNotifications,omitempty"`
+}
+
+// Expired returns true if the acknowledgement is expired at the given time.
+func (a *AlertInstanceAcknowledgement) Expired(now time.Time) bool {
+	return !a.ExpiresAt.IsZero() && !now.Before(a.ExpiresAt)
+}
+
+// FromDB loads the acknowledgement stored in the database as json.
+// FromDB is part of the xorm Conversion interface.
+func (a *AlertInstanceAcknowledgement) FromDB(b []byte) error {
+	if len(b) == 0 {
+		return nil
+	}
+	return json.Unmarshal(b, a)
+}
+
+// ToDB serializes the acknowledgement to json.
+// ToDB is part of the xorm Conversion interface.
+func (a *AlertInstanceAcknowledgement) ToDB() ([]byte, error) {
+	return json.Marshal(a)
+}
+
 // InstanceStateType is an enum for instance states.
 type InstanceStateType string
 
@@ -0,0 +1,130 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/robfig/cron/v3"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+var (
+	ErrProvisionedSilenceNotFound = errors.New("provisioned silence not found")
+	ErrProvisionedSilenceExists   = errors.New("provisioned silence with this UID already exists")
+)
+
+// DefaultProvisionedSilenceCreator is the author of the silences of a provisioned silence that does not have one.
+const DefaultProvisionedSilenceCreator = "provisioning"
+
+// SilenceSchedule repeats a silence. Each silence starts at the times of the cron expression and lasts for the duration.
+type SilenceSchedule struct {
+	// Cron is a standard cron expression, such as "0 2 * * SUN" for every Sunday at 02:00.
+	Cron string
+	// Duration is how long each silence lasts.
+	Duration time.Duration
+	// Location is the time zone of the cron expression. Defaults to UTC.
+	Location string
+}
+
+// ProvisionedSilence is a silence declared in provisioning files or created with the provisioning API.
+// The multi-org Alertmanager creates the silences of the provisioned silence and expires them when they change.
+// A provisioned silence is either a one-off silence with a start and an end, or a silence repeated on a schedule.
+type ProvisionedSilence struct {
+	ID        int64
+	OrgID     int64
+	UID       string
+	Matchers  labels.Matchers
+	Comment   string
+	CreatedBy string
+	StartsAt  time.Time
+	EndsAt    time.Time
+	Schedule  *SilenceSchedule
+	// SilenceID is the ID of the last silence created in the Alertmanager for the provisioned silence.
+	SilenceID string
+	Updated   time.Time
+}
+
+func (s *ProvisionedSilence) ResourceType() string {
+	return "silence"
+}
+
+func (s *ProvisionedSilence) ResourceID() string {
+	return s.UID
+}
+
+// Validate returns an error if the provisioned silence is not valid.
+func (s *ProvisionedSilence) Validate() error {
+	if err := util.ValidateUID(s.UID); err != nil {
+		return fmt.Errorf("invalid silence UID: %w", err)
+	}
+	if len(s.Matchers) == 0 {
+		return errors.New("silence must have at least one matcher")
+	}
+	if s.Comment == "" {
+		return errors.New("silence comment must not be empty")
+	}
+	if s.Schedule == nil {
+		if s.StartsAt.IsZero() || s.EndsAt.IsZero() {
+			return errors.New("silence without a schedule must have a start and an end")
+		}
+		if !s.EndsAt.After(s.StartsAt) {
+			return errors.New("silence must end after it starts")
+		}
+		return nil
+	}
+	if !s.StartsAt.IsZero() || !s.EndsAt.IsZero() {
+		return errors.New("silence with a schedule must not have a start and an end")
+	}
+	if s.Schedule.Duration <= 0 {
+		return errors.New("silence schedule must have a positive duration")
+	}
+	if _, err := s.Schedule.parse(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Window returns the start and the end of the silence that is active at the given time or, if there is none, of
+// the next silence. It returns false if the provisioned silence has no silence that ends after the given time.
+func (s *ProvisionedSilence) Window(now time.Time) (time.Time, time.Time, bool) {
+	if s.Schedule == nil {
+		if !s.EndsAt.After(now) {
+			return time.Time{}, time.Time{}, false
+		}
+		return s.StartsAt, s.EndsAt, true
+	}
+	schedule, err := s.Schedule.parse()
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	// The first start after now-duration is the start of the silence that is active now, if there is one,
+	// or the start of the next silence.
+	start := schedule.Next(now.Add(-s.Schedule.Duration))
+	if start.IsZero() {
+		return time.Time{}, time.Time{}, false
+	}
+	return start, start.Add(s.Schedule.Duration), true
+}
+
+func (s *SilenceSchedule) parse() (cron.Schedule, error) {
+	location := s.Location
+	if location == "" {
+		location = "UTC"
+	}
+	if _, err := time.LoadLocation(location); err != nil {
+		return nil, fmt.Errorf("invalid silence schedule location %q: %w", s.Location, err)
+	}
+	spec := strings.TrimSpace(s.Cron)
+	if strings.HasPrefix(spec, "TZ=") || strings.HasPrefix(spec, "CRON_TZ=") {
+		return nil, errors.New("silence schedule must set the time zone with the location instead of the cron expression")
+	}
+	schedule, err := cron.ParseStandard("CRON_TZ=" + location + " " + spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid silence schedule cron expression %q: %w", s.Cron, err)
+	}
+	return schedule, nil
+}
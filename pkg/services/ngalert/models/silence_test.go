@@ -0,0 +1,150 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvisionedSilenceValidate(t *testing.T) {
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	matcher, err := labels.NewMatcher(labels.MatchEqual, "alertname", "DiskFull")
+	require.NoError(t, err)
+	valid := func() ProvisionedSilence {
+		return ProvisionedSilence{
+			UID:      "maintenance",
+			Matchers: labels.Matchers{matcher},
+			Comment:  "Planned maintenance",
+			StartsAt: start,
+			EndsAt:   start.Add(time.Hour),
+		}
+	}
+	scheduled := func() ProvisionedSilence {
+		s := valid()
+		s.StartsAt, s.EndsAt = time.Time{}, time.Time{}
+		s.Schedule = &SilenceSchedule{Cron: "0 2 * * SUN", Duration: 2 * time.Hour, Location: "Europe/Berlin"}
+		return s
+	}
+
+	testCases := []struct {
+		name     string
+		silence  func() ProvisionedSilence
+		expected string
+	}{
+		{name: "one-off silence", silence: valid},
+		{name: "scheduled silence", silence: scheduled},
+		{
+			name:     "invalid UID",
+			silence:  func() ProvisionedSilence { s := valid(); s.UID = "a/b"; return s },
+			expected: "invalid silence UID",
+		},
+		{
+			name:     "no matchers",
+			silence:  func() ProvisionedSilence { s := valid(); s.Matchers = nil; return s },
+			expected: "silence must have at least one matcher",
+		},
+		{
+			name:     "no comment",
+			silence:  func() ProvisionedSilence { s := valid(); s.Comment = ""; return s },
+			expected: "silence comment must not be empty",
+		},
+		{
+			name:     "one-off silence without an end",
+			silence:  func() ProvisionedSilence { s := valid(); s.EndsAt = time.Time{}; return s },
+			expected: "silence without a schedule must have a start and an end",
+		},
+		{
+			name:     "one-off silence that ends before it starts",
+			silence:  func() ProvisionedSilence { s := valid(); s.EndsAt = s.StartsAt.Add(-time.Minute); return s },
+			expected: "silence must end after it starts",
+		},
+		{
+			name:     "scheduled silence with a start",
+			silence:  func() ProvisionedSilence { s := scheduled(); s.StartsAt = start; return s },
+			expected: "silence with a schedule must not have a start and an end",
+		},
+		{
+			name:     "scheduled silence without a duration",
+			silence:  func() ProvisionedSilence { s := scheduled(); s.Schedule.Duration = 0; return s },
+			expected: "silence schedule must have a positive duration",
+		},
+		{
+			name:     "scheduled silence with an invalid cron expression",
+			silence:  func() ProvisionedSilence { s := scheduled(); s.Schedule.Cron = "0 2 * *"; return s },
+			expected: "invalid silence schedule cron expression",
+		},
+		{
+			name:     "scheduled silence with a time zone in the cron expression",
+			silence:  func() ProvisionedSilence { s := scheduled(); s.Schedule.Cron = "CRON_TZ=UTC 0 2 * * SUN"; return s },
+			expected: "silence schedule must set the time zone with the location",
+		},
+		{
+			name:     "scheduled silence with an invalid location",
+			silence:  func() ProvisionedSilence { s := scheduled(); s.Schedule.Location = "Mars/Olympus"; return s },
+			expected: "invalid silence schedule location",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := tc.silence()
+			err := s.Validate()
+			if tc.expected == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tc.expected)
+		})
+	}
+}
+
+func TestProvisionedSilenceWindow(t *testing.T) {
+	t.Run("one-off silence", func(t *testing.T) {
+		start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+		s := ProvisionedSilence{StartsAt: start, EndsAt: start.Add(time.Hour)}
+
+		from, to, ok := s.Window(start.Add(-time.Hour))
+		require.True(t, ok)
+		require.Equal(t, start, from)
+		require.Equal(t, start.Add(time.Hour), to)
+
+		_, _, ok = s.Window(start.Add(30 * time.Minute))
+		require.True(t, ok)
+
+		_, _, ok = s.Window(start.Add(time.Hour))
+		require.False(t, ok)
+	})
+
+	t.Run("scheduled silence", func(t *testing.T) {
+		berlin, err := time.LoadLocation("Europe/Berlin")
+		require.NoError(t, err)
+		s := ProvisionedSilence{Schedule: &SilenceSchedule{Cron: "0 2 * * SUN", Duration: 2 * time.Hour, Location: "Europe/Berlin"}}
+		// Sunday 3 March 2024 at 02:00 in Berlin.
+		sunday := time.Date(2024, 3, 3, 2, 0, 0, 0, berlin)
+
+		// before the silence, the next silence is returned.
+		from, to, ok := s.Window(sunday.Add(-24 * time.Hour))
+		require.True(t, ok)
+		require.True(t, sunday.Equal(from))
+		require.True(t, sunday.Add(2*time.Hour).Equal(to))
+
+		// during the silence, the active silence is returned.
+		from, _, ok = s.Window(sunday.Add(time.Hour))
+		require.True(t, ok)
+		require.True(t, sunday.Equal(from))
+
+		// after the silence, the silence of the next week is returned.
+		from, _, ok = s.Window(sunday.Add(2 * time.Hour))
+		require.True(t, ok)
+		require.True(t, sunday.AddDate(0, 0, 7).Equal(from))
+	})
+
+	t.Run("scheduled silence with the default location", func(t *testing.T) {
+		s := ProvisionedSilence{Schedule: &SilenceSchedule{Cron: "30 * * * *", Duration: 10 * time.Minute}}
+		from, to, ok := s.Window(time.Date(2024, 3, 1, 10, 35, 0, 0, time.UTC))
+		require.True(t, ok)
+		require.True(t, time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC).Equal(from))
+		require.True(t, time.Date(2024, 3, 1, 10, 40, 0, 0, time.UTC).Equal(to))
+	})
+}
@@ -211,6 +211,12 @@ func WithFor(duration time.Duration) AlertRuleMutator {
 	}
 }
 
+func WithKeepFiringFor(duration time.Duration) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.KeepFiringFor = duration
+	}
+}
+
 func WithForNTimes(timesOfInterval int64) AlertRuleMutator {
 	return func(rule *AlertRule) {
 		rule.For = time.Duration(rule.IntervalSeconds*timesOfInterval) * time.Second
@@ -316,6 +322,27 @@ func WithNoNotificationSettings() AlertRuleMutator {
 	}
 }
 
+// WithRecord turns the rule into a recording rule that writes the result of the query or expression 'from' to the metric 'metric'.
+func WithRecord(metric, from string) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.Record = &Record{
+			Metric: metric,
+			From:   from,
+		}
+		rule.NotificationSettings = nil
+	}
+}
+
+// WithInhibitedBy makes the rule inhibited by the rule with the given UID while it fires with the same values of the equal labels.
+func WithInhibitedBy(ruleUID string, equal ...string) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.InhibitedBy = append(rule.InhibitedBy, RuleInhibition{
+			RuleUID: ruleUID,
+			Equal:   equal,
+		})
+	}
+}
+
 func GenerateAlertLabels(count int, prefix string) data.Labels {
 	labels := make(data.Labels, count)
 	for i := 0; i < count; i++ {
@@ -408,6 +435,7 @@ func CopyRule(r *AlertRule) *AlertRule {
 		NoDataState:     r.NoDataState,
 		ExecErrState:    r.ExecErrState,
 		For:             r.For,
+		KeepFiringFor:   r.KeepFiringFor,
 	}
 
 	if r.DashboardUID != nil {
@@ -449,6 +477,18 @@ func CopyRule(r *AlertRule) *AlertRule {
 		result.NotificationSettings = append(result.NotificationSettings, CopyNotificationSettings(s))
 	}
 
+	if r.Record != nil {
+		record := *r.Record
+		result.Record = &record
+	}
+
+	for _, inhibition := range r.InhibitedBy {
+		result.InhibitedBy = append(result.InhibitedBy, RuleInhibition{
+			RuleUID: inhibition.RuleUID,
+			Equal:   slices.Clone(inhibition.Equal),
+		})
+	}
+
 	return &result
 }
 
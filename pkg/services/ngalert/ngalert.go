@@ -3,6 +3,7 @@ package ngalert
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"net/url"
 	"time"
 
@@ -39,6 +40,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/state/historian"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
 	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
 	"github.com/grafana/grafana/pkg/services/quota"
@@ -214,6 +216,8 @@ func (ng *AlertNG) init() error {
 		}
 	}
 
+	overrides = append(overrides, notifier.WithProvisionedSilences(ng.store))
+
 	decryptFn := ng.SecretsService.GetDecryptedValue
 	multiOrgMetrics := ng.Metrics.GetMultiOrgAlertmanagerMetrics()
 	moa, err := notifier.NewMultiOrgAlertmanager(ng.Cfg, ng.store, ng.store, ng.KVStore, ng.store, decryptFn, multiOrgMetrics, ng.NotificationService, moaLogger, ng.SecretsService, ng.FeatureToggles, overrides...)
@@ -252,6 +256,10 @@ func (ng *AlertNG) init() error {
 	ng.AlertsRouter = alertsRouter
 
 	evalFactory := eval.NewEvaluatorFactory(ng.Cfg.UnifiedAlerting, ng.DataSourceCache, ng.ExpressionService, ng.pluginsStore)
+	recordingWriter, err := configureRecordingWriter(ng.Cfg.UnifiedAlerting.RecordingRules)
+	if err != nil {
+		return fmt.Errorf("failed to initialize recording rules writer: %w", err)
+	}
 	schedCfg := schedule.SchedulerCfg{
 		MaxAttempts:          ng.Cfg.UnifiedAlerting.MaxAttempts,
 		C:                    clk,
@@ -264,6 +272,7 @@ func (ng *AlertNG) init() error {
 		RuleStore:            ng.store,
 		Metrics:              ng.Metrics.GetSchedulerMetrics(),
 		AlertSender:          alertsRouter,
+		RecordingWriter:      recordingWriter,
 		Tracer:               ng.tracer,
 		Log:                  log.New("ngalert.scheduler"),
 	}
@@ -271,7 +280,7 @@ func (ng *AlertNG) init() error {
 	// There are a set of feature toggles available that act as short-circuits for common configurations.
 	// If any are set, override the config accordingly.
 	ApplyStateHistoryFeatureToggles(&ng.Cfg.UnifiedAlerting.StateHistory, ng.FeatureToggles, ng.Log)
-	history, err := configureHistorianBackend(initCtx, ng.Cfg.UnifiedAlerting.StateHistory, ng.annotationsRepo, ng.dashboardService, ng.store, ng.Metrics.GetHistorianMetrics(), ng.Log)
+	history, err := configureHistorianBackend(initCtx, ng.Cfg.UnifiedAlerting.StateHistory, ng.annotationsRepo, ng.dashboardService, ng.store, ng.SQLStore, ng.Metrics.GetHistorianMetrics(), ng.Log)
 	if err != nil {
 		return err
 	}
@@ -289,11 +298,26 @@ func (ng *AlertNG) init() error {
 		Tracer:                         ng.tracer,
 		Log:                            log.New("ngalert.state.manager"),
 	}
+	haEnabled := ng.Cfg.UnifiedAlerting.HARedisAddr != "" || len(ng.Cfg.UnifiedAlerting.HAPeers) > 0
+	shardRuleEvaluation := haEnabled && ng.Cfg.UnifiedAlerting.HAShardRuleEvaluation
+	if shardRuleEvaluation {
+		schedCfg.ClusterMembership = ng.MultiOrgAlertmanager
+		cfg.ShardedEvaluation = true
+	} else if ng.Cfg.UnifiedAlerting.HAShardRuleEvaluation {
+		ng.Log.Warn("Sharding of alert rule evaluation is ignored because high availability mode is not configured")
+	}
+
 	logger := log.New("ngalert.state.manager.persist")
 	statePersister := state.NewSyncStatePersisiter(logger, cfg)
 	if ng.FeatureToggles.IsEnabledGlobally(featuremgmt.FlagAlertingSaveStatePeriodic) {
-		ticker := clock.New().Ticker(ng.Cfg.UnifiedAlerting.StatePeriodicSaveInterval)
-		statePersister = state.NewAsyncStatePersister(logger, ticker, cfg)
+		// The periodic save replaces all alert instances in the database with the ones of this instance,
+		// which would remove the alert instances of the rules evaluated by other instances.
+		if shardRuleEvaluation {
+			ng.Log.Warn("Periodic saving of alert state is disabled because the evaluation of alert rules is sharded")
+		} else {
+			ticker := clock.New().Ticker(ng.Cfg.UnifiedAlerting.StatePeriodicSaveInterval)
+			statePersister = state.NewAsyncStatePersister(logger, ticker, cfg)
+		}
 	}
 	stateManager := state.NewManager(cfg, statePersister)
 	scheduler := schedule.NewScheduler(schedCfg, stateManager)
@@ -313,6 +337,7 @@ func (ng *AlertNG) init() error {
 	contactPointService := provisioning.NewContactPointService(ng.store, ng.SecretsService, ng.store, ng.store, receiverService, ng.Log, ng.store)
 	templateService := provisioning.NewTemplateService(ng.store, ng.store, ng.store, ng.Log)
 	muteTimingService := provisioning.NewMuteTimingService(ng.store, ng.store, ng.store, ng.Log)
+	silenceService := provisioning.NewSilenceService(ng.store, ng.store, ng.store, ng.MultiOrgAlertmanager, ng.Log)
 	alertRuleService := provisioning.NewAlertRuleService(ng.store, ng.store, ng.folderService, ng.dashboardService, ng.QuotaService, ng.store,
 		int64(ng.Cfg.UnifiedAlerting.DefaultRuleEvaluationInterval.Seconds()),
 		int64(ng.Cfg.UnifiedAlerting.BaseInterval.Seconds()),
@@ -332,6 +357,7 @@ func (ng *AlertNG) init() error {
 		ProvenanceStore:      ng.store,
 		MultiOrgAlertmanager: ng.MultiOrgAlertmanager,
 		StateManager:         ng.stateManager,
+		RuleEvaluationStats:  scheduler,
 		AccessControl:        ng.accesscontrol,
 		Policies:             policyService,
 		ReceiverService:      receiverService,
@@ -339,8 +365,10 @@ func (ng *AlertNG) init() error {
 		Templates:            templateService,
 		MuteTimings:          muteTimingService,
 		AlertRules:           alertRuleService,
+		Silences:             silenceService,
 		AlertsRouter:         alertsRouter,
 		EvaluatorFactory:     evalFactory,
+		ExpressionService:    ng.ExpressionService,
 		FeatureManager:       ng.FeatureToggles,
 		AppUrl:               appUrl,
 		Historian:            history,
@@ -401,7 +429,7 @@ func (ng *AlertNG) Run(ctx context.Context) error {
 		// Also note that this runs synchronously to ensure state is loaded
 		// before rule evaluation begins, hence we use ctx and not subCtx.
 		//
-		ng.stateManager.Warm(ctx, ng.store)
+		ng.stateManager.Warm(ctx, ng.store, ng.schedule.OwnsAlertRule)
 
 		children.Go(func() error {
 			return ng.schedule.Run(subCtx)
@@ -433,7 +461,7 @@ type Historian interface {
 	state.Historian
 }
 
-func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingStateHistorySettings, ar annotations.Repository, ds dashboards.DashboardService, rs historian.RuleStore, met *metrics.Historian, l log.Logger) (Historian, error) {
+func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingStateHistorySettings, ar annotations.Repository, ds dashboards.DashboardService, rs historian.RuleStore, store db.DB, met *metrics.Historian, l log.Logger) (Historian, error) {
 	if !cfg.Enabled {
 		met.Info.WithLabelValues("noop").Set(0)
 		return historian.NewNopHistorian(), nil
@@ -448,7 +476,7 @@ func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingS
 	if backend == historian.BackendTypeMultiple {
 		primaryCfg := cfg
 		primaryCfg.Backend = cfg.MultiPrimary
-		primary, err := configureHistorianBackend(ctx, primaryCfg, ar, ds, rs, met, l)
+		primary, err := configureHistorianBackend(ctx, primaryCfg, ar, ds, rs, store, met, l)
 		if err != nil {
 			return nil, fmt.Errorf("multi-backend target \"%s\" was misconfigured: %w", cfg.MultiPrimary, err)
 		}
@@ -457,7 +485,7 @@ func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingS
 		for _, b := range cfg.MultiSecondaries {
 			secCfg := cfg
 			secCfg.Backend = b
-			sec, err := configureHistorianBackend(ctx, secCfg, ar, ds, rs, met, l)
+			sec, err := configureHistorianBackend(ctx, secCfg, ar, ds, rs, store, met, l)
 			if err != nil {
 				return nil, fmt.Errorf("multi-backend target \"%s\" was miconfigured: %w", b, err)
 			}
@@ -486,10 +514,26 @@ func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingS
 		}
 		return backend, nil
 	}
+	if backend == historian.BackendTypeSQL {
+		return historian.NewSQLBackend(store, cfg.SQLMaxAge, met), nil
+	}
 
 	return nil, fmt.Errorf("unrecognized state history backend: %s", backend)
 }
 
+// configureRecordingWriter creates the writer that persists the results of recording rules.
+// If recording rules are disabled, the results are discarded.
+func configureRecordingWriter(cfg setting.RecordingRuleSettings) (schedule.RecordingWriter, error) {
+	if !cfg.Enabled {
+		return writer.NoopWriter{}, nil
+	}
+	pcfg, err := writer.NewPrometheusConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return writer.NewPrometheusWriter(pcfg, &http.Client{}, log.New("ngalert.writer")), nil
+}
+
 // ApplyStateHistoryFeatureToggles edits state history configuration to comply with currently active feature toggles.
 func ApplyStateHistoryFeatureToggles(cfg *setting.UnifiedAlertingStateHistorySettings, ft featuremgmt.FeatureToggles, logger log.Logger) {
 	backend, _ := historian.ParseBackendType(cfg.Backend)
@@ -85,6 +85,8 @@ type MultiOrgAlertmanager struct {
 
 	metrics *metrics.MultiOrgAlertmanager
 	ns      notifications.Service
+
+	provisionedSilences ProvisionedSilenceStore
 }
 
 type OrgAlertmanagerFactory func(ctx context.Context, orgID int64) (Alertmanager, error)
@@ -221,6 +223,7 @@ func (moa *MultiOrgAlertmanager) Run(ctx context.Context) error {
 			if err := moa.LoadAndSyncAlertmanagersForOrgs(ctx); err != nil {
 				moa.logger.Error("Error while synchronizing Alertmanager orgs", "error", err)
 			}
+			moa.SyncProvisionedSilences(ctx)
 		}
 	}
 }
@@ -412,6 +415,23 @@ func (moa *MultiOrgAlertmanager) StopAndWait() {
 	}
 }
 
+// ClusterMembers returns the name of this instance and the names of all live instances in the Alertmanager cluster.
+// It returns no members if Grafana does not run in high availability mode.
+func (moa *MultiOrgAlertmanager) ClusterMembers() (string, []string) {
+	switch p := moa.peer.(type) {
+	case *alertingCluster.Peer:
+		peers := p.Peers()
+		members := make([]string, 0, len(peers))
+		for _, m := range peers {
+			members = append(members, m.Name())
+		}
+		return p.Name(), members
+	case *redisPeer:
+		return p.withPrefix(p.name), p.Members()
+	}
+	return "", nil
+}
+
 // AlertmanagerFor returns the Alertmanager instance for the organization provided.
 // When the organization does not have an active Alertmanager, it returns a ErrNoAlertmanagerForOrg.
 // When the Alertmanager of the organization is not ready, it returns a ErrAlertmanagerNotReady.
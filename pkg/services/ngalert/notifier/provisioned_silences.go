@@ -0,0 +1,242 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/pkg/labels"
+
+	alertingNotify "github.com/grafana/alerting/notify"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// ProvisionedSilenceStore is the store of the provisioned silences that the multi-org Alertmanager creates silences for.
+type ProvisionedSilenceStore interface {
+	ListProvisionedSilences(ctx context.Context, orgID int64) ([]models.ProvisionedSilence, error)
+	SetProvisionedSilenceID(ctx context.Context, orgID int64, uid string, silenceID string) error
+}
+
+// WithProvisionedSilences makes the multi-org Alertmanager create the silences of the provisioned silences in the store.
+func WithProvisionedSilences(store ProvisionedSilenceStore) Option {
+	return func(moa *MultiOrgAlertmanager) {
+		moa.provisionedSilences = store
+	}
+}
+
+var timeNow = time.Now
+
+// SyncProvisionedSilences creates the silences of all provisioned silences, and expires the silences of the
+// provisioned silences that changed.
+func (moa *MultiOrgAlertmanager) SyncProvisionedSilences(ctx context.Context) {
+	if moa.provisionedSilences == nil {
+		return
+	}
+	silences, err := moa.provisionedSilences.ListProvisionedSilences(ctx, 0)
+	if err != nil {
+		moa.logger.Error("Failed to list provisioned silences", "error", err)
+		return
+	}
+	for _, s := range silences {
+		if err := moa.SyncProvisionedSilence(ctx, s); err != nil {
+			moa.logger.Warn("Failed to synchronize provisioned silence", "org", s.OrgID, "uid", s.UID, "error", err)
+		}
+	}
+}
+
+// SyncProvisionedSilence makes sure that the Alertmanager of the organization has the silence of the provisioned
+// silence that is active now, or the next one if there is none. A silence that does not match the provisioned silence
+// anymore is expired. Scheduled silences are created one at a time, the next one is created when the current one expires.
+func (moa *MultiOrgAlertmanager) SyncProvisionedSilence(ctx context.Context, s models.ProvisionedSilence) error {
+	am, err := moa.AlertmanagerFor(s.OrgID)
+	if err != nil {
+		return err
+	}
+	start, end, ok := s.Window(timeNow())
+
+	if s.SilenceID != "" {
+		current, err := am.GetSilence(ctx, s.SilenceID)
+		if err != nil && !errors.Is(err, alertingNotify.ErrSilenceNotFound) {
+			return fmt.Errorf("failed to get silence %s: %w", s.SilenceID, err)
+		}
+		if err == nil && !isSilenceExpired(current) {
+			if ok && silenceMatches(current.Silence, s, end) {
+				return nil
+			}
+			if err := am.DeleteSilence(ctx, s.SilenceID); err != nil && !errors.Is(err, alertingNotify.ErrSilenceNotFound) {
+				return fmt.Errorf("failed to expire silence %s: %w", s.SilenceID, err)
+			}
+			moa.logger.Debug("Expired silence of a provisioned silence that changed", "org", s.OrgID, "uid", s.UID, "silence", s.SilenceID)
+		}
+	}
+	if !ok {
+		return nil
+	}
+
+	// Another replica can have created the silence already, for example when the silence ID was not stored yet.
+	id, err := findProvisionedSilence(ctx, am, s, end)
+	if err != nil {
+		return err
+	}
+	if id == "" {
+		createdBy := s.CreatedBy
+		if createdBy == "" {
+			createdBy = models.DefaultProvisionedSilenceCreator
+		}
+		comment := s.Comment
+		startsAt, endsAt := strfmt.DateTime(start), strfmt.DateTime(end)
+		id, err = am.CreateSilence(ctx, &apimodels.PostableSilence{
+			Silence: amv2.Silence{
+				Comment:   &comment,
+				CreatedBy: &createdBy,
+				StartsAt:  &startsAt,
+				EndsAt:    &endsAt,
+				Matchers:  ToSilenceMatchers(s.Matchers),
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create silence: %w", err)
+		}
+		moa.logger.Debug("Created silence of a provisioned silence", "org", s.OrgID, "uid", s.UID, "silence", id, "starts_at", start, "ends_at", end)
+	}
+	if id == s.SilenceID {
+		return nil
+	}
+	return moa.provisionedSilences.SetProvisionedSilenceID(ctx, s.OrgID, s.UID, id)
+}
+
+// ExpireProvisionedSilence expires the silence of the provisioned silence, if it has one.
+func (moa *MultiOrgAlertmanager) ExpireProvisionedSilence(ctx context.Context, s models.ProvisionedSilence) error {
+	if s.SilenceID == "" {
+		return nil
+	}
+	am, err := moa.AlertmanagerFor(s.OrgID)
+	if err != nil {
+		return err
+	}
+	current, err := am.GetSilence(ctx, s.SilenceID)
+	if err != nil {
+		if errors.Is(err, alertingNotify.ErrSilenceNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get silence %s: %w", s.SilenceID, err)
+	}
+	if isSilenceExpired(current) {
+		return nil
+	}
+	if err := am.DeleteSilence(ctx, s.SilenceID); err != nil && !errors.Is(err, alertingNotify.ErrSilenceNotFound) {
+		return fmt.Errorf("failed to expire silence %s: %w", s.SilenceID, err)
+	}
+	return nil
+}
+
+// findProvisionedSilence returns the ID of a silence that is not expired and matches the provisioned silence.
+func findProvisionedSilence(ctx context.Context, am Alertmanager, s models.ProvisionedSilence, end time.Time) (string, error) {
+	silences, err := am.ListSilences(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to list silences: %w", err)
+	}
+	for _, silence := range silences {
+		if silence == nil || silence.ID == nil || isSilenceExpired(*silence) {
+			continue
+		}
+		if silenceMatches(silence.Silence, s, end) {
+			return *silence.ID, nil
+		}
+	}
+	return "", nil
+}
+
+func isSilenceExpired(s apimodels.GettableSilence) bool {
+	return s.Status != nil && s.Status.State != nil && *s.Status.State == amv2.SilenceStatusStateExpired
+}
+
+// silenceMatches returns true if the silence has the matchers and the comment of the provisioned silence and ends at the given time.
+func silenceMatches(silence amv2.Silence, s models.ProvisionedSilence, end time.Time) bool {
+	if silence.Comment == nil || *silence.Comment != s.Comment {
+		return false
+	}
+	if silence.EndsAt == nil {
+		return false
+	}
+	// The Alertmanager can round the end of the silence.
+	if d := time.Time(*silence.EndsAt).Sub(end); d < -time.Second || d > time.Second {
+		return false
+	}
+	expected := make([]string, 0, len(s.Matchers))
+	for _, m := range s.Matchers {
+		expected = append(expected, m.String())
+	}
+	matchers, err := FromSilenceMatchers(silence.Matchers)
+	if err != nil {
+		return false
+	}
+	actual := make([]string, 0, len(matchers))
+	for _, m := range matchers {
+		actual = append(actual, m.String())
+	}
+	if len(expected) != len(actual) {
+		return false
+	}
+	sort.Strings(expected)
+	sort.Strings(actual)
+	for i := range expected {
+		if expected[i] != actual[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// ToSilenceMatchers converts label matchers to the matchers of a silence.
+func ToSilenceMatchers(matchers labels.Matchers) amv2.Matchers {
+	result := make(amv2.Matchers, 0, len(matchers))
+	for _, m := range matchers {
+		name, value := m.Name, m.Value
+		isEqual := m.Type == labels.MatchEqual || m.Type == labels.MatchRegexp
+		isRegex := m.Type == labels.MatchRegexp || m.Type == labels.MatchNotRegexp
+		result = append(result, &amv2.Matcher{
+			Name:    &name,
+			Value:   &value,
+			IsEqual: &isEqual,
+			IsRegex: &isRegex,
+		})
+	}
+	return result
+}
+
+// FromSilenceMatchers converts the matchers of a silence to label matchers.
+func FromSilenceMatchers(matchers amv2.Matchers) (labels.Matchers, error) {
+	result := make(labels.Matchers, 0, len(matchers))
+	for _, m := range matchers {
+		matcher, err := fromSilenceMatcher(m)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, matcher)
+	}
+	return result, nil
+}
+
+func fromSilenceMatcher(m *amv2.Matcher) (*labels.Matcher, error) {
+	if m == nil || m.Name == nil || m.Value == nil || m.IsRegex == nil {
+		return nil, errors.New("matcher must have a name, a value and isRegex")
+	}
+	isEqual := m.IsEqual == nil || *m.IsEqual
+	t := labels.MatchEqual
+	switch {
+	case *m.IsRegex && isEqual:
+		t = labels.MatchRegexp
+	case *m.IsRegex:
+		t = labels.MatchNotRegexp
+	case !isEqual:
+		t = labels.MatchNotEqual
+	}
+	return labels.NewMatcher(t, *m.Name, *m.Value)
+}
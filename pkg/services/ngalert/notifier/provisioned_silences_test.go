@@ -0,0 +1,199 @@
+package notifier
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	ngfakes "github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakeProvisionedSilenceStore struct {
+	mtx      sync.Mutex
+	silences []models.ProvisionedSilence
+}
+
+func (f *fakeProvisionedSilenceStore) ListProvisionedSilences(_ context.Context, orgID int64) ([]models.ProvisionedSilence, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	result := make([]models.ProvisionedSilence, 0, len(f.silences))
+	for _, s := range f.silences {
+		if orgID == 0 || s.OrgID == orgID {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeProvisionedSilenceStore) SetProvisionedSilenceID(_ context.Context, orgID int64, uid string, silenceID string) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for i := range f.silences {
+		if f.silences[i].OrgID == orgID && f.silences[i].UID == uid {
+			f.silences[i].SilenceID = silenceID
+		}
+	}
+	return nil
+}
+
+func (f *fakeProvisionedSilenceStore) get(t *testing.T) models.ProvisionedSilence {
+	t.Helper()
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	require.Len(t, f.silences, 1)
+	return f.silences[0]
+}
+
+func (f *fakeProvisionedSilenceStore) update(fn func(s *models.ProvisionedSilence)) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	fn(&f.silences[0])
+}
+
+func setupProvisionedSilencesMoa(t *testing.T, store ProvisionedSilenceStore) *MultiOrgAlertmanager {
+	t.Helper()
+	configStore := NewFakeConfigStore(t, map[int64]*models.AlertConfiguration{})
+	orgStore := &FakeOrgStore{orgs: []int64{1}}
+	cfg := &setting.Cfg{
+		DataPath:        t.TempDir(),
+		UnifiedAlerting: setting.UnifiedAlertingSettings{AlertmanagerConfigPollInterval: 3 * time.Minute, DefaultConfiguration: setting.GetAlertmanagerDefaultConfiguration()}, // do not poll in tests.
+	}
+	secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
+	m := metrics.NewNGAlert(prometheus.NewPedanticRegistry())
+	moa, err := NewMultiOrgAlertmanager(cfg, configStore, orgStore, ngfakes.NewFakeKVStore(t), ngfakes.NewFakeProvisioningStore(), secretsService.GetDecryptedValue, m.GetMultiOrgAlertmanagerMetrics(), nil, log.New("testlogger"), secretsService, &featuremgmt.FeatureManager{}, WithProvisionedSilences(store))
+	require.NoError(t, err)
+	require.NoError(t, moa.LoadAndSyncAlertmanagersForOrgs(context.Background()))
+	return moa
+}
+
+func activeSilences(t *testing.T, moa *MultiOrgAlertmanager) []*amv2.GettableSilence {
+	t.Helper()
+	am, err := moa.AlertmanagerFor(1)
+	require.NoError(t, err)
+	silences, err := am.ListSilences(context.Background(), nil)
+	require.NoError(t, err)
+	var result []*amv2.GettableSilence
+	for _, s := range silences {
+		if !isSilenceExpired(*s) {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func TestMultiOrgAlertmanager_SyncProvisionedSilences(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+	parsed, err := labels.ParseMatchers(`{alertname="DiskFull", cluster=~"prod-.*"}`)
+	require.NoError(t, err)
+	matchers := labels.Matchers(parsed)
+
+	t.Run("one-off silence", func(t *testing.T) {
+		store := &fakeProvisionedSilenceStore{silences: []models.ProvisionedSilence{{
+			OrgID:    1,
+			UID:      "maintenance",
+			Matchers: matchers,
+			Comment:  "Planned maintenance",
+			StartsAt: now.Add(-time.Hour),
+			EndsAt:   now.Add(time.Hour),
+		}}}
+		moa := setupProvisionedSilencesMoa(t, store)
+
+		moa.SyncProvisionedSilences(ctx)
+		silences := activeSilences(t, moa)
+		require.Len(t, silences, 1)
+		require.Equal(t, *silences[0].ID, store.get(t).SilenceID)
+		require.Equal(t, "Planned maintenance", *silences[0].Comment)
+		require.Equal(t, models.DefaultProvisionedSilenceCreator, *silences[0].CreatedBy)
+		actual, err := FromSilenceMatchers(silences[0].Matchers)
+		require.NoError(t, err)
+		require.Equal(t, matchers.String(), actual.String())
+
+		// a silence that did not change is kept.
+		id := store.get(t).SilenceID
+		moa.SyncProvisionedSilences(ctx)
+		require.Len(t, activeSilences(t, moa), 1)
+		require.Equal(t, id, store.get(t).SilenceID)
+
+		// a silence that changed is replaced.
+		store.update(func(s *models.ProvisionedSilence) { s.Comment = "Extended maintenance" })
+		moa.SyncProvisionedSilences(ctx)
+		silences = activeSilences(t, moa)
+		require.Len(t, silences, 1)
+		require.NotEqual(t, id, *silences[0].ID)
+		require.Equal(t, *silences[0].ID, store.get(t).SilenceID)
+		require.Equal(t, "Extended maintenance", *silences[0].Comment)
+
+		// the silence of a deleted provisioned silence is expired.
+		require.NoError(t, moa.ExpireProvisionedSilence(ctx, store.get(t)))
+		require.Empty(t, activeSilences(t, moa))
+	})
+
+	t.Run("an existing silence is adopted", func(t *testing.T) {
+		store := &fakeProvisionedSilenceStore{silences: []models.ProvisionedSilence{{
+			OrgID:    1,
+			UID:      "maintenance",
+			Matchers: matchers,
+			Comment:  "Planned maintenance",
+			StartsAt: now.Add(-time.Hour),
+			EndsAt:   now.Add(time.Hour),
+		}}}
+		moa := setupProvisionedSilencesMoa(t, store)
+		moa.SyncProvisionedSilences(ctx)
+		id := store.get(t).SilenceID
+
+		// for example, another replica created the silence but did not store its ID yet.
+		store.update(func(s *models.ProvisionedSilence) { s.SilenceID = "" })
+		moa.SyncProvisionedSilences(ctx)
+		require.Len(t, activeSilences(t, moa), 1)
+		require.Equal(t, id, store.get(t).SilenceID)
+	})
+
+	t.Run("a silence that ended is not created", func(t *testing.T) {
+		store := &fakeProvisionedSilenceStore{silences: []models.ProvisionedSilence{{
+			OrgID:    1,
+			UID:      "maintenance",
+			Matchers: matchers,
+			Comment:  "Planned maintenance",
+			StartsAt: now.Add(-2 * time.Hour),
+			EndsAt:   now.Add(-time.Hour),
+		}}}
+		moa := setupProvisionedSilencesMoa(t, store)
+		moa.SyncProvisionedSilences(ctx)
+		require.Empty(t, activeSilences(t, moa))
+		require.Empty(t, store.get(t).SilenceID)
+	})
+
+	t.Run("scheduled silence", func(t *testing.T) {
+		store := &fakeProvisionedSilenceStore{silences: []models.ProvisionedSilence{{
+			OrgID:    1,
+			UID:      "hourly",
+			Matchers: matchers,
+			Comment:  "Hourly job",
+			Schedule: &models.SilenceSchedule{Cron: "0 * * * *", Duration: 10 * time.Minute},
+		}}}
+		moa := setupProvisionedSilencesMoa(t, store)
+		moa.SyncProvisionedSilences(ctx)
+
+		silences := activeSilences(t, moa)
+		require.Len(t, silences, 1)
+		s := store.get(t)
+		_, end, ok := s.Window(time.Now())
+		require.True(t, ok)
+		require.WithinDuration(t, end, time.Time(*silences[0].EndsAt), time.Second)
+		require.Equal(t, *silences[0].ID, s.SilenceID)
+	})
+}
@@ -0,0 +1,384 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
+
+	alertingNotify "github.com/grafana/alerting/notify"
+
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// SimulatedAlert is an alert received by a NotificationSimulator. The alert is firing until EndsAt, or forever if EndsAt is zero.
+type SimulatedAlert struct {
+	Labels   model.LabelSet
+	StartsAt time.Time
+	EndsAt   time.Time
+}
+
+func (a SimulatedAlert) resolvedAt(t time.Time) bool {
+	return !a.EndsAt.IsZero() && !a.EndsAt.After(t)
+}
+
+// SimulatedNotification is a notification of an aggregation group that the Alertmanager would have sent.
+type SimulatedNotification struct {
+	Time     time.Time
+	Receiver string
+	// Route is the key of the notification policy of the aggregation group, like the key of the dispatcher of the Alertmanager.
+	Route       string
+	GroupLabels model.LabelSet
+	Firing      []model.LabelSet
+	Resolved    []model.LabelSet
+	// MutedBy are the time intervals that muted the notification. The notification is not sent if it is not empty.
+	MutedBy []string
+}
+
+// NotificationSimulator routes alerts through the notification policies of a configuration and computes the
+// notifications of the aggregation groups like the Alertmanager does, without sending them. Time is simulated: the
+// alerts must be put in chronological order. The notification log is the one of a single Alertmanager, with one
+// integration per contact point. Inhibition rules and silences are not applied.
+type NotificationSimulator struct {
+	route      *dispatch.Route
+	intervener *timeinterval.Intervener
+
+	now           time.Time
+	groups        map[string]*simulatedGroup
+	notifications []SimulatedNotification
+}
+
+// NewNotificationSimulator returns a NotificationSimulator of the notification policy tree and the time intervals of the
+// configuration.
+func NewNotificationSimulator(cfg definitions.Config) (*NotificationSimulator, error) {
+	if cfg.Route == nil {
+		return nil, errors.New("the configuration has no notification policies")
+	}
+	if err := cfg.Route.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid notification policies: %w", err)
+	}
+	intervals := make(map[string][]timeinterval.TimeInterval, len(cfg.MuteTimeIntervals)+len(cfg.TimeIntervals))
+	for _, mt := range cfg.MuteTimeIntervals {
+		intervals[mt.Name] = mt.TimeIntervals
+	}
+	for _, ti := range cfg.TimeIntervals {
+		intervals[ti.Name] = ti.TimeIntervals
+	}
+	return &NotificationSimulator{
+		route:      dispatch.NewRoute(cfg.Route.AsAMRoute(), nil),
+		intervener: timeinterval.NewIntervener(intervals),
+		groups:     make(map[string]*simulatedGroup),
+	}, nil
+}
+
+// Match returns the notification policies that the labels match, in the order the Alertmanager notifies them.
+func (s *NotificationSimulator) Match(lset model.LabelSet) []*dispatch.Route {
+	return s.route.Match(lset)
+}
+
+// GroupLabels returns the labels of the aggregation group of the labels in the notification policy.
+func (s *NotificationSimulator) GroupLabels(route *dispatch.Route, lset model.LabelSet) model.LabelSet {
+	return simulatedGroupLabels(lset, route)
+}
+
+// Path returns the indexes of the nested notification policies from the default notification policy to the route, like
+// in the notification policies of the configuration: the autogenerated notification policies are not counted, and their
+// path is nil. The path of the default notification policy is empty.
+func (s *NotificationSimulator) Path(route *dispatch.Route) []int {
+	if route == s.route {
+		return []int{}
+	}
+	i := 0
+	for _, child := range s.route.Routes {
+		if isAutogeneratedDispatchRoot(child) {
+			continue
+		}
+		if path, ok := routePath(child, route); ok {
+			return append([]int{i}, path...)
+		}
+		i++
+	}
+	return nil
+}
+
+func routePath(parent, route *dispatch.Route) ([]int, bool) {
+	if parent == route {
+		return []int{}, true
+	}
+	for i, child := range parent.Routes {
+		if path, ok := routePath(child, route); ok {
+			return append([]int{i}, path...), true
+		}
+	}
+	return nil, false
+}
+
+func isAutogeneratedDispatchRoot(route *dispatch.Route) bool {
+	return len(route.Matchers) == 1 && route.Matchers[0].Name == models.AutogeneratedRouteLabel
+}
+
+// Mutes returns the time intervals that mute the notifications of the notification policy at the time now. Like the
+// Alertmanager, a notification policy is muted during its mute timings and outside its active timings.
+func (s *NotificationSimulator) Mutes(route *dispatch.Route, now time.Time) ([]string, error) {
+	var muted []string
+	for _, name := range route.RouteOpts.MuteTimeIntervals {
+		mutes, err := s.intervener.Mutes([]string{name}, now)
+		if err != nil {
+			return nil, err
+		}
+		if mutes {
+			muted = append(muted, name)
+		}
+	}
+	if len(route.RouteOpts.ActiveTimeIntervals) > 0 {
+		active, err := s.intervener.Mutes(route.RouteOpts.ActiveTimeIntervals, now)
+		if err != nil {
+			return nil, err
+		}
+		if !active {
+			muted = append(muted, route.RouteOpts.ActiveTimeIntervals...)
+		}
+	}
+	return muted, nil
+}
+
+// Put sends the alerts to the simulated Alertmanager at the time now, after flushing the aggregation groups that are
+// due before. An alert replaces the alert with the same labels.
+func (s *NotificationSimulator) Put(now time.Time, alerts ...SimulatedAlert) error {
+	if err := s.Advance(now); err != nil {
+		return err
+	}
+	for _, alert := range alerts {
+		for _, route := range s.route.Match(alert.Labels) {
+			groupLabels := simulatedGroupLabels(alert.Labels, route)
+			key := route.Key() + ":" + groupLabels.String()
+			group, ok := s.groups[key]
+			if !ok {
+				group = &simulatedGroup{
+					route:  route,
+					labels: groupLabels,
+					alerts: make(map[model.Fingerprint]SimulatedAlert),
+					next:   now.Add(route.RouteOpts.GroupWait),
+				}
+				s.groups[key] = group
+			}
+			group.alerts[alert.Labels.Fingerprint()] = alert
+			if !group.hasFlushed && alert.StartsAt.Add(route.RouteOpts.GroupWait).Before(now) {
+				group.next = now
+			}
+		}
+	}
+	return nil
+}
+
+// Advance flushes the aggregation groups that are due until the time now, in chronological order.
+func (s *NotificationSimulator) Advance(now time.Time) error {
+	if now.Before(s.now) {
+		return fmt.Errorf("time %s is before the current time of the simulation %s", now, s.now)
+	}
+	for {
+		var next *simulatedGroup
+		var nextKey string
+		for key, group := range s.groups {
+			if group.next.After(now) {
+				continue
+			}
+			if next == nil || group.next.Before(next.next) || group.next.Equal(next.next) && key < nextKey {
+				next, nextKey = group, key
+			}
+		}
+		if next == nil {
+			break
+		}
+		if err := s.flush(next); err != nil {
+			return err
+		}
+		if len(next.alerts) == 0 {
+			delete(s.groups, nextKey)
+		}
+	}
+	s.now = now
+	return nil
+}
+
+// Notifications returns the notifications of the simulation until now.
+func (s *NotificationSimulator) Notifications() []SimulatedNotification {
+	return s.notifications
+}
+
+// flush computes the notification of the group like the dedup stage of the Alertmanager, and removes the resolved alerts.
+func (s *NotificationSimulator) flush(group *simulatedGroup) error {
+	now := group.next
+	group.next = now.Add(group.route.RouteOpts.GroupInterval)
+	group.hasFlushed = true
+
+	var firing, resolved []model.LabelSet
+	firingFps := make(map[model.Fingerprint]struct{})
+	resolvedFps := make(map[model.Fingerprint]struct{})
+	for fp, alert := range group.alerts {
+		if alert.resolvedAt(now) {
+			resolved = append(resolved, alert.Labels)
+			resolvedFps[fp] = struct{}{}
+			delete(group.alerts, fp)
+			continue
+		}
+		firing = append(firing, alert.Labels)
+		firingFps[fp] = struct{}{}
+	}
+
+	if !group.needsUpdate(firingFps, resolvedFps, now) {
+		return nil
+	}
+	mutedBy, err := s.Mutes(group.route, now)
+	if err != nil {
+		return err
+	}
+	sortLabelSets(firing)
+	sortLabelSets(resolved)
+	s.notifications = append(s.notifications, SimulatedNotification{
+		Time:        now,
+		Receiver:    group.route.RouteOpts.Receiver,
+		Route:       group.route.Key(),
+		GroupLabels: group.labels,
+		Firing:      firing,
+		Resolved:    resolved,
+		MutedBy:     mutedBy,
+	})
+	if len(mutedBy) == 0 {
+		group.lastNotified = &simulatedNotificationLogEntry{time: now, firing: firingFps, resolved: resolvedFps}
+	}
+	return nil
+}
+
+type simulatedGroup struct {
+	route        *dispatch.Route
+	labels       model.LabelSet
+	alerts       map[model.Fingerprint]SimulatedAlert
+	next         time.Time
+	hasFlushed   bool
+	lastNotified *simulatedNotificationLogEntry
+}
+
+type simulatedNotificationLogEntry struct {
+	time     time.Time
+	firing   map[model.Fingerprint]struct{}
+	resolved map[model.Fingerprint]struct{}
+}
+
+// needsUpdate returns whether the group is notified, like the dedup stage of the Alertmanager for an integration that
+// sends resolved notifications.
+func (g *simulatedGroup) needsUpdate(firing, resolved map[model.Fingerprint]struct{}, now time.Time) bool {
+	entry := g.lastNotified
+	if entry == nil {
+		return len(firing) > 0
+	}
+	for fp := range firing {
+		if _, ok := entry.firing[fp]; !ok {
+			return true
+		}
+	}
+	if len(firing) == 0 {
+		return len(entry.firing) > 0
+	}
+	for fp := range resolved {
+		if _, ok := entry.resolved[fp]; !ok {
+			return true
+		}
+	}
+	return entry.time.Before(now.Add(-g.route.RouteOpts.RepeatInterval))
+}
+
+// simulatedGroupLabels returns the labels of the aggregation group of the alert, like the dispatcher of the Alertmanager.
+func simulatedGroupLabels(lset model.LabelSet, route *dispatch.Route) model.LabelSet {
+	groupLabels := model.LabelSet{}
+	for ln, lv := range lset {
+		if _, ok := route.RouteOpts.GroupBy[ln]; ok || route.RouteOpts.GroupByAll {
+			groupLabels[ln] = lv
+		}
+	}
+	return groupLabels
+}
+
+// SilencedBy returns the IDs of the silences that silence the labels at the time now. Unlike the Alertmanager, it uses the
+// start and end time of the silences instead of their state, so that the pending silences silence the labels after they
+// start.
+func SilencedBy(silences alertingNotify.GettableSilences, lset model.LabelSet, now time.Time) ([]string, error) {
+	var ids []string
+	for _, silence := range silences {
+		if silence.ID == nil || silence.StartsAt == nil || silence.EndsAt == nil {
+			continue
+		}
+		if time.Time(*silence.StartsAt).After(now) || !time.Time(*silence.EndsAt).After(now) {
+			continue
+		}
+		matchers, err := FromSilenceMatchers(silence.Matchers)
+		if err != nil {
+			return nil, fmt.Errorf("invalid matchers of silence %s: %w", *silence.ID, err)
+		}
+		if matchers.Matches(lset) {
+			ids = append(ids, *silence.ID)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func sortLabelSets(sets []model.LabelSet) {
+	sort.Slice(sets, func(i, j int) bool {
+		return sets[i].String() < sets[j].String()
+	})
+}
+
+// NewNotificationSimulator returns a NotificationSimulator of the latest configuration of the organization, or of cfg if
+// it is not nil. Like in the Alertmanager of the organization, the configuration has the autogenerated notification
+// policies of the notification settings of the rules, where the given rules replace the stored rules with the same key.
+func (moa *MultiOrgAlertmanager) NewNotificationSimulator(ctx context.Context, org int64, cfg *definitions.PostableUserConfig, rules ...*models.AlertRule) (*NotificationSimulator, error) {
+	if cfg == nil {
+		amConfig, err := moa.configStore.GetLatestAlertmanagerConfiguration(ctx, org)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get latest configuration: %w", err)
+		}
+		cfg, err = Load([]byte(amConfig.AlertmanagerConfiguration))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse Alertmanager config: %w", err)
+		}
+	}
+	if moa.featureManager.IsEnabled(ctx, featuremgmt.FlagAlertingSimplifiedRouting) {
+		store := simulationRuleStore{store: moa.configStore, rules: rules}
+		if err := AddAutogenConfig(ctx, moa.logger, store, org, &cfg.AlertmanagerConfig, true); err != nil {
+			return nil, err
+		}
+	}
+	return NewNotificationSimulator(cfg.AlertmanagerConfig.Config)
+}
+
+// simulationRuleStore lists the notification settings of the stored rules, replaced by the rules of the simulation.
+type simulationRuleStore struct {
+	store autogenRuleStore
+	rules []*models.AlertRule
+}
+
+func (s simulationRuleStore) ListNotificationSettings(ctx context.Context, q models.ListNotificationSettingsQuery) (map[models.AlertRuleKey][]models.NotificationSettings, error) {
+	settings, err := s.store.ListNotificationSettings(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		settings = make(map[models.AlertRuleKey][]models.NotificationSettings, len(s.rules))
+	}
+	for _, rule := range s.rules {
+		if len(rule.NotificationSettings) == 0 {
+			delete(settings, rule.GetKey())
+			continue
+		}
+		settings[rule.GetKey()] = rule.NotificationSettings
+	}
+	return settings, nil
+}
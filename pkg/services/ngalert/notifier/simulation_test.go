@@ -0,0 +1,202 @@
+package notifier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+const simulationConfig = `
+{
+  "alertmanager_config": {
+    "route": {
+      "receiver": "default",
+      "group_by": ["alertname"],
+      "group_wait": "30s",
+      "group_interval": "5m",
+      "repeat_interval": "1h",
+      "routes": [
+        {
+          "receiver": "team",
+          "object_matchers": [["team", "=", "a"]],
+          "group_by": ["alertname", "instance"],
+          "mute_time_intervals": ["weekends"]
+        }
+      ]
+    },
+    "time_intervals": [
+      {
+        "name": "weekends",
+        "time_intervals": [{"weekdays": ["saturday", "sunday"]}]
+      }
+    ],
+    "receivers": [
+      {"name": "default"},
+      {"name": "team"}
+    ]
+  }
+}
+`
+
+func TestNotificationSimulator(t *testing.T) {
+	newSimulator := func(t *testing.T) *NotificationSimulator {
+		t.Helper()
+		cfg, err := Load([]byte(simulationConfig))
+		require.NoError(t, err)
+		s, err := NewNotificationSimulator(cfg.AlertmanagerConfig.Config)
+		require.NoError(t, err)
+		return s
+	}
+	// Monday.
+	start := time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)
+	alert := func(name, instance string, startsAt time.Time) SimulatedAlert {
+		return SimulatedAlert{
+			Labels:   model.LabelSet{"alertname": model.LabelValue(name), "instance": model.LabelValue(instance)},
+			StartsAt: startsAt,
+		}
+	}
+
+	t.Run("notifies after group wait, then at group interval when the alerts change", func(t *testing.T) {
+		s := newSimulator(t)
+		a, b := alert("HighCPU", "a", start), alert("HighCPU", "b", start.Add(time.Minute))
+
+		require.NoError(t, s.Put(start, a))
+		require.NoError(t, s.Put(start.Add(time.Minute), b))
+		resolvedA := a
+		resolvedA.EndsAt = start.Add(6 * time.Minute)
+		require.NoError(t, s.Put(start.Add(6*time.Minute), resolvedA))
+		require.NoError(t, s.Advance(start.Add(20*time.Minute)))
+
+		require.Equal(t, []SimulatedNotification{
+			{
+				Time:        start.Add(30 * time.Second),
+				Receiver:    "default",
+				Route:       "{}",
+				GroupLabels: model.LabelSet{"alertname": "HighCPU"},
+				Firing:      []model.LabelSet{a.Labels},
+			},
+			{
+				Time:        start.Add(5*time.Minute + 30*time.Second),
+				Receiver:    "default",
+				Route:       "{}",
+				GroupLabels: model.LabelSet{"alertname": "HighCPU"},
+				Firing:      []model.LabelSet{a.Labels, b.Labels},
+			},
+			{
+				Time:        start.Add(10*time.Minute + 30*time.Second),
+				Receiver:    "default",
+				Route:       "{}",
+				GroupLabels: model.LabelSet{"alertname": "HighCPU"},
+				Firing:      []model.LabelSet{b.Labels},
+				Resolved:    []model.LabelSet{a.Labels},
+			},
+		}, s.Notifications())
+	})
+
+	t.Run("notifies again after repeat interval", func(t *testing.T) {
+		s := newSimulator(t)
+
+		require.NoError(t, s.Put(start, alert("HighCPU", "a", start)))
+		require.NoError(t, s.Advance(start.Add(2*time.Hour)))
+
+		var times []time.Time
+		for _, n := range s.Notifications() {
+			times = append(times, n.Time)
+		}
+		require.Equal(t, []time.Time{
+			start.Add(30 * time.Second),
+			start.Add(time.Hour + 5*time.Minute + 30*time.Second),
+		}, times)
+	})
+
+	t.Run("flushes immediately alerts that started before group wait", func(t *testing.T) {
+		s := newSimulator(t)
+
+		require.NoError(t, s.Put(start, alert("HighCPU", "a", start.Add(-time.Hour))))
+		require.NoError(t, s.Advance(start))
+
+		require.Len(t, s.Notifications(), 1)
+		require.Equal(t, start, s.Notifications()[0].Time)
+	})
+
+	t.Run("mutes notifications during mute timings", func(t *testing.T) {
+		s := newSimulator(t)
+		sunday := start.Add(-34 * time.Hour)
+		a := alert("HighCPU", "a", sunday)
+		a.Labels["team"] = "a"
+
+		require.NoError(t, s.Put(sunday, a))
+		require.NoError(t, s.Advance(sunday.Add(24*time.Hour+time.Minute)))
+
+		notifications := s.Notifications()
+		require.NotEmpty(t, notifications)
+		require.Equal(t, "team", notifications[0].Receiver)
+		require.Equal(t, `{}/{team="a"}`, notifications[0].Route)
+		require.Equal(t, model.LabelSet{"alertname": "HighCPU", "instance": "a"}, notifications[0].GroupLabels)
+		require.Equal(t, []string{"weekends"}, notifications[0].MutedBy)
+		last := notifications[len(notifications)-1]
+		require.Empty(t, last.MutedBy)
+		require.Equal(t, sunday.Add(24*time.Hour+30*time.Second), last.Time)
+	})
+
+	t.Run("fails if alerts are not in chronological order", func(t *testing.T) {
+		s := newSimulator(t)
+
+		require.NoError(t, s.Put(start, alert("HighCPU", "a", start)))
+		require.Error(t, s.Put(start.Add(-time.Second), alert("HighCPU", "b", start)))
+	})
+}
+
+func TestNotificationSimulatorPath(t *testing.T) {
+	cfg, err := Load([]byte(simulationConfig))
+	require.NoError(t, err)
+	s, err := NewNotificationSimulator(cfg.AlertmanagerConfig.Config)
+	require.NoError(t, err)
+
+	routes := s.Match(model.LabelSet{"alertname": "HighCPU", "team": "a"})
+	require.Len(t, routes, 1)
+	require.Equal(t, []int{0}, s.Path(routes[0]))
+
+	routes = s.Match(model.LabelSet{"alertname": "HighCPU"})
+	require.Len(t, routes, 1)
+	require.Equal(t, []int{}, s.Path(routes[0]))
+}
+
+func TestSilencedBy(t *testing.T) {
+	now := time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)
+	silence := func(id string, startsAt, endsAt time.Time, matchers ...*labels.Matcher) *amv2.GettableSilence {
+		start, end := strfmt.DateTime(startsAt), strfmt.DateTime(endsAt)
+		return &amv2.GettableSilence{
+			ID: &id,
+			Silence: amv2.Silence{
+				Matchers: ToSilenceMatchers(matchers),
+				StartsAt: &start,
+				EndsAt:   &end,
+			},
+		}
+	}
+	team, err := labels.NewMatcher(labels.MatchEqual, "team", "a")
+	require.NoError(t, err)
+	other, err := labels.NewMatcher(labels.MatchEqual, "team", "b")
+	require.NoError(t, err)
+	silences := []*amv2.GettableSilence{
+		silence("active", now.Add(-time.Hour), now.Add(time.Hour), team),
+		silence("other", now.Add(-time.Hour), now.Add(time.Hour), other),
+		silence("expired", now.Add(-2*time.Hour), now.Add(-time.Hour), team),
+		silence("pending", now.Add(time.Hour), now.Add(2*time.Hour), team),
+	}
+	lset := model.LabelSet{"alertname": "HighCPU", "team": "a"}
+
+	ids, err := SilencedBy(silences, lset, now)
+	require.NoError(t, err)
+	require.Equal(t, []string{"active"}, ids)
+
+	ids, err = SilencedBy(silences, lset, now.Add(90*time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, []string{"pending"}, ids)
+}
@@ -0,0 +1,224 @@
+package prom
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/prometheus/prometheus/promql/parser"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+const (
+	// queryRefID is the RefID of the query of the converted rules. The value of a Prometheus alert is the value of this query.
+	queryRefID = "A"
+	// presenceRefID is the RefID of the expression that is 1 for every series returned by the query.
+	presenceRefID = "B"
+	// conditionRefID is the RefID of the condition of the converted alert rules.
+	conditionRefID = "C"
+
+	// queryTimeRange is the relative time range of the queries. Only its end matters for instant queries.
+	queryTimeRange = 10 * time.Minute
+)
+
+var (
+	// valueRe matches $value that is not the prefix of a longer variable name, for example $values.
+	valueRe = regexp.MustCompile(`\$value\b`)
+	// dotValueRe matches .Value when it is applied to the data of the template, and not to a field.
+	dotValueRe = regexp.MustCompile(`(^|[^\w\])])\.Value\b`)
+	// externalLabelsRe matches the external labels, that Grafana does not have.
+	externalLabelsRe = regexp.MustCompile(`\$externalLabels\b|(^|[^\w\])])\.ExternalLabels\b`)
+)
+
+// Config is the configuration of the conversion of Prometheus rules to Grafana alert rules.
+type Config struct {
+	// DatasourceUID is the UID of the Prometheus data source that the queries of the converted rules use.
+	DatasourceUID string
+}
+
+// Converter converts Prometheus rules to Grafana alert rules.
+type Converter struct {
+	cfg Config
+}
+
+func NewConverter(cfg Config) (*Converter, error) {
+	if cfg.DatasourceUID == "" {
+		return nil, errors.New("data source UID must be specified")
+	}
+	return &Converter{cfg: cfg}, nil
+}
+
+// CheckGroup returns an error if the rule group uses features that Grafana alert rules do not support.
+func (c *Converter) CheckGroup(group apimodels.PrometheusRuleGroup) error {
+	if group.Name == "" {
+		return errors.New("rule group name cannot be empty")
+	}
+	if group.Limit > 0 {
+		return errors.New("limit is not supported")
+	}
+	return nil
+}
+
+// ConvertRule converts a Prometheus alerting rule or recording rule to a Grafana alert rule.
+//
+// The query of an alerting rule returns the series that fire, whatever their value. The condition of the converted
+// rule is therefore a threshold on a math expression that is 1 for every series returned by the query. The converted
+// alert rule is OK when the query returns no series, like a Prometheus alerting rule.
+func (c *Converter) ConvertRule(rule apimodels.ApiRuleNode) (apimodels.PostableExtendedRuleNode, error) {
+	if rule.Alert == "" && rule.Record == "" {
+		return apimodels.PostableExtendedRuleNode{}, errors.New("rule must be either an alerting rule or a recording rule")
+	}
+	if rule.Alert != "" && rule.Record != "" {
+		return apimodels.PostableExtendedRuleNode{}, errors.New("rule cannot be both an alerting rule and a recording rule")
+	}
+	if _, err := parser.ParseExpr(rule.Expr); err != nil {
+		return apimodels.PostableExtendedRuleNode{}, fmt.Errorf("invalid expression: %w", err)
+	}
+	query, err := c.query(rule.Expr)
+	if err != nil {
+		return apimodels.PostableExtendedRuleNode{}, err
+	}
+	annotations, err := convertTemplates(rule.Annotations)
+	if err != nil {
+		return apimodels.PostableExtendedRuleNode{}, fmt.Errorf("invalid annotations: %w", err)
+	}
+	labels, err := convertTemplates(rule.Labels)
+	if err != nil {
+		return apimodels.PostableExtendedRuleNode{}, fmt.Errorf("invalid labels: %w", err)
+	}
+
+	result := apimodels.PostableExtendedRuleNode{
+		ApiRuleNode: &apimodels.ApiRuleNode{
+			For:           rule.For,
+			KeepFiringFor: rule.KeepFiringFor,
+			Labels:        labels,
+			Annotations:   annotations,
+		},
+	}
+	if rule.Record != "" {
+		if rule.For != nil || rule.KeepFiringFor != nil {
+			return apimodels.PostableExtendedRuleNode{}, errors.New("recording rules cannot have for or keep_firing_for")
+		}
+		result.GrafanaManagedAlert = &apimodels.PostableGrafanaRule{
+			Title:     rule.Record,
+			Condition: queryRefID,
+			Data:      []apimodels.AlertQuery{query},
+			Record:    &apimodels.Record{Metric: rule.Record, From: queryRefID},
+		}
+		return result, nil
+	}
+
+	presence, err := expression(presenceRefID, map[string]any{
+		"type":       "math",
+		"expression": fmt.Sprintf("is_number($%[1]s) || is_nan($%[1]s) || is_inf($%[1]s)", queryRefID),
+	})
+	if err != nil {
+		return apimodels.PostableExtendedRuleNode{}, err
+	}
+	condition, err := expression(conditionRefID, map[string]any{
+		"type":       "threshold",
+		"expression": presenceRefID,
+		"conditions": []any{
+			map[string]any{"evaluator": map[string]any{"type": "gt", "params": []float64{0}}},
+		},
+	})
+	if err != nil {
+		return apimodels.PostableExtendedRuleNode{}, err
+	}
+	result.GrafanaManagedAlert = &apimodels.PostableGrafanaRule{
+		Title:        rule.Alert,
+		Condition:    conditionRefID,
+		Data:         []apimodels.AlertQuery{query, presence, condition},
+		NoDataState:  apimodels.OK,
+		ExecErrState: apimodels.ErrorErrState,
+	}
+	return result, nil
+}
+
+func (c *Converter) query(promQL string) (apimodels.AlertQuery, error) {
+	m, err := json.Marshal(map[string]any{
+		"refId":   queryRefID,
+		"expr":    promQL,
+		"instant": true,
+		"range":   false,
+		"datasource": map[string]any{
+			"type": datasources.DS_PROMETHEUS,
+			"uid":  c.cfg.DatasourceUID,
+		},
+	})
+	if err != nil {
+		return apimodels.AlertQuery{}, fmt.Errorf("failed to create query: %w", err)
+	}
+	return apimodels.AlertQuery{
+		RefID:             queryRefID,
+		DatasourceUID:     c.cfg.DatasourceUID,
+		RelativeTimeRange: apimodels.RelativeTimeRange{From: apimodels.Duration(queryTimeRange)},
+		Model:             m,
+	}, nil
+}
+
+// UniqueTitles adds a suffix to the titles of the converted rules of a group that have the same title as a previous
+// rule of the group, for example HostDown (2). The rules are numbered within their group only, so that the titles
+// do not change when the rules of other groups change.
+func UniqueTitles(rules []apimodels.PostableExtendedRuleNode) {
+	counts := make(map[string]int, len(rules))
+	for _, rule := range rules {
+		title := rule.GrafanaManagedAlert.Title
+		counts[title]++
+		if n := counts[title]; n > 1 {
+			rule.GrafanaManagedAlert.Title = fmt.Sprintf("%s (%d)", title, n)
+		}
+	}
+}
+
+func expression(refID string, m map[string]any) (apimodels.AlertQuery, error) {
+	m["refId"] = refID
+	m["datasource"] = map[string]any{
+		"type": expr.DatasourceType,
+		"uid":  expr.DatasourceUID,
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return apimodels.AlertQuery{}, fmt.Errorf("failed to create expression: %w", err)
+	}
+	return apimodels.AlertQuery{
+		RefID:         refID,
+		DatasourceUID: expr.DatasourceUID,
+		Model:         b,
+	}, nil
+}
+
+func convertTemplates(templates map[string]string) (map[string]string, error) {
+	if templates == nil {
+		return nil, nil
+	}
+	result := make(map[string]string, len(templates))
+	for k, v := range templates {
+		converted, err := ConvertTemplate(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", k, err)
+		}
+		result[k] = converted
+	}
+	return result, nil
+}
+
+// ConvertTemplate converts a Prometheus template to a Grafana template. $labels and .Labels are the same in Grafana,
+// but $value and .Value are the value of the query of the rule. The external labels are not supported.
+func ConvertTemplate(text string) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	if externalLabelsRe.MatchString(text) {
+		return "", errors.New("external labels are not supported")
+	}
+	value := fmt.Sprintf("$values.%s.Value", queryRefID)
+	text = dotValueRe.ReplaceAllString(text, "${1}"+strings.ReplaceAll(value, "$", "$$"))
+	return valueRe.ReplaceAllLiteralString(text, value), nil
+}
@@ -0,0 +1,161 @@
+package prom
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+func TestConvertRule(t *testing.T) {
+	newConverter := func(t *testing.T) *Converter {
+		c, err := NewConverter(Config{DatasourceUID: "prom"})
+		require.NoError(t, err)
+		return c
+	}
+	forDuration := model.Duration(5 * time.Minute)
+
+	t.Run("alerting rule", func(t *testing.T) {
+		c := newConverter(t)
+		node, err := c.ConvertRule(apimodels.ApiRuleNode{
+			Alert:       "HostDown",
+			Expr:        "up == 0",
+			For:         &forDuration,
+			Labels:      map[string]string{"severity": "critical"},
+			Annotations: map[string]string{"summary": "{{ $labels.instance }} is down"},
+		})
+		require.NoError(t, err)
+
+		rule := node.GrafanaManagedAlert
+		require.Equal(t, "HostDown", rule.Title)
+		require.Equal(t, conditionRefID, rule.Condition)
+		require.Equal(t, apimodels.OK, rule.NoDataState)
+		require.Equal(t, apimodels.ErrorErrState, rule.ExecErrState)
+		require.Nil(t, rule.Record)
+		require.Equal(t, &forDuration, node.For)
+		require.Equal(t, map[string]string{"severity": "critical"}, node.Labels)
+		require.Equal(t, map[string]string{"summary": "{{ $labels.instance }} is down"}, node.Annotations)
+
+		require.Len(t, rule.Data, 3)
+		query := rule.Data[0]
+		require.Equal(t, queryRefID, query.RefID)
+		require.Equal(t, "prom", query.DatasourceUID)
+		var m map[string]any
+		require.NoError(t, json.Unmarshal(query.Model, &m))
+		require.Equal(t, "up == 0", m["expr"])
+		require.Equal(t, true, m["instant"])
+
+		require.NoError(t, json.Unmarshal(rule.Data[1].Model, &m))
+		require.Equal(t, "math", m["type"])
+		require.Equal(t, "is_number($A) || is_nan($A) || is_inf($A)", m["expression"])
+		require.NoError(t, json.Unmarshal(rule.Data[2].Model, &m))
+		require.Equal(t, "threshold", m["type"])
+		require.Equal(t, presenceRefID, m["expression"])
+	})
+
+	t.Run("recording rule", func(t *testing.T) {
+		c := newConverter(t)
+		node, err := c.ConvertRule(apimodels.ApiRuleNode{
+			Record: "instance:up:sum",
+			Expr:   "sum by (instance) (up)",
+		})
+		require.NoError(t, err)
+
+		rule := node.GrafanaManagedAlert
+		require.Equal(t, "instance:up:sum", rule.Title)
+		require.Equal(t, queryRefID, rule.Condition)
+		require.Equal(t, &apimodels.Record{Metric: "instance:up:sum", From: queryRefID}, rule.Record)
+		require.Len(t, rule.Data, 1)
+	})
+
+	t.Run("rules with the same name get unique titles", func(t *testing.T) {
+		c := newConverter(t)
+		var rules []apimodels.PostableExtendedRuleNode
+		for _, name := range []string{"HostDown", "HostUp", "HostDown", "HostDown"} {
+			node, err := c.ConvertRule(apimodels.ApiRuleNode{Alert: name, Expr: "up == 0"})
+			require.NoError(t, err)
+			require.Equal(t, name, node.GrafanaManagedAlert.Title)
+			rules = append(rules, node)
+		}
+		UniqueTitles(rules)
+		for i, expected := range []string{"HostDown", "HostUp", "HostDown (2)", "HostDown (3)"} {
+			require.Equal(t, expected, rules[i].GrafanaManagedAlert.Title)
+		}
+	})
+
+	testCases := []struct {
+		name     string
+		rule     apimodels.ApiRuleNode
+		expected string
+	}{
+		{
+			name:     "neither alert nor record",
+			rule:     apimodels.ApiRuleNode{Expr: "up == 0"},
+			expected: "rule must be either an alerting rule or a recording rule",
+		},
+		{
+			name:     "both alert and record",
+			rule:     apimodels.ApiRuleNode{Alert: "a", Record: "b", Expr: "up == 0"},
+			expected: "rule cannot be both an alerting rule and a recording rule",
+		},
+		{
+			name:     "invalid expression",
+			rule:     apimodels.ApiRuleNode{Alert: "a", Expr: "up =="},
+			expected: "invalid expression",
+		},
+		{
+			name:     "recording rule with for",
+			rule:     apimodels.ApiRuleNode{Record: "a", Expr: "up", For: &forDuration},
+			expected: "recording rules cannot have for or keep_firing_for",
+		},
+		{
+			name:     "external labels",
+			rule:     apimodels.ApiRuleNode{Alert: "a", Expr: "up == 0", Annotations: map[string]string{"summary": "{{ $externalLabels.cluster }}"}},
+			expected: "invalid annotations: summary: external labels are not supported",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := newConverter(t).ConvertRule(tc.rule)
+			require.ErrorContains(t, err, tc.expected)
+		})
+	}
+}
+
+func TestConvertTemplate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		text     string
+		expected string
+		err      string
+	}{
+		{name: "text without template", text: "Host is down", expected: "Host is down"},
+		{name: "labels", text: "{{ $labels.instance }} is down", expected: "{{ $labels.instance }} is down"},
+		{name: "value", text: "{{ $value }}", expected: "{{ $values.A.Value }}"},
+		{name: "value in a function", text: "{{ $value | humanizePercentage }}", expected: "{{ $values.A.Value | humanizePercentage }}"},
+		{name: "dot value", text: "{{ .Value }} for {{ .Labels.job }}", expected: "{{ $values.A.Value }} for {{ .Labels.job }}"},
+		{name: "values is not changed", text: "{{ $values.B }}", expected: "{{ $values.B }}"},
+		{name: "external labels", text: "{{ $externalLabels.cluster }}", err: "external labels are not supported"},
+		{name: "dot external labels", text: "{{ .ExternalLabels.cluster }}", err: "external labels are not supported"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := ConvertTemplate(tc.text)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestNewConverter(t *testing.T) {
+	_, err := NewConverter(Config{})
+	require.Error(t, err)
+}
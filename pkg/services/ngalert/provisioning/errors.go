@@ -19,6 +19,10 @@ var (
 	ErrTimeIntervalExists   = errutil.BadRequest("alerting.notifications.time-intervals.nameExists", errutil.WithPublicMessage("Time interval with this name already exists. Use a different name or update existing one."))
 	ErrTimeIntervalInvalid  = errutil.BadRequest("alerting.notifications.time-intervals.invalidFormat").MustTemplate("Invalid format of the submitted time interval", errutil.WithPublic("Time interval is in invalid format. Correct the payload and try again."))
 	ErrTimeIntervalInUse    = errutil.Conflict("alerting.notifications.time-intervals.used", errutil.WithPublicMessage("Time interval is used by one or many notification policies"))
+
+	ErrSilenceNotFound = errutil.NotFound("alerting.provisioning.silences.notFound", errutil.WithPublicMessage("Provisioned silence not found"))
+	ErrSilenceExists   = errutil.BadRequest("alerting.provisioning.silences.uidExists", errutil.WithPublicMessage("Provisioned silence with this UID already exists. Use a different UID or update the existing one."))
+	ErrSilenceInvalid  = errutil.BadRequest("alerting.provisioning.silences.invalidFormat").MustTemplate("Invalid format of the submitted silence", errutil.WithPublic("Silence is in invalid format: {{ .Public.Error }}. Correct the payload and try again."))
 )
 
 func makeErrBadAlertmanagerConfiguration(err error) error {
@@ -42,3 +46,15 @@ func MakeErrTimeIntervalInvalid(err error) error {
 
 	return ErrTimeIntervalInvalid.Build(data)
 }
+
+// MakeErrSilenceInvalid creates an error with the ErrSilenceInvalid template
+func MakeErrSilenceInvalid(err error) error {
+	data := errutil.TemplateData{
+		Public: map[string]interface{}{
+			"Error": err.Error(),
+		},
+		Error: err,
+	}
+
+	return ErrSilenceInvalid.Build(data)
+}
@@ -44,6 +44,15 @@ type RuleStore interface {
 	GetAlertRulesGroupByRuleUID(ctx context.Context, query *models.GetAlertRulesGroupByRuleUIDQuery) ([]*models.AlertRule, error)
 }
 
+// SilenceStore represents the ability to persist and query provisioned silences.
+type SilenceStore interface {
+	ListProvisionedSilences(ctx context.Context, orgID int64) ([]models.ProvisionedSilence, error)
+	GetProvisionedSilence(ctx context.Context, orgID int64, uid string) (models.ProvisionedSilence, error)
+	InsertProvisionedSilence(ctx context.Context, silence models.ProvisionedSilence) error
+	UpdateProvisionedSilence(ctx context.Context, silence models.ProvisionedSilence) error
+	DeleteProvisionedSilence(ctx context.Context, orgID int64, uid string) error
+}
+
 // QuotaChecker represents the ability to evaluate whether quotas are met.
 //
 //go:generate mockery --name QuotaChecker --structname MockQuotaChecker --inpackage --filename quota_checker_mock.go --with-expecter
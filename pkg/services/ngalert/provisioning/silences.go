@@ -0,0 +1,160 @@
+package provisioning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// SilenceSyncer creates and expires the silences of provisioned silences in the Alertmanager.
+type SilenceSyncer interface {
+	SyncProvisionedSilence(ctx context.Context, s models.ProvisionedSilence) error
+	ExpireProvisionedSilence(ctx context.Context, s models.ProvisionedSilence) error
+}
+
+type SilenceService struct {
+	store           SilenceStore
+	provenanceStore ProvisioningStore
+	xact            TransactionManager
+	syncer          SilenceSyncer
+	log             log.Logger
+}
+
+func NewSilenceService(store SilenceStore, prov ProvisioningStore, xact TransactionManager, syncer SilenceSyncer, log log.Logger) *SilenceService {
+	return &SilenceService{
+		store:           store,
+		provenanceStore: prov,
+		xact:            xact,
+		syncer:          syncer,
+		log:             log,
+	}
+}
+
+// GetSilences returns all provisioned silences of the org and their provenance.
+func (svc *SilenceService) GetSilences(ctx context.Context, orgID int64) ([]models.ProvisionedSilence, map[string]models.Provenance, error) {
+	silences, err := svc.store.ListProvisionedSilences(ctx, orgID)
+	if err != nil {
+		return nil, nil, err
+	}
+	provenances, err := svc.provenanceStore.GetProvenances(ctx, orgID, (&models.ProvisionedSilence{}).ResourceType())
+	if err != nil {
+		return nil, nil, err
+	}
+	return silences, provenances, nil
+}
+
+// GetSilence returns the provisioned silence with the UID and its provenance. If the silence does not exist, ErrSilenceNotFound is returned.
+func (svc *SilenceService) GetSilence(ctx context.Context, orgID int64, uid string) (models.ProvisionedSilence, models.Provenance, error) {
+	silence, err := svc.store.GetProvisionedSilence(ctx, orgID, uid)
+	if err != nil {
+		if errors.Is(err, models.ErrProvisionedSilenceNotFound) {
+			return models.ProvisionedSilence{}, models.ProvenanceNone, ErrSilenceNotFound.Errorf("")
+		}
+		return models.ProvisionedSilence{}, models.ProvenanceNone, err
+	}
+	provenance, err := svc.provenanceStore.GetProvenance(ctx, &silence, orgID)
+	if err != nil {
+		return models.ProvisionedSilence{}, models.ProvenanceNone, err
+	}
+	return silence, provenance, nil
+}
+
+// CreateSilence adds a new provisioned silence. A UID is generated if the silence does not have one. The created silence is returned.
+func (svc *SilenceService) CreateSilence(ctx context.Context, silence models.ProvisionedSilence, provenance models.Provenance) (models.ProvisionedSilence, error) {
+	if silence.UID == "" {
+		silence.UID = util.GenerateShortUID()
+	}
+	silence.SilenceID = ""
+	if err := silence.Validate(); err != nil {
+		return models.ProvisionedSilence{}, MakeErrSilenceInvalid(err)
+	}
+	err := svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if err := svc.store.InsertProvisionedSilence(ctx, silence); err != nil {
+			if errors.Is(err, models.ErrProvisionedSilenceExists) {
+				return ErrSilenceExists.Errorf("")
+			}
+			return err
+		}
+		return svc.provenanceStore.SetProvenance(ctx, &silence, silence.OrgID, provenance)
+	})
+	if err != nil {
+		return models.ProvisionedSilence{}, err
+	}
+	svc.sync(ctx, silence)
+	return silence, nil
+}
+
+// UpdateSilence replaces an existing provisioned silence. The silence that was created for the previous definition
+// is expired. If the silence does not exist, ErrSilenceNotFound is returned.
+func (svc *SilenceService) UpdateSilence(ctx context.Context, silence models.ProvisionedSilence, provenance models.Provenance) (models.ProvisionedSilence, error) {
+	if err := silence.Validate(); err != nil {
+		return models.ProvisionedSilence{}, MakeErrSilenceInvalid(err)
+	}
+	stored, storedProvenance, err := svc.GetSilence(ctx, silence.OrgID, silence.UID)
+	if err != nil {
+		return models.ProvisionedSilence{}, err
+	}
+	if storedProvenance != provenance && storedProvenance != models.ProvenanceNone {
+		return models.ProvisionedSilence{}, fmt.Errorf("cannot change provenance from '%s' to '%s'", storedProvenance, provenance)
+	}
+	silence.ID = stored.ID
+	silence.SilenceID = stored.SilenceID
+	err = svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if err := svc.store.UpdateProvisionedSilence(ctx, silence); err != nil {
+			if errors.Is(err, models.ErrProvisionedSilenceNotFound) {
+				return ErrSilenceNotFound.Errorf("")
+			}
+			return err
+		}
+		return svc.provenanceStore.SetProvenance(ctx, &silence, silence.OrgID, provenance)
+	})
+	if err != nil {
+		return models.ProvisionedSilence{}, err
+	}
+	svc.sync(ctx, silence)
+	return silence, nil
+}
+
+// DeleteSilence deletes the provisioned silence and expires its silence. If the silence does not exist, no error is returned.
+func (svc *SilenceService) DeleteSilence(ctx context.Context, orgID int64, uid string, provenance models.Provenance) error {
+	stored, storedProvenance, err := svc.GetSilence(ctx, orgID, uid)
+	if err != nil {
+		if errors.Is(err, ErrSilenceNotFound) {
+			return nil
+		}
+		return err
+	}
+	if storedProvenance != provenance && storedProvenance != models.ProvenanceNone {
+		return fmt.Errorf("cannot delete with provided provenance '%s', needs '%s'", provenance, storedProvenance)
+	}
+	err = svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if err := svc.store.DeleteProvisionedSilence(ctx, orgID, uid); err != nil {
+			return err
+		}
+		return svc.provenanceStore.DeleteProvenance(ctx, &stored, orgID)
+	})
+	if err != nil {
+		return err
+	}
+	if svc.syncer != nil {
+		if err := svc.syncer.ExpireProvisionedSilence(ctx, stored); err != nil {
+			svc.log.Warn("Failed to expire the silence of a deleted provisioned silence", "org", orgID, "uid", uid, "silence", stored.SilenceID, "error", err)
+		}
+	}
+	return nil
+}
+
+// sync creates the silence of the provisioned silence right away. The Alertmanager might not be ready yet,
+// so errors are only logged: the silence is created when the Alertmanager synchronizes the provisioned silences.
+func (svc *SilenceService) sync(ctx context.Context, silence models.ProvisionedSilence) {
+	if svc.syncer == nil {
+		return
+	}
+	if err := svc.syncer.SyncProvisionedSilence(ctx, silence); err != nil {
+		svc.log.Warn("Failed to synchronize provisioned silence", "org", silence.OrgID, "uid", silence.UID, "error", err)
+	}
+}
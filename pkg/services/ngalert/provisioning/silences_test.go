@@ -0,0 +1,181 @@
+package provisioning
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+)
+
+type fakeSilenceStore struct {
+	silences map[string]models.ProvisionedSilence
+}
+
+func (f *fakeSilenceStore) ListProvisionedSilences(_ context.Context, orgID int64) ([]models.ProvisionedSilence, error) {
+	result := make([]models.ProvisionedSilence, 0, len(f.silences))
+	for _, s := range f.silences {
+		if s.OrgID == orgID {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeSilenceStore) GetProvisionedSilence(_ context.Context, orgID int64, uid string) (models.ProvisionedSilence, error) {
+	s, ok := f.silences[uid]
+	if !ok || s.OrgID != orgID {
+		return models.ProvisionedSilence{}, models.ErrProvisionedSilenceNotFound
+	}
+	return s, nil
+}
+
+func (f *fakeSilenceStore) InsertProvisionedSilence(_ context.Context, silence models.ProvisionedSilence) error {
+	if _, ok := f.silences[silence.UID]; ok {
+		return models.ErrProvisionedSilenceExists
+	}
+	f.silences[silence.UID] = silence
+	return nil
+}
+
+func (f *fakeSilenceStore) UpdateProvisionedSilence(_ context.Context, silence models.ProvisionedSilence) error {
+	if _, ok := f.silences[silence.UID]; !ok {
+		return models.ErrProvisionedSilenceNotFound
+	}
+	f.silences[silence.UID] = silence
+	return nil
+}
+
+func (f *fakeSilenceStore) DeleteProvisionedSilence(_ context.Context, _ int64, uid string) error {
+	delete(f.silences, uid)
+	return nil
+}
+
+type fakeSilenceSyncer struct {
+	synced  []models.ProvisionedSilence
+	expired []models.ProvisionedSilence
+}
+
+func (f *fakeSilenceSyncer) SyncProvisionedSilence(_ context.Context, s models.ProvisionedSilence) error {
+	f.synced = append(f.synced, s)
+	return nil
+}
+
+func (f *fakeSilenceSyncer) ExpireProvisionedSilence(_ context.Context, s models.ProvisionedSilence) error {
+	f.expired = append(f.expired, s)
+	return nil
+}
+
+func createSilenceSvcSut() (*SilenceService, *fakeSilenceStore, *fakes.FakeProvisioningStore, *fakeSilenceSyncer) {
+	store := &fakeSilenceStore{silences: map[string]models.ProvisionedSilence{}}
+	prov := fakes.NewFakeProvisioningStore()
+	syncer := &fakeSilenceSyncer{}
+	return NewSilenceService(store, prov, newNopTransactionManager(), syncer, log.NewNopLogger()), store, prov, syncer
+}
+
+func TestSilenceService(t *testing.T) {
+	ctx := context.Background()
+	matcher, err := labels.NewMatcher(labels.MatchEqual, "alertname", "DiskFull")
+	require.NoError(t, err)
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	silence := models.ProvisionedSilence{
+		OrgID:    1,
+		Matchers: labels.Matchers{matcher},
+		Comment:  "Planned maintenance",
+		StartsAt: start,
+		EndsAt:   start.Add(time.Hour),
+	}
+
+	t.Run("create generates a UID and synchronizes the silence", func(t *testing.T) {
+		sut, store, _, syncer := createSilenceSvcSut()
+		created, err := sut.CreateSilence(ctx, silence, models.ProvenanceAPI)
+		require.NoError(t, err)
+		require.NotEmpty(t, created.UID)
+		require.Contains(t, store.silences, created.UID)
+		require.Equal(t, []models.ProvisionedSilence{created}, syncer.synced)
+
+		got, provenance, err := sut.GetSilence(ctx, 1, created.UID)
+		require.NoError(t, err)
+		require.Equal(t, models.ProvenanceAPI, provenance)
+		require.Equal(t, created, got)
+
+		silences, provenances, err := sut.GetSilences(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, silences, 1)
+		require.Equal(t, models.ProvenanceAPI, provenances[created.UID])
+	})
+
+	t.Run("create fails if the silence is invalid or exists", func(t *testing.T) {
+		sut, _, _, _ := createSilenceSvcSut()
+		invalid := silence
+		invalid.Comment = ""
+		_, err := sut.CreateSilence(ctx, invalid, models.ProvenanceAPI)
+		require.ErrorIs(t, err, ErrSilenceInvalid)
+
+		s := silence
+		s.UID = "maintenance"
+		_, err = sut.CreateSilence(ctx, s, models.ProvenanceAPI)
+		require.NoError(t, err)
+		_, err = sut.CreateSilence(ctx, s, models.ProvenanceAPI)
+		require.ErrorIs(t, err, ErrSilenceExists)
+	})
+
+	t.Run("update keeps the silence ID of the stored silence", func(t *testing.T) {
+		sut, store, _, syncer := createSilenceSvcSut()
+		created, err := sut.CreateSilence(ctx, silence, models.ProvenanceAPI)
+		require.NoError(t, err)
+		stored := store.silences[created.UID]
+		stored.SilenceID = "silence-1"
+		store.silences[created.UID] = stored
+
+		update := created
+		update.Comment = "Extended maintenance"
+		update.SilenceID = ""
+		updated, err := sut.UpdateSilence(ctx, update, models.ProvenanceAPI)
+		require.NoError(t, err)
+		require.Equal(t, "silence-1", updated.SilenceID)
+		require.Equal(t, "Extended maintenance", store.silences[created.UID].Comment)
+		require.Equal(t, updated, syncer.synced[len(syncer.synced)-1])
+	})
+
+	t.Run("update fails if the silence does not exist", func(t *testing.T) {
+		sut, _, _, _ := createSilenceSvcSut()
+		s := silence
+		s.UID = "missing"
+		_, err := sut.UpdateSilence(ctx, s, models.ProvenanceAPI)
+		require.ErrorIs(t, err, ErrSilenceNotFound)
+	})
+
+	t.Run("provenance cannot be changed", func(t *testing.T) {
+		sut, store, _, _ := createSilenceSvcSut()
+		created, err := sut.CreateSilence(ctx, silence, models.ProvenanceFile)
+		require.NoError(t, err)
+
+		_, err = sut.UpdateSilence(ctx, created, models.ProvenanceAPI)
+		require.ErrorContains(t, err, "cannot change provenance from 'file' to 'api'")
+		err = sut.DeleteSilence(ctx, 1, created.UID, models.ProvenanceAPI)
+		require.ErrorContains(t, err, "cannot delete with provided provenance 'api', needs 'file'")
+		require.Contains(t, store.silences, created.UID)
+	})
+
+	t.Run("delete removes the silence and expires it", func(t *testing.T) {
+		sut, store, prov, syncer := createSilenceSvcSut()
+		created, err := sut.CreateSilence(ctx, silence, models.ProvenanceAPI)
+		require.NoError(t, err)
+
+		require.NoError(t, sut.DeleteSilence(ctx, 1, created.UID, models.ProvenanceAPI))
+		require.Empty(t, store.silences)
+		require.Equal(t, []models.ProvisionedSilence{created}, syncer.expired)
+		provenances, err := prov.GetProvenances(ctx, 1, created.ResourceType())
+		require.NoError(t, err)
+		require.Empty(t, provenances)
+
+		// deleting a silence that does not exist is not an error.
+		require.NoError(t, sut.DeleteSilence(ctx, 1, created.UID, models.ProvenanceAPI))
+	})
+}
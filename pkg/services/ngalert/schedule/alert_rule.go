@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/services/datasources"
@@ -15,6 +16,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/state/template"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/util"
@@ -34,12 +36,16 @@ type Rule interface {
 	Eval(eval *Evaluation) (bool, *Evaluation)
 	// Update sends a singal to change the definition of the rule.
 	Update(lastVersion RuleVersionAndPauseStatus) bool
+	// Type gives the type of the rule.
+	Type() ngmodels.RuleType
+	// EvaluationStats returns the statistics of the last evaluations of the rule.
+	EvaluationStats() EvaluationStats
 }
 
-type ruleFactoryFunc func(context.Context) Rule
+type ruleFactoryFunc func(context.Context, *ngmodels.AlertRule) Rule
 
-func (f ruleFactoryFunc) new(ctx context.Context) Rule {
-	return f(ctx)
+func (f ruleFactoryFunc) new(ctx context.Context, rule *ngmodels.AlertRule) Rule {
+	return f(ctx, rule)
 }
 
 func newRuleFactory(
@@ -50,6 +56,7 @@ func newRuleFactory(
 	stateManager *state.Manager,
 	evalFactory eval.EvaluatorFactory,
 	ruleProvider ruleProvider,
+	recordingWriter RecordingWriter,
 	clock clock.Clock,
 	met *metrics.Scheduler,
 	logger log.Logger,
@@ -57,7 +64,21 @@ func newRuleFactory(
 	evalAppliedHook evalAppliedFunc,
 	stopAppliedHook stopAppliedFunc,
 ) ruleFactoryFunc {
-	return func(ctx context.Context) Rule {
+	return func(ctx context.Context, rule *ngmodels.AlertRule) Rule {
+		if rule.Type() == ngmodels.RuleTypeRecording {
+			return newRecordingRule(
+				ctx,
+				maxAttempts,
+				evalFactory,
+				recordingWriter,
+				clock,
+				met,
+				logger,
+				tracer,
+				evalAppliedHook,
+				stopAppliedHook,
+			)
+		}
 		return newAlertRule(
 			ctx,
 			appURL,
@@ -99,6 +120,7 @@ type alertRule struct {
 	stateManager *state.Manager
 	evalFactory  eval.EvaluatorFactory
 	ruleProvider ruleProvider
+	history      *evaluationHistory
 
 	// Event hooks that are only used in tests.
 	evalAppliedHook evalAppliedFunc
@@ -139,6 +161,7 @@ func newAlertRule(
 		stateManager:         stateManager,
 		evalFactory:          evalFactory,
 		ruleProvider:         ruleProvider,
+		history:              newEvaluationHistory(),
 		evalAppliedHook:      evalAppliedHook,
 		stopAppliedHook:      stopAppliedHook,
 		metrics:              met,
@@ -162,6 +185,10 @@ func (a *alertRule) Eval(eval *Evaluation) (bool, *Evaluation) {
 	default:
 	}
 
+	if droppedMsg != nil {
+		a.history.addMissed(droppedMsg.scheduledAt)
+	}
+
 	select {
 	case a.evalCh <- eval:
 		return true, droppedMsg
@@ -188,6 +215,14 @@ func (a *alertRule) Update(lastVersion RuleVersionAndPauseStatus) bool {
 	}
 }
 
+func (a *alertRule) Type() ngmodels.RuleType {
+	return ngmodels.RuleTypeAlerting
+}
+
+func (a *alertRule) EvaluationStats() EvaluationStats {
+	return a.history.stats()
+}
+
 // stop sends an instruction to the rule evaluation routine to shut down. an optional shutdown reason can be given.
 func (a *alertRule) Stop(reason error) {
 	if a.stopFn != nil {
@@ -295,7 +330,8 @@ func (a *alertRule) Run(key ngmodels.AlertRuleKey) error {
 
 		case <-grafanaCtx.Done():
 			// clean up the state only if the reason for stopping the evaluation loop is that the rule was deleted
-			if errors.Is(grafanaCtx.Err(), errRuleDeleted) {
+			// or it is not an alerting rule anymore.
+			if errors.Is(grafanaCtx.Err(), errRuleDeleted) || errors.Is(grafanaCtx.Err(), errRuleTypeChanged) {
 				// We do not want a context to be unbounded which could potentially cause a go routine running
 				// indefinitely. 1 minute is an almost randomly chosen timeout, big enough to cover the majority of the
 				// cases.
@@ -304,6 +340,10 @@ func (a *alertRule) Run(key ngmodels.AlertRuleKey) error {
 				states := a.stateManager.DeleteStateByRuleUID(ngmodels.WithRuleKey(ctx, key), key, ngmodels.StateReasonRuleDeleted)
 				a.notify(grafanaCtx, key, states)
 			}
+			// the rule is evaluated by another instance of the cluster, which loads the state from the database.
+			if errors.Is(grafanaCtx.Err(), errRuleNotOwned) {
+				a.stateManager.ForgetStateByRuleUID(grafanaCtx, key)
+			}
 			logger.Debug("Stopping alert rule routine")
 			return nil
 		}
@@ -325,11 +365,12 @@ func (a *alertRule) evaluate(ctx context.Context, key ngmodels.AlertRuleKey, f f
 	ruleEval, err := a.evalFactory.Create(evalCtx, e.rule.GetEvalCondition())
 	var results eval.Results
 	var dur time.Duration
+	stats := &expr.ExecutionStats{}
 	if err != nil {
 		dur = a.clock.Now().Sub(start)
 		logger.Error("Failed to build rule evaluator", "error", err)
 	} else {
-		results, err = ruleEval.Evaluate(ctx, e.scheduledAt)
+		results, err = ruleEval.Evaluate(expr.WithExecutionStats(ctx, stats), e.scheduledAt)
 		dur = a.clock.Now().Sub(start)
 		if err != nil {
 			logger.Error("Failed to evaluate rule", "error", err, "duration", dur)
@@ -338,6 +379,18 @@ func (a *alertRule) evaluate(ctx context.Context, key ngmodels.AlertRuleKey, f f
 
 	evalTotal.Inc()
 	evalDuration.Observe(dur.Seconds())
+	evalErr := err
+	if evalErr == nil && results.HasErrors() {
+		evalErr = results.Error()
+	}
+	a.history.add(EvaluationStat{
+		ScheduledAt: e.scheduledAt,
+		Attempt:     attempt,
+		Duration:    dur,
+		Series:      len(results),
+		Nodes:       stats.Nodes(),
+		Error:       evalErr,
+	})
 
 	if ctx.Err() != nil { // check if the context is not cancelled. The evaluation can be a long-running task.
 		span.SetStatus(codes.Error, "rule evaluation cancelled")
@@ -385,8 +438,10 @@ func (a *alertRule) evaluate(ctx context.Context, key ngmodels.AlertRuleKey, f f
 		))
 	}
 	start = a.clock.Now()
+	// the templates of the annotations and labels can execute queries with the `query` function.
+	templateCtx := template.WithQueryFunc(ctx, newTemplateQuerier(a.evalFactory, e.rule).Query)
 	processedStates := a.stateManager.ProcessEvalResults(
-		ctx,
+		templateCtx,
 		e.scheduledAt,
 		e.rule,
 		results,
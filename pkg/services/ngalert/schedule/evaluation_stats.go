@@ -0,0 +1,117 @@
+package schedule
+
+import (
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/expr"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	// evaluationStatsSize is the number of evaluations whose statistics are kept for each rule.
+	evaluationStatsSize = 20
+	// evaluationErrorsSize is the number of failed evaluations whose statistics are kept for each rule.
+	evaluationErrorsSize = 10
+)
+
+// EvaluationStats are the statistics of the last evaluations of a rule by this instance.
+type EvaluationStats struct {
+	// Evaluations are the last evaluations, the latest first. Every attempt of an evaluation is an evaluation.
+	Evaluations []EvaluationStat
+	// Errors are the last failed evaluations, the latest first. They can be older than Evaluations.
+	Errors []EvaluationStat
+	// Missed is the number of evaluations that were dropped because the previous evaluation was too slow.
+	Missed     int64
+	LastMissed time.Time
+}
+
+// EvaluationStat is the statistics of an evaluation of a rule.
+type EvaluationStat struct {
+	ScheduledAt time.Time
+	Attempt     int64
+	Duration    time.Duration
+	// Series is the number of series of the result of the condition.
+	Series int
+	// Nodes are the statistics of the queries and expressions of the rule, in the order of execution.
+	Nodes []expr.NodeStats
+	Error error
+}
+
+// evaluationHistory keeps the statistics of the last evaluations of a rule in bounded rings.
+type evaluationHistory struct {
+	mtx         sync.Mutex
+	evaluations evaluationRing
+	errors      evaluationRing
+	missed      int64
+	lastMissed  time.Time
+}
+
+func newEvaluationHistory() *evaluationHistory {
+	return &evaluationHistory{
+		evaluations: evaluationRing{size: evaluationStatsSize},
+		errors:      evaluationRing{size: evaluationErrorsSize},
+	}
+}
+
+func (h *evaluationHistory) add(stat EvaluationStat) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.evaluations.add(stat)
+	if stat.Error != nil {
+		h.errors.add(stat)
+	}
+}
+
+func (h *evaluationHistory) addMissed(scheduledAt time.Time) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.missed++
+	if scheduledAt.After(h.lastMissed) {
+		h.lastMissed = scheduledAt
+	}
+}
+
+func (h *evaluationHistory) stats() EvaluationStats {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	return EvaluationStats{
+		Evaluations: h.evaluations.latestFirst(),
+		Errors:      h.errors.latestFirst(),
+		Missed:      h.missed,
+		LastMissed:  h.lastMissed,
+	}
+}
+
+// evaluationRing is a ring of the statistics of at most size evaluations.
+type evaluationRing struct {
+	items []EvaluationStat
+	next  int
+	size  int
+}
+
+func (r *evaluationRing) add(stat EvaluationStat) {
+	if len(r.items) < r.size {
+		r.items = append(r.items, stat)
+	} else {
+		r.items[r.next] = stat
+	}
+	r.next = (r.next + 1) % r.size
+}
+
+func (r *evaluationRing) latestFirst() []EvaluationStat {
+	result := make([]EvaluationStat, 0, len(r.items))
+	for i := 1; i <= len(r.items); i++ {
+		result = append(result, r.items[(r.next-i+len(r.items))%len(r.items)])
+	}
+	return result
+}
+
+// EvaluationStats returns the statistics of the last evaluations of the rule, if the rule is evaluated by this instance.
+func (sch *schedule) EvaluationStats(key ngmodels.AlertRuleKey) (EvaluationStats, bool) {
+	rule, ok := sch.registry.get(key)
+	if !ok {
+		return EvaluationStats{}, false
+	}
+	return rule.EvaluationStats(), true
+}
@@ -0,0 +1,36 @@
+package schedule
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluationHistory(t *testing.T) {
+	start := time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)
+	h := newEvaluationHistory()
+	for i := 0; i < evaluationStatsSize+5; i++ {
+		stat := EvaluationStat{ScheduledAt: start.Add(time.Duration(i) * time.Minute), Attempt: 1}
+		if i%2 == 0 {
+			stat.Error = errors.New("failed")
+		}
+		h.add(stat)
+	}
+	h.addMissed(start.Add(time.Hour))
+	h.addMissed(start)
+
+	stats := h.stats()
+	require.Len(t, stats.Evaluations, evaluationStatsSize)
+	for i, stat := range stats.Evaluations {
+		require.Equal(t, start.Add(time.Duration(evaluationStatsSize+4-i)*time.Minute), stat.ScheduledAt)
+	}
+	require.Len(t, stats.Errors, evaluationErrorsSize)
+	for i, stat := range stats.Errors {
+		require.Equal(t, start.Add(time.Duration(evaluationStatsSize+4-2*i)*time.Minute), stat.ScheduledAt)
+		require.Error(t, stat.Error)
+	}
+	require.EqualValues(t, 2, stats.Missed)
+	require.Equal(t, start.Add(time.Hour), stats.LastMissed)
+}
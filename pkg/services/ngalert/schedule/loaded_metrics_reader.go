@@ -33,7 +33,7 @@ func (n AlertingResultsFromRuleState) Read() map[data.Fingerprint]struct{} {
 
 	active := map[data.Fingerprint]struct{}{}
 	for _, st := range states {
-		if st.StateReason != "" {
+		if st.StateReason != "" && st.StateReason != ngmodels.StateReasonInhibited {
 			continue
 		}
 		if st.State == eval.Alerting || st.State == eval.Pending {
@@ -0,0 +1,254 @@
+package schedule
+
+import (
+	context "context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// recordingRule is a Rule that evaluates the queries and expressions of a recording rule
+// and writes the result to a time series database as a new metric.
+type recordingRule struct {
+	evalCh chan *Evaluation
+	ctx    context.Context
+	stopFn util.CancelCauseFunc
+
+	maxAttempts int64
+
+	clock       clock.Clock
+	evalFactory eval.EvaluatorFactory
+	writer      RecordingWriter
+	history     *evaluationHistory
+
+	// Event hooks that are only used in tests.
+	evalAppliedHook evalAppliedFunc
+	stopAppliedHook stopAppliedFunc
+
+	metrics *metrics.Scheduler
+	logger  log.Logger
+	tracer  tracing.Tracer
+}
+
+func newRecordingRule(
+	parent context.Context,
+	maxAttempts int64,
+	evalFactory eval.EvaluatorFactory,
+	writer RecordingWriter,
+	clock clock.Clock,
+	met *metrics.Scheduler,
+	logger log.Logger,
+	tracer tracing.Tracer,
+	evalAppliedHook evalAppliedFunc,
+	stopAppliedHook stopAppliedFunc,
+) *recordingRule {
+	ctx, stop := util.WithCancelCause(parent)
+	return &recordingRule{
+		evalCh:          make(chan *Evaluation),
+		ctx:             ctx,
+		stopFn:          stop,
+		maxAttempts:     maxAttempts,
+		clock:           clock,
+		evalFactory:     evalFactory,
+		writer:          writer,
+		history:         newEvaluationHistory(),
+		evalAppliedHook: evalAppliedHook,
+		stopAppliedHook: stopAppliedHook,
+		metrics:         met,
+		logger:          logger,
+		tracer:          tracer,
+	}
+}
+
+func (r *recordingRule) Type() ngmodels.RuleType {
+	return ngmodels.RuleTypeRecording
+}
+
+func (r *recordingRule) EvaluationStats() EvaluationStats {
+	return r.history.stats()
+}
+
+// Eval signals the rule evaluation routine to perform the evaluation of the rule. Does nothing if the loop is stopped.
+// See alertRule.Eval for details about the returned values.
+func (r *recordingRule) Eval(eval *Evaluation) (bool, *Evaluation) {
+	// read the channel in unblocking manner to make sure that there is no concurrent send operation.
+	var droppedMsg *Evaluation
+	select {
+	case droppedMsg = <-r.evalCh:
+	default:
+	}
+
+	if droppedMsg != nil {
+		r.history.addMissed(droppedMsg.scheduledAt)
+	}
+
+	select {
+	case r.evalCh <- eval:
+		return true, droppedMsg
+	case <-r.ctx.Done():
+		return false, droppedMsg
+	}
+}
+
+// Update does nothing because recording rules do not have a state that needs to be reset when the rule changes.
+// Every evaluation uses the version of the rule it was scheduled with.
+func (r *recordingRule) Update(_ RuleVersionAndPauseStatus) bool {
+	return r.ctx.Err() == nil
+}
+
+// Stop shuts down the rule's evaluation routine with an optional reason.
+func (r *recordingRule) Stop(reason error) {
+	if r.stopFn != nil {
+		r.stopFn(reason)
+	}
+}
+
+func (r *recordingRule) Run(key ngmodels.AlertRuleKey) error {
+	ctx := ngmodels.WithRuleKey(r.ctx, key)
+	logger := r.logger.FromContext(ctx)
+	logger.Debug("Recording rule routine started")
+	defer r.stopApplied(key)
+
+	for {
+		select {
+		case e, ok := <-r.evalCh:
+			if !ok {
+				logger.Debug("Evaluation channel has been closed. Exiting")
+				return nil
+			}
+			r.doEvaluate(ctx, key, e)
+			r.evalApplied(key, e.scheduledAt)
+		case <-ctx.Done():
+			logger.Debug("Stopping recording rule routine")
+			return nil
+		}
+	}
+}
+
+func (r *recordingRule) doEvaluate(ctx context.Context, key ngmodels.AlertRuleKey, e *Evaluation) {
+	logger := r.logger.FromContext(ctx).New("version", e.rule.Version, "now", e.scheduledAt)
+	if e.rule.IsPaused {
+		logger.Debug("Skip rule evaluation because it is paused")
+		return
+	}
+
+	for attempt := int64(1); attempt <= r.maxAttempts; attempt++ {
+		tracingCtx, span := r.tracer.Start(ctx, "recording rule execution", trace.WithAttributes(
+			attribute.String("rule_uid", e.rule.UID),
+			attribute.Int64("org_id", e.rule.OrgID),
+			attribute.Int64("rule_version", e.rule.Version),
+			attribute.String("tick", e.scheduledAt.UTC().Format(time.RFC3339Nano)),
+		))
+		if tracingCtx.Err() != nil {
+			span.SetStatus(codes.Error, "rule evaluation cancelled")
+			span.End()
+			logger.Error("Skip evaluation because the context has been cancelled", "attempt", attempt)
+			return
+		}
+
+		err := r.tryEvaluation(tracingCtx, key, e, attempt, logger)
+		if err == nil {
+			span.End()
+			return
+		}
+		span.SetStatus(codes.Error, "rule evaluation failed")
+		span.RecordError(err)
+		span.End()
+
+		logger.Error("Failed to evaluate recording rule", "attempt", attempt, "error", err)
+		if attempt == r.maxAttempts {
+			return
+		}
+		select {
+		case <-tracingCtx.Done():
+			logger.Error("Context has been cancelled while backing off", "attempt", attempt)
+			return
+		case <-time.After(retryDelay):
+			continue
+		}
+	}
+}
+
+func (r *recordingRule) tryEvaluation(ctx context.Context, key ngmodels.AlertRuleKey, e *Evaluation, attempt int64, logger log.Logger) error {
+	orgID := fmt.Sprint(key.OrgID)
+	evalTotal := r.metrics.EvalTotal.WithLabelValues(orgID)
+	evalDuration := r.metrics.EvalDuration.WithLabelValues(orgID)
+	evalTotalFailures := r.metrics.EvalFailures.WithLabelValues(orgID)
+
+	start := r.clock.Now()
+	evalCtx := eval.NewContext(ctx, SchedulerUserFor(e.rule.OrgID))
+	condition := e.rule.GetEvalCondition()
+	ruleEval, err := r.evalFactory.Create(evalCtx, condition)
+	if err != nil {
+		evalTotal.Inc()
+		evalTotalFailures.Inc()
+		err = fmt.Errorf("failed to build rule evaluator: %w", err)
+		r.history.add(EvaluationStat{ScheduledAt: e.scheduledAt, Attempt: attempt, Duration: r.clock.Now().Sub(start), Error: err})
+		return err
+	}
+
+	stats := &expr.ExecutionStats{}
+	resp, err := ruleEval.EvaluateRaw(expr.WithExecutionStats(ctx, stats), e.scheduledAt)
+	dur := r.clock.Now().Sub(start)
+	evalTotal.Inc()
+	evalDuration.Observe(dur.Seconds())
+	if err == nil && resp == nil {
+		err = errors.New("evaluation returned no response")
+	}
+	if err == nil {
+		result, ok := resp.Responses[condition.Condition]
+		switch {
+		case !ok:
+			err = fmt.Errorf("no result for the recorded query or expression %s", condition.Condition)
+		case result.Error != nil:
+			err = fmt.Errorf("the recorded query or expression %s returned an error: %w", condition.Condition, result.Error)
+		default:
+			logger.Debug("Recording rule evaluated", "frames", len(result.Frames), "duration", dur)
+			err = r.writer.Write(ctx, e.rule.Record.Metric, e.scheduledAt, result.Frames, e.rule.Labels)
+			if err != nil {
+				err = fmt.Errorf("failed to write the result of the recording rule: %w", err)
+			}
+		}
+	}
+	stat := EvaluationStat{ScheduledAt: e.scheduledAt, Attempt: attempt, Duration: dur, Nodes: stats.Nodes(), Error: err}
+	for _, node := range stat.Nodes {
+		if node.RefID == condition.Condition {
+			stat.Series = node.Series
+		}
+	}
+	r.history.add(stat)
+	if err != nil {
+		evalTotalFailures.Inc()
+		return err
+	}
+	return nil
+}
+
+// evalApplied is only used on tests.
+func (r *recordingRule) evalApplied(key ngmodels.AlertRuleKey, now time.Time) {
+	if r.evalAppliedHook == nil {
+		return
+	}
+	r.evalAppliedHook(key, now)
+}
+
+// stopApplied is only used on tests.
+func (r *recordingRule) stopApplied(key ngmodels.AlertRuleKey) {
+	if r.stopAppliedHook == nil {
+		return
+	}
+	r.stopAppliedHook(key)
+}
@@ -0,0 +1,155 @@
+package schedule
+
+import (
+	context "context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+	models "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestRecordingRule(t *testing.T) {
+	createSchedule := func(writer *fakeRecordingWriter) (*schedule, chan time.Time) {
+		evalAppliedChan := make(chan time.Time)
+		sch := setupScheduler(t, newFakeRulesStore(), nil, prometheus.NewPedanticRegistry(), nil, nil)
+		sch.evalAppliedFunc = func(key models.AlertRuleKey, t time.Time) {
+			evalAppliedChan <- t
+		}
+		sch.recordingWriter = writer
+		return sch, evalAppliedChan
+	}
+
+	withExpression := func(expression string) models.AlertRuleMutator {
+		return func(rule *models.AlertRule) {
+			rule.Condition = ""
+			rule.Data = []models.AlertQuery{
+				{
+					RefID:         "A",
+					DatasourceUID: expr.DatasourceUID,
+					Model:         json.RawMessage(`{"datasourceUid": "__expr__", "type": "math", "expression": "` + expression + `"}`),
+					RelativeTimeRange: models.RelativeTimeRange{
+						From: models.Duration(5 * time.Hour),
+						To:   models.Duration(3 * time.Hour),
+					},
+				},
+			}
+		}
+	}
+
+	t.Run("factory should create recording rule routine for recording rules", func(t *testing.T) {
+		sch, _ := createSchedule(&fakeRecordingWriter{})
+		factory := ruleFactoryFromScheduler(sch)
+
+		rule := models.AlertRuleGen(withExpression("2 + 2"), models.WithRecord("test_metric", "A"))()
+		routine := factory.new(context.Background(), rule)
+		require.IsType(t, &recordingRule{}, routine)
+		require.Equal(t, models.RuleTypeRecording, routine.Type())
+
+		rule = models.AlertRuleGen(withExpression("2 + 2"))()
+		routine = factory.new(context.Background(), rule)
+		require.IsType(t, &alertRule{}, routine)
+		require.Equal(t, models.RuleTypeAlerting, routine.Type())
+	})
+
+	t.Run("should write the result of the recorded expression", func(t *testing.T) {
+		writer := &fakeRecordingWriter{}
+		sch, evalAppliedChan := createSchedule(writer)
+		rule := models.AlertRuleGen(withExpression("2 + 2"), models.WithRecord("test_metric", "A"), models.WithLabels(map[string]string{"team": "a"}))()
+		rule.IsPaused = false
+
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		routine := ruleFactoryFromScheduler(sch).new(ctx, rule)
+		go func() {
+			_ = routine.Run(rule.GetKey())
+		}()
+
+		expectedTime := time.UnixMicro(time.Now().UnixMicro())
+		routine.Eval(&Evaluation{
+			scheduledAt: expectedTime,
+			rule:        rule,
+		})
+		actualTime := waitForTimeChannel(t, evalAppliedChan)
+		require.Equal(t, expectedTime, actualTime)
+
+		writes := writer.Writes()
+		require.Len(t, writes, 1)
+		require.Equal(t, "test_metric", writes[0].Name)
+		require.Equal(t, expectedTime, writes[0].T)
+		require.Equal(t, map[string]string{"team": "a"}, writes[0].ExtraLabels)
+		require.Len(t, writes[0].Frames, 1)
+		v, ok := writes[0].Frames[0].Fields[0].ConcreteAt(0)
+		require.True(t, ok)
+		require.Equal(t, 4.0, v)
+	})
+
+	t.Run("should not write anything when evaluation fails", func(t *testing.T) {
+		writer := &fakeRecordingWriter{}
+		sch, evalAppliedChan := createSchedule(writer)
+		rule := models.AlertRuleGen(withExpression("$B"), models.WithRecord("test_metric", "A"))()
+		rule.IsPaused = false
+
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		routine := ruleFactoryFromScheduler(sch).new(ctx, rule)
+		go func() {
+			_ = routine.Run(rule.GetKey())
+		}()
+
+		routine.Eval(&Evaluation{
+			scheduledAt: sch.clock.Now(),
+			rule:        rule,
+		})
+		waitForTimeChannel(t, evalAppliedChan)
+		require.Empty(t, writer.Writes())
+	})
+
+	t.Run("should retry when writing fails", func(t *testing.T) {
+		writer := &fakeRecordingWriter{err: errors.New("remote write failed")}
+		sch, evalAppliedChan := createSchedule(writer)
+		sch.maxAttempts = 2
+		rule := models.AlertRuleGen(withExpression("2 + 2"), models.WithRecord("test_metric", "A"))()
+		rule.IsPaused = false
+
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		routine := ruleFactoryFromScheduler(sch).new(ctx, rule)
+		go func() {
+			_ = routine.Run(rule.GetKey())
+		}()
+
+		routine.Eval(&Evaluation{
+			scheduledAt: sch.clock.Now(),
+			rule:        rule,
+		})
+		waitForTimeChannel(t, evalAppliedChan)
+		require.Len(t, writer.Writes(), 2)
+	})
+
+	t.Run("should skip evaluation if rule is paused", func(t *testing.T) {
+		writer := &fakeRecordingWriter{}
+		sch, evalAppliedChan := createSchedule(writer)
+		rule := models.AlertRuleGen(withExpression("2 + 2"), models.WithRecord("test_metric", "A"))()
+		rule.IsPaused = true
+
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		routine := ruleFactoryFromScheduler(sch).new(ctx, rule)
+		go func() {
+			_ = routine.Run(rule.GetKey())
+		}()
+
+		routine.Eval(&Evaluation{
+			scheduledAt: sch.clock.Now(),
+			rule:        rule,
+		})
+		waitForTimeChannel(t, evalAppliedChan)
+		require.Empty(t, writer.Writes())
+	})
+}
@@ -15,10 +15,14 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 )
 
-var errRuleDeleted = errors.New("rule deleted")
+var (
+	errRuleDeleted     = errors.New("rule deleted")
+	errRuleTypeChanged = errors.New("rule type changed")
+	errRuleNotOwned    = errors.New("rule is evaluated by another instance")
+)
 
 type ruleFactory interface {
-	new(context.Context) Rule
+	new(context.Context, *models.AlertRule) Rule
 }
 
 type ruleRegistry struct {
@@ -30,20 +34,29 @@ func newRuleRegistry() ruleRegistry {
 	return ruleRegistry{rules: make(map[models.AlertRuleKey]Rule)}
 }
 
-// getOrCreate gets rule routine from registry by the key. If it does not exist, it creates a new one.
+// getOrCreate gets rule routine from registry by the key of the given rule. If it does not exist, it creates a new one.
 // Returns a pointer to the rule routine and a flag that indicates whether it is a new struct or not.
-func (r *ruleRegistry) getOrCreate(context context.Context, key models.AlertRuleKey, factory ruleFactory) (Rule, bool) {
+func (r *ruleRegistry) getOrCreate(context context.Context, item *models.AlertRule, factory ruleFactory) (Rule, bool) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	key := item.GetKey()
 	rule, ok := r.rules[key]
 	if !ok {
-		rule = factory.new(context)
+		rule = factory.new(context, item)
 		r.rules[key] = rule
 	}
 	return rule, !ok
 }
 
+func (r *ruleRegistry) get(key models.AlertRuleKey) (Rule, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rule, ok := r.rules[key]
+	return rule, ok
+}
+
 func (r *ruleRegistry) exists(key models.AlertRuleKey) bool {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -301,6 +314,7 @@ func (r ruleWithFolder) Fingerprint() fingerprint {
 	writeInt(rule.OrgID)
 	writeInt(rule.IntervalSeconds)
 	writeInt(int64(rule.For))
+	writeInt(int64(rule.KeepFiringFor))
 	writeLabels(rule.Annotations)
 	if rule.DashboardUID != nil {
 		writeString(*rule.DashboardUID)
@@ -312,5 +326,15 @@ func (r ruleWithFolder) Fingerprint() fingerprint {
 	writeInt(int64(rule.RuleGroupIndex))
 	writeString(string(rule.NoDataState))
 	writeString(string(rule.ExecErrState))
+	if rule.Record != nil {
+		writeString(rule.Record.Metric)
+		writeString(rule.Record.From)
+	}
+	for _, inhibition := range rule.InhibitedBy {
+		writeString(inhibition.RuleUID)
+		for _, l := range inhibition.Equal {
+			writeString(l)
+		}
+	}
 	return fingerprint(sum.Sum64())
 }
@@ -189,6 +189,8 @@ func TestRuleWithFolderFingerprint(t *testing.T) {
 			NoDataState:     "test-nodata",
 			ExecErrState:    "test-err",
 			For:             12,
+			KeepFiringFor:   21,
+			InhibitedBy:     []models.RuleInhibition{{RuleUID: "test-inhibiting-uid", Equal: []string{"test-label"}}},
 			Annotations: map[string]string{
 				"key-annotation": "value-annotation",
 			},
@@ -199,6 +201,10 @@ func TestRuleWithFolderFingerprint(t *testing.T) {
 			NotificationSettings: []models.NotificationSettings{
 				models.NotificationSettingsGen()(),
 			},
+			Record: &models.Record{
+				Metric: "test_metric",
+				From:   "A",
+			},
 		}
 		r2 := &models.AlertRule{
 			ID:        2,
@@ -227,6 +233,8 @@ func TestRuleWithFolderFingerprint(t *testing.T) {
 			NoDataState:     "test-nodata2",
 			ExecErrState:    "test-err2",
 			For:             1141,
+			KeepFiringFor:   2214,
+			InhibitedBy:     []models.RuleInhibition{{RuleUID: "test-inhibiting-uid2", Equal: []string{"test-label2"}}},
 			Annotations: map[string]string{
 				"key-annotation2": "value-annotation",
 			},
@@ -237,6 +245,10 @@ func TestRuleWithFolderFingerprint(t *testing.T) {
 			NotificationSettings: []models.NotificationSettings{
 				models.NotificationSettingsGen()(),
 			},
+			Record: &models.Record{
+				Metric: "test_metric_2",
+				From:   "B",
+			},
 		}
 
 		excludedFields := map[string]struct{}{
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -16,6 +17,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
 	"github.com/grafana/grafana/pkg/util/ticker"
 )
 
@@ -25,6 +27,8 @@ type ScheduleService interface {
 	// Run the scheduler until the context is canceled or the scheduler returns
 	// an error. The scheduler is terminated when this function returns.
 	Run(context.Context) error
+	// OwnsAlertRule returns true if the alert rule is evaluated by this instance of the cluster.
+	OwnsAlertRule(key ngmodels.AlertRuleKey) bool
 }
 
 // retryDelay represents how long to wait between each failed rule evaluation.
@@ -37,6 +41,11 @@ type AlertsSender interface {
 	Send(ctx context.Context, key ngmodels.AlertRuleKey, alerts definitions.PostableAlerts)
 }
 
+// RecordingWriter is an interface for a service that writes the results of recording rules to a time series database.
+type RecordingWriter interface {
+	Write(ctx context.Context, name string, t time.Time, frames data.Frames, extraLabels map[string]string) error
+}
+
 // RulesStore is a store that provides alert rules for scheduling
 type RulesStore interface {
 	GetAlertRulesKeysForScheduling(ctx context.Context) ([]ngmodels.AlertRuleKeyWithVersion, error)
@@ -81,12 +90,20 @@ type schedule struct {
 	alertsSender    AlertsSender
 	minRuleInterval time.Duration
 
+	recordingWriter RecordingWriter
+
 	// schedulableAlertRules contains the alert rules that are considered for
 	// evaluation in the current tick. The evaluation of an alert rule in the
 	// current tick depends on its evaluation interval and when it was
 	// last evaluated.
 	schedulableAlertRules alertRulesRegistry
 
+	// sharder decides which rules are evaluated by this instance. It is nil if the evaluation is not sharded.
+	sharder *ruleSharder
+	// ticked is true once the first tick has been processed. The state of the rules that are evaluated
+	// from the first tick is loaded by the state manager on startup.
+	ticked bool
+
 	tracer tracing.Tracer
 }
 
@@ -103,8 +120,11 @@ type SchedulerCfg struct {
 	RuleStore            RulesStore
 	Metrics              *metrics.Scheduler
 	AlertSender          AlertsSender
-	Tracer               tracing.Tracer
-	Log                  log.Logger
+	RecordingWriter      RecordingWriter
+	// ClusterMembership, if set, shards the evaluation of alert rules across the members of the cluster.
+	ClusterMembership ClusterMembership
+	Tracer            tracing.Tracer
+	Log               log.Logger
 }
 
 // NewScheduler returns a new scheduler.
@@ -115,6 +135,10 @@ func NewScheduler(cfg SchedulerCfg, stateManager *state.Manager) *schedule {
 		cfg.MaxAttempts = minMaxAttempts
 	}
 
+	if cfg.RecordingWriter == nil {
+		cfg.RecordingWriter = writer.NoopWriter{}
+	}
+
 	sch := schedule{
 		registry:              newRuleRegistry(),
 		maxAttempts:           cfg.MaxAttempts,
@@ -131,8 +155,12 @@ func NewScheduler(cfg SchedulerCfg, stateManager *state.Manager) *schedule {
 		minRuleInterval:       cfg.MinRuleInterval,
 		schedulableAlertRules: alertRulesRegistry{rules: make(map[ngmodels.AlertRuleKey]*ngmodels.AlertRule)},
 		alertsSender:          cfg.AlertSender,
+		recordingWriter:       cfg.RecordingWriter,
 		tracer:                cfg.Tracer,
 	}
+	if cfg.ClusterMembership != nil {
+		sch.sharder = newRuleSharder(cfg.ClusterMembership, cfg.Metrics, cfg.Log)
+	}
 
 	return &sch
 }
@@ -155,6 +183,16 @@ func (sch *schedule) Rules() ([]*ngmodels.AlertRule, map[ngmodels.FolderKey]stri
 	return sch.schedulableAlertRules.all()
 }
 
+// OwnsAlertRule returns true if the alert rule is evaluated by this instance of the cluster. It is always true if
+// the evaluation is not sharded.
+func (sch *schedule) OwnsAlertRule(key ngmodels.AlertRuleKey) bool {
+	if sch.sharder == nil {
+		return true
+	}
+	sch.sharder.refresh()
+	return sch.sharder.owns(key)
+}
+
 // deleteAlertRule stops evaluation of the rule, deletes it from active rules, and cleans up state cache.
 func (sch *schedule) deleteAlertRule(keys ...ngmodels.AlertRuleKey) {
 	for _, key := range keys {
@@ -232,6 +270,12 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 
 	sch.updateRulesMetrics(alertRules)
 
+	// rules that are evaluated by another instance of the cluster.
+	notOwned := make(map[ngmodels.AlertRuleKey]struct{})
+	if sch.sharder != nil {
+		sch.sharder.refresh()
+	}
+
 	readyToRun := make([]readyToRunItem, 0)
 	updatedRules := make([]ngmodels.AlertRuleKeyWithVersion, 0, len(updated)) // this is needed for tests only
 	missingFolder := make(map[string][]string)
@@ -243,6 +287,7 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		sch.stateManager,
 		sch.evaluatorFactory,
 		&sch.schedulableAlertRules,
+		sch.recordingWriter,
 		sch.clock,
 		sch.metrics,
 		sch.log,
@@ -250,9 +295,24 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		sch.evalAppliedFunc,
 		sch.stopAppliedFunc,
 	)
+	owned := 0
 	for _, item := range alertRules {
 		key := item.GetKey()
-		ruleRoutine, newRoutine := sch.registry.getOrCreate(ctx, key, ruleFactory)
+		if sch.sharder != nil && !sch.sharder.owns(key) {
+			notOwned[key] = struct{}{}
+			continue
+		}
+		owned++
+		ruleRoutine, newRoutine := sch.registry.getOrCreate(ctx, item, ruleFactory)
+
+		// the rule was converted from alerting to recording rule or vice versa. Stop the old routine and start the new one.
+		if !newRoutine && ruleRoutine.Type() != item.Type() {
+			sch.log.Debug("Rule type has changed. Restarting evaluation routine", append(key.LogContext(), "oldType", ruleRoutine.Type(), "newType", item.Type())...)
+			if oldRoutine, ok := sch.registry.del(key); ok {
+				oldRoutine.Stop(errRuleTypeChanged)
+			}
+			ruleRoutine, newRoutine = sch.registry.getOrCreate(ctx, item, ruleFactory)
+		}
 
 		// enforce minimum evaluation interval
 		if item.IntervalSeconds < int64(sch.minRuleInterval.Seconds()) {
@@ -263,7 +323,15 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		invalidInterval := item.IntervalSeconds%int64(sch.baseInterval.Seconds()) != 0
 
 		if newRoutine && !invalidInterval {
+			// The rule is handed over by another instance of the cluster, so its state is loaded before it is evaluated.
+			loadState := sch.sharder != nil && sch.ticked && item.Type() == ngmodels.RuleTypeAlerting
+			rule := item
 			dispatcherGroup.Go(func() error {
+				if loadState {
+					if err := sch.stateManager.LoadStateByRule(ngmodels.WithRuleKey(ctx, key), rule); err != nil {
+						sch.log.Error("Failed to load the state of the rule handed over by another instance", append(key.LogContext(), "error", err)...)
+					}
+				}
 				return ruleRoutine.Run(key)
 			})
 		}
@@ -343,11 +411,42 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		})
 	}
 
+	// unregister and stop routines of the alert rules that are now evaluated by another instance of the cluster.
+	// Their state is kept in the database for the new owner to load. On the first tick, the state of all rules that
+	// are not owned is dropped too, since the cache could be warmed up before this instance joined the cluster.
+	handedOver := make([]ngmodels.AlertRuleKey, 0)
+	for key := range notOwned {
+		if _, ok := registeredDefinitions[key]; ok || !sch.ticked {
+			handedOver = append(handedOver, key)
+		}
+	}
+	sch.handOverAlertRule(ctx, handedOver...)
+
 	// unregister and stop routines of the deleted alert rules
 	toDelete := make([]ngmodels.AlertRuleKey, 0, len(registeredDefinitions))
 	for key := range registeredDefinitions {
+		if _, ok := notOwned[key]; ok {
+			continue
+		}
 		toDelete = append(toDelete, key)
 	}
 	sch.deleteAlertRule(toDelete...)
+	if sch.sharder != nil {
+		sch.metrics.ShardOwnedAlertRules.Set(float64(owned))
+	}
+	sch.ticked = true
 	return readyToRun, registeredDefinitions, updatedRules
 }
+
+// handOverAlertRule stops evaluation of the rules that are evaluated by another instance of the cluster and removes
+// their state from the cache. Unlike deleteAlertRule, the rules are kept in the active rules and their state is
+// neither deleted from the database nor resolved.
+func (sch *schedule) handOverAlertRule(ctx context.Context, keys ...ngmodels.AlertRuleKey) {
+	for _, key := range keys {
+		if ruleRoutine, ok := sch.registry.del(key); ok {
+			sch.log.Debug("Alert rule is handed over to another instance", key.LogContext()...)
+			ruleRoutine.Stop(errRuleNotOwned)
+		}
+		sch.stateManager.ForgetStateByRuleUID(ngmodels.WithRuleKey(ctx, key), key)
+	}
+}
@@ -363,7 +363,7 @@ func TestSchedule_deleteAlertRule(t *testing.T) {
 			ruleFactory := ruleFactoryFromScheduler(sch)
 			rule := models.AlertRuleGen()()
 			key := rule.GetKey()
-			info, _ := sch.registry.getOrCreate(context.Background(), key, ruleFactory)
+			info, _ := sch.registry.getOrCreate(context.Background(), rule, ruleFactory)
 			sch.deleteAlertRule(key)
 			require.ErrorIs(t, info.(*alertRule).ctx.Err(), errRuleDeleted)
 			require.False(t, sch.registry.exists(key))
@@ -548,3 +548,33 @@ func assertStopRun(t *testing.T, ch <-chan models.AlertRuleKey, keys ...models.A
 		}
 	}
 }
+
+func TestSchedule_ruleTypeChanged(t *testing.T) {
+	ruleStore := newFakeRulesStore()
+	sch := setupScheduler(t, ruleStore, nil, nil, nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	dispatcherGroup, ctx := errgroup.WithContext(ctx)
+
+	rule := models.AlertRuleGen(withQueryForState(t, eval.Normal), models.WithInterval(time.Second))()
+	key := rule.GetKey()
+	ruleStore.PutRule(ctx, rule)
+
+	tick := time.Time{}.Add(time.Second)
+	sch.processTick(ctx, dispatcherGroup, tick)
+	oldRoutine, ok := sch.registry.rules[key]
+	require.True(t, ok)
+	require.Equal(t, models.RuleTypeAlerting, oldRoutine.Type())
+
+	recording := models.CopyRule(rule)
+	recording.Version++
+	recording.Record = &models.Record{Metric: "test_metric", From: rule.Condition}
+	ruleStore.PutRule(ctx, recording)
+
+	tick = tick.Add(time.Second)
+	sch.processTick(ctx, dispatcherGroup, tick)
+	newRoutine, ok := sch.registry.rules[key]
+	require.True(t, ok)
+	require.Equal(t, models.RuleTypeRecording, newRoutine.Type())
+	require.ErrorIs(t, oldRoutine.(*alertRule).ctx.Err(), errRuleTypeChanged)
+}
@@ -0,0 +1,148 @@
+package schedule
+
+import (
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// ringTokensPerMember is the number of virtual nodes each member gets in the hash ring.
+// More tokens give a more even distribution of the rules across the members.
+const ringTokensPerMember = 128
+
+// ClusterMembership provides the live members of the cluster that the evaluation of alert rules is sharded across.
+type ClusterMembership interface {
+	// ClusterMembers returns the name of this instance and the names of all live instances in the cluster, including this one.
+	ClusterMembers() (self string, members []string)
+}
+
+// hashRing is a consistent hash ring that maps keys to members.
+// Adding or removing a member only moves the keys of that member.
+type hashRing struct {
+	tokens []uint64
+	owners []string
+}
+
+func newHashRing(members []string) hashRing {
+	type token struct {
+		hash  uint64
+		owner string
+	}
+	all := make([]token, 0, len(members)*ringTokensPerMember)
+	for _, m := range members {
+		for i := 0; i < ringTokensPerMember; i++ {
+			all = append(all, token{hash: hashString(m + "-" + strconv.Itoa(i)), owner: m})
+		}
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].hash == all[j].hash {
+			return all[i].owner < all[j].owner
+		}
+		return all[i].hash < all[j].hash
+	})
+	r := hashRing{
+		tokens: make([]uint64, 0, len(all)),
+		owners: make([]string, 0, len(all)),
+	}
+	for _, t := range all {
+		r.tokens = append(r.tokens, t.hash)
+		r.owners = append(r.owners, t.owner)
+	}
+	return r
+}
+
+// owner returns the member that owns the key, that is the member of the first token after the hash of the key.
+func (r hashRing) owner(key string) string {
+	if len(r.tokens) == 0 {
+		return ""
+	}
+	h := hashString(key)
+	i := sort.Search(len(r.tokens), func(i int) bool { return r.tokens[i] >= h })
+	if i == len(r.tokens) {
+		i = 0
+	}
+	return r.owners[i]
+}
+
+// hashString returns the FNV-1a hash of the string, mixed with the finalizer of MurmurHash3
+// to spread similar strings across the ring.
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+// ruleSharder decides which alert rules are evaluated by this instance when the evaluation is sharded across
+// the members of the cluster.
+type ruleSharder struct {
+	membership ClusterMembership
+
+	// mtx guards the fields below, since the state manager asks for the owned rules outside of the scheduler loop.
+	mtx     sync.RWMutex
+	self    string
+	members []string
+	ring    hashRing
+	// owningAll is true if this instance evaluates all rules because it cannot find itself in the cluster.
+	owningAll bool
+
+	metrics *metrics.Scheduler
+	log     log.Logger
+}
+
+func newRuleSharder(membership ClusterMembership, metrics *metrics.Scheduler, logger log.Logger) *ruleSharder {
+	return &ruleSharder{
+		membership: membership,
+		owningAll:  true,
+		metrics:    metrics,
+		log:        logger,
+	}
+}
+
+// refresh fetches the members of the cluster and rebuilds the ring if they changed since the last call.
+// If this instance is not a live member of the cluster yet, it evaluates all rules, like without sharding,
+// to make sure that no rule is left without evaluation.
+func (s *ruleSharder) refresh() {
+	self, members := s.membership.ClusterMembers()
+	members = slices.Clone(members)
+	sort.Strings(members)
+	members = slices.Compact(members)
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if self == s.self && slices.Equal(members, s.members) {
+		return
+	}
+	s.self = self
+	s.members = members
+	s.ring = newHashRing(members)
+	s.owningAll = self == "" || !slices.Contains(members, self)
+	s.metrics.ShardMembers.Set(float64(len(members)))
+	if s.owningAll {
+		s.log.Warn("This instance is not a live member of the cluster, evaluating all alert rules", "self", self, "members", members)
+		return
+	}
+	s.log.Info("Cluster members changed, rebalancing alert rules", "self", self, "members", members)
+}
+
+// owns returns true if the rule should be evaluated by this instance.
+func (s *ruleSharder) owns(key ngmodels.AlertRuleKey) bool {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	if s.owningAll {
+		return true
+	}
+	return s.ring.owner(fmt.Sprintf("%d/%s", key.OrgID, key.UID)) == s.self
+}
@@ -0,0 +1,219 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+type fakeClusterMembership struct {
+	mtx     sync.Mutex
+	self    string
+	members []string
+}
+
+func (f *fakeClusterMembership) ClusterMembers() (string, []string) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return f.self, f.members
+}
+
+func (f *fakeClusterMembership) setMembers(members ...string) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.members = members
+}
+
+// listInstancesQueries returns the queries of the instance store that load the state of a rule.
+func listInstancesQueries(store *state.FakeInstanceStore) []any {
+	var result []any
+	for _, op := range store.RecordedOps() {
+		if q, ok := op.(models.ListAlertInstancesQuery); ok {
+			result = append(result, q)
+		}
+	}
+	return result
+}
+
+func TestHashRing(t *testing.T) {
+	keys := make([]string, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		keys = append(keys, fmt.Sprintf("1/rule-%d", i))
+	}
+
+	t.Run("empty ring has no owner", func(t *testing.T) {
+		require.Empty(t, newHashRing(nil).owner("1/rule"))
+	})
+
+	t.Run("keys are distributed across all members", func(t *testing.T) {
+		ring := newHashRing([]string{"a", "b", "c"})
+		counts := map[string]int{}
+		for _, k := range keys {
+			counts[ring.owner(k)]++
+		}
+		require.Len(t, counts, 3)
+		for member, count := range counts {
+			require.Greaterf(t, count, 200, "member %s owns too few keys", member)
+		}
+	})
+
+	t.Run("removing a member only moves the keys of that member", func(t *testing.T) {
+		before := newHashRing([]string{"a", "b", "c"})
+		after := newHashRing([]string{"a", "b"})
+		for _, k := range keys {
+			if owner := before.owner(k); owner != "c" {
+				require.Equal(t, owner, after.owner(k))
+			}
+		}
+	})
+}
+
+func TestRuleSharder(t *testing.T) {
+	m := metrics.NewSchedulerMetrics(prometheus.NewPedanticRegistry())
+	membership := &fakeClusterMembership{self: "a"}
+	sharder := newRuleSharder(membership, m, log.NewNopLogger())
+	rules := models.GenerateAlertRules(100, models.AlertRuleGen())
+
+	t.Run("owns all rules if it is not a member of the cluster", func(t *testing.T) {
+		membership.setMembers("b", "c")
+		sharder.refresh()
+		for _, rule := range rules {
+			require.True(t, sharder.owns(rule.GetKey()))
+		}
+	})
+
+	t.Run("owns part of the rules if it is a member of the cluster", func(t *testing.T) {
+		membership.setMembers("c", "b", "a", "a")
+		sharder.refresh()
+		require.Equal(t, []string{"a", "b", "c"}, sharder.members)
+		owned := 0
+		for _, rule := range rules {
+			if sharder.owns(rule.GetKey()) {
+				owned++
+			}
+		}
+		require.Greater(t, owned, 0)
+		require.Less(t, owned, len(rules))
+	})
+
+	t.Run("owns all rules if it is the only member", func(t *testing.T) {
+		membership.setMembers("a")
+		sharder.refresh()
+		for _, rule := range rules {
+			require.True(t, sharder.owns(rule.GetKey()))
+		}
+	})
+}
+
+func TestProcessTicksWithSharding(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	dispatcherGroup, ctx := errgroup.WithContext(ctx)
+
+	ruleStore := newFakeRulesStore()
+	instanceStore := &state.FakeInstanceStore{}
+	sch := setupScheduler(t, ruleStore, instanceStore, nil, nil, nil)
+	membership := &fakeClusterMembership{self: "a", members: []string{"a", "b"}}
+	sch.sharder = newRuleSharder(membership, sch.metrics, log.NewNopLogger())
+
+	rules := models.GenerateAlertRules(20, models.AlertRuleGen(withQueryForState(t, eval.Normal), models.WithOrgID(1), models.WithInterval(time.Second)))
+	for _, rule := range rules {
+		ruleStore.PutRule(ctx, rule)
+	}
+
+	sch.sharder.refresh()
+	ownedByA := map[models.AlertRuleKey]struct{}{}
+	for _, rule := range rules {
+		if sch.sharder.owns(rule.GetKey()) {
+			ownedByA[rule.GetKey()] = struct{}{}
+		}
+	}
+	require.NotEmpty(t, ownedByA)
+	require.Less(t, len(ownedByA), len(rules))
+
+	// the cache is warmed up with the states of all rules, as if this instance had not joined the cluster yet.
+	for _, rule := range rules {
+		sch.stateManager.ProcessEvalResults(ctx, time.Time{}, rule, eval.Results{{State: eval.Normal}}, nil)
+	}
+	hasState := func(key models.AlertRuleKey) bool {
+		return len(sch.stateManager.GetStatesForRuleUID(key.OrgID, key.UID)) > 0
+	}
+
+	tick := time.Time{}
+
+	t.Run("only rules owned by the instance are evaluated", func(t *testing.T) {
+		tick = tick.Add(time.Second)
+		scheduled, stopped, _ := sch.processTick(ctx, dispatcherGroup, tick)
+		require.Empty(t, stopped)
+		require.Len(t, scheduled, len(ownedByA))
+		for _, item := range scheduled {
+			require.Contains(t, ownedByA, item.rule.GetKey())
+		}
+		for _, rule := range rules {
+			_, owned := ownedByA[rule.GetKey()]
+			require.Equal(t, owned, sch.registry.exists(rule.GetKey()))
+			require.Equal(t, owned, hasState(rule.GetKey()), "the state of the rules that are not owned must be forgotten")
+		}
+		// the state of the rules evaluated from the first tick is loaded on startup.
+		require.Empty(t, listInstancesQueries(instanceStore))
+	})
+
+	t.Run("rules of a member that joins are handed over", func(t *testing.T) {
+		membership.setMembers("a", "b", "c")
+		sch.sharder.refresh()
+		stillOwned := map[models.AlertRuleKey]struct{}{}
+		for key := range ownedByA {
+			if sch.sharder.owns(key) {
+				stillOwned[key] = struct{}{}
+			}
+		}
+		require.Less(t, len(stillOwned), len(ownedByA))
+
+		tick = tick.Add(time.Second)
+		scheduled, stopped, _ := sch.processTick(ctx, dispatcherGroup, tick)
+		require.Len(t, scheduled, len(stillOwned))
+		require.Len(t, stopped, len(ownedByA)-len(stillOwned))
+		for key := range stopped {
+			require.NotContains(t, stillOwned, key)
+			require.False(t, sch.registry.exists(key))
+			require.NotNil(t, sch.schedulableAlertRules.get(key), "handed over rules must not be deleted")
+			require.False(t, hasState(key), "the state of handed over rules must be forgotten")
+		}
+		for key := range stillOwned {
+			require.True(t, hasState(key))
+		}
+		ownedByA = stillOwned
+	})
+
+	t.Run("the state of the rules that are taken over is loaded", func(t *testing.T) {
+		membership.setMembers("a")
+		tick = tick.Add(time.Second)
+		scheduled, stopped, _ := sch.processTick(ctx, dispatcherGroup, tick)
+		require.Empty(t, stopped)
+		require.Len(t, scheduled, len(rules))
+
+		expected := make([]any, 0, len(rules)-len(ownedByA))
+		for _, rule := range rules {
+			if _, ok := ownedByA[rule.GetKey()]; ok {
+				continue
+			}
+			expected = append(expected, models.ListAlertInstancesQuery{RuleOrgID: rule.OrgID, RuleUID: rule.UID})
+		}
+		require.Eventually(t, func() bool {
+			return len(listInstancesQueries(instanceStore)) == len(expected)
+		}, 5*time.Second, 10*time.Millisecond)
+		require.ElementsMatch(t, expected, listInstancesQueries(instanceStore))
+	})
+}
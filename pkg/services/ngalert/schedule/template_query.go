@@ -0,0 +1,236 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/timestamp"
+	"github.com/prometheus/prometheus/promql"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// templateQueriesTimeout is the maximum duration of all queries of the `query` function of the templates of an
+// evaluation, so that slow queries cannot delay the evaluation of the rule by more than this duration.
+const templateQueriesTimeout = 10 * time.Second
+
+const (
+	templateQueryRefID  = "A"
+	templateReduceRefID = "B"
+)
+
+// templateQuery is the query of the `query` function of templates. It is either an expression for the first
+// datasource of the rule, or a JSON object with the datasource and the expression or the model of the query.
+// Expressions are only supported by the datasources that have a query model with an expression, such as
+// Prometheus and Loki, other datasources require the model of the query.
+type templateQuery struct {
+	Datasource string          `json:"datasource"`
+	Expr       string          `json:"expr"`
+	Model      json.RawMessage `json:"model"`
+}
+
+// templateQuerier executes the queries of the `query` function of the templates of a rule. The queries are executed
+// with the evaluator of the rule, as the scheduler, and can only use the datasources that the rule queries.
+// The querier is created for each evaluation of the rule. The results and errors are cached, so a query that is
+// used in the templates of all alerts of the rule is executed once, and all queries share the same deadline.
+type templateQuerier struct {
+	evalFactory eval.EvaluatorFactory
+	rule        *ngmodels.AlertRule
+	deadline    time.Time
+
+	mtx     sync.Mutex
+	results map[string]templateQueryResult
+}
+
+type templateQueryResult struct {
+	vector promql.Vector
+	err    error
+}
+
+func newTemplateQuerier(evalFactory eval.EvaluatorFactory, rule *ngmodels.AlertRule) *templateQuerier {
+	return &templateQuerier{
+		evalFactory: evalFactory,
+		rule:        rule,
+		deadline:    time.Now().Add(templateQueriesTimeout),
+		results:     make(map[string]templateQueryResult),
+	}
+}
+
+// Query executes the query at the given time and returns the last value of each series or number of the result.
+func (q *templateQuerier) Query(ctx context.Context, query string, ts time.Time) (promql.Vector, error) {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	result, ok := q.results[query]
+	if !ok {
+		result.vector, result.err = q.execute(ctx, query, ts)
+		q.results[query] = result
+	}
+	return result.vector, result.err
+}
+
+func (q *templateQuerier) execute(ctx context.Context, query string, ts time.Time) (promql.Vector, error) {
+	condition, err := q.condition(query)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithDeadline(ctx, q.deadline)
+	defer cancel()
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("failed to execute query: the queries of the evaluation exceeded the timeout of %s", templateQueriesTimeout)
+	}
+	evaluator, err := q.evalFactory.Create(eval.NewContext(ctx, SchedulerUserFor(q.rule.OrgID)), condition)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+	resp, err := evaluator.EvaluateRaw(ctx, ts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	res, ok := resp.Responses[templateReduceRefID]
+	if !ok {
+		return nil, errors.New("query returned no response")
+	}
+	if res.Error != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", res.Error)
+	}
+	return framesToVector(res.Frames, ts), nil
+}
+
+// condition returns the condition that executes the query against the datasource and reduces each series of
+// the result to its last value.
+func (q *templateQuerier) condition(query string) (ngmodels.Condition, error) {
+	var tq templateQuery
+	if strings.HasPrefix(strings.TrimSpace(query), "{") {
+		if err := json.Unmarshal([]byte(query), &tq); err != nil {
+			return ngmodels.Condition{}, fmt.Errorf("failed to parse query: %w", err)
+		}
+	} else {
+		tq.Expr = query
+	}
+	if tq.Expr == "" && len(tq.Model) == 0 {
+		return ngmodels.Condition{}, errors.New("query requires an expression or a model")
+	}
+
+	// The query can only use a datasource of the rule, so it cannot access a datasource that the author of
+	// the rule has no permission to query.
+	var source *ngmodels.AlertQuery
+	for i := range q.rule.Data {
+		d := &q.rule.Data[i]
+		if expr.NodeTypeFromDatasourceUID(d.DatasourceUID) != expr.TypeDatasourceNode {
+			continue
+		}
+		if tq.Datasource == "" || tq.Datasource == d.DatasourceUID {
+			source = d
+			break
+		}
+	}
+	if source == nil {
+		if tq.Datasource == "" {
+			return ngmodels.Condition{}, errors.New("the rule does not query any datasource")
+		}
+		return ngmodels.Condition{}, fmt.Errorf("datasource %s is not queried by the rule", tq.Datasource)
+	}
+
+	dsQuery := ngmodels.AlertQuery{
+		RefID:             templateQueryRefID,
+		RelativeTimeRange: source.RelativeTimeRange,
+		DatasourceUID:     source.DatasourceUID,
+		Model:             tq.Model,
+	}
+	if len(dsQuery.Model) == 0 {
+		if err := setInstantQueryModel(&dsQuery, source, tq.Expr); err != nil {
+			return ngmodels.Condition{}, err
+		}
+	}
+	reduce, err := json.Marshal(map[string]any{
+		"refId":      templateReduceRefID,
+		"type":       "reduce",
+		"expression": templateQueryRefID,
+		"reducer":    "last",
+		"settings":   map[string]any{"mode": "dropNN"},
+	})
+	if err != nil {
+		return ngmodels.Condition{}, err
+	}
+
+	return ngmodels.Condition{
+		Condition: templateReduceRefID,
+		Data: []ngmodels.AlertQuery{
+			dsQuery,
+			{
+				RefID:         templateReduceRefID,
+				DatasourceUID: expr.DatasourceUID,
+				Model:         reduce,
+			},
+		},
+	}, nil
+}
+
+// setInstantQueryModel sets the model of the instant query of the expression for the datasource of the source query.
+// It fails if the datasource does not support expressions, as its model cannot be built from one.
+func setInstantQueryModel(query *ngmodels.AlertQuery, source *ngmodels.AlertQuery, expression string) error {
+	var ds struct {
+		Datasource struct {
+			Type string `json:"type"`
+		} `json:"datasource"`
+	}
+	// The type is unknown if the model has no datasource, which is rejected below.
+	_ = json.Unmarshal(source.Model, &ds)
+
+	model := map[string]any{
+		"refId":      templateQueryRefID,
+		"expr":       expression,
+		"datasource": map[string]any{"type": ds.Datasource.Type, "uid": source.DatasourceUID},
+	}
+	switch ds.Datasource.Type {
+	case datasources.DS_PROMETHEUS:
+		model["instant"] = true
+		model["range"] = false
+	case datasources.DS_LOKI:
+		model["queryType"] = "instant"
+		query.QueryType = "instant"
+	default:
+		return fmt.Errorf("datasource %s of type %q does not support expressions, the query requires the model of the query", source.DatasourceUID, ds.Datasource.Type)
+	}
+	m, err := json.Marshal(model)
+	if err != nil {
+		return err
+	}
+	query.Model = m
+	return nil
+}
+
+// framesToVector converts the numbers of the reduce expression to samples.
+// Numbers without a value are returned as NaN.
+func framesToVector(frames data.Frames, ts time.Time) promql.Vector {
+	result := make(promql.Vector, 0, len(frames))
+	for _, frame := range frames {
+		for _, field := range frame.Fields {
+			if !field.Type().Numeric() || field.Len() == 0 {
+				continue
+			}
+			f, err := field.FloatAt(0)
+			if err != nil {
+				f = math.NaN()
+			}
+			result = append(result, promql.Sample{
+				T:      timestamp.FromTime(ts),
+				F:      f,
+				Metric: labels.FromMap(field.Labels),
+			})
+		}
+	}
+	return result
+}
@@ -0,0 +1,163 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval/eval_mocks"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type recordingEvaluatorFactory struct {
+	eval.EvaluatorFactory
+	conditions []models.Condition
+}
+
+func (f *recordingEvaluatorFactory) Create(ctx eval.EvaluationContext, condition models.Condition) (eval.ConditionEvaluator, error) {
+	f.conditions = append(f.conditions, condition)
+	return f.EvaluatorFactory.Create(ctx, condition)
+}
+
+func TestTemplateQuerier(t *testing.T) {
+	ts := time.Unix(1000, 0)
+	rule := models.AlertRuleGen(models.WithOrgID(1))()
+	rule.Data = []models.AlertQuery{
+		{RefID: "A", DatasourceUID: "ds-1", RelativeTimeRange: models.RelativeTimeRange{From: 600}, Model: json.RawMessage(`{"datasource": {"type": "prometheus", "uid": "ds-1"}}`)},
+		{RefID: "B", DatasourceUID: "ds-2", RelativeTimeRange: models.RelativeTimeRange{From: 300}, Model: json.RawMessage(`{"datasource": {"type": "grafana-postgresql-datasource", "uid": "ds-2"}}`)},
+		{RefID: "C", DatasourceUID: "ds-3", RelativeTimeRange: models.RelativeTimeRange{From: 300}, Model: json.RawMessage(`{"datasource": {"type": "loki", "uid": "ds-3"}}`)},
+		{RefID: "D", DatasourceUID: expr.DatasourceUID, Model: json.RawMessage(`{}`)},
+	}
+
+	v1, v2 := 1.5, 2.5
+	response := &backend.QueryDataResponse{Responses: backend.Responses{
+		templateReduceRefID: backend.DataResponse{Frames: data.Frames{
+			data.NewFrame("", data.NewField("B", data.Labels{"pod": "a"}, []*float64{&v1})),
+			data.NewFrame("", data.NewField("B", data.Labels{"pod": "b"}, []*float64{&v2})),
+			data.NewFrame("", data.NewField("B", data.Labels{"pod": "c"}, []*float64{nil})),
+		}},
+	}}
+
+	setup := func(t *testing.T, resp *backend.QueryDataResponse, err error) (*templateQuerier, *recordingEvaluatorFactory, *eval_mocks.ConditionEvaluatorMock) {
+		t.Helper()
+		evaluator := &eval_mocks.ConditionEvaluatorMock{}
+		evaluator.EXPECT().EvaluateRaw(mock.Anything, ts).Return(resp, err)
+		factory := &recordingEvaluatorFactory{EvaluatorFactory: eval_mocks.NewEvaluatorFactory(evaluator)}
+		return newTemplateQuerier(factory, rule), factory, evaluator
+	}
+
+	t.Run("executes the expression against the first datasource of the rule", func(t *testing.T) {
+		q, factory, _ := setup(t, response, nil)
+		result, err := q.Query(context.Background(), "up", ts)
+		require.NoError(t, err)
+		require.Len(t, result, 3)
+		require.Equal(t, "a", result[0].Metric.Get("pod"))
+		require.Equal(t, 1.5, result[0].F)
+		require.Equal(t, 2.5, result[1].F)
+		require.True(t, math.IsNaN(result[2].F))
+
+		require.Len(t, factory.conditions, 1)
+		condition := factory.conditions[0]
+		require.Equal(t, templateReduceRefID, condition.Condition)
+		require.Len(t, condition.Data, 2)
+		require.Equal(t, "ds-1", condition.Data[0].DatasourceUID)
+		require.Equal(t, rule.Data[0].RelativeTimeRange, condition.Data[0].RelativeTimeRange)
+		require.JSONEq(t, `{"refId":"A","expr":"up","instant":true,"range":false,"datasource":{"type":"prometheus","uid":"ds-1"}}`, string(condition.Data[0].Model))
+		require.Equal(t, expr.DatasourceUID, condition.Data[1].DatasourceUID)
+	})
+
+	t.Run("results are cached", func(t *testing.T) {
+		q, factory, evaluator := setup(t, response, nil)
+		for i := 0; i < 3; i++ {
+			_, err := q.Query(context.Background(), "up", ts)
+			require.NoError(t, err)
+		}
+		require.Len(t, factory.conditions, 1)
+		evaluator.AssertNumberOfCalls(t, "EvaluateRaw", 1)
+	})
+
+	t.Run("executes the query against another datasource of the rule", func(t *testing.T) {
+		q, factory, _ := setup(t, response, nil)
+		_, err := q.Query(context.Background(), `{"datasource": "ds-2", "model": {"refId": "A", "rawSql": "SELECT 1"}}`, ts)
+		require.NoError(t, err)
+		require.Equal(t, "ds-2", factory.conditions[0].Data[0].DatasourceUID)
+		require.Equal(t, rule.Data[1].RelativeTimeRange, factory.conditions[0].Data[0].RelativeTimeRange)
+		require.JSONEq(t, `{"refId": "A", "rawSql": "SELECT 1"}`, string(factory.conditions[0].Data[0].Model))
+	})
+
+	t.Run("executes the expression as an instant query of Loki", func(t *testing.T) {
+		q, factory, _ := setup(t, response, nil)
+		_, err := q.Query(context.Background(), `{"datasource": "ds-3", "expr": "count_over_time({job=\"api\"}[5m])"}`, ts)
+		require.NoError(t, err)
+		query := factory.conditions[0].Data[0]
+		require.Equal(t, "ds-3", query.DatasourceUID)
+		require.Equal(t, "instant", query.QueryType)
+		require.JSONEq(t, `{"refId":"A","expr":"count_over_time({job=\"api\"}[5m])","queryType":"instant","datasource":{"type":"loki","uid":"ds-3"}}`, string(query.Model))
+	})
+
+	t.Run("fails if the datasource does not support expressions", func(t *testing.T) {
+		q, factory, _ := setup(t, response, nil)
+		_, err := q.Query(context.Background(), `{"datasource": "ds-2", "expr": "SELECT 1"}`, ts)
+		require.ErrorContains(t, err, `datasource ds-2 of type "grafana-postgresql-datasource" does not support expressions`)
+		require.Empty(t, factory.conditions)
+	})
+
+	t.Run("fails if the datasource is not queried by the rule", func(t *testing.T) {
+		q, factory, _ := setup(t, response, nil)
+		_, err := q.Query(context.Background(), `{"datasource": "ds-4", "expr": "up"}`, ts)
+		require.ErrorContains(t, err, "datasource ds-4 is not queried by the rule")
+		_, err = q.Query(context.Background(), `{"datasource": "__expr__", "expr": "up"}`, ts)
+		require.ErrorContains(t, err, "datasource __expr__ is not queried by the rule")
+		require.Empty(t, factory.conditions)
+	})
+
+	t.Run("fails if the query is empty or invalid", func(t *testing.T) {
+		q, _, _ := setup(t, response, nil)
+		_, err := q.Query(context.Background(), "", ts)
+		require.ErrorContains(t, err, "query requires an expression or a model")
+		_, err = q.Query(context.Background(), `{"expr":`, ts)
+		require.ErrorContains(t, err, "failed to parse query")
+	})
+
+	t.Run("fails if the query fails", func(t *testing.T) {
+		q, _, _ := setup(t, nil, errors.New("timeout"))
+		_, err := q.Query(context.Background(), "up", ts)
+		require.ErrorContains(t, err, "failed to execute query: timeout")
+
+		q, _, _ = setup(t, &backend.QueryDataResponse{Responses: backend.Responses{
+			templateReduceRefID: backend.DataResponse{Error: errors.New("bad query")},
+		}}, nil)
+		_, err = q.Query(context.Background(), "up", ts)
+		require.ErrorContains(t, err, "failed to execute query: bad query")
+	})
+
+	t.Run("errors are cached", func(t *testing.T) {
+		q, _, evaluator := setup(t, nil, errors.New("timeout"))
+		for i := 0; i < 3; i++ {
+			_, err := q.Query(context.Background(), "up", ts)
+			require.ErrorContains(t, err, "failed to execute query: timeout")
+		}
+		evaluator.AssertNumberOfCalls(t, "EvaluateRaw", 1)
+	})
+
+	t.Run("queries fail once the deadline of the evaluation is exceeded", func(t *testing.T) {
+		q, _, evaluator := setup(t, response, nil)
+		_, err := q.Query(context.Background(), "up", ts)
+		require.NoError(t, err)
+
+		q.deadline = time.Now().Add(-time.Second)
+		_, err = q.Query(context.Background(), "sum(up)", ts)
+		require.ErrorContains(t, err, "the queries of the evaluation exceeded the timeout")
+		evaluator.AssertNumberOfCalls(t, "EvaluateRaw", 1)
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	definitions "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	mock "github.com/stretchr/testify/mock"
@@ -108,3 +109,29 @@ func (m *SyncAlertsSenderMock) Calls() []mock.Call {
 	defer m.mu.Unlock()
 	return slices.Clone(m.AlertsSenderMock.Calls)
 }
+
+type fakeRecordingWrite struct {
+	Name        string
+	T           time.Time
+	Frames      data.Frames
+	ExtraLabels map[string]string
+}
+
+type fakeRecordingWriter struct {
+	mu     sync.Mutex
+	writes []fakeRecordingWrite
+	err    error
+}
+
+func (w *fakeRecordingWriter) Write(_ context.Context, name string, t time.Time, frames data.Frames, extraLabels map[string]string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writes = append(w.writes, fakeRecordingWrite{Name: name, T: t, Frames: frames, ExtraLabels: extraLabels})
+	return w.err
+}
+
+func (w *fakeRecordingWriter) Writes() []fakeRecordingWrite {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return slices.Clone(w.writes)
+}
@@ -272,6 +272,16 @@ func (c *cache) setAllStates(newStates map[int64]map[string]*ruleStates) {
 	c.states = newStates
 }
 
+// setRuleStates replaces all states of the rule in the cache.
+func (c *cache) setRuleStates(ruleKey ngModels.AlertRuleKey, states map[string]*State) {
+	c.mtxStates.Lock()
+	defer c.mtxStates.Unlock()
+	if _, ok := c.states[ruleKey.OrgID]; !ok {
+		c.states[ruleKey.OrgID] = make(map[string]*ruleStates)
+	}
+	c.states[ruleKey.OrgID][ruleKey.UID] = &ruleStates{states: states}
+}
+
 func (c *cache) set(entry *State) {
 	c.mtxStates.Lock()
 	defer c.mtxStates.Unlock()
@@ -381,6 +391,7 @@ func (c *cache) asInstances(skipNormalState bool) []ngModels.AlertInstance {
 					CurrentStateSince: v2.StartsAt,
 					CurrentStateEnd:   v2.EndsAt,
 					ResultFingerprint: v2.ResultFingerprint.String(),
+					Acknowledgement:   v2.Acknowledgement,
 				})
 			}
 		}
@@ -28,6 +28,7 @@ const (
 )
 
 // StateToPostableAlert converts a state to a model that is accepted by Alertmanager. Annotations and Labels are copied from the state.
+// - if the state just became inhibited, the alert ends at the time of the evaluation, which resolves it
 // - if state has at least one result, a new label '__value_string__' is added to the label set
 // - the alert's GeneratorURL is constructed to point to the alert detail view
 // - if evaluation state is either NoData or Error, the resulting set of labels is changed:
@@ -80,23 +81,29 @@ func StateToPostableAlert(transition StateTransition, appURL *url.URL) *models.P
 		state = transition.PreviousState
 	}
 
-	if state == eval.NoData {
-		return noDataAlert(nL, nA, alertState, urlStr)
-	}
-
-	if state == eval.Error {
-		return errorAlert(nL, nA, alertState, urlStr)
+	var alert *models.PostableAlert
+	switch state {
+	case eval.NoData:
+		alert = noDataAlert(nL, nA, alertState, urlStr)
+	case eval.Error:
+		alert = errorAlert(nL, nA, alertState, urlStr)
+	default:
+		alert = &models.PostableAlert{
+			Annotations: models.LabelSet(nA),
+			StartsAt:    strfmt.DateTime(alertState.StartsAt),
+			EndsAt:      strfmt.DateTime(alertState.EndsAt),
+			Alert: models.Alert{
+				Labels:       models.LabelSet(nL),
+				GeneratorURL: strfmt.URI(urlStr),
+			},
+		}
 	}
 
-	return &models.PostableAlert{
-		Annotations: models.LabelSet(nA),
-		StartsAt:    strfmt.DateTime(alertState.StartsAt),
-		EndsAt:      strfmt.DateTime(alertState.EndsAt),
-		Alert: models.Alert{
-			Labels:       models.LabelSet(nL),
-			GeneratorURL: strfmt.URI(urlStr),
-		},
+	if alertState.InhibitionStarted {
+		// The alert that was sent before the state was inhibited is resolved, so that the Alertmanager stops notifying it.
+		alert.EndsAt = strfmt.DateTime(alertState.LastEvaluationTime)
 	}
+	return alert
 }
 
 // NoDataAlert is a special alert sent by Grafana to the Alertmanager, that indicates we received no data from the datasource.
@@ -145,16 +152,19 @@ func FromStateTransitionToPostableAlerts(firingStates []StateTransition, stateMa
 	ts := time.Now()
 
 	for _, alertState := range firingStates {
+		// the state can be acknowledged concurrently.
+		unlock := stateManager.lockExistingRule(ngModels.AlertRuleKey{OrgID: alertState.OrgID, UID: alertState.AlertRuleUID})
 		if !alertState.NeedsSending(stateManager.ResendDelay) {
+			unlock()
 			continue
 		}
 		alert := StateToPostableAlert(alertState, appURL)
 		alerts.PostableAlerts = append(alerts.PostableAlerts, *alert)
-		if alertState.StateReason == ngModels.StateReasonMissingSeries { // do not put stale state back to state manager
-			continue
+		if alertState.StateReason != ngModels.StateReasonMissingSeries { // do not put stale state back to state manager
+			alertState.LastSentAt = ts
+			sentAlerts = append(sentAlerts, alertState.State)
 		}
-		alertState.LastSentAt = ts
-		sentAlerts = append(sentAlerts, alertState.State)
+		unlock()
 	}
 	stateManager.Put(sentAlerts)
 	return alerts
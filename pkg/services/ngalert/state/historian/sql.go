@@ -0,0 +1,340 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// sqlCleanupInterval is the minimum time between two deletions of expired state history entries.
+const sqlCleanupInterval = 10 * time.Minute
+
+// stateHistoryEntry is a single state transition stored in the alert_state_history table.
+type stateHistoryEntry struct {
+	ID            int64  `xorm:"pk autoincr 'id'"`
+	OrgID         int64  `xorm:"org_id"`
+	RuleUID       string `xorm:"rule_uid"`
+	RuleID        int64  `xorm:"rule_id"`
+	RuleTitle     string `xorm:"rule_title"`
+	RuleGroup     string `xorm:"rule_group"`
+	NamespaceUID  string `xorm:"namespace_uid"`
+	DashboardUID  string `xorm:"dashboard_uid"`
+	PanelID       int64  `xorm:"panel_id"`
+	RuleCondition string `xorm:"rule_condition"`
+	Fingerprint   string `xorm:"fingerprint"`
+	// Labels is the JSON encoded set of labels of the alert instance, with the keys sorted.
+	Labels        string `xorm:"labels"`
+	PreviousState string `xorm:"previous_state"`
+	CurrentState  string `xorm:"current_state"`
+	// StateValues is the JSON encoded set of values of the evaluation.
+	StateValues  string `xorm:"state_values"`
+	ErrorMessage string `xorm:"error_message"`
+	// EvaluatedAt is the time of the evaluation in milliseconds since epoch.
+	EvaluatedAt int64 `xorm:"evaluated_at"`
+}
+
+func (stateHistoryEntry) TableName() string {
+	return "alert_state_history"
+}
+
+// SQLBackend is a state.Historian that records state history to a table in the Grafana database.
+type SQLBackend struct {
+	db      db.DB
+	clock   clock.Clock
+	metrics *metrics.Historian
+	log     log.Logger
+	// maxAge is how long entries are kept. Zero keeps them forever.
+	maxAge time.Duration
+
+	cleanupMtx  sync.Mutex
+	lastCleanup time.Time
+}
+
+func NewSQLBackend(store db.DB, maxAge time.Duration, metrics *metrics.Historian) *SQLBackend {
+	return &SQLBackend{
+		db:      store,
+		clock:   clock.New(),
+		metrics: metrics,
+		log:     log.New("ngalert.state.historian", "backend", "sql"),
+		maxAge:  maxAge,
+	}
+}
+
+// Record writes a number of state transitions for a given rule to the Grafana database.
+func (h *SQLBackend) Record(ctx context.Context, rule history_model.RuleMeta, states []state.StateTransition) <-chan error {
+	logger := h.log.FromContext(ctx)
+	entries := statesToSQLEntries(rule, states, logger)
+
+	errCh := make(chan error, 1)
+	if len(entries) == 0 {
+		close(errCh)
+		return errCh
+	}
+
+	// This is a new background job, so let's create a brand new context for it, like in the Loki backend.
+	writeCtx := context.Background()
+	writeCtx, cancel := context.WithTimeout(writeCtx, StateHistoryWriteTimeout)
+	writeCtx = history_model.WithRuleData(writeCtx, rule)
+	writeCtx = trace.ContextWithSpan(writeCtx, trace.SpanFromContext(ctx))
+
+	go func(ctx context.Context) {
+		defer cancel()
+		defer close(errCh)
+		logger := h.log.FromContext(ctx)
+
+		org := fmt.Sprint(rule.OrgID)
+		h.metrics.WritesTotal.WithLabelValues(org, "sql").Inc()
+		h.metrics.TransitionsTotal.WithLabelValues(org).Add(float64(len(entries)))
+
+		// the entries are inserted in batches, so that the statements stay within the limit of bind parameters of the database.
+		err := h.db.WithDbSession(ctx, func(sess *db.Session) error {
+			_, err := sess.BulkInsert(stateHistoryEntry{}.TableName(), entries, sqlstore.NativeSettingsForDialect(h.db.GetDialect()))
+			return err
+		})
+		if err != nil {
+			logger.Error("Failed to save alert state history batch", "error", err)
+			h.metrics.WritesFailed.WithLabelValues(org, "sql").Inc()
+			h.metrics.TransitionsFailed.WithLabelValues(org).Add(float64(len(entries)))
+			errCh <- fmt.Errorf("failed to save alert state history batch: %w", err)
+			return
+		}
+		logger.Debug("Done saving alert state history batch")
+
+		if h.shouldCleanup() {
+			if _, err := h.DeleteExpired(ctx); err != nil {
+				logger.Error("Failed to delete expired alert state history", "error", err)
+			}
+		}
+	}(writeCtx)
+	return errCh
+}
+
+// shouldCleanup returns true if expired entries should be deleted, at most once per sqlCleanupInterval.
+func (h *SQLBackend) shouldCleanup() bool {
+	if h.maxAge <= 0 {
+		return false
+	}
+	h.cleanupMtx.Lock()
+	defer h.cleanupMtx.Unlock()
+	now := h.clock.Now()
+	if now.Sub(h.lastCleanup) < sqlCleanupInterval {
+		return false
+	}
+	h.lastCleanup = now
+	return true
+}
+
+// DeleteExpired deletes the state history entries that are older than the configured max age.
+// It returns the number of deleted entries.
+func (h *SQLBackend) DeleteExpired(ctx context.Context) (int64, error) {
+	if h.maxAge <= 0 {
+		return 0, nil
+	}
+	before := h.clock.Now().Add(-h.maxAge).UnixMilli()
+	var affected int64
+	err := h.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		affected, err = sess.Where("evaluated_at < ?", before).Delete(&stateHistoryEntry{})
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	if affected > 0 {
+		h.log.FromContext(ctx).Debug("Deleted expired alert state history", "count", affected)
+	}
+	return affected, nil
+}
+
+// Query retrieves state history entries from the Grafana database and formats the results into a dataframe.
+// The entries are the most recent ones in the time range, paginated by query.Limit and query.Offset, in ascending order of time.
+func (h *SQLBackend) Query(ctx context.Context, query models.HistoryQuery) (*data.Frame, error) {
+	now := h.clock.Now().UTC()
+	if query.To.IsZero() {
+		query.To = now
+	}
+	if query.From.IsZero() {
+		query.From = now.Add(-defaultQueryRange)
+	}
+	if query.From.After(query.To) {
+		return nil, fmt.Errorf("start time cannot be after end time")
+	}
+	limit := query.Limit
+	if limit < 1 {
+		limit = defaultPageSize
+	}
+	if limit > maximumPageSize {
+		limit = maximumPageSize
+	}
+	offset := query.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	var entries []stateHistoryEntry
+	err := h.db.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Table(stateHistoryEntry{}).
+			Where("org_id = ?", query.OrgID).
+			And("evaluated_at >= ?", query.From.UnixMilli()).
+			And("evaluated_at <= ?", query.To.UnixMilli())
+		if query.RuleUID != "" {
+			q = q.And("rule_uid = ?", query.RuleUID)
+		}
+		if query.DashboardUID != "" {
+			q = q.And("dashboard_uid = ?", query.DashboardUID)
+		}
+		if query.PanelID != 0 {
+			q = q.And("panel_id = ?", query.PanelID)
+		}
+		keys := make([]string, 0, len(query.Labels))
+		for k := range query.Labels {
+			keys = append(keys, k)
+		}
+		// Ensure that all queries we build are deterministic.
+		sort.Strings(keys)
+		for _, k := range keys {
+			pattern, err := labelMatchPattern(k, query.Labels[k])
+			if err != nil {
+				return err
+			}
+			q = q.And("labels LIKE ? ESCAPE '!'", pattern)
+		}
+		return q.Desc("evaluated_at", "id").Limit(limit, offset).Find(&entries)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alert state history: %w", err)
+	}
+	return sqlEntriesToFrame(entries)
+}
+
+// labelMatchPattern returns a LIKE pattern that matches the JSON encoded labels that contain the given label.
+func labelMatchPattern(key, value string) (string, error) {
+	k, err := json.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+	v, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	escaper := strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+	return "%" + escaper.Replace(string(k)+":"+string(v)) + "%", nil
+}
+
+func statesToSQLEntries(rule history_model.RuleMeta, states []state.StateTransition, logger log.Logger) []stateHistoryEntry {
+	entries := make([]stateHistoryEntry, 0, len(states))
+	for _, state := range states {
+		if !shouldRecord(state) {
+			continue
+		}
+
+		sanitizedLabels := removePrivateLabels(state.Labels)
+		labels, err := json.Marshal(sanitizedLabels)
+		if err != nil {
+			logger.Error("Failed to serialize labels of state, skipping", "error", err)
+			continue
+		}
+		values, err := valuesAsDataBlob(state.State).MarshalJSON()
+		if err != nil {
+			logger.Error("Failed to serialize values of state, skipping", "error", err)
+			continue
+		}
+		entry := stateHistoryEntry{
+			OrgID:         rule.OrgID,
+			RuleUID:       rule.UID,
+			RuleID:        rule.ID,
+			RuleTitle:     rule.Title,
+			RuleGroup:     rule.Group,
+			NamespaceUID:  rule.NamespaceUID,
+			DashboardUID:  rule.DashboardUID,
+			PanelID:       rule.PanelID,
+			RuleCondition: rule.Condition,
+			Fingerprint:   labelFingerprint(sanitizedLabels),
+			Labels:        string(labels),
+			PreviousState: state.PreviousFormatted(),
+			CurrentState:  state.Formatted(),
+			StateValues:   string(values),
+			EvaluatedAt:   state.State.LastEvaluationTime.UnixMilli(),
+		}
+		if state.State.State == eval.Error && state.Error != nil {
+			entry.ErrorMessage = state.Error.Error()
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// sqlEntriesToFrame converts the entries, in descending order of time, to a dataframe in the same format as the Loki backend.
+func sqlEntriesToFrame(entries []stateHistoryEntry) (*data.Frame, error) {
+	frame := data.NewFrame("states")
+	lbls := data.Labels(map[string]string{})
+
+	times := make([]time.Time, 0, len(entries))
+	lines := make([]json.RawMessage, 0, len(entries))
+	labels := make([]json.RawMessage, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		var instanceLabels map[string]string
+		if err := json.Unmarshal([]byte(e.Labels), &instanceLabels); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal labels of entry %d: %w", e.ID, err)
+		}
+		values, err := simplejson.NewJson([]byte(e.StateValues))
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal values of entry %d: %w", e.ID, err)
+		}
+		line, err := json.Marshal(LokiEntry{
+			SchemaVersion:  1,
+			Previous:       e.PreviousState,
+			Current:        e.CurrentState,
+			Error:          e.ErrorMessage,
+			Values:         values,
+			Condition:      e.RuleCondition,
+			DashboardUID:   e.DashboardUID,
+			PanelID:        e.PanelID,
+			Fingerprint:    e.Fingerprint,
+			RuleTitle:      e.RuleTitle,
+			RuleID:         e.RuleID,
+			RuleUID:        e.RuleUID,
+			InstanceLabels: instanceLabels,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize entry %d: %w", e.ID, err)
+		}
+		streamLabels, err := json.Marshal(map[string]string{
+			StateHistoryLabelKey: StateHistoryLabelValue,
+			OrgIDLabel:           fmt.Sprint(e.OrgID),
+			GroupLabel:           e.RuleGroup,
+			FolderUIDLabel:       e.NamespaceUID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize stream labels: %w", err)
+		}
+
+		times = append(times, time.UnixMilli(e.EvaluatedAt))
+		lines = append(lines, line)
+		labels = append(labels, streamLabels)
+	}
+
+	frame.Fields = append(frame.Fields, data.NewField(dfTime, lbls, times))
+	frame.Fields = append(frame.Fields, data.NewField(dfLine, lbls, lines))
+	frame.Fields = append(frame.Fields, data.NewField(dfLabels, lbls, labels))
+	return frame, nil
+}
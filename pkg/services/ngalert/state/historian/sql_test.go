@@ -0,0 +1,142 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationSQLBackend(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	store := db.InitTestDB(t)
+	clk := clock.NewMock()
+	clk.Set(time.Now())
+	backend := NewSQLBackend(store, 2*time.Hour, metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem))
+	backend.clock = clk
+
+	rule := createTestRule()
+	start := clk.Now().Add(-30 * time.Minute)
+	transition := func(ts time.Time, current eval.State, labels data.Labels) state.StateTransition {
+		return state.StateTransition{
+			PreviousState: eval.Normal,
+			State: &state.State{
+				State:              current,
+				Labels:             labels,
+				Values:             map[string]float64{"A": 1},
+				LastEvaluationTime: ts,
+				Error:              fmt.Errorf("oh no"),
+			},
+		}
+	}
+	states := []state.StateTransition{
+		transition(start, eval.Alerting, data.Labels{"team": "a", "__private__": "x"}),
+		transition(start.Add(time.Minute), eval.Alerting, data.Labels{"team": "b%"}),
+		transition(start.Add(2*time.Minute), eval.Error, data.Labels{"team": "a", "env": "prod"}),
+		// not a transition, must not be recorded
+		{PreviousState: eval.Normal, State: &state.State{State: eval.Normal, LastEvaluationTime: start}},
+	}
+	require.NoError(t, <-backend.Record(context.Background(), rule, states))
+
+	query := func(t *testing.T, q models.HistoryQuery) []LokiEntry {
+		t.Helper()
+		q.OrgID = rule.OrgID
+		frame, err := backend.Query(context.Background(), q)
+		require.NoError(t, err)
+		require.Len(t, frame.Fields, 3)
+		entries := make([]LokiEntry, 0, frame.Rows())
+		for i := 0; i < frame.Rows(); i++ {
+			var entry LokiEntry
+			require.NoError(t, json.Unmarshal(frame.Fields[1].At(i).(json.RawMessage), &entry))
+			entries = append(entries, entry)
+		}
+		return entries
+	}
+
+	t.Run("records transitions and returns them in ascending order of time", func(t *testing.T) {
+		entries := query(t, models.HistoryQuery{})
+		require.Len(t, entries, 3)
+		require.Equal(t, map[string]string{"team": "a"}, entries[0].InstanceLabels)
+		require.Equal(t, "Normal", entries[0].Previous)
+		require.Equal(t, "Alerting", entries[0].Current)
+		require.Equal(t, rule.UID, entries[0].RuleUID)
+		require.Equal(t, rule.Title, entries[0].RuleTitle)
+		require.Equal(t, rule.DashboardUID, entries[0].DashboardUID)
+		require.Equal(t, labelFingerprint(data.Labels{"team": "a"}), entries[0].Fingerprint)
+		require.Equal(t, 1.0, entries[0].Values.Get("A").MustFloat64())
+		require.Empty(t, entries[0].Error)
+		require.Equal(t, "oh no", entries[2].Error)
+	})
+
+	t.Run("filters by labels", func(t *testing.T) {
+		entries := query(t, models.HistoryQuery{Labels: map[string]string{"team": "a"}})
+		require.Len(t, entries, 2)
+		entries = query(t, models.HistoryQuery{Labels: map[string]string{"team": "a", "env": "prod"}})
+		require.Len(t, entries, 1)
+		entries = query(t, models.HistoryQuery{Labels: map[string]string{"team": "b%"}})
+		require.Len(t, entries, 1)
+		entries = query(t, models.HistoryQuery{Labels: map[string]string{"team": "%"}})
+		require.Empty(t, entries)
+	})
+
+	t.Run("filters by rule, dashboard and time range", func(t *testing.T) {
+		require.Len(t, query(t, models.HistoryQuery{RuleUID: rule.UID, DashboardUID: rule.DashboardUID, PanelID: rule.PanelID}), 3)
+		require.Empty(t, query(t, models.HistoryQuery{RuleUID: "other"}))
+		require.Len(t, query(t, models.HistoryQuery{From: start.Add(time.Minute), To: clk.Now()}), 2)
+	})
+
+	t.Run("paginates the most recent entries", func(t *testing.T) {
+		entries := query(t, models.HistoryQuery{Limit: 2})
+		require.Len(t, entries, 2)
+		require.Equal(t, map[string]string{"team": "b%"}, entries[0].InstanceLabels)
+		entries = query(t, models.HistoryQuery{Limit: 2, Offset: 2})
+		require.Len(t, entries, 1)
+		require.Equal(t, map[string]string{"team": "a"}, entries[0].InstanceLabels)
+	})
+
+	t.Run("deletes expired entries", func(t *testing.T) {
+		clk.Add(91 * time.Minute)
+		deleted, err := backend.DeleteExpired(context.Background())
+		require.NoError(t, err)
+		require.EqualValues(t, 1, deleted)
+		require.Len(t, query(t, models.HistoryQuery{From: start.Add(-time.Hour)}), 2)
+	})
+
+	t.Run("records more transitions than fit in one statement", func(t *testing.T) {
+		large := createTestRule()
+		large.UID = "large-rule-uid"
+		states := make([]state.StateTransition, 0, 2500)
+		for i := 0; i < cap(states); i++ {
+			states = append(states, transition(clk.Now(), eval.Alerting, data.Labels{"instance": fmt.Sprint(i)}))
+		}
+		require.NoError(t, <-backend.Record(context.Background(), large, states))
+
+		var count int64
+		err := store.WithDbSession(context.Background(), func(sess *db.Session) error {
+			var err error
+			count, err = sess.Table(stateHistoryEntry{}.TableName()).Where("rule_uid = ?", large.UID).Count()
+			return err
+		})
+		require.NoError(t, err)
+		require.EqualValues(t, len(states), count)
+	})
+}
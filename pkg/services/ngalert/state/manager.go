@@ -2,8 +2,11 @@ package state
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/url"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/benbjohnson/clock"
@@ -23,6 +26,11 @@ var (
 	ResendDelay = 30 * time.Second
 )
 
+var (
+	ErrAlertInstanceNotFound  = errors.New("alert instance not found")
+	ErrAlertInstanceNotFiring = errors.New("alert instance is not firing")
+)
+
 // AlertInstanceManager defines the interface for querying the current alert instances.
 type AlertInstanceManager interface {
 	GetAll(orgID int64) []*State
@@ -51,8 +59,13 @@ type Manager struct {
 	doNotSaveNormalState           bool
 	applyNoDataAndErrorToAllStates bool
 	rulesPerRuleGroupLimit         int64
+	shardedEvaluation              bool
 
 	persister StatePersister
+
+	// ruleLocks holds a *sync.Mutex by ngModels.AlertRuleKey, see lockRule. The lock of a rule is removed with its
+	// states from the cache, see removeRuleStates.
+	ruleLocks sync.Map
 }
 
 type ManagerCfg struct {
@@ -70,6 +83,9 @@ type ManagerCfg struct {
 	// to all states when corresponding execution in the rule definition is set to either `Alerting` or `OK`
 	ApplyNoDataAndErrorToAllStates bool
 	RulesPerRuleGroupLimit         int64
+	// ShardedEvaluation is true if the rules are evaluated by different instances of the cluster. The acknowledgements
+	// made on the other instances are then loaded from the instance store when a firing rule is evaluated.
+	ShardedEvaluation bool
 
 	Tracer tracing.Tracer
 	Log    log.Logger
@@ -95,6 +111,7 @@ func NewManager(cfg ManagerCfg, statePersister StatePersister) *Manager {
 		doNotSaveNormalState:           cfg.DoNotSaveNormalState,
 		applyNoDataAndErrorToAllStates: cfg.ApplyNoDataAndErrorToAllStates,
 		rulesPerRuleGroupLimit:         cfg.RulesPerRuleGroupLimit,
+		shardedEvaluation:              cfg.ShardedEvaluation,
 		persister:                      statePersister,
 		tracer:                         cfg.Tracer,
 	}
@@ -111,7 +128,10 @@ func (st *Manager) Run(ctx context.Context) error {
 	return nil
 }
 
-func (st *Manager) Warm(ctx context.Context, rulesReader RuleReader) {
+// Warm loads the states of the alert rules from the instance store into the cache. If owns is not nil, only the
+// states of the rules it returns true for are loaded, since the other rules are evaluated by another instance of
+// the cluster, which keeps their state.
+func (st *Manager) Warm(ctx context.Context, rulesReader RuleReader, owns func(ngModels.AlertRuleKey) bool) {
 	if st.instanceStore == nil {
 		st.log.Info("Skip warming the state because instance store is not configured")
 		return
@@ -139,8 +159,11 @@ func (st *Manager) Warm(ctx context.Context, rulesReader RuleReader) {
 		ruleByUID := make(map[string]*ngModels.AlertRule, len(alertRules))
 		groupSizes := make(map[string]int64)
 		for _, rule := range alertRules {
-			ruleByUID[rule.UID] = rule
 			groupSizes[rule.RuleGroup] += 1
+			if owns != nil && !owns(rule.GetKey()) {
+				continue
+			}
+			ruleByUID[rule.UID] = rule
 		}
 
 		// Emit a warning if we detect a large group.
@@ -181,33 +204,8 @@ func (st *Manager) Warm(ctx context.Context, rulesReader RuleReader) {
 				orgStates[entry.RuleUID] = rulesStates
 			}
 
-			lbs := map[string]string(entry.Labels)
-			cacheID, err := entry.Labels.StringKey()
-			if err != nil {
-				st.log.Error("Error getting cacheId for entry", "error", err)
-			}
-			var resultFp data.Fingerprint
-			if entry.ResultFingerprint != "" {
-				fp, err := strconv.ParseUint(entry.ResultFingerprint, 16, 64)
-				if err != nil {
-					st.log.Error("Failed to parse result fingerprint of alert instance", "error", err, "ruleUID", entry.RuleUID)
-				}
-				resultFp = data.Fingerprint(fp)
-			}
-			rulesStates.states[cacheID] = &State{
-				AlertRuleUID:         entry.RuleUID,
-				OrgID:                entry.RuleOrgID,
-				CacheID:              cacheID,
-				Labels:               lbs,
-				State:                translateInstanceState(entry.CurrentState),
-				StateReason:          entry.CurrentReason,
-				LastEvaluationString: "",
-				StartsAt:             entry.CurrentStateSince,
-				EndsAt:               entry.CurrentStateEnd,
-				LastEvaluationTime:   entry.LastEvalTime,
-				Annotations:          ruleForEntry.Annotations,
-				ResultFingerprint:    resultFp,
-			}
+			s := st.stateFromInstance(entry, ruleForEntry)
+			rulesStates.states[s.CacheID] = s
 			statesCount++
 		}
 	}
@@ -215,6 +213,68 @@ func (st *Manager) Warm(ctx context.Context, rulesReader RuleReader) {
 	st.log.Info("State cache has been initialized", "states", statesCount, "duration", time.Since(startTime))
 }
 
+// LoadStateByRule replaces the states of the rule in the cache with the ones saved in the instance store.
+// It is used when another Grafana instance in the cluster hands over the evaluation of the rule to this one.
+func (st *Manager) LoadStateByRule(ctx context.Context, rule *ngModels.AlertRule) error {
+	if st.instanceStore == nil {
+		return nil
+	}
+	alertInstances, err := st.instanceStore.ListAlertInstances(ctx, &ngModels.ListAlertInstancesQuery{
+		RuleOrgID: rule.OrgID,
+		RuleUID:   rule.UID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fetch the state of the rule: %w", err)
+	}
+	states := make(map[string]*State, len(alertInstances))
+	for _, entry := range alertInstances {
+		s := st.stateFromInstance(entry, rule)
+		states[s.CacheID] = s
+	}
+	st.cache.setRuleStates(rule.GetKey(), states)
+	st.log.FromContext(ctx).Debug("Loaded the state of the rule", "states", len(states))
+	return nil
+}
+
+// ForgetStateByRuleUID removes the states of the rule from the cache but, unlike DeleteStateByRuleUID, keeps them
+// in the instance store and does not resolve them. It is used when the evaluation of the rule is handed over to another
+// Grafana instance in the cluster, which loads the states from the instance store.
+func (st *Manager) ForgetStateByRuleUID(ctx context.Context, ruleKey ngModels.AlertRuleKey) {
+	states := st.removeRuleStates(ruleKey)
+	st.log.FromContext(ctx).Debug("Removed the state of the rule from the cache", "states", len(states))
+}
+
+// stateFromInstance creates the cached state of an alert instance saved in the instance store.
+func (st *Manager) stateFromInstance(entry *ngModels.AlertInstance, rule *ngModels.AlertRule) *State {
+	cacheID, err := entry.Labels.StringKey()
+	if err != nil {
+		st.log.Error("Error getting cacheId for entry", "error", err)
+	}
+	var resultFp data.Fingerprint
+	if entry.ResultFingerprint != "" {
+		fp, err := strconv.ParseUint(entry.ResultFingerprint, 16, 64)
+		if err != nil {
+			st.log.Error("Failed to parse result fingerprint of alert instance", "error", err, "ruleUID", entry.RuleUID)
+		}
+		resultFp = data.Fingerprint(fp)
+	}
+	return &State{
+		AlertRuleUID:         entry.RuleUID,
+		OrgID:                entry.RuleOrgID,
+		CacheID:              cacheID,
+		Labels:               map[string]string(entry.Labels),
+		State:                translateInstanceState(entry.CurrentState),
+		StateReason:          entry.CurrentReason,
+		LastEvaluationString: "",
+		StartsAt:             entry.CurrentStateSince,
+		EndsAt:               entry.CurrentStateEnd,
+		LastEvaluationTime:   entry.LastEvalTime,
+		Annotations:          rule.Annotations,
+		ResultFingerprint:    resultFp,
+		Acknowledgement:      entry.Acknowledgement,
+	}
+}
+
 func (st *Manager) Get(orgID int64, alertRuleUID, stateId string) *State {
 	return st.cache.get(orgID, alertRuleUID, stateId)
 }
@@ -226,7 +286,7 @@ func (st *Manager) DeleteStateByRuleUID(ctx context.Context, ruleKey ngModels.Al
 	logger := st.log.FromContext(ctx)
 	logger.Debug("Resetting state of the rule")
 
-	states := st.cache.removeByRuleUID(ruleKey.OrgID, ruleKey.UID)
+	states := st.removeRuleStates(ruleKey)
 
 	if len(states) == 0 {
 		return nil
@@ -247,6 +307,7 @@ func (st *Manager) DeleteStateByRuleUID(ctx context.Context, ruleKey ngModels.Al
 		s.Resolved = oldState == eval.Alerting || oldState == eval.Error || oldState == eval.NoData
 		s.LastEvaluationTime = now
 		s.Values = map[string]float64{}
+		s.Acknowledgement = nil
 		transitions = append(transitions, StateTransition{
 			State:               s,
 			PreviousState:       oldState,
@@ -286,6 +347,177 @@ func (st *Manager) ResetStateByRuleUID(ctx context.Context, rule *ngModels.Alert
 	return transitions
 }
 
+// AcknowledgeState acknowledges the firing state of the rule whose labels have the given fingerprint, and saves the
+// acknowledgement to the instance store and the state history. The acknowledgement is removed by the next evaluation
+// that changes the state, or after it expires.
+//
+// The state is changed under the lock of the rule, so that a concurrent evaluation does not overwrite the
+// acknowledgement. If the states of the rule are not in the cache, because the rule is evaluated by another instance
+// of the cluster, the acknowledgement is saved to the instance store only, and the instance that evaluates the rule
+// loads it at the next evaluation.
+func (st *Manager) AcknowledgeState(ctx context.Context, rule *ngModels.AlertRule, fingerprint string, ack ngModels.AlertInstanceAcknowledgement) (*State, error) {
+	ctx, span := st.tracer.Start(ctx, "acknowledge alert instance", trace.WithAttributes(
+		attribute.String("rule_uid", rule.UID),
+		attribute.Int64("org_id", rule.OrgID),
+		attribute.String("fingerprint", fingerprint)))
+	defer span.End()
+
+	unlock := st.lockRule(rule.GetKey())
+	defer unlock()
+
+	var s *State
+	for _, candidate := range st.cache.getStatesForRuleUID(rule.OrgID, rule.UID, false) {
+		if candidate.Labels.Fingerprint().String() == fingerprint {
+			s = candidate
+			break
+		}
+	}
+	var stored *ngModels.AlertInstance
+	if s == nil {
+		var err error
+		if stored, err = st.storedInstance(ctx, rule, fingerprint); err != nil {
+			return nil, err
+		}
+		if stored == nil {
+			return nil, ErrAlertInstanceNotFound
+		}
+		s = st.stateFromInstance(stored, rule)
+	}
+	if s.State != eval.Alerting && s.State != eval.NoData && s.State != eval.Error {
+		return nil, ErrAlertInstanceNotFiring
+	}
+
+	oldReason := s.StateReason
+	if s.Acknowledgement == nil {
+		if s.StateReason == "" {
+			s.StateReason = ngModels.StateReasonAcknowledged
+		} else {
+			s.StateReason = ngModels.ConcatReasons(s.StateReason, ngModels.StateReasonAcknowledged)
+		}
+	}
+	s.Acknowledgement = &ack
+
+	logger := st.log.FromContext(ctx)
+	logger.Info("Alert instance is acknowledged", append(rule.GetKey().LogContext(), "fingerprint", fingerprint, "acknowledgedBy", ack.By, "cached", stored == nil)...)
+
+	transitions := []StateTransition{{
+		State:               s,
+		PreviousState:       s.State,
+		PreviousStateReason: oldReason,
+	}}
+	if stored == nil {
+		st.cache.set(s)
+		st.persister.Sync(ctx, span, transitions, nil)
+	} else {
+		stored.CurrentReason = s.StateReason
+		stored.Acknowledgement = s.Acknowledgement
+		if err := st.instanceStore.SaveAlertInstance(ctx, *stored); err != nil {
+			return nil, fmt.Errorf("failed to save the acknowledgement: %w", err)
+		}
+	}
+	if st.historian != nil {
+		errCh := st.historian.Record(ctx, history_model.NewRuleMeta(rule, logger), transitions)
+		go func() {
+			if err := <-errCh; err != nil {
+				logger.Error("Error recording the acknowledgement of alert instance in the state history", append(rule.GetKey().LogContext(), "fingerprint", fingerprint, "error", err)...)
+			}
+		}()
+	}
+	result := *s
+	return &result, nil
+}
+
+// storedInstance returns the alert instance of the rule whose labels have the given fingerprint from the instance
+// store, or nil if there is none.
+func (st *Manager) storedInstance(ctx context.Context, rule *ngModels.AlertRule, fingerprint string) (*ngModels.AlertInstance, error) {
+	if st.instanceStore == nil {
+		return nil, nil
+	}
+	instances, err := st.instanceStore.ListAlertInstances(ctx, &ngModels.ListAlertInstancesQuery{
+		RuleOrgID: rule.OrgID,
+		RuleUID:   rule.UID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the state of the rule: %w", err)
+	}
+	for _, instance := range instances {
+		if data.Labels(instance.Labels).Fingerprint().String() == fingerprint {
+			return instance, nil
+		}
+	}
+	return nil, nil
+}
+
+// loadAcknowledgements copies to the cached states of the rule the acknowledgements that were saved to the instance
+// store by other instances of the cluster. An acknowledgement is loaded only if it was made after the state started
+// and after the acknowledgement of the cached state, so that the acknowledgements that this instance removed but did
+// not save yet are not loaded again. The states are read only if the evaluation is sharded and the rule has a firing state.
+func (st *Manager) loadAcknowledgements(ctx context.Context, alertRule *ngModels.AlertRule, logger log.Logger) {
+	if !st.shardedEvaluation || st.instanceStore == nil {
+		return
+	}
+	cached := make(map[string]*State)
+	for _, s := range st.cache.getStatesForRuleUID(alertRule.OrgID, alertRule.UID, false) {
+		if s.State == eval.Alerting || s.State == eval.NoData || s.State == eval.Error {
+			cached[s.Labels.Fingerprint().String()] = s
+		}
+	}
+	if len(cached) == 0 {
+		return
+	}
+	instances, err := st.instanceStore.ListAlertInstances(ctx, &ngModels.ListAlertInstancesQuery{
+		RuleOrgID: alertRule.OrgID,
+		RuleUID:   alertRule.UID,
+	})
+	if err != nil {
+		logger.Error("Failed to fetch the acknowledgements of the rule", "error", err)
+		return
+	}
+	for _, instance := range instances {
+		ack := instance.Acknowledgement
+		if ack == nil {
+			continue
+		}
+		s, ok := cached[data.Labels(instance.Labels).Fingerprint().String()]
+		if !ok || ack.At.Before(s.StartsAt) || (s.Acknowledgement != nil && !ack.At.After(s.Acknowledgement.At)) {
+			continue
+		}
+		logger.Debug("Loaded the acknowledgement of alert instance", "acknowledgedBy", ack.By)
+		s.Acknowledgement = ack
+	}
+}
+
+// lockRule locks the states of the rule until the returned function is called. It serializes the evaluation of the
+// rule with the changes that users make to its states.
+func (st *Manager) lockRule(key ngModels.AlertRuleKey) func() {
+	mtx, _ := st.ruleLocks.LoadOrStore(key, &sync.Mutex{})
+	m := mtx.(*sync.Mutex)
+	m.Lock()
+	return m.Unlock
+}
+
+// lockExistingRule is like lockRule, but does not create the lock of the rule if it has none, which is the case after
+// its states were removed from the cache. The returned function does nothing then.
+func (st *Manager) lockExistingRule(key ngModels.AlertRuleKey) func() {
+	mtx, ok := st.ruleLocks.Load(key)
+	if !ok {
+		return func() {}
+	}
+	m := mtx.(*sync.Mutex)
+	m.Lock()
+	return m.Unlock
+}
+
+// removeRuleStates removes the states of the rule from the cache and drops the lock of the rule, which is created
+// again if the rule is evaluated again. It waits for the changes in progress to the states of the rule, so that they
+// cannot add a state back to the cache.
+func (st *Manager) removeRuleStates(key ngModels.AlertRuleKey) []*State {
+	unlock := st.lockRule(key)
+	defer unlock()
+	st.ruleLocks.Delete(key)
+	return st.cache.removeByRuleUID(key.OrgID, key.UID)
+}
+
 // ProcessEvalResults updates the current states that belong to a rule with the evaluation results.
 // if extraLabels is not empty, those labels will be added to every state. The extraLabels take precedence over rule labels and result labels
 func (st *Manager) ProcessEvalResults(ctx context.Context, evaluatedAt time.Time, alertRule *ngModels.AlertRule, results eval.Results, extraLabels data.Labels) []StateTransition {
@@ -298,8 +530,12 @@ func (st *Manager) ProcessEvalResults(ctx context.Context, evaluatedAt time.Time
 		attribute.Int("results", len(results))))
 	defer span.End()
 
+	unlock := st.lockRule(alertRule.GetKey())
+	defer unlock()
+
 	logger := st.log.FromContext(tracingCtx)
 	logger.Debug("State manager processing evaluation results", "resultCount", len(results))
+	st.loadAcknowledgements(tracingCtx, alertRule, logger)
 	states := st.setNextStateForRule(tracingCtx, alertRule, results, extraLabels, logger)
 	span.AddEvent("results processed", trace.WithAttributes(
 		attribute.Int64("state_transitions", int64(len(states))),
@@ -316,16 +552,17 @@ func (st *Manager) ProcessEvalResults(ctx context.Context, evaluatedAt time.Time
 }
 
 func (st *Manager) setNextStateForRule(ctx context.Context, alertRule *ngModels.AlertRule, results eval.Results, extraLabels data.Labels, logger log.Logger) []StateTransition {
+	sources := &inhibitionSources{}
 	if st.applyNoDataAndErrorToAllStates && results.IsNoData() && (alertRule.NoDataState == ngModels.Alerting || alertRule.NoDataState == ngModels.OK || alertRule.NoDataState == ngModels.KeepLast) { // If it is no data, check the mapping and switch all results to the new state
 		// TODO aggregate UID of datasources that returned NoData into one and provide as auxiliary info, probably annotation
-		transitions := st.setNextStateForAll(ctx, alertRule, results[0], logger)
+		transitions := st.setNextStateForAll(ctx, alertRule, results[0], sources, logger)
 		if len(transitions) > 0 {
 			return transitions // if there are no current states for the rule. Create ones for each result
 		}
 	}
 	if st.applyNoDataAndErrorToAllStates && results.IsError() && (alertRule.ExecErrState == ngModels.AlertingErrState || alertRule.ExecErrState == ngModels.OkErrState || alertRule.ExecErrState == ngModels.KeepLastErrState) {
 		// TODO squash all errors into one, and provide as annotation
-		transitions := st.setNextStateForAll(ctx, alertRule, results[0], logger)
+		transitions := st.setNextStateForAll(ctx, alertRule, results[0], sources, logger)
 		if len(transitions) > 0 {
 			return transitions // if there are no current states for the rule. Create ones for each result
 		}
@@ -333,24 +570,24 @@ func (st *Manager) setNextStateForRule(ctx context.Context, alertRule *ngModels.
 	transitions := make([]StateTransition, 0, len(results))
 	for _, result := range results {
 		currentState := st.cache.getOrCreate(ctx, logger, alertRule, result, extraLabels, st.externalURL)
-		s := st.setNextState(ctx, alertRule, currentState, result, logger)
+		s := st.setNextState(ctx, alertRule, currentState, result, sources, logger)
 		transitions = append(transitions, s)
 	}
 	return transitions
 }
 
-func (st *Manager) setNextStateForAll(ctx context.Context, alertRule *ngModels.AlertRule, result eval.Result, logger log.Logger) []StateTransition {
+func (st *Manager) setNextStateForAll(ctx context.Context, alertRule *ngModels.AlertRule, result eval.Result, sources *inhibitionSources, logger log.Logger) []StateTransition {
 	currentStates := st.cache.getStatesForRuleUID(alertRule.OrgID, alertRule.UID, false)
 	transitions := make([]StateTransition, 0, len(currentStates))
 	for _, currentState := range currentStates {
-		t := st.setNextState(ctx, alertRule, currentState, result, logger)
+		t := st.setNextState(ctx, alertRule, currentState, result, sources, logger)
 		transitions = append(transitions, t)
 	}
 	return transitions
 }
 
 // Set the current state based on evaluation results
-func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRule, currentState *State, result eval.Result, logger log.Logger) StateTransition {
+func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRule, currentState *State, result eval.Result, sources *inhibitionSources, logger log.Logger) StateTransition {
 	start := st.clock.Now()
 
 	currentState.LastEvaluationTime = result.EvaluatedAt
@@ -416,6 +653,41 @@ func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRu
 		currentState.StateReason = resultStateReason(result, alertRule)
 	}
 
+	if currentState.State == eval.Alerting && !currentState.KeepFiringSince.IsZero() {
+		currentState.StateReason = ngModels.StateReasonKeepFiring
+	}
+
+	wasInhibited := currentState.InhibitedBy != ""
+	currentState.InhibitedBy = ""
+	if currentState.State == eval.Alerting || currentState.State == eval.NoData || currentState.State == eval.Error {
+		if ruleUID := st.inhibitingRuleUID(ctx, alertRule, sources, currentState); ruleUID != "" {
+			logger.Debug("Alert instance is inhibited", "inhibitingRuleUID", ruleUID)
+			currentState.InhibitedBy = ruleUID
+			if currentState.StateReason == "" {
+				currentState.StateReason = ngModels.StateReasonInhibited
+			} else {
+				currentState.StateReason = ngModels.ConcatReasons(currentState.StateReason, ngModels.StateReasonInhibited)
+			}
+		}
+	}
+	// An alert that was sent before the state is inhibited is resolved in the Alertmanager. When the inhibition ends,
+	// nothing is left to resolve, and the alert is sent at once instead of after the resend delay.
+	currentState.InhibitionStarted = currentState.InhibitedBy != "" && !wasInhibited && !currentState.LastSentAt.IsZero()
+	if wasInhibited && currentState.InhibitedBy == "" {
+		currentState.LastSentAt = time.Time{}
+	}
+
+	if currentState.Acknowledgement != nil {
+		if currentState.State != oldState || currentState.Acknowledgement.Expired(result.EvaluatedAt) {
+			logger.Debug("Acknowledgement of alert instance is removed", "acknowledgedBy", currentState.Acknowledgement.By)
+			currentState.Acknowledgement = nil
+		} else if currentState.StateReason == "" {
+			currentState.StateReason = ngModels.StateReasonAcknowledged
+		} else {
+			currentState.StateReason = ngModels.ConcatReasons(currentState.StateReason, ngModels.StateReasonAcknowledged)
+		}
+	}
+
 	// Set Resolved property so the scheduler knows to send a postable alert
 	// to Alertmanager.
 	currentState.Resolved = oldState == eval.Alerting && currentState.State == eval.Normal
@@ -447,6 +719,76 @@ func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRu
 	return nextState
 }
 
+// inhibitionSources holds the labels of the Alerting states of the rules that inhibit an alert rule, by rule UID.
+// They are fetched once per evaluation of the rule, when the first state that can be inhibited needs them.
+type inhibitionSources struct {
+	fetched bool
+	labels  map[string][]data.Labels
+}
+
+// inhibitingRuleUID returns the UID of the first rule in alertRule.InhibitedBy that has an Alerting state whose labels
+// listed in the inhibition are equal to the labels of the given state. Returns an empty string if the state is not inhibited.
+func (st *Manager) inhibitingRuleUID(ctx context.Context, alertRule *ngModels.AlertRule, sources *inhibitionSources, s *State) string {
+	if len(alertRule.InhibitedBy) == 0 {
+		return ""
+	}
+	if !sources.fetched {
+		sources.labels = st.inhibitingLabels(ctx, alertRule)
+		sources.fetched = true
+	}
+	for _, inhibition := range alertRule.InhibitedBy {
+		for _, source := range sources.labels[inhibition.RuleUID] {
+			equal := true
+			for _, l := range inhibition.Equal {
+				if source[l] != s.Labels[l] {
+					equal = false
+					break
+				}
+			}
+			if equal {
+				return inhibition.RuleUID
+			}
+		}
+	}
+	return ""
+}
+
+// inhibitingLabels returns the labels of the Alerting states of the inhibiting rules of the alert rule, by rule UID.
+// The states of a rule are read from the cache if it has any. Otherwise, the rule is evaluated by another instance
+// of the cluster, or was not evaluated yet, so they are read from the instance store, which can lag behind the
+// evaluation if the states are saved periodically.
+func (st *Manager) inhibitingLabels(ctx context.Context, alertRule *ngModels.AlertRule) map[string][]data.Labels {
+	result := make(map[string][]data.Labels, len(alertRule.InhibitedBy))
+	for _, inhibition := range alertRule.InhibitedBy {
+		if _, ok := result[inhibition.RuleUID]; ok {
+			continue
+		}
+		var labels []data.Labels
+		if cached := st.cache.getStatesForRuleUID(alertRule.OrgID, inhibition.RuleUID, false); len(cached) > 0 {
+			for _, source := range cached {
+				if source.State == eval.Alerting {
+					labels = append(labels, source.Labels)
+				}
+			}
+		} else if st.instanceStore != nil {
+			instances, err := st.instanceStore.ListAlertInstances(ctx, &ngModels.ListAlertInstancesQuery{
+				RuleOrgID: alertRule.OrgID,
+				RuleUID:   inhibition.RuleUID,
+			})
+			if err != nil {
+				st.log.FromContext(ctx).Error("Failed to fetch the state of the inhibiting rule", "inhibitingRuleUID", inhibition.RuleUID, "error", err)
+			}
+			for _, instance := range instances {
+				if instance.CurrentState == ngModels.InstanceStateFiring {
+					labels = append(labels, data.Labels(instance.Labels))
+				}
+			}
+		}
+		result[inhibition.RuleUID] = labels
+	}
+	return result
+}
+
 func resultStateReason(result eval.Result, rule *ngModels.AlertRule) string {
 	if rule.ExecErrState == ngModels.KeepLastErrState || rule.NoDataState == ngModels.KeepLast {
 		return ngModels.ConcatReasons(result.State.String(), ngModels.StateReasonKeepLast)
@@ -4010,3 +4010,50 @@ func setCacheID(s *State) *State {
 	s.CacheID = id
 	return s
 }
+
+func TestRuleLocksAreRemovedWithTheStates(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewMock()
+	cfg := ManagerCfg{
+		Metrics:       metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+		Tracer:        tracing.InitializeTracerForTest(),
+		Log:           log.New("ngalert.state.manager"),
+		InstanceStore: &FakeInstanceStore{},
+		Images:        &NotAvailableImageService{},
+		Clock:         clk,
+		Historian:     &FakeHistorian{},
+	}
+	st := NewManager(cfg, NewNoopPersister())
+
+	hasLock := func(key ngmodels.AlertRuleKey) bool {
+		_, ok := st.ruleLocks.Load(key)
+		return ok
+	}
+	evaluate := func(rule *ngmodels.AlertRule) []StateTransition {
+		result := eval.ResultGen(eval.WithState(eval.Alerting), eval.WithEvaluatedAt(clk.Now()))()
+		return st.ProcessEvalResults(ctx, clk.Now(), rule, eval.Results{result}, nil)
+	}
+
+	t.Run("deleting the state of a rule removes its lock", func(t *testing.T) {
+		rule := ngmodels.AlertRuleGen(ngmodels.WithFor(0))()
+		evaluate(rule)
+		require.True(t, hasLock(rule.GetKey()))
+
+		transitions := st.DeleteStateByRuleUID(ctx, rule.GetKey(), "")
+		require.False(t, hasLock(rule.GetKey()))
+
+		// sending the resolved alerts does not create the lock again.
+		require.Len(t, FromStateTransitionToPostableAlerts(transitions, st, nil).PostableAlerts, 1)
+		require.False(t, hasLock(rule.GetKey()))
+	})
+
+	t.Run("forgetting the state of a rule removes its lock", func(t *testing.T) {
+		rule := ngmodels.AlertRuleGen(ngmodels.WithFor(0))()
+		evaluate(rule)
+		require.True(t, hasLock(rule.GetKey()))
+
+		st.ForgetStateByRuleUID(ctx, rule.GetKey())
+		require.False(t, hasLock(rule.GetKey()))
+		require.Empty(t, st.GetStatesForRuleUID(rule.OrgID, rule.UID))
+	})
+}
@@ -16,10 +16,12 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
 
 	"github.com/grafana/grafana/pkg/expr"
@@ -219,7 +221,7 @@ func TestWarmStateCache(t *testing.T) {
 		Log:           log.New("ngalert.state.manager"),
 	}
 	st := state.NewManager(cfg, state.NewNoopPersister())
-	st.Warm(ctx, dbstore)
+	st.Warm(ctx, dbstore, nil)
 
 	t.Run("instance cache has expected entries", func(t *testing.T) {
 		for _, entry := range expectedEntries {
@@ -234,6 +236,79 @@ func TestWarmStateCache(t *testing.T) {
 	})
 }
 
+func TestLoadAndForgetStateByRule(t *testing.T) {
+	evaluationTime, err := time.Parse("2006-01-02", "2021-03-25")
+	require.NoError(t, err)
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, 1)
+
+	const mainOrgID int64 = 1
+	rule := tests.CreateTestAlertRule(t, ctx, dbstore, 600, mainOrgID)
+	otherRule := tests.CreateTestAlertRule(t, ctx, dbstore, 600, mainOrgID)
+
+	labels := models.InstanceLabels{"test1": "testValue1"}
+	_, hash, _ := labels.StringAndHash()
+	require.NoError(t, dbstore.SaveAlertInstance(ctx, models.AlertInstance{
+		AlertInstanceKey: models.AlertInstanceKey{
+			RuleOrgID:  rule.OrgID,
+			RuleUID:    rule.UID,
+			LabelsHash: hash,
+		},
+		CurrentState:      models.InstanceStateFiring,
+		LastEvalTime:      evaluationTime,
+		CurrentStateSince: evaluationTime.Add(-1 * time.Minute),
+		CurrentStateEnd:   evaluationTime.Add(1 * time.Minute),
+		Labels:            labels,
+	}))
+	require.NoError(t, dbstore.SaveAlertInstance(ctx, models.AlertInstance{
+		AlertInstanceKey: models.AlertInstanceKey{
+			RuleOrgID:  otherRule.OrgID,
+			RuleUID:    otherRule.UID,
+			LabelsHash: hash,
+		},
+		CurrentState: models.InstanceStateNormal,
+		LastEvalTime: evaluationTime,
+		Labels:       labels,
+	}))
+
+	cfg := state.ManagerCfg{
+		Metrics:       metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+		InstanceStore: dbstore,
+		Images:        &state.NoopImageService{},
+		Clock:         clock.NewMock(),
+		Historian:     &state.FakeHistorian{},
+		Tracer:        tracing.InitializeTracerForTest(),
+		Log:           log.New("ngalert.state.manager"),
+	}
+	st := state.NewManager(cfg, state.NewNoopPersister())
+
+	t.Run("load adds the states of the rule to the cache", func(t *testing.T) {
+		require.NoError(t, st.LoadStateByRule(ctx, rule))
+		states := st.GetStatesForRuleUID(rule.OrgID, rule.UID)
+		require.Len(t, states, 1)
+		require.Equal(t, eval.Alerting, states[0].State)
+		require.Equal(t, data.Labels(labels), states[0].Labels)
+		require.Equal(t, evaluationTime, states[0].LastEvaluationTime)
+		require.Empty(t, st.GetStatesForRuleUID(otherRule.OrgID, otherRule.UID))
+	})
+
+	t.Run("forget removes the states from the cache but not from the database", func(t *testing.T) {
+		st.ForgetStateByRuleUID(ctx, rule.GetKey())
+		require.Empty(t, st.GetStatesForRuleUID(rule.OrgID, rule.UID))
+		instances, err := dbstore.ListAlertInstances(ctx, &models.ListAlertInstancesQuery{RuleOrgID: rule.OrgID, RuleUID: rule.UID})
+		require.NoError(t, err)
+		require.Len(t, instances, 1)
+	})
+
+	t.Run("warm skips the rules that are not owned", func(t *testing.T) {
+		st.Warm(ctx, dbstore, func(key models.AlertRuleKey) bool {
+			return key == otherRule.GetKey()
+		})
+		require.Empty(t, st.GetStatesForRuleUID(rule.OrgID, rule.UID))
+		require.Len(t, st.GetStatesForRuleUID(otherRule.OrgID, otherRule.UID), 1)
+	})
+}
+
 func TestDashboardAnnotations(t *testing.T) {
 	evaluationTime, err := time.Parse("2006-01-02", "2022-01-01")
 	require.NoError(t, err)
@@ -264,7 +339,7 @@ func TestDashboardAnnotations(t *testing.T) {
 		"test2": "{{ $labels.instance_label }}",
 	})
 
-	st.Warm(ctx, dbstore)
+	st.Warm(ctx, dbstore, nil)
 	bValue := float64(42)
 	cValue := float64(1)
 	_ = st.ProcessEvalResults(ctx, evaluationTime, rule, eval.Results{{
@@ -1316,6 +1391,79 @@ func TestProcessEvalResults(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc:      "normal -> alerting -> alerting (KeepFiring) -> normal when KeepFiringFor is set",
+			alertRule: baseRuleWith(models.WithKeepFiringFor(2 * evaluationInterval)),
+			evalResults: map[time.Time]eval.Results{
+				t1: {
+					newResult(eval.WithState(eval.Alerting), eval.WithLabels(labels1)),
+				},
+				t2: {
+					newResult(eval.WithState(eval.Normal), eval.WithLabels(labels1)),
+				},
+				t3: {
+					newResult(eval.WithState(eval.Normal), eval.WithLabels(labels1)),
+				},
+				tn(4): {
+					newResult(eval.WithState(eval.Normal), eval.WithLabels(labels1)),
+				},
+			},
+			expectedAnnotations: 3,
+			expectedStates: []*state.State{
+				{
+					Labels:            labels["system + rule + labels1"],
+					ResultFingerprint: labels1.Fingerprint(),
+					State:             eval.Normal,
+					Results: []state.Evaluation{
+						newEvaluation(t1, eval.Alerting),
+						newEvaluation(t2, eval.Normal),
+						newEvaluation(t3, eval.Normal),
+						newEvaluation(tn(4), eval.Normal),
+					},
+					StartsAt:           tn(4),
+					EndsAt:             tn(4),
+					LastEvaluationTime: tn(4),
+					Resolved:           true,
+				},
+			},
+		},
+		{
+			desc:      "normal -> alerting -> alerting (KeepFiring) -> alerting -> alerting (KeepFiring) - it should restart keep firing",
+			alertRule: baseRuleWith(models.WithKeepFiringFor(2 * evaluationInterval)),
+			evalResults: map[time.Time]eval.Results{
+				t1: {
+					newResult(eval.WithState(eval.Alerting), eval.WithLabels(labels1)),
+				},
+				t2: {
+					newResult(eval.WithState(eval.Normal), eval.WithLabels(labels1)),
+				},
+				t3: {
+					newResult(eval.WithState(eval.Alerting), eval.WithLabels(labels1)),
+				},
+				tn(4): {
+					newResult(eval.WithState(eval.Normal), eval.WithLabels(labels1)),
+				},
+			},
+			expectedAnnotations: 4,
+			expectedStates: []*state.State{
+				{
+					Labels:            labels["system + rule + labels1"],
+					ResultFingerprint: labels1.Fingerprint(),
+					State:             eval.Alerting,
+					StateReason:       models.StateReasonKeepFiring,
+					Results: []state.Evaluation{
+						newEvaluation(t1, eval.Alerting),
+						newEvaluation(t2, eval.Normal),
+						newEvaluation(t3, eval.Alerting),
+						newEvaluation(tn(4), eval.Normal),
+					},
+					KeepFiringSince:    tn(4),
+					StartsAt:           t1,
+					EndsAt:             tn(4).Add(state.ResendDelay * 4),
+					LastEvaluationTime: tn(4),
+				},
+			},
+		},
 		{
 			desc: "template is correctly expanded",
 			alertRule: baseRuleWith(
@@ -1664,7 +1812,7 @@ func TestStaleResultsHandler(t *testing.T) {
 			Log:           log.New("ngalert.state.manager"),
 		}
 		st := state.NewManager(cfg, state.NewNoopPersister())
-		st.Warm(ctx, dbstore)
+		st.Warm(ctx, dbstore, nil)
 		existingStatesForRule := st.GetStatesForRuleUID(rule.OrgID, rule.UID)
 
 		// We have loaded the expected number of entries from the db
@@ -1821,6 +1969,376 @@ func TestStaleResults(t *testing.T) {
 	})
 }
 
+// storedInstances is an instance store that lists the given alert instances.
+type storedInstances struct {
+	state.FakeInstanceStore
+	instances []*models.AlertInstance
+}
+
+func (s *storedInstances) ListAlertInstances(_ context.Context, q *models.ListAlertInstancesQuery) ([]*models.AlertInstance, error) {
+	var result []*models.AlertInstance
+	for _, instance := range s.instances {
+		if instance.RuleOrgID == q.RuleOrgID && (q.RuleUID == "" || instance.RuleUID == q.RuleUID) {
+			result = append(result, instance)
+		}
+	}
+	return result, nil
+}
+
+func TestInhibition(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewMock()
+
+	instanceStore := &storedInstances{}
+	cfg := state.ManagerCfg{
+		Metrics:       metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+		ExternalURL:   nil,
+		InstanceStore: instanceStore,
+		Images:        &state.NoopImageService{},
+		Clock:         clk,
+		Historian:     &state.FakeHistorian{},
+		Tracer:        tracing.InitializeTracerForTest(),
+		Log:           log.New("ngalert.state.manager"),
+	}
+	st := state.NewManager(cfg, state.NewNoopPersister())
+
+	// the interval is short enough for the instances that were sent to not be sent again because of the resend delay.
+	source := models.AlertRuleGen(models.WithFor(0), models.WithOrgID(1), models.WithInterval(10*time.Second))()
+	rule := models.AlertRuleGen(models.WithFor(0), models.WithOrgID(1), models.WithInterval(10*time.Second), models.WithInhibitedBy(source.UID, "datacenter"))()
+
+	dc1 := data.Labels{"datacenter": "dc1"}
+	dc2 := data.Labels{"datacenter": "dc2"}
+	evaluate := func(r *models.AlertRule, results ...eval.Result) []state.StateTransition {
+		t.Helper()
+		clk.Add(time.Duration(r.IntervalSeconds) * time.Second)
+		for i := range results {
+			results[i].EvaluatedAt = clk.Now()
+		}
+		return st.ProcessEvalResults(ctx, clk.Now(), r, results, nil)
+	}
+	// sent returns the alerts sent to the Alertmanager by datacenter.
+	sent := func(transitions []state.StateTransition) map[string]amv2.PostableAlert {
+		t.Helper()
+		result := map[string]amv2.PostableAlert{}
+		for _, a := range state.FromStateTransitionToPostableAlerts(transitions, st, nil).PostableAlerts {
+			result[a.Labels["datacenter"]] = a
+		}
+		return result
+	}
+	byDatacenter := func(transitions []state.StateTransition) map[string]state.StateTransition {
+		t.Helper()
+		result := make(map[string]state.StateTransition, len(transitions))
+		for _, tr := range transitions {
+			result[tr.Labels["datacenter"]] = tr
+		}
+		return result
+	}
+
+	evaluate(source, eval.ResultGen(eval.WithState(eval.Alerting), eval.WithLabels(dc1))())
+
+	t.Run("instances with the same labels as a firing instance of the inhibiting rule are inhibited", func(t *testing.T) {
+		transitions := evaluate(rule,
+			eval.ResultGen(eval.WithState(eval.Alerting), eval.WithLabels(dc1))(),
+			eval.ResultGen(eval.WithState(eval.Alerting), eval.WithLabels(dc2))(),
+		)
+		states := byDatacenter(transitions)
+		require.Equal(t, eval.Alerting, states["dc1"].State.State)
+		require.Equal(t, models.StateReasonInhibited, states["dc1"].StateReason)
+		require.Equal(t, source.UID, states["dc1"].InhibitedBy)
+		require.Equal(t, eval.Alerting, states["dc2"].State.State)
+		require.Empty(t, states["dc2"].StateReason)
+		require.Empty(t, states["dc2"].InhibitedBy)
+
+		require.Equal(t, []string{"dc2"}, maps.Keys(sent(transitions)))
+	})
+
+	t.Run("inhibited instances are not sent", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			clk.Add(state.ResendDelay)
+			evaluate(source, eval.ResultGen(eval.WithState(eval.Alerting), eval.WithLabels(dc1))())
+			tr := byDatacenter(evaluate(rule,
+				eval.ResultGen(eval.WithState(eval.Alerting), eval.WithLabels(dc1))(),
+				eval.ResultGen(eval.WithState(eval.Alerting), eval.WithLabels(dc2))(),
+			))["dc1"]
+			require.Equal(t, source.UID, tr.InhibitedBy)
+			require.False(t, tr.NeedsSending(state.ResendDelay))
+		}
+	})
+
+	t.Run("instances are sent as soon as the inhibiting rule stops firing", func(t *testing.T) {
+		evaluate(source, eval.ResultGen(eval.WithState(eval.Normal), eval.WithLabels(dc1))())
+
+		transitions := evaluate(rule,
+			eval.ResultGen(eval.WithState(eval.Alerting), eval.WithLabels(dc1))(),
+			eval.ResultGen(eval.WithState(eval.Alerting), eval.WithLabels(dc2))(),
+		)
+		states := byDatacenter(transitions)
+		require.Empty(t, states["dc1"].StateReason)
+		require.Empty(t, states["dc1"].InhibitedBy)
+
+		require.Equal(t, []string{"dc1"}, maps.Keys(sent(transitions)))
+	})
+
+	t.Run("instances that were sent are resolved once when they are inhibited", func(t *testing.T) {
+		evaluate(source,
+			eval.ResultGen(eval.WithState(eval.Alerting), eval.WithLabels(dc1))(),
+			eval.ResultGen(eval.WithState(eval.Alerting), eval.WithLabels(dc2))(),
+		)
+
+		transitions := evaluate(rule,
+			eval.ResultGen(eval.WithState(eval.Alerting), eval.WithLabels(dc1))(),
+			eval.ResultGen(eval.WithState(eval.Alerting), eval.WithLabels(dc2))(),
+		)
+		alerts := sent(transitions)
+		require.Len(t, alerts, 2)
+		for dc, alert := range alerts {
+			require.Equal(t, source.UID, byDatacenter(transitions)[dc].InhibitedBy)
+			require.True(t, clk.Now().Equal(time.Time(alert.EndsAt)), "the alert of %s must be resolved", dc)
+			require.Equal(t, eval.Alerting, byDatacenter(transitions)[dc].State.State)
+		}
+
+		transitions = evaluate(rule,
+			eval.ResultGen(eval.WithState(eval.Alerting), eval.WithLabels(dc1))(),
+			eval.ResultGen(eval.WithState(eval.Alerting), eval.WithLabels(dc2))(),
+		)
+		require.Empty(t, sent(transitions))
+	})
+
+	t.Run("instances are inhibited by the stored state of inhibiting rules evaluated by another instance", func(t *testing.T) {
+		other := models.AlertRuleGen(models.WithFor(0), models.WithOrgID(1), models.WithInterval(10*time.Second))()
+		inhibited := models.AlertRuleGen(models.WithFor(0), models.WithOrgID(1), models.WithInterval(10*time.Second), models.WithInhibitedBy(other.UID, "datacenter"))()
+		instanceStore.instances = []*models.AlertInstance{{
+			AlertInstanceKey: models.AlertInstanceKey{RuleOrgID: other.OrgID, RuleUID: other.UID},
+			Labels:           models.InstanceLabels(dc1),
+			CurrentState:     models.InstanceStateFiring,
+		}}
+
+		transitions := evaluate(inhibited,
+			eval.ResultGen(eval.WithState(eval.Alerting), eval.WithLabels(dc1))(),
+			eval.ResultGen(eval.WithState(eval.Alerting), eval.WithLabels(dc2))(),
+		)
+		states := byDatacenter(transitions)
+		require.Equal(t, other.UID, states["dc1"].InhibitedBy)
+		require.Empty(t, states["dc2"].InhibitedBy)
+	})
+}
+
+func TestAcknowledgeState(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewMock()
+
+	instanceStore := &state.FakeInstanceStore{}
+	historian := &state.FakeHistorian{}
+	cfg := state.ManagerCfg{
+		Metrics:       metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+		ExternalURL:   nil,
+		InstanceStore: instanceStore,
+		Images:        &state.NoopImageService{},
+		Clock:         clk,
+		Historian:     historian,
+		Tracer:        tracing.InitializeTracerForTest(),
+		Log:           log.New("ngalert.state.manager"),
+
+		MaxStateSaveConcurrency: 1,
+	}
+	st := state.NewManager(cfg, state.NewSyncStatePersisiter(log.New("ngalert.state.manager.persist"), cfg))
+
+	rule := models.AlertRuleGen(models.WithFor(0), models.WithOrgID(1), models.WithInterval(10*time.Second))()
+
+	dc1 := data.Labels{"datacenter": "dc1"}
+	dc2 := data.Labels{"datacenter": "dc2"}
+	evaluate := func(results ...eval.Result) []state.StateTransition {
+		t.Helper()
+		clk.Add(time.Duration(rule.IntervalSeconds) * time.Second)
+		for i := range results {
+			results[i].EvaluatedAt = clk.Now()
+		}
+		return st.ProcessEvalResults(ctx, clk.Now(), rule, results, nil)
+	}
+	fingerprint := func(l data.Labels) string {
+		t.Helper()
+		for _, s := range st.GetStatesForRuleUID(rule.OrgID, rule.UID) {
+			if s.Labels["datacenter"] == l["datacenter"] {
+				return s.Labels.Fingerprint().String()
+			}
+		}
+		require.FailNow(t, "state not found")
+		return ""
+	}
+	// sent returns the alerts sent to the Alertmanager by datacenter.
+	sent := func(transitions []state.StateTransition) map[string]amv2.PostableAlert {
+		t.Helper()
+		result := map[string]amv2.PostableAlert{}
+		for _, a := range state.FromStateTransitionToPostableAlerts(transitions, st, nil).PostableAlerts {
+			result[a.Labels["datacenter"]] = a
+		}
+		return result
+	}
+
+	evaluate(
+		eval.ResultGen(eval.WithState(eval.Alerting), eval.WithLabels(dc1))(),
+		eval.ResultGen(eval.WithState(eval.Normal), eval.WithLabels(dc2))(),
+	)
+
+	t.Run("unknown and not firing instances cannot be acknowledged", func(t *testing.T) {
+		_, err := st.AcknowledgeState(ctx, rule, "unknown", models.AlertInstanceAcknowledgement{By: "admin"})
+		require.ErrorIs(t, err, state.ErrAlertInstanceNotFound)
+
+		_, err = st.AcknowledgeState(ctx, rule, fingerprint(dc2), models.AlertInstanceAcknowledgement{By: "admin"})
+		require.ErrorIs(t, err, state.ErrAlertInstanceNotFiring)
+	})
+
+	t.Run("acknowledgement is saved and recorded in the state history", func(t *testing.T) {
+		ack := models.AlertInstanceAcknowledgement{
+			By:                    "admin",
+			Comment:               "looking into it",
+			At:                    clk.Now(),
+			ExpiresAt:             clk.Now().Add(10 * time.Minute),
+			SuppressNotifications: true,
+		}
+		s, err := st.AcknowledgeState(ctx, rule, fingerprint(dc1), ack)
+		require.NoError(t, err)
+		require.Equal(t, models.StateReasonAcknowledged, s.StateReason)
+		require.Equal(t, &ack, s.Acknowledgement)
+
+		ops := instanceStore.RecordedOps()
+		require.IsType(t, models.AlertInstance{}, ops[len(ops)-1])
+		saved := ops[len(ops)-1].(models.AlertInstance)
+		require.Equal(t, models.StateReasonAcknowledged, saved.CurrentReason)
+		require.Equal(t, &ack, saved.Acknowledgement)
+
+		recorded := historian.StateTransitions[len(historian.StateTransitions)-1]
+		require.Equal(t, eval.Alerting, recorded.PreviousState)
+		require.Empty(t, recorded.PreviousStateReason)
+		require.Equal(t, models.StateReasonAcknowledged, recorded.StateReason)
+	})
+
+	t.Run("notifications of acknowledged instances are suppressed", func(t *testing.T) {
+		clk.Add(state.ResendDelay)
+		transitions := evaluate(
+			eval.ResultGen(eval.WithState(eval.Alerting), eval.WithLabels(dc1))(),
+			eval.ResultGen(eval.WithState(eval.Alerting), eval.WithLabels(dc2))(),
+		)
+		for _, tr := range transitions {
+			if tr.Labels["datacenter"] == "dc1" {
+				require.False(t, tr.Changed())
+				require.NotNil(t, tr.Acknowledgement)
+			}
+		}
+		require.Equal(t, []string{"dc2"}, maps.Keys(sent(transitions)))
+	})
+
+	t.Run("acknowledged instances are not sent again", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			clk.Add(state.ResendDelay)
+			transitions := evaluate(
+				eval.ResultGen(eval.WithState(eval.Alerting), eval.WithLabels(dc1))(),
+				eval.ResultGen(eval.WithState(eval.Alerting), eval.WithLabels(dc2))(),
+			)
+			for _, tr := range transitions {
+				if tr.Labels["datacenter"] == "dc1" {
+					require.NotNil(t, tr.Acknowledgement)
+					require.False(t, tr.NeedsSending(state.ResendDelay))
+				}
+			}
+		}
+	})
+
+	t.Run("acknowledgement is removed when it expires", func(t *testing.T) {
+		clk.Add(10 * time.Minute)
+		transitions := evaluate(
+			eval.ResultGen(eval.WithState(eval.Alerting), eval.WithLabels(dc1))(),
+			eval.ResultGen(eval.WithState(eval.Alerting), eval.WithLabels(dc2))(),
+		)
+		for _, tr := range transitions {
+			require.Empty(t, tr.StateReason)
+			require.Nil(t, tr.Acknowledgement)
+		}
+		// dc2 is not sent again because of the resend delay.
+		require.Equal(t, []string{"dc1"}, maps.Keys(sent(transitions)))
+	})
+
+	t.Run("acknowledgement is removed when the state changes", func(t *testing.T) {
+		_, err := st.AcknowledgeState(ctx, rule, fingerprint(dc2), models.AlertInstanceAcknowledgement{By: "admin", At: clk.Now()})
+		require.NoError(t, err)
+
+		transitions := evaluate(
+			eval.ResultGen(eval.WithState(eval.Alerting), eval.WithLabels(dc1))(),
+			eval.ResultGen(eval.WithState(eval.Normal), eval.WithLabels(dc2))(),
+		)
+		for _, tr := range transitions {
+			if tr.Labels["datacenter"] == "dc2" {
+				require.Equal(t, eval.Normal, tr.State.State)
+				require.Equal(t, models.StateReasonAcknowledged, tr.PreviousStateReason)
+				require.Nil(t, tr.Acknowledgement)
+			}
+		}
+	})
+}
+
+func TestAcknowledgeStateOfRuleEvaluatedByAnotherInstance(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewMock()
+
+	newManager := func(store state.InstanceStore, sharded bool) *state.Manager {
+		cfg := state.ManagerCfg{
+			Metrics:           metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+			ExternalURL:       nil,
+			InstanceStore:     store,
+			Images:            &state.NoopImageService{},
+			Clock:             clk,
+			Historian:         &state.FakeHistorian{},
+			Tracer:            tracing.InitializeTracerForTest(),
+			Log:               log.New("ngalert.state.manager"),
+			ShardedEvaluation: sharded,
+
+			MaxStateSaveConcurrency: 1,
+		}
+		return state.NewManager(cfg, state.NewSyncStatePersisiter(log.New("ngalert.state.manager.persist"), cfg))
+	}
+	ownerStore := &storedInstances{}
+	owner := newManager(ownerStore, true)
+	otherStore := &storedInstances{}
+	other := newManager(otherStore, true)
+
+	rule := models.AlertRuleGen(models.WithFor(0), models.WithOrgID(1), models.WithInterval(10*time.Second))()
+	dc1 := data.Labels{"datacenter": "dc1"}
+	evaluate := func() state.StateTransition {
+		t.Helper()
+		clk.Add(time.Duration(rule.IntervalSeconds) * time.Second)
+		result := eval.ResultGen(eval.WithState(eval.Alerting), eval.WithLabels(dc1), eval.WithEvaluatedAt(clk.Now()))()
+		transitions := owner.ProcessEvalResults(ctx, clk.Now(), rule, eval.Results{result}, nil)
+		require.Len(t, transitions, 1)
+		return transitions[0]
+	}
+
+	firing := evaluate()
+	otherStore.instances = []*models.AlertInstance{{
+		AlertInstanceKey:  models.AlertInstanceKey{RuleOrgID: rule.OrgID, RuleUID: rule.UID},
+		Labels:            models.InstanceLabels(firing.Labels),
+		CurrentState:      models.InstanceStateFiring,
+		CurrentStateSince: firing.StartsAt,
+	}}
+
+	ack := models.AlertInstanceAcknowledgement{By: "admin", At: clk.Now(), SuppressNotifications: true}
+	s, err := other.AcknowledgeState(ctx, rule, firing.Labels.Fingerprint().String(), ack)
+	require.NoError(t, err)
+	require.Equal(t, &ack, s.Acknowledgement)
+	require.Empty(t, other.GetStatesForRuleUID(rule.OrgID, rule.UID), "the acknowledgement must not add the state to the cache")
+
+	ops := otherStore.RecordedOps()
+	require.IsType(t, models.AlertInstance{}, ops[len(ops)-1])
+	saved := ops[len(ops)-1].(models.AlertInstance)
+	require.Equal(t, models.StateReasonAcknowledged, saved.CurrentReason)
+	require.Equal(t, &ack, saved.Acknowledgement)
+
+	// the instance that evaluates the rule loads the acknowledgement from the store.
+	ownerStore.instances = []*models.AlertInstance{&saved}
+	tr := evaluate()
+	require.Equal(t, &ack, tr.Acknowledgement)
+	require.False(t, tr.NeedsSending(state.ResendDelay))
+}
+
 func TestDeleteStateByRuleUID(t *testing.T) {
 	interval := time.Minute
 	ctx := context.Background()
@@ -1919,7 +2437,7 @@ func TestDeleteStateByRuleUID(t *testing.T) {
 				Log:           log.New("ngalert.state.manager"),
 			}
 			st := state.NewManager(cfg, state.NewNoopPersister())
-			st.Warm(ctx, dbstore)
+			st.Warm(ctx, dbstore, nil)
 			q := &models.ListAlertInstancesQuery{RuleOrgID: rule.OrgID, RuleUID: rule.UID}
 			alerts, _ := dbstore.ListAlertInstances(ctx, q)
 			existingStatesForRule := st.GetStatesForRuleUID(rule.OrgID, rule.UID)
@@ -2060,7 +2578,7 @@ func TestResetStateByRuleUID(t *testing.T) {
 				Log:           log.New("ngalert.state.manager"),
 			}
 			st := state.NewManager(cfg, state.NewNoopPersister())
-			st.Warm(ctx, dbstore)
+			st.Warm(ctx, dbstore, nil)
 			q := &models.ListAlertInstancesQuery{RuleOrgID: rule.OrgID, RuleUID: rule.UID}
 			alerts, _ := dbstore.ListAlertInstances(ctx, q)
 			existingStatesForRule := st.GetStatesForRuleUID(rule.OrgID, rule.UID)
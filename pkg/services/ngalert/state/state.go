@@ -65,6 +65,22 @@ type State struct {
 	// conditions.
 	Values map[string]float64
 
+	// KeepFiringSince is the time of the first evaluation where the condition of an Alerting state
+	// stopped being met while the rule has KeepFiringFor. It is zero if the state is not kept firing.
+	KeepFiringSince time.Time
+
+	// InhibitedBy is the UID of the rule that inhibits the state. Inhibited states are not sent to the Alertmanager.
+	// It is empty if the state is not inhibited.
+	InhibitedBy string
+
+	// InhibitionStarted is set to true if this state is the transitional state in which an alert that was sent
+	// becomes inhibited. The alert is then sent once more, resolved, so that the Alertmanager stops notifying it.
+	InhibitionStarted bool
+
+	// Acknowledgement is set if a user acknowledged the state. It is removed when the state changes or the
+	// acknowledgement expires.
+	Acknowledgement *models.AlertInstanceAcknowledgement
+
 	StartsAt             time.Time
 	EndsAt               time.Time
 	LastSentAt           time.Time
@@ -96,6 +112,7 @@ func (a *State) SetAlerting(reason string, startsAt, endsAt time.Time) {
 	a.StartsAt = startsAt
 	a.EndsAt = endsAt
 	a.Error = nil
+	a.KeepFiringSince = time.Time{}
 }
 
 // SetPending the state to Pending. It changes both the start and end time.
@@ -105,6 +122,7 @@ func (a *State) SetPending(reason string, startsAt, endsAt time.Time) {
 	a.StartsAt = startsAt
 	a.EndsAt = endsAt
 	a.Error = nil
+	a.KeepFiringSince = time.Time{}
 }
 
 // SetNoData sets the state to NoData. It changes both the start and end time.
@@ -114,6 +132,7 @@ func (a *State) SetNoData(reason string, startsAt, endsAt time.Time) {
 	a.StartsAt = startsAt
 	a.EndsAt = endsAt
 	a.Error = nil
+	a.KeepFiringSince = time.Time{}
 }
 
 // SetError sets the state to Error. It changes both the start and end time.
@@ -123,6 +142,7 @@ func (a *State) SetError(err error, startsAt, endsAt time.Time) {
 	a.StartsAt = startsAt
 	a.EndsAt = endsAt
 	a.Error = err
+	a.KeepFiringSince = time.Time{}
 }
 
 // SetNormal sets the state to Normal. It changes both the start and end time.
@@ -132,6 +152,7 @@ func (a *State) SetNormal(reason string, startsAt, endsAt time.Time) {
 	a.StartsAt = startsAt
 	a.EndsAt = endsAt
 	a.Error = nil
+	a.KeepFiringSince = time.Time{}
 }
 
 // Resolve sets the State to Normal. It updates the StateReason, the end time, and sets Resolved to true.
@@ -191,9 +212,22 @@ func NewEvaluationValues(m map[string]eval.NumberValueCapture) map[string]*float
 	return result
 }
 
-func resultNormal(state *State, _ *models.AlertRule, result eval.Result, logger log.Logger, reason string) {
+func resultNormal(state *State, rule *models.AlertRule, result eval.Result, logger log.Logger, reason string) {
 	if state.State == eval.Normal {
 		logger.Debug("Keeping state", "state", state.State)
+	} else if state.State == eval.Alerting && rule.KeepFiringFor > 0 && keepFiring(state, rule, result.EvaluatedAt) {
+		// If the alert rule has a KeepFiringFor duration then the state stays Alerting until the duration is observed
+		prevEndsAt := state.EndsAt
+		state.Maintain(rule.IntervalSeconds, result.EvaluatedAt)
+		logger.Debug("Keeping state",
+			"state",
+			state.State,
+			"keep_firing_since",
+			state.KeepFiringSince,
+			"previous_ends_at",
+			prevEndsAt,
+			"next_ends_at",
+			state.EndsAt)
 	} else {
 		nextEndsAt := result.EvaluatedAt
 		logger.Debug("Changing state",
@@ -210,10 +244,20 @@ func resultNormal(state *State, _ *models.AlertRule, result eval.Result, logger
 	}
 }
 
+// keepFiring returns true if the Alerting state should be kept because the KeepFiringFor duration of the rule has not
+// passed since the condition stopped being met. It starts counting the duration on the first call.
+func keepFiring(state *State, rule *models.AlertRule, evaluatedAt time.Time) bool {
+	if state.KeepFiringSince.IsZero() {
+		state.KeepFiringSince = evaluatedAt
+	}
+	return evaluatedAt.Sub(state.KeepFiringSince) < rule.KeepFiringFor
+}
+
 func resultAlerting(state *State, rule *models.AlertRule, result eval.Result, logger log.Logger, reason string) {
 	switch state.State {
 	case eval.Alerting:
 		prevEndsAt := state.EndsAt
+		state.KeepFiringSince = time.Time{}
 		state.Maintain(rule.IntervalSeconds, result.EvaluatedAt)
 		logger.Debug("Keeping state",
 			"state",
@@ -409,6 +453,15 @@ func (a *State) NeedsSending(resendDelay time.Duration) bool {
 		// We should send a notification if the state is Normal because it was resolved
 		return a.Resolved
 	default:
+		if a.InhibitedBy != "" {
+			// Inhibited states are only sent to resolve the alert that was sent before the state was inhibited.
+			return a.InhibitionStarted
+		}
+		if a.Acknowledgement != nil && a.Acknowledgement.SuppressNotifications {
+			// Acknowledged states are not re-sent if the user suppressed the notifications. The state expires in the
+			// Alertmanager at EndsAt, and it is sent again when the acknowledgement is removed.
+			return false
+		}
 		// We should send, and re-send notifications, each time LastSentAt is <= LastEvaluationTime + resendDelay
 		nextSent := a.LastSentAt.Add(resendDelay)
 		return nextSent.Before(a.LastEvaluationTime) || nextSent.Equal(a.LastEvaluationTime)
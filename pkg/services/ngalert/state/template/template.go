@@ -84,6 +84,28 @@ func NewData(labels map[string]string, res eval.Result) Data {
 	}
 }
 
+// QueryFunc executes the query of the `query` function of templates at the given time.
+type QueryFunc = template.QueryFunc
+
+type queryFuncContextKey struct{}
+
+// WithQueryFunc returns a copy of the context with the function that executes the queries of the `query`
+// function of the templates expanded with this context.
+func WithQueryFunc(ctx context.Context, queryFunc QueryFunc) context.Context {
+	return context.WithValue(ctx, queryFuncContextKey{}, queryFunc)
+}
+
+// QueryFuncFromContext returns the function that executes the queries of the `query` function of templates.
+// If the context has no such function, the `query` function returns no results.
+func QueryFuncFromContext(ctx context.Context) QueryFunc {
+	if queryFunc, ok := ctx.Value(queryFuncContextKey{}).(QueryFunc); ok && queryFunc != nil {
+		return queryFunc
+	}
+	return func(context.Context, string, time.Time) (promql.Vector, error) {
+		return nil, nil
+	}
+}
+
 // ExpandError is an error containing the template and the error that occurred
 // while expanding it.
 type ExpandError struct {
@@ -104,10 +126,8 @@ func Expand(ctx context.Context, name, tmpl string, data Data, externalURL *url.
 	name = "__alert_" + name
 	// add variables for the labels and values to the beginning of the template
 	tmpl = "{{- $labels := .Labels -}}{{- $values := .Values -}}{{- $value := .Value -}}" + tmpl
-	// ctx and queryFunc are no-ops as `query()` is not supported in Grafana
-	queryFunc := func(context.Context, string, time.Time) (promql.Vector, error) {
-		return nil, nil
-	}
+	// `query` executes the queries with the function from the context, if any
+	queryFunc := QueryFuncFromContext(ctx)
 	tm := model.Time(timestamp.FromTime(evaluatedAt))
 	// Use missingkey=invalid so missing data shows <no value> instead of the type's default value
 	options := []string{"missingkey=invalid"}
@@ -5,8 +5,11 @@ import (
 	"errors"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -447,3 +450,66 @@ func TestExpandTemplate(t *testing.T) {
 		})
 	}
 }
+
+func TestExpandQuery(t *testing.T) {
+	evaluatedAt := time.Unix(1000, 0)
+	var queries []string
+	queryFunc := func(_ context.Context, q string, ts time.Time) (promql.Vector, error) {
+		queries = append(queries, q)
+		require.Equal(t, evaluatedAt, ts)
+		if q == "error" {
+			return nil, errors.New("query failed")
+		}
+		return promql.Vector{
+			{Metric: labels.FromStrings("pod", "b"), F: 2},
+			{Metric: labels.FromStrings("pod", "a"), F: 1.5},
+		}, nil
+	}
+	ctx := WithQueryFunc(context.Background(), queryFunc)
+
+	cases := []struct {
+		name          string
+		text          string
+		expected      string
+		expectedError string
+	}{{
+		name:     "query results can be iterated",
+		text:     `{{ range query "up" }}{{ .Labels.pod }}={{ .Value }} {{ end }}`,
+		expected: "b=2 a=1.5 ",
+	}, {
+		name:     "query results can be sorted and reduced with first, label and value",
+		text:     `{{ with query "up" | sortByLabel "pod" | first }}{{ label "pod" . }} {{ value . }}{{ end }}`,
+		expected: "a 1.5",
+	}, {
+		name:          "failed query fails the expansion",
+		text:          `{{ query "error" }}`,
+		expectedError: "query failed",
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			queries = nil
+			v, err := Expand(ctx, "test", c.text, Data{Labels: Labels{"pod": "a"}}, nil, evaluatedAt)
+			if c.expectedError != "" {
+				require.ErrorContains(t, err, c.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expected, v)
+			require.Len(t, queries, 1)
+		})
+	}
+
+	t.Run("query returns no results without a query function", func(t *testing.T) {
+		v, err := Expand(context.Background(), "test", `{{ query "up" | len }}`, Data{}, nil, evaluatedAt)
+		require.NoError(t, err)
+		require.Equal(t, "0", v)
+	})
+
+	t.Run("query uses the labels of the alert", func(t *testing.T) {
+		queries = nil
+		_, err := Expand(ctx, "test", `{{ query (printf "up{pod=%q}" $labels.pod) }}`, Data{Labels: Labels{"pod": "a"}}, nil, evaluatedAt)
+		require.NoError(t, err)
+		require.Equal(t, []string{`up{pod="a"}`}, queries)
+	})
+}
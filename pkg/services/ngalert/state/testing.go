@@ -77,10 +77,13 @@ func (f *FakeRuleReader) ListAlertRules(_ context.Context, q *models.ListAlertRu
 }
 
 type FakeHistorian struct {
+	mtx              sync.Mutex
 	StateTransitions []StateTransition
 }
 
 func (f *FakeHistorian) Record(ctx context.Context, rule history_model.RuleMeta, states []StateTransition) <-chan error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
 	f.StateTransitions = append(f.StateTransitions, states...)
 	errCh := make(chan error)
 	close(errCh)
@@ -119,6 +119,15 @@ func (st DBstore) GetAlertRulesGroupByRuleUID(ctx context.Context, query *ngmode
 	return result, err
 }
 
+// GetAlertRuleVersions returns all stored versions of the alert rule with the given UID, ordered from the newest to the oldest.
+func (st DBstore) GetAlertRuleVersions(ctx context.Context, orgID int64, ruleUID string) ([]*ngmodels.AlertRuleVersion, error) {
+	var versions []*ngmodels.AlertRuleVersion
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table(ngmodels.AlertRuleVersion{}).Where("rule_org_id = ? AND rule_uid = ?", orgID, ruleUID).Desc("version").Find(&versions)
+	})
+	return versions, err
+}
+
 // InsertAlertRules is a handler for creating/updating alert rules.
 // Returns the UID and ID of rules that were created in the same order as the input rules.
 func (st DBstore) InsertAlertRules(ctx context.Context, rules []ngmodels.AlertRule) ([]ngmodels.AlertRuleKeyWithId, error) {
@@ -158,9 +167,13 @@ func (st DBstore) InsertAlertRules(ctx context.Context, rules []ngmodels.AlertRu
 				NoDataState:          r.NoDataState,
 				ExecErrState:         r.ExecErrState,
 				For:                  r.For,
+				KeepFiringFor:        r.KeepFiringFor,
 				Annotations:          r.Annotations,
 				Labels:               r.Labels,
+				IsPaused:             r.IsPaused,
 				NotificationSettings: r.NotificationSettings,
+				Record:               r.Record,
+				InhibitedBy:          r.InhibitedBy,
 			})
 		}
 		if len(newRules) > 0 {
@@ -235,9 +248,13 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, rules []ngmodels.UpdateR
 				NoDataState:          r.New.NoDataState,
 				ExecErrState:         r.New.ExecErrState,
 				For:                  r.New.For,
+				KeepFiringFor:        r.New.KeepFiringFor,
 				Annotations:          r.New.Annotations,
 				Labels:               r.New.Labels,
+				IsPaused:             r.New.IsPaused,
 				NotificationSettings: r.New.NotificationSettings,
+				Record:               r.New.Record,
+				InhibitedBy:          r.New.InhibitedBy,
 			})
 		}
 		if len(ruleVersions) > 0 {
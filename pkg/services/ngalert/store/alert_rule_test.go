@@ -652,6 +652,91 @@ func TestIntegrationInsertAlertRules(t *testing.T) {
 	require.ErrorContains(t, err, deref[0].NamespaceUID)
 }
 
+func TestIntegrationAlertRulesRecord(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	sqlStore := db.InitTestDB(t)
+	cfg := setting.NewCfg()
+	cfg.UnifiedAlerting.BaseInterval = 1 * time.Second
+	store := &DBstore{
+		SQLStore:      sqlStore,
+		FolderService: setupFolderService(t, sqlStore, cfg, featuremgmt.WithFeatures()),
+		Logger:        log.New("test-dbstore"),
+		Cfg:           cfg.UnifiedAlerting,
+	}
+
+	gen := models.AlertRuleGen(models.WithOrgID(1), withIntervalMatching(store.Cfg.BaseInterval))
+	recording := gen()
+	models.WithRecord("test_metric", recording.Data[0].RefID)(recording)
+	alerting := gen()
+	alerting.Record = nil
+
+	_, err := store.InsertAlertRules(context.Background(), []models.AlertRule{*recording, *alerting})
+	require.NoError(t, err)
+
+	t.Run("should read back the record of a recording rule", func(t *testing.T) {
+		rule, err := store.GetAlertRuleByUID(context.Background(), &models.GetAlertRuleByUIDQuery{OrgID: 1, UID: recording.UID})
+		require.NoError(t, err)
+		require.Equal(t, recording.Record, rule.Record)
+		require.Equal(t, models.RuleTypeRecording, rule.Type())
+	})
+
+	t.Run("should not set the record of an alerting rule", func(t *testing.T) {
+		rule, err := store.GetAlertRuleByUID(context.Background(), &models.GetAlertRuleByUIDQuery{OrgID: 1, UID: alerting.UID})
+		require.NoError(t, err)
+		require.Nil(t, rule.Record)
+		require.Equal(t, models.RuleTypeAlerting, rule.Type())
+	})
+}
+
+func TestIntegrationGetAlertRuleVersions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	sqlStore := db.InitTestDB(t)
+	cfg := setting.NewCfg()
+	cfg.UnifiedAlerting.BaseInterval = 1 * time.Second
+	store := &DBstore{
+		SQLStore:      sqlStore,
+		FolderService: setupFolderService(t, sqlStore, cfg, featuremgmt.WithFeatures()),
+		Logger:        log.New("test-dbstore"),
+		Cfg:           cfg.UnifiedAlerting,
+	}
+
+	rule := models.AlertRuleGen(models.WithOrgID(1), withIntervalMatching(store.Cfg.BaseInterval))()
+	ids, err := store.InsertAlertRules(context.Background(), []models.AlertRule{*rule})
+	require.NoError(t, err)
+	key := ids[0].AlertRuleKey
+
+	titles := []string{rule.Title}
+	for i := 0; i < 2; i++ {
+		existing, err := store.GetAlertRuleByUID(context.Background(), &models.GetAlertRuleByUIDQuery{OrgID: key.OrgID, UID: key.UID})
+		require.NoError(t, err)
+		updated := models.CopyRule(existing)
+		updated.Title = util.GenerateShortUID()
+		updated.IsPaused = !existing.IsPaused
+		titles = append(titles, updated.Title)
+		require.NoError(t, store.UpdateAlertRules(context.Background(), []models.UpdateRule{{Existing: existing, New: *updated}}))
+	}
+
+	versions, err := store.GetAlertRuleVersions(context.Background(), key.OrgID, key.UID)
+	require.NoError(t, err)
+	require.Len(t, versions, 3)
+	for i, v := range versions {
+		require.Equal(t, int64(3-i), v.Version)
+		require.Equal(t, titles[2-i], v.Title)
+		require.Equal(t, key.UID, v.RuleUID)
+	}
+	require.NotEqual(t, versions[0].IsPaused, versions[1].IsPaused)
+
+	versions, err = store.GetAlertRuleVersions(context.Background(), key.OrgID+1, key.UID)
+	require.NoError(t, err)
+	require.Empty(t, versions)
+}
+
 func TestIntegrationAlertRulesNotificationSettings(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test")
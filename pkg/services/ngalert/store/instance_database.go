@@ -55,12 +55,16 @@ func (st DBstore) SaveAlertInstance(ctx context.Context, alertInstance models.Al
 		if err != nil {
 			return err
 		}
-		params := append(make([]any, 0), alertInstance.RuleOrgID, alertInstance.RuleUID, labelTupleJSON, alertInstance.LabelsHash, alertInstance.CurrentState, alertInstance.CurrentReason, alertInstance.CurrentStateSince.Unix(), alertInstance.CurrentStateEnd.Unix(), alertInstance.LastEvalTime.Unix(), alertInstance.ResultFingerprint)
+		ack, err := acknowledgementToDB(alertInstance.Acknowledgement)
+		if err != nil {
+			return err
+		}
+		params := append(make([]any, 0), alertInstance.RuleOrgID, alertInstance.RuleUID, labelTupleJSON, alertInstance.LabelsHash, alertInstance.CurrentState, alertInstance.CurrentReason, alertInstance.CurrentStateSince.Unix(), alertInstance.CurrentStateEnd.Unix(), alertInstance.LastEvalTime.Unix(), alertInstance.ResultFingerprint, ack)
 
 		upsertSQL := st.SQLStore.GetDialect().UpsertSQL(
 			"alert_instance",
 			[]string{"rule_org_id", "rule_uid", "labels_hash"},
-			[]string{"rule_org_id", "rule_uid", "labels", "labels_hash", "current_state", "current_reason", "current_state_since", "current_state_end", "last_eval_time", "result_fingerprint"})
+			[]string{"rule_org_id", "rule_uid", "labels", "labels_hash", "current_state", "current_reason", "current_state_since", "current_state_end", "last_eval_time", "result_fingerprint", "acknowledgement"})
 		_, err = sess.SQL(upsertSQL, params...).Query()
 		if err != nil {
 			return err
@@ -70,6 +74,18 @@ func (st DBstore) SaveAlertInstance(ctx context.Context, alertInstance models.Al
 	})
 }
 
+// acknowledgementToDB returns the json of the acknowledgement, or nil if the alert instance is not acknowledged.
+func acknowledgementToDB(ack *models.AlertInstanceAcknowledgement) (any, error) {
+	if ack == nil {
+		return nil, nil
+	}
+	b, err := ack.ToDB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize alert instance acknowledgement: %w", err)
+	}
+	return string(b), nil
+}
+
 func (st DBstore) FetchOrgIds(ctx context.Context) ([]int64, error) {
 	orgIds := []int64{}
 
@@ -219,8 +235,14 @@ func (st DBstore) FullSync(ctx context.Context, instances []models.AlertInstance
 				continue
 			}
 
-			_, err = sess.Exec("INSERT INTO alert_instance (rule_org_id, rule_uid, labels, labels_hash, current_state, current_reason, current_state_since, current_state_end, last_eval_time) VALUES (?,?,?,?,?,?,?,?,?)",
-				alertInstance.RuleOrgID, alertInstance.RuleUID, labelTupleJSON, alertInstance.LabelsHash, alertInstance.CurrentState, alertInstance.CurrentReason, alertInstance.CurrentStateSince.Unix(), alertInstance.CurrentStateEnd.Unix(), alertInstance.LastEvalTime.Unix())
+			ack, err := acknowledgementToDB(alertInstance.Acknowledgement)
+			if err != nil {
+				st.Logger.Warn("Failed to serialize alert instance acknowledgement, skipping", "err", err, "rule_uid", alertInstance.RuleUID)
+				continue
+			}
+
+			_, err = sess.Exec("INSERT INTO alert_instance (rule_org_id, rule_uid, labels, labels_hash, current_state, current_reason, current_state_since, current_state_end, last_eval_time, acknowledgement) VALUES (?,?,?,?,?,?,?,?,?,?)",
+				alertInstance.RuleOrgID, alertInstance.RuleUID, labelTupleJSON, alertInstance.LabelsHash, alertInstance.CurrentState, alertInstance.CurrentReason, alertInstance.CurrentStateSince.Unix(), alertInstance.CurrentStateEnd.Unix(), alertInstance.LastEvalTime.Unix(), ack)
 			if err != nil {
 				return fmt.Errorf("failed to insert into alert_instance table: %w", err)
 			}
@@ -294,6 +294,49 @@ func TestIntegrationAlertInstanceOperations(t *testing.T) {
 		require.Equal(t, instance2.Labels, alerts[0].Labels)
 		require.Equal(t, instance2.CurrentState, alerts[0].CurrentState)
 	})
+
+	t.Run("can save, read and remove the acknowledgement of alert instance", func(t *testing.T) {
+		alertRule := tests.CreateTestAlertRule(t, ctx, dbstore, 60, mainOrgID)
+		labels := models.InstanceLabels{"test": "testValue"}
+		_, hash, _ := labels.StringAndHash()
+		ack := &models.AlertInstanceAcknowledgement{
+			By:                    "admin",
+			Comment:               "looking into it",
+			At:                    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			ExpiresAt:             time.Date(2024, 1, 2, 5, 4, 5, 0, time.UTC),
+			SuppressNotifications: true,
+		}
+		instance := models.AlertInstance{
+			AlertInstanceKey: models.AlertInstanceKey{
+				RuleOrgID:  alertRule.OrgID,
+				RuleUID:    alertRule.UID,
+				LabelsHash: hash,
+			},
+			CurrentState:    models.InstanceStateFiring,
+			CurrentReason:   models.StateReasonAcknowledged,
+			Labels:          labels,
+			Acknowledgement: ack,
+		}
+		require.NoError(t, dbstore.SaveAlertInstance(ctx, instance))
+
+		listQuery := &models.ListAlertInstancesQuery{
+			RuleOrgID: alertRule.OrgID,
+			RuleUID:   alertRule.UID,
+		}
+		alerts, err := dbstore.ListAlertInstances(ctx, listQuery)
+		require.NoError(t, err)
+		require.Len(t, alerts, 1)
+		require.Equal(t, ack, alerts[0].Acknowledgement)
+
+		instance.Acknowledgement = nil
+		instance.CurrentReason = ""
+		require.NoError(t, dbstore.SaveAlertInstance(ctx, instance))
+
+		alerts, err = dbstore.ListAlertInstances(ctx, listQuery)
+		require.NoError(t, err)
+		require.Len(t, alerts, 1)
+		require.Nil(t, alerts[0].Acknowledgement)
+	})
 }
 
 func TestIntegrationFullSync(t *testing.T) {
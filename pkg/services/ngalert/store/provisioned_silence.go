@@ -0,0 +1,234 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type provisionedSilence struct {
+	ID        int64  `xorm:"pk autoincr 'id'"`
+	OrgID     int64  `xorm:"org_id"`
+	UID       string `xorm:"uid"`
+	Matchers  string `xorm:"matchers"`
+	Comment   string `xorm:"comment"`
+	CreatedBy string `xorm:"created_by"`
+	// StartsAt and EndsAt are the Unix times in milliseconds of a silence without a schedule.
+	StartsAt  *int64    `xorm:"starts_at"`
+	EndsAt    *int64    `xorm:"ends_at"`
+	Schedule  *string   `xorm:"schedule"`
+	SilenceID string    `xorm:"silence_id"`
+	Updated   time.Time `xorm:"updated"`
+}
+
+func (provisionedSilence) TableName() string {
+	return "alert_provisioned_silence"
+}
+
+type provisionedSilenceSchedule struct {
+	Cron     string `json:"cron"`
+	Duration string `json:"duration"`
+	Location string `json:"location,omitempty"`
+}
+
+// ListProvisionedSilences returns the provisioned silences of the organization, or of all organizations if orgID is 0.
+func (st DBstore) ListProvisionedSilences(ctx context.Context, orgID int64) ([]models.ProvisionedSilence, error) {
+	var result []models.ProvisionedSilence
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Table(provisionedSilence{})
+		if orgID > 0 {
+			q = q.Where("org_id = ?", orgID)
+		}
+		var rows []provisionedSilence
+		if err := q.Asc("org_id", "uid").Find(&rows); err != nil {
+			return fmt.Errorf("failed to list provisioned silences: %w", err)
+		}
+		result = make([]models.ProvisionedSilence, 0, len(rows))
+		for _, row := range rows {
+			silence, err := row.toModel()
+			if err != nil {
+				st.Logger.Error("Invalid provisioned silence in the database, skipping it", "org_id", row.OrgID, "uid", row.UID, "error", err)
+				continue
+			}
+			result = append(result, silence)
+		}
+		return nil
+	})
+	return result, err
+}
+
+// GetProvisionedSilence returns the provisioned silence with the UID, or ErrProvisionedSilenceNotFound.
+func (st DBstore) GetProvisionedSilence(ctx context.Context, orgID int64, uid string) (models.ProvisionedSilence, error) {
+	var result models.ProvisionedSilence
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		var row provisionedSilence
+		has, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(&row)
+		if err != nil {
+			return fmt.Errorf("failed to get provisioned silence: %w", err)
+		}
+		if !has {
+			return models.ErrProvisionedSilenceNotFound
+		}
+		result, err = row.toModel()
+		return err
+	})
+	return result, err
+}
+
+// InsertProvisionedSilence stores a new provisioned silence. It returns ErrProvisionedSilenceExists if there is
+// already a provisioned silence with the UID.
+func (st DBstore) InsertProvisionedSilence(ctx context.Context, silence models.ProvisionedSilence) error {
+	row, err := provisionedSilenceFromModel(silence)
+	if err != nil {
+		return err
+	}
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Table(provisionedSilence{}).Where("org_id = ? AND uid = ?", row.OrgID, row.UID).Exist()
+		if err != nil {
+			return fmt.Errorf("failed to check if the provisioned silence exists: %w", err)
+		}
+		if exists {
+			return models.ErrProvisionedSilenceExists
+		}
+		row.ID = 0
+		if _, err := sess.Insert(&row); err != nil {
+			return fmt.Errorf("failed to insert provisioned silence: %w", err)
+		}
+		return nil
+	})
+}
+
+// UpdateProvisionedSilence replaces the definition of the provisioned silence with the same UID. The ID of the
+// silence in the Alertmanager is not changed. It returns ErrProvisionedSilenceNotFound if there is no such silence.
+func (st DBstore) UpdateProvisionedSilence(ctx context.Context, silence models.ProvisionedSilence) error {
+	row, err := provisionedSilenceFromModel(silence)
+	if err != nil {
+		return err
+	}
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Table(provisionedSilence{}).
+			Where("org_id = ? AND uid = ?", row.OrgID, row.UID).
+			Cols("matchers", "comment", "created_by", "starts_at", "ends_at", "schedule", "updated").
+			Update(&row)
+		if err != nil {
+			return fmt.Errorf("failed to update provisioned silence: %w", err)
+		}
+		if affected == 0 {
+			return models.ErrProvisionedSilenceNotFound
+		}
+		return nil
+	})
+}
+
+// SetProvisionedSilenceID stores the ID of the silence created in the Alertmanager for the provisioned silence.
+func (st DBstore) SetProvisionedSilenceID(ctx context.Context, orgID int64, uid string, silenceID string) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Table(provisionedSilence{}).
+			Where("org_id = ? AND uid = ?", orgID, uid).
+			Cols("silence_id").
+			Update(&provisionedSilence{SilenceID: silenceID})
+		if err != nil {
+			return fmt.Errorf("failed to update the silence ID of the provisioned silence: %w", err)
+		}
+		return nil
+	})
+}
+
+// DeleteProvisionedSilence deletes the provisioned silence. It does not return an error if the silence does not exist.
+func (st DBstore) DeleteProvisionedSilence(ctx context.Context, orgID int64, uid string) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Delete(&provisionedSilence{}); err != nil {
+			return fmt.Errorf("failed to delete provisioned silence: %w", err)
+		}
+		return nil
+	})
+}
+
+func provisionedSilenceFromModel(s models.ProvisionedSilence) (provisionedSilence, error) {
+	matchers := make([]string, 0, len(s.Matchers))
+	for _, m := range s.Matchers {
+		matchers = append(matchers, m.String())
+	}
+	b, err := json.Marshal(matchers)
+	if err != nil {
+		return provisionedSilence{}, fmt.Errorf("failed to marshal silence matchers: %w", err)
+	}
+	row := provisionedSilence{
+		ID:        s.ID,
+		OrgID:     s.OrgID,
+		UID:       s.UID,
+		Matchers:  string(b),
+		Comment:   s.Comment,
+		CreatedBy: s.CreatedBy,
+		SilenceID: s.SilenceID,
+		Updated:   TimeNow().UTC(),
+	}
+	if s.Schedule != nil {
+		b, err := json.Marshal(provisionedSilenceSchedule{
+			Cron:     s.Schedule.Cron,
+			Duration: s.Schedule.Duration.String(),
+			Location: s.Schedule.Location,
+		})
+		if err != nil {
+			return provisionedSilence{}, fmt.Errorf("failed to marshal silence schedule: %w", err)
+		}
+		schedule := string(b)
+		row.Schedule = &schedule
+	} else {
+		startsAt, endsAt := s.StartsAt.UnixMilli(), s.EndsAt.UnixMilli()
+		row.StartsAt, row.EndsAt = &startsAt, &endsAt
+	}
+	return row, nil
+}
+
+func (row provisionedSilence) toModel() (models.ProvisionedSilence, error) {
+	var matchers []string
+	if err := json.Unmarshal([]byte(row.Matchers), &matchers); err != nil {
+		return models.ProvisionedSilence{}, fmt.Errorf("failed to unmarshal silence matchers: %w", err)
+	}
+	result := models.ProvisionedSilence{
+		ID:        row.ID,
+		OrgID:     row.OrgID,
+		UID:       row.UID,
+		Matchers:  make(labels.Matchers, 0, len(matchers)),
+		Comment:   row.Comment,
+		CreatedBy: row.CreatedBy,
+		SilenceID: row.SilenceID,
+		Updated:   row.Updated,
+	}
+	for _, m := range matchers {
+		matcher, err := labels.ParseMatcher(m)
+		if err != nil {
+			return models.ProvisionedSilence{}, fmt.Errorf("failed to parse silence matcher %q: %w", m, err)
+		}
+		result.Matchers = append(result.Matchers, matcher)
+	}
+	if row.Schedule != nil {
+		var schedule provisionedSilenceSchedule
+		if err := json.Unmarshal([]byte(*row.Schedule), &schedule); err != nil {
+			return models.ProvisionedSilence{}, fmt.Errorf("failed to unmarshal silence schedule: %w", err)
+		}
+		duration, err := time.ParseDuration(schedule.Duration)
+		if err != nil {
+			return models.ProvisionedSilence{}, fmt.Errorf("failed to parse silence schedule duration: %w", err)
+		}
+		result.Schedule = &models.SilenceSchedule{
+			Cron:     schedule.Cron,
+			Duration: duration,
+			Location: schedule.Location,
+		}
+	}
+	if row.StartsAt != nil {
+		result.StartsAt = time.UnixMilli(*row.StartsAt).UTC()
+	}
+	if row.EndsAt != nil {
+		result.EndsAt = time.UnixMilli(*row.EndsAt).UTC()
+	}
+	return result, nil
+}
@@ -0,0 +1,108 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestIntegrationProvisionedSilences(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	store := &DBstore{SQLStore: db.InitTestDB(t), Logger: log.NewNopLogger()}
+
+	matchers, err := labels.ParseMatchers(`{alertname="DiskFull", cluster=~"prod-.*"}`)
+	require.NoError(t, err)
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	oneOff := models.ProvisionedSilence{
+		OrgID:     1,
+		UID:       "maintenance",
+		Matchers:  matchers,
+		Comment:   "Planned maintenance",
+		CreatedBy: "ops",
+		StartsAt:  start,
+		EndsAt:    start.Add(2 * time.Hour),
+	}
+	scheduled := models.ProvisionedSilence{
+		OrgID:    2,
+		UID:      "weekly-backup",
+		Matchers: matchers[:1],
+		Comment:  "Weekly backup",
+		Schedule: &models.SilenceSchedule{Cron: "0 2 * * SUN", Duration: 2 * time.Hour, Location: "Europe/Berlin"},
+	}
+
+	t.Run("insert and get", func(t *testing.T) {
+		require.NoError(t, store.InsertProvisionedSilence(ctx, oneOff))
+		require.NoError(t, store.InsertProvisionedSilence(ctx, scheduled))
+
+		got, err := store.GetProvisionedSilence(ctx, 1, "maintenance")
+		require.NoError(t, err)
+		require.Equal(t, oneOff.Matchers.String(), got.Matchers.String())
+		require.Equal(t, oneOff.Comment, got.Comment)
+		require.Equal(t, oneOff.CreatedBy, got.CreatedBy)
+		require.True(t, oneOff.StartsAt.Equal(got.StartsAt))
+		require.True(t, oneOff.EndsAt.Equal(got.EndsAt))
+		require.Nil(t, got.Schedule)
+
+		got, err = store.GetProvisionedSilence(ctx, 2, "weekly-backup")
+		require.NoError(t, err)
+		require.Equal(t, scheduled.Schedule, got.Schedule)
+		require.True(t, got.StartsAt.IsZero())
+		require.True(t, got.EndsAt.IsZero())
+	})
+
+	t.Run("insert fails if the UID exists in the org", func(t *testing.T) {
+		require.ErrorIs(t, store.InsertProvisionedSilence(ctx, oneOff), models.ErrProvisionedSilenceExists)
+	})
+
+	t.Run("get fails if the silence does not exist", func(t *testing.T) {
+		_, err := store.GetProvisionedSilence(ctx, 2, "maintenance")
+		require.ErrorIs(t, err, models.ErrProvisionedSilenceNotFound)
+	})
+
+	t.Run("list by org and for all orgs", func(t *testing.T) {
+		silences, err := store.ListProvisionedSilences(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, silences, 1)
+		require.Equal(t, "maintenance", silences[0].UID)
+
+		silences, err = store.ListProvisionedSilences(ctx, 0)
+		require.NoError(t, err)
+		require.Len(t, silences, 2)
+	})
+
+	t.Run("update keeps the silence ID", func(t *testing.T) {
+		require.NoError(t, store.SetProvisionedSilenceID(ctx, 1, "maintenance", "silence-1"))
+
+		updated := oneOff
+		updated.Comment = "Extended maintenance"
+		updated.EndsAt = start.Add(4 * time.Hour)
+		require.NoError(t, store.UpdateProvisionedSilence(ctx, updated))
+
+		got, err := store.GetProvisionedSilence(ctx, 1, "maintenance")
+		require.NoError(t, err)
+		require.Equal(t, "Extended maintenance", got.Comment)
+		require.True(t, updated.EndsAt.Equal(got.EndsAt))
+		require.Equal(t, "silence-1", got.SilenceID)
+
+		missing := oneOff
+		missing.UID = "missing"
+		require.ErrorIs(t, store.UpdateProvisionedSilence(ctx, missing), models.ErrProvisionedSilenceNotFound)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, store.DeleteProvisionedSilence(ctx, 1, "maintenance"))
+		require.NoError(t, store.DeleteProvisionedSilence(ctx, 1, "maintenance"))
+		_, err := store.GetProvisionedSilence(ctx, 1, "maintenance")
+		require.ErrorIs(t, err, models.ErrProvisionedSilenceNotFound)
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"sort"
 	"sync"
 	"testing"
 	"time"
@@ -25,6 +26,8 @@ type RuleStore struct {
 	Hook        func(cmd any) error // use Hook if you need to intercept some query and return an error
 	RecordedOps []any
 	Folders     map[int64][]*folder.Folder
+	// OrgID -> Versions of rules
+	Versions map[int64][]*models.AlertRuleVersion
 }
 
 type GenericRecordedQuery struct {
@@ -39,7 +42,8 @@ func NewRuleStore(t *testing.T) *RuleStore {
 		Hook: func(any) error {
 			return nil
 		},
-		Folders: map[int64][]*folder.Folder{},
+		Folders:  map[int64][]*folder.Folder{},
+		Versions: map[int64][]*models.AlertRuleVersion{},
 	}
 }
 
@@ -80,6 +84,15 @@ mainloop:
 }
 
 // GetRecordedCommands filters recorded commands using predicate function. Returns the subset of the recorded commands that meet the predicate
+// PutRuleVersion puts the versions of rules in the Versions map.
+func (f *RuleStore) PutRuleVersion(versions ...*models.AlertRuleVersion) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for _, v := range versions {
+		f.Versions[v.RuleOrgID] = append(f.Versions[v.RuleOrgID], v)
+	}
+}
+
 func (f *RuleStore) GetRecordedCommands(predicate func(cmd any) (any, bool)) []any {
 	f.mtx.Lock()
 	defer f.mtx.Unlock()
@@ -230,6 +243,29 @@ func (f *RuleStore) ListAlertRules(_ context.Context, q *models.ListAlertRulesQu
 	return ruleList, nil
 }
 
+func (f *RuleStore) GetAlertRuleVersions(_ context.Context, orgID int64, ruleUID string) ([]*models.AlertRuleVersion, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	q := GenericRecordedQuery{
+		Name:   "GetAlertRuleVersions",
+		Params: []any{orgID, ruleUID},
+	}
+	f.RecordedOps = append(f.RecordedOps, q)
+	if err := f.Hook(q); err != nil {
+		return nil, err
+	}
+	var result []*models.AlertRuleVersion
+	for _, v := range f.Versions[orgID] {
+		if v.RuleUID == ruleUID {
+			result = append(result, v)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version > result[j].Version
+	})
+	return result, nil
+}
+
 func (f *RuleStore) GetUserVisibleNamespaces(_ context.Context, orgID int64, _ identity.Requester) (map[string]*folder.Folder, error) {
 	f.mtx.Lock()
 	defer f.mtx.Unlock()
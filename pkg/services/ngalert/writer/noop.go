@@ -0,0 +1,15 @@
+package writer
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// NoopWriter is a writer that discards the results of recording rules, to be used when recording rules are disabled.
+type NoopWriter struct{}
+
+func (w NoopWriter) Write(_ context.Context, _ string, _ time.Time, _ data.Frames, _ map[string]string) error {
+	return nil
+}
@@ -0,0 +1,110 @@
+package writer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/live/remotewrite"
+	"github.com/grafana/grafana/pkg/services/ngalert/client"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// PrometheusConfig is the configuration of a Prometheus remote write endpoint.
+type PrometheusConfig struct {
+	URL               *url.URL
+	BasicAuthUser     string
+	BasicAuthPassword string
+	Timeout           time.Duration
+}
+
+// NewPrometheusConfig builds the configuration of the remote write endpoint from the recording rules settings.
+func NewPrometheusConfig(cfg setting.RecordingRuleSettings) (PrometheusConfig, error) {
+	if cfg.URL == "" {
+		return PrometheusConfig{}, fmt.Errorf("remote write URL must be provided")
+	}
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return PrometheusConfig{}, fmt.Errorf("failed to parse remote write URL: %w", err)
+	}
+	return PrometheusConfig{
+		URL:               u,
+		BasicAuthUser:     cfg.BasicAuthUsername,
+		BasicAuthPassword: cfg.BasicAuthPassword,
+		Timeout:           cfg.Timeout,
+	}, nil
+}
+
+// PrometheusWriter writes the results of recording rules to a Prometheus remote write endpoint.
+type PrometheusWriter struct {
+	client client.Requester
+	cfg    PrometheusConfig
+	logger log.Logger
+}
+
+func NewPrometheusWriter(cfg PrometheusConfig, req client.Requester, logger log.Logger) *PrometheusWriter {
+	return &PrometheusWriter{
+		client: req,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// Write converts the frames to Prometheus time series named after the metric and sends them to the remote write endpoint.
+// Frames that do not have a time field, such as numbers produced by server side expressions, are recorded at the time t.
+func (w *PrometheusWriter) Write(ctx context.Context, name string, t time.Time, frames data.Frames, extraLabels map[string]string) error {
+	series, err := remotewrite.TimeSeriesFromFramesWithMetricName(name, t, extraLabels, frames...)
+	if err != nil {
+		return err
+	}
+	if len(series) == 0 {
+		w.logger.Debug("Nothing to write", "metric", name)
+		return nil
+	}
+
+	body, err := remotewrite.TimeSeriesToBytes(series)
+	if err != nil {
+		return fmt.Errorf("failed to serialize time series: %w", err)
+	}
+
+	if w.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.cfg.Timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.cfg.URL.String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create remote write request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	if w.cfg.BasicAuthUser != "" || w.cfg.BasicAuthPassword != "" {
+		req.SetBasicAuth(w.cfg.BasicAuthUser, w.cfg.BasicAuthPassword)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send remote write request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			w.logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("remote write endpoint returned a non-200 status code %d: %s", resp.StatusCode, string(msg))
+	}
+	w.logger.Debug("Recording rule result written", "metric", name, "series", len(series))
+	return nil
+}
@@ -0,0 +1,119 @@
+package writer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestNewPrometheusConfig(t *testing.T) {
+	t.Run("requires URL", func(t *testing.T) {
+		_, err := NewPrometheusConfig(setting.RecordingRuleSettings{Enabled: true})
+		require.Error(t, err)
+	})
+
+	t.Run("copies settings", func(t *testing.T) {
+		cfg, err := NewPrometheusConfig(setting.RecordingRuleSettings{
+			Enabled:           true,
+			URL:               "http://localhost:9090/api/v1/write",
+			BasicAuthUsername: "user",
+			BasicAuthPassword: "password",
+			Timeout:           time.Second,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "http://localhost:9090/api/v1/write", cfg.URL.String())
+		require.Equal(t, "user", cfg.BasicAuthUser)
+		require.Equal(t, "password", cfg.BasicAuthPassword)
+		require.Equal(t, time.Second, cfg.Timeout)
+	})
+}
+
+func TestPrometheusWriter_Write(t *testing.T) {
+	now := time.Now()
+	frames := data.Frames{
+		data.NewFrame("",
+			data.NewField("", data.Labels{"instance": "a"}, []*float64{util.Pointer(42.0)}),
+		),
+	}
+
+	t.Run("sends samples to remote write endpoint", func(t *testing.T) {
+		var received prompb.WriteRequest
+		var user, password string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, password, _ = r.BasicAuth()
+			require.Equal(t, "snappy", r.Header.Get("Content-Encoding"))
+			b, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			decoded, err := snappy.Decode(nil, b)
+			require.NoError(t, err)
+			require.NoError(t, proto.Unmarshal(decoded, &received))
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		t.Cleanup(srv.Close)
+
+		w := NewPrometheusWriter(PrometheusConfig{
+			URL:               mustParseURL(t, srv.URL),
+			BasicAuthUser:     "user",
+			BasicAuthPassword: "password",
+		}, http.DefaultClient, log.NewNopLogger())
+
+		err := w.Write(context.Background(), "test_metric", now, frames, map[string]string{"extra": "label"})
+		require.NoError(t, err)
+
+		require.Equal(t, "user", user)
+		require.Equal(t, "password", password)
+		require.Len(t, received.Timeseries, 1)
+		require.Equal(t, []prompb.Label{
+			{Name: "extra", Value: "label"},
+			{Name: "instance", Value: "a"},
+			{Name: "__name__", Value: "test_metric"},
+		}, received.Timeseries[0].Labels)
+		require.Equal(t, []prompb.Sample{{Value: 42, Timestamp: now.UnixMilli()}}, received.Timeseries[0].Samples)
+	})
+
+	t.Run("returns error if endpoint fails", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte("out of order sample"))
+		}))
+		t.Cleanup(srv.Close)
+
+		w := NewPrometheusWriter(PrometheusConfig{URL: mustParseURL(t, srv.URL)}, http.DefaultClient, log.NewNopLogger())
+		err := w.Write(context.Background(), "test_metric", now, frames, nil)
+		require.ErrorContains(t, err, "out of order sample")
+	})
+
+	t.Run("does not send empty requests", func(t *testing.T) {
+		called := false
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		}))
+		t.Cleanup(srv.Close)
+
+		w := NewPrometheusWriter(PrometheusConfig{URL: mustParseURL(t, srv.URL)}, http.DefaultClient, log.NewNopLogger())
+		err := w.Write(context.Background(), "test_metric", now, nil, nil)
+		require.NoError(t, err)
+		require.False(t, called)
+	})
+}
+
+func mustParseURL(t *testing.T, s string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(s)
+	require.NoError(t, err)
+	return u
+}
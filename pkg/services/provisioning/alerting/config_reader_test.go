@@ -3,10 +3,12 @@ package alerting
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
 )
 
 const (
@@ -33,6 +35,8 @@ const (
 	testFileCorrectProperties_t         = "./testdata/templates/correct-properties"
 	testFileCorrectPropertiesWithOrg_t  = "./testdata/templates/correct-properties-with-org"
 	testFileMultipleTs                  = "./testdata/templates/multiple-templates"
+	testFileCorrectProperties_s         = "./testdata/silences/correct-properties"
+	testFileInvalidMatcher_s            = "./testdata/silences/invalid-matcher"
 )
 
 func TestConfigReader(t *testing.T) {
@@ -143,6 +147,27 @@ func TestConfigReader(t *testing.T) {
 		require.NoError(t, err)
 		require.Len(t, file[0].MuteTimes, 2)
 	})
+	t.Run("a silences file with correct properties should not error", func(t *testing.T) {
+		file, err := configReader.readConfig(ctx, testFileCorrectProperties_s)
+		require.NoError(t, err)
+		require.Len(t, file[0].Silences, 2)
+		oneOff := file[0].Silences[0]
+		require.Equal(t, int64(1), oneOff.OrgID)
+		require.Equal(t, "maintenance", oneOff.UID)
+		require.Equal(t, `{alertname="DiskFull",cluster=~"prod-.*"}`, oneOff.Matchers.String())
+		require.Equal(t, time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), oneOff.EndsAt.UTC())
+		require.NoError(t, oneOff.Validate())
+		scheduled := file[0].Silences[1]
+		require.Equal(t, int64(1337), scheduled.OrgID)
+		require.Equal(t, "ops", scheduled.CreatedBy)
+		require.Equal(t, &models.SilenceSchedule{Cron: "0 2 * * SUN", Duration: 2 * time.Hour, Location: "Europe/Berlin"}, scheduled.Schedule)
+		require.NoError(t, scheduled.Validate())
+		require.Equal(t, []DeleteSilence{{OrgID: 1, UID: "old-maintenance"}}, file[0].DeleteSilences)
+	})
+	t.Run("a silences file with an invalid matcher should error", func(t *testing.T) {
+		_, err := configReader.readConfig(ctx, testFileInvalidMatcher_s)
+		require.ErrorContains(t, err, "silence 'maintenance' has an invalid matcher")
+	})
 	t.Run("a template file with correct properties and specific org should not error", func(t *testing.T) {
 		_, err := configReader.readConfig(ctx, testFileCorrectProperties_t)
 		require.NoError(t, err)
@@ -18,6 +18,7 @@ type ProvisionerConfig struct {
 	NotificiationPolicyService provisioning.NotificationPolicyService
 	MuteTimingService          provisioning.MuteTimingService
 	TemplateService            provisioning.TemplateService
+	SilenceService             provisioning.SilenceService
 }
 
 func Provision(ctx context.Context, cfg ProvisionerConfig) error {
@@ -74,6 +75,15 @@ func Provision(ctx context.Context, cfg ProvisionerConfig) error {
 	if err != nil {
 		return fmt.Errorf("contact points: %w", err)
 	}
+	silenceProvisioner := NewSilencesProvisioner(logger, cfg.SilenceService)
+	err = silenceProvisioner.Provision(ctx, files)
+	if err != nil {
+		return fmt.Errorf("silences: %w", err)
+	}
+	err = silenceProvisioner.Unprovision(ctx, files)
+	if err != nil {
+		return fmt.Errorf("silences: %w", err)
+	}
 	logger.Info("finished to provision alerting")
 	return nil
 }
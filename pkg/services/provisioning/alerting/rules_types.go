@@ -71,6 +71,7 @@ type AlertRuleV1 struct {
 	NoDataState          values.StringValue      `json:"noDataState" yaml:"noDataState"`
 	ExecErrState         values.StringValue      `json:"execErrState" yaml:"execErrState"`
 	For                  values.StringValue      `json:"for" yaml:"for"`
+	KeepFiringFor        values.StringValue      `json:"keepFiringFor" yaml:"keepFiringFor"`
 	Annotations          values.StringMapValue   `json:"annotations" yaml:"annotations"`
 	Labels               values.StringMapValue   `json:"labels" yaml:"labels"`
 	IsPaused             values.BoolValue        `json:"isPaused" yaml:"isPaused"`
@@ -93,6 +94,13 @@ func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
 		return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: %w", alertRule.Title, err)
 	}
 	alertRule.For = time.Duration(duration)
+	if keepFiringFor := strings.TrimSpace(rule.KeepFiringFor.Value()); keepFiringFor != "" {
+		duration, err := model.ParseDuration(keepFiringFor)
+		if err != nil {
+			return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse keepFiringFor: %w", alertRule.Title, err)
+		}
+		alertRule.KeepFiringFor = time.Duration(duration)
+	}
 	dashboardUID := rule.DashboardUID.Value()
 	alertRule.DashboardUID = &dashboardUID
 	panelID := rule.PanelID.Value()
@@ -127,6 +127,25 @@ func TestRules(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, 48*time.Hour, ruleMapped.For)
 	})
+	t.Run("a rule with out a keep firing for duration should default to zero", func(t *testing.T) {
+		rule := validRuleV1(t)
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		require.Equal(t, time.Duration(0), ruleMapped.KeepFiringFor)
+	})
+	t.Run("a rule with a keep firing for duration should work", func(t *testing.T) {
+		rule := validRuleV1(t)
+		rule.KeepFiringFor = stringToStringValue("5m")
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		require.Equal(t, 5*time.Minute, ruleMapped.KeepFiringFor)
+	})
+	t.Run("a rule with an invalid keep firing for duration should error", func(t *testing.T) {
+		rule := validRuleV1(t)
+		rule.KeepFiringFor = stringToStringValue("10x")
+		_, err := rule.mapToModel(1)
+		require.Error(t, err)
+	})
 	t.Run("a rule with out a condition should error", func(t *testing.T) {
 		rule := validRuleV1(t)
 		rule.Condition = values.StringValue{}
@@ -0,0 +1,63 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+)
+
+type SilencesProvisioner interface {
+	Provision(ctx context.Context, files []*AlertingFile) error
+	Unprovision(ctx context.Context, files []*AlertingFile) error
+}
+
+type defaultSilencesProvisioner struct {
+	logger         log.Logger
+	silenceService provisioning.SilenceService
+}
+
+func NewSilencesProvisioner(logger log.Logger,
+	silenceService provisioning.SilenceService) SilencesProvisioner {
+	return &defaultSilencesProvisioner{
+		logger:         logger,
+		silenceService: silenceService,
+	}
+}
+
+func (c *defaultSilencesProvisioner) Provision(ctx context.Context,
+	files []*AlertingFile) error {
+	for _, file := range files {
+		for _, silence := range file.Silences {
+			_, _, err := c.silenceService.GetSilence(ctx, silence.OrgID, silence.UID)
+			if err != nil {
+				if !errors.Is(err, provisioning.ErrSilenceNotFound) {
+					return err
+				}
+				if _, err := c.silenceService.CreateSilence(ctx, silence, models.ProvenanceFile); err != nil {
+					return err
+				}
+				continue
+			}
+			if _, err := c.silenceService.UpdateSilence(ctx, silence, models.ProvenanceFile); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *defaultSilencesProvisioner) Unprovision(ctx context.Context,
+	files []*AlertingFile) error {
+	for _, file := range files {
+		for _, deleteSilence := range file.DeleteSilences {
+			err := c.silenceService.DeleteSilence(ctx, deleteSilence.OrgID, deleteSilence.UID, models.ProvenanceFile)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package alerting
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/provisioning/values"
+)
+
+type SilenceV1 struct {
+	OrgID     values.Int64Value    `json:"orgId" yaml:"orgId"`
+	UID       values.StringValue   `json:"uid" yaml:"uid"`
+	Matchers  []values.StringValue `json:"matchers" yaml:"matchers"`
+	Comment   values.StringValue   `json:"comment" yaml:"comment"`
+	CreatedBy values.StringValue   `json:"createdBy" yaml:"createdBy"`
+	StartsAt  values.StringValue   `json:"startsAt" yaml:"startsAt"`
+	EndsAt    values.StringValue   `json:"endsAt" yaml:"endsAt"`
+	Schedule  *SilenceScheduleV1   `json:"schedule" yaml:"schedule"`
+}
+
+type SilenceScheduleV1 struct {
+	Cron     values.StringValue `json:"cron" yaml:"cron"`
+	Duration values.StringValue `json:"duration" yaml:"duration"`
+	Location values.StringValue `json:"location" yaml:"location"`
+}
+
+func (v1 *SilenceV1) mapToModel() (models.ProvisionedSilence, error) {
+	uid := strings.TrimSpace(v1.UID.Value())
+	if uid == "" {
+		return models.ProvisionedSilence{}, errors.New("silence missing uid")
+	}
+	orgID := v1.OrgID.Value()
+	if orgID < 1 {
+		orgID = 1
+	}
+	silence := models.ProvisionedSilence{
+		OrgID:     orgID,
+		UID:       uid,
+		Comment:   v1.Comment.Value(),
+		CreatedBy: v1.CreatedBy.Value(),
+	}
+	for _, m := range v1.Matchers {
+		matcher, err := labels.ParseMatcher(m.Value())
+		if err != nil {
+			return models.ProvisionedSilence{}, fmt.Errorf("silence '%s' has an invalid matcher: %w", uid, err)
+		}
+		silence.Matchers = append(silence.Matchers, matcher)
+	}
+	if v := strings.TrimSpace(v1.StartsAt.Value()); v != "" {
+		startsAt, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return models.ProvisionedSilence{}, fmt.Errorf("silence '%s' has an invalid startsAt: %w", uid, err)
+		}
+		silence.StartsAt = startsAt
+	}
+	if v := strings.TrimSpace(v1.EndsAt.Value()); v != "" {
+		endsAt, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return models.ProvisionedSilence{}, fmt.Errorf("silence '%s' has an invalid endsAt: %w", uid, err)
+		}
+		silence.EndsAt = endsAt
+	}
+	if v1.Schedule != nil {
+		duration, err := model.ParseDuration(v1.Schedule.Duration.Value())
+		if err != nil {
+			return models.ProvisionedSilence{}, fmt.Errorf("silence '%s' has an invalid schedule duration: %w", uid, err)
+		}
+		silence.Schedule = &models.SilenceSchedule{
+			Cron:     v1.Schedule.Cron.Value(),
+			Duration: time.Duration(duration),
+			Location: v1.Schedule.Location.Value(),
+		}
+	}
+	return silence, nil
+}
+
+type DeleteSilenceV1 struct {
+	OrgID values.Int64Value  `json:"orgId" yaml:"orgId"`
+	UID   values.StringValue `json:"uid" yaml:"uid"`
+}
+
+func (v1 *DeleteSilenceV1) mapToModel() (DeleteSilence, error) {
+	uid := strings.TrimSpace(v1.UID.Value())
+	if uid == "" {
+		return DeleteSilence{}, errors.New("delete silence missing uid")
+	}
+	orgID := v1.OrgID.Value()
+	if orgID < 1 {
+		orgID = 1
+	}
+	return DeleteSilence{
+		OrgID: orgID,
+		UID:   uid,
+	}, nil
+}
+
+type DeleteSilence struct {
+	OrgID int64
+	UID   string
+}
@@ -26,6 +26,8 @@ type AlertingFile struct {
 	DeleteMuteTimes     []DeleteMuteTime
 	Templates           []Template
 	DeleteTemplates     []DeleteTemplate
+	Silences            []models.ProvisionedSilence
+	DeleteSilences      []DeleteSilence
 }
 
 type AlertingFileV1 struct {
@@ -41,6 +43,8 @@ type AlertingFileV1 struct {
 	DeleteMuteTimes     []DeleteMuteTimeV1      `json:"deleteMuteTimes" yaml:"deleteMuteTimes"`
 	Templates           []TemplateV1            `json:"templates" yaml:"templates"`
 	DeleteTemplates     []DeleteTemplateV1      `json:"deleteTemplates" yaml:"deleteTemplates"`
+	Silences            []SilenceV1             `json:"silences" yaml:"silences"`
+	DeleteSilences      []DeleteSilenceV1       `json:"deleteSilences" yaml:"deleteSilences"`
 }
 
 func (fileV1 *AlertingFileV1) MapToModel() (AlertingFile, error) {
@@ -61,9 +65,30 @@ func (fileV1 *AlertingFileV1) MapToModel() (AlertingFile, error) {
 	if err := fileV1.mapTemplates(&alertingFile); err != nil {
 		return AlertingFile{}, fmt.Errorf("failure parsing templates: %w", err)
 	}
+	if err := fileV1.mapSilences(&alertingFile); err != nil {
+		return AlertingFile{}, fmt.Errorf("failure parsing silences: %w", err)
+	}
 	return alertingFile, nil
 }
 
+func (fileV1 *AlertingFileV1) mapSilences(alertingFile *AlertingFile) error {
+	for _, silenceV1 := range fileV1.Silences {
+		silence, err := silenceV1.mapToModel()
+		if err != nil {
+			return err
+		}
+		alertingFile.Silences = append(alertingFile.Silences, silence)
+	}
+	for _, deleteV1 := range fileV1.DeleteSilences {
+		delReq, err := deleteV1.mapToModel()
+		if err != nil {
+			return err
+		}
+		alertingFile.DeleteSilences = append(alertingFile.DeleteSilences, delReq)
+	}
+	return nil
+}
+
 func (fileV1 *AlertingFileV1) mapTemplates(alertingFile *AlertingFile) error {
 	for _, ttV1 := range fileV1.Templates {
 		alertingFile.Templates = append(alertingFile.Templates, ttV1.mapToModel())
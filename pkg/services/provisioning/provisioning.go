@@ -263,6 +263,8 @@ func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error
 		st, ps.SQLStore, ps.Cfg.UnifiedAlerting, ps.log)
 	mutetimingsService := provisioning.NewMuteTimingService(&st, st, &st, ps.log)
 	templateService := provisioning.NewTemplateService(&st, st, &st, ps.log)
+	// The silences are created by the multi-org Alertmanager when it synchronizes the provisioned silences.
+	silenceService := provisioning.NewSilenceService(st, st, ps.SQLStore, nil, ps.log)
 	cfg := prov_alerting.ProvisionerConfig{
 		Path:                       alertingPath,
 		RuleService:                *ruleService,
@@ -272,6 +274,7 @@ func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error
 		NotificiationPolicyService: *notificationPolicyService,
 		MuteTimingService:          *mutetimingsService,
 		TemplateService:            *templateService,
+		SilenceService:             *silenceService,
 	}
 	return ps.provisionAlerting(ctx, cfg)
 }
@@ -119,6 +119,18 @@ func (oss *OSSMigrations) AddMigration(mg *Migrator) {
 	ualert.AddRuleNotificationSettingsColumns(mg)
 
 	accesscontrol.AddAlertingScopeRemovalMigration(mg)
+
+	ualert.AddRecordingRuleColumns(mg)
+
+	ualert.AddKeepFiringForColumns(mg)
+
+	ualert.AddRuleInhibitionColumns(mg)
+
+	ualert.AddStateHistoryMigrations(mg)
+
+	ualert.AddProvisionedSilenceMigrations(mg)
+
+	ualert.AddAlertInstanceAcknowledgementColumn(mg)
 }
 
 func addStarMigrations(mg *Migrator) {
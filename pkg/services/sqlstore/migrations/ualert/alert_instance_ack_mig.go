@@ -0,0 +1,14 @@
+package ualert
+
+import (
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// AddAlertInstanceAcknowledgementColumn creates a column for the acknowledgement of the alert instance in the alert_instance table.
+func AddAlertInstanceAcknowledgementColumn(mg *migrator.Migrator) {
+	mg.AddMigration("add acknowledgement column to alert_instance table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_instance"}, &migrator.Column{
+		Name:     "acknowledgement",
+		Type:     migrator.DB_Text,
+		Nullable: true,
+	}))
+}
@@ -0,0 +1,22 @@
+package ualert
+
+import (
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// AddKeepFiringForColumns creates a column for the keep_firing_for duration in the alert_rule and alert_rule_version tables.
+func AddKeepFiringForColumns(mg *migrator.Migrator) {
+	mg.AddMigration("add keep_firing_for column to alert_rule table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule"}, &migrator.Column{
+		Name:     "keep_firing_for",
+		Type:     migrator.DB_BigInt,
+		Nullable: false,
+		Default:  "0",
+	}))
+
+	mg.AddMigration("add keep_firing_for column to alert_rule_version table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule_version"}, &migrator.Column{
+		Name:     "keep_firing_for",
+		Type:     migrator.DB_BigInt,
+		Nullable: false,
+		Default:  "0",
+	}))
+}
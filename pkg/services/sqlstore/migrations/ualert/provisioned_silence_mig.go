@@ -0,0 +1,32 @@
+package ualert
+
+import (
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// AddProvisionedSilenceMigrations creates the alert_provisioned_silence table that stores the silences declared in
+// provisioning files or created with the provisioning API.
+func AddProvisionedSilenceMigrations(mg *migrator.Migrator) {
+	silence := migrator.Table{
+		Name: "alert_provisioned_silence",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "matchers", Type: migrator.DB_Text, Nullable: false},
+			{Name: "comment", Type: migrator.DB_Text, Nullable: false},
+			{Name: "created_by", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "starts_at", Type: migrator.DB_BigInt, Nullable: true},
+			{Name: "ends_at", Type: migrator.DB_BigInt, Nullable: true},
+			{Name: "schedule", Type: migrator.DB_Text, Nullable: true},
+			{Name: "silence_id", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "uid"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create alert_provisioned_silence table", migrator.NewAddTableMigration(silence))
+	mg.AddMigration("add unique index in alert_provisioned_silence on org_id and uid columns", migrator.NewAddIndexMigration(silence, silence.Indices[0]))
+}
@@ -0,0 +1,20 @@
+package ualert
+
+import (
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// AddRecordingRuleColumns creates a column for recording rule settings in the alert_rule and alert_rule_version tables.
+func AddRecordingRuleColumns(mg *migrator.Migrator) {
+	mg.AddMigration("add record column to alert_rule table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule"}, &migrator.Column{
+		Name:     "record",
+		Type:     migrator.DB_Text,
+		Nullable: true,
+	}))
+
+	mg.AddMigration("add record column to alert_rule_version table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule_version"}, &migrator.Column{
+		Name:     "record",
+		Type:     migrator.DB_Text,
+		Nullable: true,
+	}))
+}
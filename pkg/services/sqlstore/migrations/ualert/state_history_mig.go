@@ -0,0 +1,39 @@
+package ualert
+
+import (
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// AddStateHistoryMigrations creates the alert_state_history table used by the SQL state history backend.
+func AddStateHistoryMigrations(mg *migrator.Migrator) {
+	stateHistory := migrator.Table{
+		Name: "alert_state_history",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "rule_uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "rule_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "rule_title", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "rule_group", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "namespace_uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "dashboard_uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "panel_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "rule_condition", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "fingerprint", Type: migrator.DB_NVarchar, Length: 16, Nullable: false},
+			{Name: "labels", Type: migrator.DB_Text, Nullable: false},
+			{Name: "previous_state", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "current_state", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "state_values", Type: migrator.DB_Text, Nullable: false},
+			{Name: "error_message", Type: migrator.DB_Text, Nullable: true},
+			{Name: "evaluated_at", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "evaluated_at"}},
+			{Cols: []string{"org_id", "rule_uid", "evaluated_at"}},
+		},
+	}
+
+	mg.AddMigration("create alert_state_history table", migrator.NewAddTableMigration(stateHistory))
+	mg.AddMigration("add index in alert_state_history on org_id and evaluated_at columns", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[0]))
+	mg.AddMigration("add index in alert_state_history on org_id, rule_uid and evaluated_at columns", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[1]))
+}
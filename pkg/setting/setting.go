@@ -341,6 +341,7 @@ type Cfg struct {
 	SqlDatasourceMaxOpenConnsDefault    int
 	SqlDatasourceMaxIdleConnsDefault    int
 	SqlDatasourceMaxConnLifetimeDefault int
+	SqlDatasourceSQLiteFilesPath        string
 
 	// Snapshots
 	SnapshotEnabled      bool
@@ -1943,6 +1944,9 @@ func (cfg *Cfg) readSqlDataSourceSettings() {
 	cfg.SqlDatasourceMaxOpenConnsDefault = sqlDatasources.Key("max_open_conns_default").MustInt(100)
 	cfg.SqlDatasourceMaxIdleConnsDefault = sqlDatasources.Key("max_idle_conns_default").MustInt(100)
 	cfg.SqlDatasourceMaxConnLifetimeDefault = sqlDatasources.Key("max_conn_lifetime_default").MustInt(14400)
+	if sqliteFilesPath := sqlDatasources.Key("sqlite_files_path").String(); sqliteFilesPath != "" {
+		cfg.SqlDatasourceSQLiteFilesPath = makeAbsolute(sqliteFilesPath, cfg.DataPath)
+	}
 }
 
 func GetAllowedOriginGlobs(originPatterns []string) ([]glob.Glob, error) {
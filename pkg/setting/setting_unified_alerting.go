@@ -61,6 +61,7 @@ const (
 	DefaultRuleEvaluationInterval = SchedulerBaseInterval * 6 // == 60 seconds
 	stateHistoryDefaultEnabled    = true
 	lokiDefaultMaxQueryLength     = 721 * time.Hour // 30d1h, matches the default value in Loki
+	recordingRulesDefaultTimeout  = 10 * time.Second
 )
 
 type UnifiedAlertingSettings struct {
@@ -80,6 +81,7 @@ type UnifiedAlertingSettings struct {
 	HARedisPassword                string
 	HARedisDB                      int
 	HARedisMaxConns                int
+	HAShardRuleEvaluation          bool
 	MaxAttempts                    int64
 	MinInterval                    time.Duration
 	EvaluationTimeout              time.Duration
@@ -97,6 +99,7 @@ type UnifiedAlertingSettings struct {
 	ReservedLabels                UnifiedAlertingReservedLabelSettings
 	StateHistory                  UnifiedAlertingStateHistorySettings
 	RemoteAlertmanager            RemoteAlertmanagerSettings
+	RecordingRules                RecordingRuleSettings
 	// MaxStateSaveConcurrency controls the number of goroutines (per rule) that can save alert state in parallel.
 	MaxStateSaveConcurrency   int
 	StatePeriodicSaveInterval time.Duration
@@ -113,6 +116,18 @@ type RemoteAlertmanagerSettings struct {
 	SyncInterval time.Duration
 }
 
+// RecordingRuleSettings contains the configuration of the Prometheus remote write
+// endpoint that recording rules write their results to.
+type RecordingRuleSettings struct {
+	Enabled bool
+	URL     string
+	// BasicAuthUsername and BasicAuthPassword are used for basic auth
+	// if one of them is set.
+	BasicAuthUsername string
+	BasicAuthPassword string
+	Timeout           time.Duration
+}
+
 type UnifiedAlertingScreenshotSettings struct {
 	Capture                    bool
 	CaptureTimeout             time.Duration
@@ -136,9 +151,11 @@ type UnifiedAlertingStateHistorySettings struct {
 	LokiBasicAuthPassword string
 	LokiBasicAuthUsername string
 	LokiMaxQueryLength    time.Duration
-	MultiPrimary          string
-	MultiSecondaries      []string
-	ExternalLabels        map[string]string
+	// SQLMaxAge is how long state history is kept in the Grafana database. Zero keeps it forever.
+	SQLMaxAge        time.Duration
+	MultiPrimary     string
+	MultiSecondaries []string
+	ExternalLabels   map[string]string
 }
 
 // IsEnabled returns true if UnifiedAlertingSettings.Enabled is either nil or true.
@@ -222,6 +239,7 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	uaCfg.HARedisPassword = ua.Key("ha_redis_password").MustString("")
 	uaCfg.HARedisDB = ua.Key("ha_redis_db").MustInt(0)
 	uaCfg.HARedisMaxConns = ua.Key("ha_redis_max_conns").MustInt(alertmanagerRedisDefaultMaxConns)
+	uaCfg.HAShardRuleEvaluation = ua.Key("ha_shard_rule_evaluation").MustBool(false)
 	peers := ua.Key("ha_peers").MustString("")
 	uaCfg.HAPeers = make([]string, 0)
 	if peers != "" {
@@ -329,6 +347,19 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 
 	uaCfg.RemoteAlertmanager = uaCfgRemoteAM
 
+	recordingRules := iniFile.Section("unified_alerting.recording_rules")
+	uaCfgRecordingRules := RecordingRuleSettings{
+		Enabled:           recordingRules.Key("enabled").MustBool(false),
+		URL:               recordingRules.Key("url").MustString(""),
+		BasicAuthUsername: recordingRules.Key("basic_auth_username").MustString(""),
+		BasicAuthPassword: recordingRules.Key("basic_auth_password").MustString(""),
+		Timeout:           recordingRules.Key("timeout").MustDuration(recordingRulesDefaultTimeout),
+	}
+	if uaCfgRecordingRules.Enabled && uaCfgRecordingRules.URL == "" {
+		return fmt.Errorf("setting 'url' in section 'unified_alerting.recording_rules' is required when recording rules are enabled")
+	}
+	uaCfg.RecordingRules = uaCfgRecordingRules
+
 	screenshots := iniFile.Section("unified_alerting.screenshots")
 	uaCfgScreenshots := uaCfg.Screenshots
 
@@ -369,6 +400,10 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 		MultiSecondaries:      splitTrim(stateHistory.Key("secondaries").MustString(""), ","),
 		ExternalLabels:        stateHistoryLabels.KeysHash(),
 	}
+	uaCfgStateHistory.SQLMaxAge, err = gtime.ParseDuration(valueAsString(stateHistory, "sql_max_age", "0s"))
+	if err != nil {
+		return fmt.Errorf("failed to parse setting 'sql_max_age' in section [unified_alerting.state_history]: %w", err)
+	}
 	uaCfg.StateHistory = uaCfgStateHistory
 
 	uaCfg.MaxStateSaveConcurrency = ua.Key("max_state_save_concurrency").MustInt(1)
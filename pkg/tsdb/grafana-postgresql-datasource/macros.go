@@ -3,6 +3,7 @@ package postgres
 import (
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -66,6 +67,15 @@ func (m *postgresMacroEngine) Interpolate(query *backend.DataQuery, timeRange ba
 	return sql, nil
 }
 
+// BindVariables replaces the references to the variables with driver parameters.
+func (m *postgresMacroEngine) BindVariables(sql string, variables []sqleng.QueryVariable, requireBound bool) (string, []any, error) {
+	return m.ReplaceVariablesWithParameters(sql, variables, requireBound, sqleng.ParameterSyntax{
+		Placeholder: func(position int) string {
+			return "$" + strconv.Itoa(position)
+		},
+	})
+}
+
 //nolint:gocyclo
 func (m *postgresMacroEngine) evaluateMacro(timeRange backend.TimeRange, query *backend.DataQuery, name string, args []string) (string, error) {
 	switch name {
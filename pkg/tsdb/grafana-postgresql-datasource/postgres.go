@@ -81,6 +81,7 @@ func newPostgres(userFacingDefaultError string, rowLimit int64, dsInfo sqleng.Da
 		DSInfo:            dsInfo,
 		MetricColumnTypes: []string{"UNKNOWN", "TEXT", "VARCHAR", "CHAR"},
 		RowLimit:          rowLimit,
+		SchemaQueries:     schemaQueries,
 	}
 
 	queryResultTransformer := postgresQueryResultTransformer{}
@@ -239,6 +240,15 @@ func (t *postgresQueryResultTransformer) TransformQueryError(_ log.Logger, err e
 	return err
 }
 
+// CallResource serves the schema of the connected SQL database
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	dsHandler, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		return err
+	}
+	return dsHandler.CallResource(ctx, req, sender)
+}
+
 // CheckHealth pings the connected SQL database
 func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
 	dsHandler, err := s.getDSInfo(ctx, req.PluginContext)
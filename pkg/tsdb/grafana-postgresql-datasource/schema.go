@@ -0,0 +1,27 @@
+package postgres
+
+import "github.com/grafana/grafana/pkg/tsdb/sqleng"
+
+// schemaQueries browse the schema through information_schema and the system catalogs, leaving out the internal schemas.
+var schemaQueries = sqleng.SchemaQueries{
+	Databases: `SELECT datname FROM pg_database WHERE NOT datistemplate AND datallowconn ORDER BY datname`,
+	Schemas: `SELECT schema_name FROM information_schema.schemata
+		WHERE schema_name NOT IN ('information_schema', 'pg_catalog', 'pg_toast')
+		AND schema_name NOT LIKE 'pg\_temp\_%' AND schema_name NOT LIKE 'pg\_toast\_temp\_%'
+		ORDER BY schema_name`,
+	Tables: `SELECT table_name FROM information_schema.tables
+		WHERE table_schema = COALESCE(NULLIF($1::text, ''), current_schema())
+		ORDER BY table_name`,
+	Columns: `SELECT column_name, data_type, is_nullable FROM information_schema.columns
+		WHERE table_schema = COALESCE(NULLIF($1::text, ''), current_schema()) AND table_name = $2::text
+		ORDER BY ordinal_position`,
+	Indexes: `SELECT ic.relname, i.indisunique, i.indisprimary, a.attname
+		FROM pg_index i
+		JOIN pg_class ic ON ic.oid = i.indexrelid
+		JOIN pg_class t ON t.oid = i.indrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		CROSS JOIN LATERAL unnest(i.indkey) WITH ORDINALITY AS k(attnum, position)
+		LEFT JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum AND k.attnum > 0
+		WHERE n.nspname = COALESCE(NULLIF($1::text, ''), current_schema()) AND t.relname = $2::text
+		ORDER BY ic.relname, k.position`,
+}
@@ -0,0 +1,89 @@
+package sqlite
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMacroEngine(t *testing.T) {
+	engine := newSQLiteMacroEngine()
+	query := &backend.DataQuery{}
+
+	t.Run("Given a time range between 2018-04-12 18:00 and 2018-04-12 18:05", func(t *testing.T) {
+		from := time.Date(2018, 4, 12, 18, 0, 0, 0, time.UTC)
+		to := from.Add(5 * time.Minute)
+		timeRange := backend.TimeRange{From: from, To: to}
+
+		t.Run("interpolate __time function", func(t *testing.T) {
+			sql, err := engine.Interpolate(query, timeRange, "select $__time(time_column)")
+			require.Nil(t, err)
+
+			require.Equal(t, "select CAST(strftime('%s', time_column) AS INTEGER) AS time", sql)
+		})
+
+		t.Run("interpolate __timeGroup function", func(t *testing.T) {
+			sql, err := engine.Interpolate(query, timeRange, "GROUP BY $__timeGroup(time_column,'5m')")
+			require.Nil(t, err)
+			sql2, err := engine.Interpolate(query, timeRange, "GROUP BY $__timeGroupAlias(time_column , '5m')")
+			require.Nil(t, err)
+
+			require.Equal(t, "GROUP BY CAST(strftime('%s', time_column) AS INTEGER) / 300 * 300", sql)
+			require.Equal(t, sql+" AS \"time\"", sql2)
+		})
+
+		t.Run("interpolate __timeFilter function", func(t *testing.T) {
+			sql, err := engine.Interpolate(query, timeRange, "WHERE $__timeFilter(time_column)")
+			require.Nil(t, err)
+
+			require.Equal(t, "WHERE datetime(time_column) BETWEEN '2018-04-12 18:00:00' AND '2018-04-12 18:05:00'", sql)
+		})
+
+		t.Run("interpolate __timeFrom and __timeTo functions", func(t *testing.T) {
+			sql, err := engine.Interpolate(query, timeRange, "select $__timeFrom(), $__timeTo()")
+			require.Nil(t, err)
+
+			require.Equal(t, "select '2018-04-12 18:00:00', '2018-04-12 18:05:00'", sql)
+		})
+
+		t.Run("interpolate __unixEpochFilter function", func(t *testing.T) {
+			sql, err := engine.Interpolate(query, timeRange, "select $__unixEpochFilter(time)")
+			require.Nil(t, err)
+
+			require.Equal(t, fmt.Sprintf("select time >= %d AND time <= %d", from.Unix(), to.Unix()), sql)
+		})
+
+		t.Run("interpolate __unixEpochNanoFilter function", func(t *testing.T) {
+			sql, err := engine.Interpolate(query, timeRange, "select $__unixEpochNanoFilter(time)")
+			require.Nil(t, err)
+
+			require.Equal(t, fmt.Sprintf("select time >= %d AND time <= %d", from.UnixNano(), to.UnixNano()), sql)
+		})
+
+		t.Run("interpolate __unixEpochGroup function", func(t *testing.T) {
+			sql, err := engine.Interpolate(query, timeRange, "SELECT $__unixEpochGroup(time_column,'5m')")
+			require.Nil(t, err)
+			sql2, err := engine.Interpolate(query, timeRange, "SELECT $__unixEpochGroupAlias(time_column,'5m')")
+			require.Nil(t, err)
+
+			require.Equal(t, "SELECT time_column / 300 * 300", sql)
+			require.Equal(t, sql+" AS \"time\"", sql2)
+		})
+
+		t.Run("fail on unknown macros", func(t *testing.T) {
+			_, err := engine.Interpolate(query, timeRange, "SELECT $__unknown(time_column)")
+			require.EqualError(t, err, "unknown macro __unknown")
+		})
+
+		t.Run("fail on invalid arguments", func(t *testing.T) {
+			_, err := engine.Interpolate(query, timeRange, "SELECT $__timeGroup(time_column,'5x')")
+			require.ErrorContains(t, err, "error parsing interval '5x': ")
+
+			_, err = engine.Interpolate(query, timeRange, "SELECT $__timeFilter()")
+			require.EqualError(t, err, "missing time column argument for macro __timeFilter")
+		})
+	})
+}
@@ -0,0 +1,19 @@
+package sqlite
+
+import "github.com/grafana/grafana/pkg/tsdb/sqleng"
+
+// schemaQueries browse the schema through the table-valued pragma functions. An SQLite data source has a single
+// database, main, which is also its only schema.
+var schemaQueries = sqleng.SchemaQueries{
+	Databases: `SELECT name FROM pragma_database_list WHERE name = 'main'`,
+	Schemas:   `SELECT name FROM pragma_database_list WHERE name = 'main'`,
+	Tables: `SELECT name FROM sqlite_master
+		WHERE type IN ('table', 'view') AND name NOT LIKE 'sqlite\_%' ESCAPE '\' AND ?1 IN ('', 'main')
+		ORDER BY name`,
+	Columns: `SELECT name, type, CASE WHEN "notnull" THEN 'NO' ELSE 'YES' END FROM pragma_table_info(?2)
+		WHERE ?1 IN ('', 'main')
+		ORDER BY cid`,
+	Indexes: `SELECT il.name, il."unique", il.origin = 'pk', ii.name FROM pragma_index_list(?2) il, pragma_index_info(il.name) ii
+		WHERE ?1 IN ('', 'main')
+		ORDER BY il.name, ii.seqno`,
+}
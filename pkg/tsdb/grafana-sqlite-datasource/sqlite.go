@@ -0,0 +1,263 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+	"github.com/mattn/go-sqlite3"
+
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
+)
+
+// driverName is the name of the read-only SQLite driver used by the data source.
+const driverName = "sqlite3_grafana_datasource"
+
+func init() {
+	sql.Register(driverName, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			conn.RegisterAuthorizer(authorize)
+			return nil
+		},
+	})
+}
+
+// readOnlyPragmas are the pragmas that queries are allowed to use, which only read the schema.
+var readOnlyPragmas = map[string]bool{
+	"database_list":    true,
+	"foreign_key_list": true,
+	"index_info":       true,
+	"index_list":       true,
+	"index_xinfo":      true,
+	"table_info":       true,
+	"table_list":       true,
+	"table_xinfo":      true,
+}
+
+// authorize denies attaching other database files and changing the settings of the connection through pragmas, so
+// that queries can't read files other than the database of the data source nor lift its read-only mode.
+func authorize(op int, arg1, _, _ string) int {
+	switch op {
+	case sqlite3.SQLITE_ATTACH, sqlite3.SQLITE_DETACH:
+		return sqlite3.SQLITE_DENY
+	case sqlite3.SQLITE_PRAGMA:
+		if !readOnlyPragmas[strings.ToLower(arg1)] {
+			return sqlite3.SQLITE_DENY
+		}
+	}
+	return sqlite3.SQLITE_OK
+}
+
+type Service struct {
+	im     instancemgmt.InstanceManager
+	logger log.Logger
+}
+
+func ProvideService(cfg *setting.Cfg) *Service {
+	logger := backend.NewLoggerWith("logger", "tsdb.sqlite")
+	return &Service{
+		im:     datasource.NewInstanceManager(newInstanceSettings(cfg.SqlDatasourceSQLiteFilesPath, logger)),
+		logger: logger,
+	}
+}
+
+func newInstanceSettings(filesPath string, logger log.Logger) datasource.InstanceFactoryFunc {
+	return func(ctx context.Context, settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+		cfg := backend.GrafanaConfigFromContext(ctx)
+		sqlCfg, err := cfg.SQL()
+		if err != nil {
+			return nil, err
+		}
+
+		jsonData := sqleng.JsonData{
+			MaxOpenConns:    sqlCfg.DefaultMaxOpenConns,
+			MaxIdleConns:    sqlCfg.DefaultMaxIdleConns,
+			ConnMaxLifetime: sqlCfg.DefaultMaxConnLifetimeSeconds,
+		}
+
+		err = json.Unmarshal(settings.JSONData, &jsonData)
+		if err != nil {
+			return nil, fmt.Errorf("error reading settings: %w", err)
+		}
+
+		database := jsonData.Database
+		if database == "" {
+			database = settings.Database
+		}
+
+		dsInfo := sqleng.DataSourceInfo{
+			JsonData:                jsonData,
+			URL:                     settings.URL,
+			User:                    settings.User,
+			Database:                database,
+			ID:                      settings.ID,
+			Updated:                 settings.Updated,
+			UID:                     settings.UID,
+			DecryptedSecureJSONData: settings.DecryptedSecureJSONData,
+		}
+
+		userFacingDefaultError, err := cfg.UserFacingDefaultError()
+		if err != nil {
+			return nil, err
+		}
+
+		config := sqleng.DataPluginConfiguration{
+			DSInfo:        dsInfo,
+			RowLimit:      sqlCfg.RowLimit,
+			SchemaQueries: schemaQueries,
+		}
+
+		// An invalid path doesn't prevent the instance from being created, so that the health check can report it.
+		dsn, err := dataSourceName(filesPath, database)
+		if err != nil {
+			logger.Warn("Invalid SQLite database file", "uid", settings.UID, "error", err)
+			return &instance{pathErr: err}, nil
+		}
+
+		db, err := sql.Open(driverName, dsn)
+		if err != nil {
+			return nil, err
+		}
+
+		db.SetMaxOpenConns(config.DSInfo.JsonData.MaxOpenConns)
+		db.SetMaxIdleConns(config.DSInfo.JsonData.MaxIdleConns)
+		db.SetConnMaxLifetime(time.Duration(config.DSInfo.JsonData.ConnMaxLifetime) * time.Second)
+
+		handler, err := sqleng.NewQueryDataHandler(userFacingDefaultError, db, config, &sqliteQueryResultTransformer{}, newSQLiteMacroEngine(), logger)
+		if err != nil {
+			return nil, err
+		}
+
+		return &instance{db: db, handler: handler}, nil
+	}
+}
+
+// instance is an SQLite data source, or the error that prevents it from opening its database file.
+type instance struct {
+	db      *sql.DB
+	handler *sqleng.DataSourceHandler
+	pathErr error
+}
+
+func (i *instance) Dispose() {
+	if i.handler != nil {
+		i.handler.Dispose()
+	}
+}
+
+// dataSourceName returns the DSN opening the database file in read-only mode. The file must exist and be within
+// filesPath, after resolving symbolic links.
+func dataSourceName(filesPath, database string) (string, error) {
+	if filesPath == "" {
+		return "", errors.New("the directory of the SQLite database files isn't configured, see sqlite_files_path in the [sql_datasources] section of the Grafana configuration")
+	}
+	if database == "" {
+		return "", errors.New("no database file")
+	}
+	if filepath.IsAbs(database) {
+		return "", errors.New("the database file must be a path relative to the directory of the SQLite database files")
+	}
+
+	root, err := filepath.EvalSymlinks(filesPath)
+	if err != nil {
+		return "", fmt.Errorf("the directory of the SQLite database files is invalid: %w", err)
+	}
+	path, err := filepath.EvalSymlinks(filepath.Join(root, database))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("database file %s not found", database)
+		}
+		return "", fmt.Errorf("invalid database file %s: %w", database, err)
+	}
+	if rel, err := filepath.Rel(root, path); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("database file %s is outside of the directory of the SQLite database files", database)
+	}
+	if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+		return "", fmt.Errorf("database file %s isn't a regular file", database)
+	}
+
+	u := url.URL{
+		Scheme:   "file",
+		Path:     filepath.ToSlash(path),
+		RawQuery: "mode=ro&_query_only=true",
+	}
+	return u.String(), nil
+}
+
+func (s *Service) getInstance(ctx context.Context, pluginCtx backend.PluginContext) (*instance, error) {
+	i, err := s.im.Get(ctx, pluginCtx)
+	if err != nil {
+		return nil, err
+	}
+	return i.(*instance), nil
+}
+
+func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	inst, err := s.getInstance(ctx, req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+	if inst.pathErr != nil {
+		return nil, inst.pathErr
+	}
+	return inst.handler.QueryData(ctx, req)
+}
+
+// CallResource serves the schema of the SQLite database
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	inst, err := s.getInstance(ctx, req.PluginContext)
+	if err != nil {
+		return err
+	}
+	if inst.pathErr != nil {
+		return inst.pathErr
+	}
+	return inst.handler.CallResource(ctx, req, sender)
+}
+
+// CheckHealth checks that the database file can be opened and read
+func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	inst, err := s.getInstance(ctx, req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+	if inst.pathErr != nil {
+		return &backend.CheckHealthResult{Status: backend.HealthStatusError, Message: inst.pathErr.Error()}, nil
+	}
+
+	// Pinging doesn't read the file, reading the schema does and fails if it isn't an SQLite database.
+	var tables int
+	if err := inst.db.QueryRowContext(ctx, "SELECT count(*) FROM sqlite_master").Scan(&tables); err != nil {
+		return &backend.CheckHealthResult{Status: backend.HealthStatusError, Message: err.Error()}, nil
+	}
+	return &backend.CheckHealthResult{Status: backend.HealthStatusOk, Message: "Database Connection OK"}, nil
+}
+
+type sqliteQueryResultTransformer struct{}
+
+func (t *sqliteQueryResultTransformer) TransformQueryError(_ log.Logger, err error) error {
+	return err
+}
+
+func (t *sqliteQueryResultTransformer) GetConverterList() []sqlutil.StringConverter {
+	return nil
+}
+
+// GetConverters returns a dynamic converter: the columns of SQLite have no fixed type, so the types of the fields
+// are taken from the values.
+func (t *sqliteQueryResultTransformer) GetConverters() []sqlutil.Converter {
+	return []sqlutil.Converter{{Name: "dynamic", Dynamic: true}}
+}
@@ -0,0 +1,212 @@
+package sqlite
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func createDatabaseFile(t *testing.T, path string) {
+	t.Helper()
+	db, err := sql.Open("sqlite3", path)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, db.Close()) }()
+
+	_, err = db.Exec(`CREATE TABLE metric (time DATETIME, host TEXT, value REAL);
+		INSERT INTO metric VALUES
+			('2024-01-01 00:00:10', 'a', 1),
+			('2024-01-01 00:00:50', 'a', 3),
+			('2024-01-01 00:02:30', 'a', 5),
+			('2024-01-01 01:00:00', 'a', 100);
+		CREATE TABLE kpi (name TEXT PRIMARY KEY, target INTEGER);`)
+	require.NoError(t, err)
+}
+
+func TestDataSourceName(t *testing.T) {
+	dir := t.TempDir()
+	createDatabaseFile(t, filepath.Join(dir, "metrics.db"))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0750))
+	createDatabaseFile(t, filepath.Join(dir, "sub", "kpis.db"))
+
+	outside := t.TempDir()
+	createDatabaseFile(t, filepath.Join(outside, "secret.db"))
+	require.NoError(t, os.Symlink(filepath.Join(outside, "secret.db"), filepath.Join(dir, "link.db")))
+
+	t.Run("should open files within the directory in read-only mode", func(t *testing.T) {
+		dsn, err := dataSourceName(dir, "metrics.db")
+		require.NoError(t, err)
+		require.Equal(t, "file://"+filepath.ToSlash(filepath.Join(mustEvalSymlinks(t, dir), "metrics.db"))+"?mode=ro&_query_only=true", dsn)
+
+		_, err = dataSourceName(dir, "sub/../sub/kpis.db")
+		require.NoError(t, err)
+	})
+
+	t.Run("should not open files outside of the directory", func(t *testing.T) {
+		for _, database := range []string{"../" + filepath.Base(outside) + "/secret.db", "link.db", filepath.Join(outside, "secret.db")} {
+			_, err := dataSourceName(dir, database)
+			require.Error(t, err, database)
+		}
+	})
+
+	t.Run("should fail for missing files and directories", func(t *testing.T) {
+		_, err := dataSourceName(dir, "missing.db")
+		require.EqualError(t, err, "database file missing.db not found")
+
+		_, err = dataSourceName(dir, "sub")
+		require.EqualError(t, err, "database file sub isn't a regular file")
+
+		_, err = dataSourceName(dir, "")
+		require.EqualError(t, err, "no database file")
+	})
+
+	t.Run("should fail when the directory is not configured", func(t *testing.T) {
+		_, err := dataSourceName("", "metrics.db")
+		require.ErrorContains(t, err, "sqlite_files_path")
+	})
+}
+
+func mustEvalSymlinks(t *testing.T, path string) string {
+	t.Helper()
+	path, err := filepath.EvalSymlinks(path)
+	require.NoError(t, err)
+	return path
+}
+
+func TestSQLiteDataSource(t *testing.T) {
+	dir := t.TempDir()
+	createDatabaseFile(t, filepath.Join(dir, "metrics.db"))
+
+	svc := ProvideService(&setting.Cfg{SqlDatasourceSQLiteFilesPath: dir})
+	ctx := backend.WithGrafanaConfig(context.Background(), backend.NewGrafanaCfg(map[string]string{
+		backend.SQLRowLimit:                      "1000",
+		backend.SQLMaxOpenConnsDefault:           "10",
+		backend.SQLMaxIdleConnsDefault:           "10",
+		backend.SQLMaxConnLifetimeSecondsDefault: "60",
+		backend.UserFacingDefaultError:           "error",
+	}))
+	pluginContext := func(id int64, database string) backend.PluginContext {
+		return backend.PluginContext{
+			DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
+				ID:       id,
+				JSONData: json.RawMessage(`{"database": "` + database + `"}`),
+			},
+		}
+	}
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	query := func(t *testing.T, rawSQL, format string) backend.DataResponse {
+		t.Helper()
+		model, err := json.Marshal(map[string]string{"rawSql": rawSQL, "format": format})
+		require.NoError(t, err)
+		resp, err := svc.QueryData(ctx, &backend.QueryDataRequest{
+			PluginContext: pluginContext(1, "metrics.db"),
+			Queries: []backend.DataQuery{{
+				RefID:     "A",
+				JSON:      model,
+				TimeRange: backend.TimeRange{From: from, To: from.Add(5 * time.Minute)},
+			}},
+		})
+		require.NoError(t, err)
+		return resp.Responses["A"]
+	}
+
+	t.Run("should report a healthy database", func(t *testing.T) {
+		res, err := svc.CheckHealth(ctx, &backend.CheckHealthRequest{PluginContext: pluginContext(1, "metrics.db")})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusOk, res.Status)
+	})
+
+	t.Run("should report invalid database files", func(t *testing.T) {
+		res, err := svc.CheckHealth(ctx, &backend.CheckHealthRequest{PluginContext: pluginContext(2, "missing.db")})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusError, res.Status)
+		require.Equal(t, "database file missing.db not found", res.Message)
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "text.db"), bytes.Repeat([]byte("not a database file "), 100), 0600))
+		res, err = svc.CheckHealth(ctx, &backend.CheckHealthRequest{PluginContext: pluginContext(3, "text.db")})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusError, res.Status)
+		require.Equal(t, "file is not a database", res.Message)
+	})
+
+	t.Run("should query time series with macros and fill", func(t *testing.T) {
+		resp := query(t, `SELECT $__timeGroupAlias(time, '1m', NULL), avg(value) AS value FROM metric
+			WHERE $__timeFilter(time) GROUP BY 1 ORDER BY 1`, "time_series")
+		require.NoError(t, resp.Error)
+		require.Len(t, resp.Frames, 1)
+
+		frame := resp.Frames[0]
+		require.Equal(t, 6, frame.Rows())
+		require.Equal(t, data.TimeSeriesTimeFieldName, frame.Fields[0].Name)
+		require.Equal(t, from.Unix(), frame.Fields[0].At(0).(*time.Time).Unix())
+		require.Equal(t, 2.0, *frame.Fields[1].At(0).(*float64))
+		require.Nil(t, frame.Fields[1].At(1))
+		require.Equal(t, 5.0, *frame.Fields[1].At(2).(*float64))
+	})
+
+	t.Run("should query tables", func(t *testing.T) {
+		resp := query(t, `SELECT host, count(*) AS points, max(time) AS last FROM metric GROUP BY host`, "table")
+		require.NoError(t, resp.Error)
+		frame := resp.Frames[0]
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, "a", *frame.Fields[0].At(0).(*string))
+		require.Equal(t, 4.0, *frame.Fields[1].At(0).(*float64))
+	})
+
+	t.Run("should not modify the database file", func(t *testing.T) {
+		query(t, `DELETE FROM metric`, "table")
+		resp := query(t, `SELECT count(*) AS points FROM metric`, "table")
+		require.NoError(t, resp.Error)
+		require.Equal(t, 4.0, *resp.Frames[0].Fields[0].At(0).(*float64))
+
+		resp = query(t, `PRAGMA query_only = false`, "table")
+		require.ErrorContains(t, resp.Error, "not authorized")
+	})
+
+	t.Run("should not attach other database files", func(t *testing.T) {
+		createDatabaseFile(t, filepath.Join(dir, "other.db"))
+		resp := query(t, `ATTACH DATABASE '`+filepath.Join(dir, "other.db")+`' AS other`, "table")
+		require.ErrorContains(t, resp.Error, "not authorized")
+	})
+
+	t.Run("should serve the schema", func(t *testing.T) {
+		sender := &fakeSender{}
+		err := svc.CallResource(ctx, &backend.CallResourceRequest{
+			PluginContext: pluginContext(1, "metrics.db"),
+			Path:          "columns",
+			URL:           "columns?table=kpi",
+		}, sender)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, sender.resp.Status)
+		require.JSONEq(t, `[{"name": "name", "type": "TEXT", "nullable": true}, {"name": "target", "type": "INTEGER", "nullable": true}]`, string(sender.resp.Body))
+
+		err = svc.CallResource(ctx, &backend.CallResourceRequest{
+			PluginContext: pluginContext(1, "metrics.db"),
+			Path:          "indexes",
+			URL:           "indexes?table=kpi",
+		}, sender)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, sender.resp.Status)
+		require.JSONEq(t, `[{"name": "sqlite_autoindex_kpi_1", "columns": ["name"], "unique": true, "primary": true}]`, string(sender.resp.Body))
+	})
+}
+
+type fakeSender struct {
+	resp *backend.CallResourceResponse
+}
+
+func (s *fakeSender) Send(resp *backend.CallResourceResponse) error {
+	s.resp = resp
+	return nil
+}
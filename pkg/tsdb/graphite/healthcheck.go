@@ -0,0 +1,50 @@
+package graphite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+const healthCheckRefID = "__healthcheck__"
+
+// CheckHealth renders constantLine(100) over the last hour. Graphite generates the series itself, so the check also
+// passes on an instance that stores no metrics, as long as the render API answers.
+func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	logger := logger.FromContext(ctx)
+
+	model, err := json.Marshal(map[string]string{TargetModelField: "constantLine(100)"})
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	resp, err := s.QueryData(ctx, &backend.QueryDataRequest{
+		PluginContext: req.PluginContext,
+		Queries: []backend.DataQuery{{
+			RefID:     healthCheckRefID,
+			JSON:      model,
+			TimeRange: backend.TimeRange{From: now.Add(-time.Hour), To: now},
+		}},
+	})
+	if err == nil {
+		err = resp.Responses[healthCheckRefID].Error
+	}
+	if err == nil && len(resp.Responses[healthCheckRefID].Frames) == 0 {
+		err = fmt.Errorf("no data returned for the test query")
+	}
+	if err != nil {
+		logger.Warn("Graphite health check failed", "error", err)
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusError,
+			Message: fmt.Sprintf("Graphite health check failed: %s", err),
+		}, nil
+	}
+
+	return &backend.CheckHealthResult{
+		Status:  backend.HealthStatusOk,
+		Message: "Data source is working",
+	}, nil
+}
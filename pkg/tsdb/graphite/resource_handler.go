@@ -0,0 +1,282 @@
+package graphite
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// resourceParams are the parameters each resource forwards to Graphite, other parameters are dropped.
+var resourceParams = map[string][]string{
+	"metrics/find":             {"query", "from", "until"},
+	"tags":                     {"filter", "from", "until"},
+	"tags/autoComplete/tags":   {"expr", "tagPrefix", "limit", "from", "until"},
+	"tags/autoComplete/values": {"expr", "tag", "valuePrefix", "limit", "from", "until"},
+	"functions":                {},
+}
+
+// MetricFindResult is a node of the metric tree returned by the metrics/find resource.
+type MetricFindResult struct {
+	Text       string `json:"text"`
+	ID         string `json:"id"`
+	Expandable bool   `json:"expandable"`
+	Leaf       bool   `json:"leaf"`
+}
+
+// CallResource serves the metric discovery endpoints of Graphite through the HTTP client of the data source:
+// metrics/find, tags, tags/autoComplete/tags, tags/autoComplete/values and functions. The responses are
+// normalized, since their format varies across Graphite versions and implementations.
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	logger := logger.FromContext(ctx)
+
+	resourcePath := strings.Trim(req.Path, "/")
+	allowedParams, ok := resourceParams[resourcePath]
+	if !ok {
+		return sendResourceError(sender, http.StatusNotFound, fmt.Errorf("unknown resource %q", req.Path))
+	}
+	if req.Method != http.MethodGet && req.Method != http.MethodPost {
+		return sendResourceError(sender, http.StatusMethodNotAllowed, fmt.Errorf("invalid method %s", req.Method))
+	}
+
+	params, err := resourceRequestParams(req, allowedParams)
+	if err != nil {
+		return sendResourceError(sender, http.StatusBadRequest, err)
+	}
+	if resourcePath == "metrics/find" && params.Get("query") == "" {
+		return sendResourceError(sender, http.StatusBadRequest, errors.New("missing query"))
+	}
+	if resourcePath == "tags/autoComplete/values" && params.Get("tag") == "" {
+		return sendResourceError(sender, http.StatusBadRequest, errors.New("missing tag"))
+	}
+
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		return err
+	}
+
+	ctx, span := s.tracer.Start(ctx, "graphite resource")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("path", resourcePath),
+		attribute.Int64("datasource_id", dsInfo.Id),
+		attribute.Int64("org_id", req.PluginContext.OrgID),
+	)
+
+	body, status, err := s.doResourceRequest(ctx, span, dsInfo, resourcePath, params)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logger.Warn("Graphite resource request failed", "path", resourcePath, "error", err)
+		return sendResourceError(sender, status, err)
+	}
+
+	var result any
+	switch resourcePath {
+	case "metrics/find":
+		result, err = parseMetricFindResponse(body)
+	case "tags":
+		result, err = parseTagsResponse(body)
+	case "tags/autoComplete/tags", "tags/autoComplete/values":
+		result, err = parseAutoCompleteResponse(body)
+	case "functions":
+		result, err = parseFunctionsResponse(body)
+	}
+	if err != nil {
+		logger.Warn("Failed to parse Graphite resource response", "path", resourcePath, "error", err)
+		return sendResourceError(sender, http.StatusBadGateway, fmt.Errorf("invalid response from Graphite: %w", err))
+	}
+
+	return resource.SendJSON(sender, result)
+}
+
+// resourceRequestParams returns the allowed parameters of the query string and, for POST requests, of the form body.
+func resourceRequestParams(req *backend.CallResourceRequest, allowed []string) (url.Values, error) {
+	reqURL, err := url.Parse(req.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	values := reqURL.Query()
+	if req.Method == http.MethodPost && len(req.Body) > 0 {
+		form, err := url.ParseQuery(string(req.Body))
+		if err != nil {
+			return nil, fmt.Errorf("invalid form body: %w", err)
+		}
+		for key, v := range form {
+			values[key] = append(values[key], v...)
+		}
+	}
+
+	params := url.Values{}
+	for _, key := range allowed {
+		if v, ok := values[key]; ok {
+			params[key] = v
+		}
+	}
+	return params, nil
+}
+
+// doResourceRequest sends the parameters to the Graphite endpoint, and returns the body of the response or the error
+// with the status to respond with.
+func (s *Service) doResourceRequest(ctx context.Context, span trace.Span, dsInfo *datasourceInfo, resourcePath string, params url.Values) ([]byte, int, error) {
+	u, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	u.Path = path.Join(u.Path, resourcePath)
+
+	// metrics/find is sent as a form since queries may be too long for the URL.
+	var req *http.Request
+	if resourcePath == "metrics/find" {
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, u.String(), strings.NewReader(params.Encode()))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	} else {
+		u.RawQuery = params.Encode()
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	}
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("failed to create request: %w", err)
+	}
+	s.tracer.Inject(ctx, req.Header, span)
+
+	res, err := dsInfo.HTTPClient.Do(req)
+	if err != nil {
+		return nil, http.StatusBadGateway, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, http.StatusBadGateway, err
+	}
+	// an error of Graphite, even 401 or 404, is not an error of the request to Grafana
+	if res.StatusCode/100 != 2 {
+		return nil, http.StatusBadGateway, fmt.Errorf("request to Graphite failed, status: %s", res.Status)
+	}
+	return body, http.StatusOK, nil
+}
+
+// flexibleBool is a boolean that Graphite implementations encode as a boolean, a number or a string.
+type flexibleBool bool
+
+func (b *flexibleBool) UnmarshalJSON(data []byte) error {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case nil:
+		*b = false
+	case bool:
+		*b = flexibleBool(v)
+	case float64:
+		*b = v != 0
+	case string:
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", v)
+		}
+		*b = flexibleBool(parsed)
+	default:
+		return fmt.Errorf("invalid boolean %s", data)
+	}
+	return nil
+}
+
+func parseMetricFindResponse(body []byte) ([]MetricFindResult, error) {
+	var nodes []struct {
+		Text          string       `json:"text"`
+		ID            string       `json:"id"`
+		Expandable    flexibleBool `json:"expandable"`
+		AllowChildren flexibleBool `json:"allowChildren"`
+		Leaf          flexibleBool `json:"leaf"`
+	}
+	if err := json.Unmarshal(body, &nodes); err != nil {
+		return nil, err
+	}
+
+	results := make([]MetricFindResult, 0, len(nodes))
+	for _, n := range nodes {
+		results = append(results, MetricFindResult{
+			Text:       n.Text,
+			ID:         n.ID,
+			Expandable: bool(n.Expandable || n.AllowChildren),
+			Leaf:       bool(n.Leaf),
+		})
+	}
+	return results, nil
+}
+
+// parseTagsResponse returns the names of the tags, which Graphite lists as objects.
+func parseTagsResponse(body []byte) ([]string, error) {
+	var tags []struct {
+		Tag string `json:"tag"`
+	}
+	if err := json.Unmarshal(body, &tags); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(tags))
+	for _, t := range tags {
+		names = append(names, t.Tag)
+	}
+	return names, nil
+}
+
+func parseAutoCompleteResponse(body []byte) ([]string, error) {
+	var values []string
+	if err := json.Unmarshal(body, &values); err != nil {
+		return nil, err
+	}
+	if values == nil {
+		values = []string{}
+	}
+	return values, nil
+}
+
+// infinityDefault matches the parameter defaults that Graphite 1.1.7 encodes as Infinity, which isn't valid JSON.
+// See https://github.com/graphite-project/graphite-web/issues/2609
+var infinityDefault = regexp.MustCompile(`"default": ?Infinity`)
+
+// parseFunctionsResponse returns the function definitions, with the infinite defaults encoded as "inf" like the
+// function editor expects.
+func parseFunctionsResponse(body []byte) (json.RawMessage, error) {
+	body = infinityDefault.ReplaceAll(body, []byte(`"default": "inf"`))
+
+	var functions map[string]json.RawMessage
+	if err := json.Unmarshal(body, &functions); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+func sendResourceError(sender backend.CallResourceResponseSender, status int, err error) error {
+	body, jsonErr := json.Marshal(map[string]string{"error": err.Error()})
+	if jsonErr != nil {
+		return jsonErr
+	}
+	return sender.Send(&backend.CallResourceResponse{
+		Status:  status,
+		Headers: map[string][]string{"Content-Type": {"application/json"}},
+		Body:    body,
+	})
+}
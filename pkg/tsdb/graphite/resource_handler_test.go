@@ -0,0 +1,183 @@
+package graphite
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+type testInstanceManager struct {
+	dsInfo datasourceInfo
+}
+
+func (m testInstanceManager) Get(_ context.Context, _ backend.PluginContext) (instancemgmt.Instance, error) {
+	return m.dsInfo, nil
+}
+
+func (m testInstanceManager) Do(_ context.Context, _ backend.PluginContext, _ instancemgmt.InstanceCallbackFunc) error {
+	return nil
+}
+
+type resourceSender struct {
+	resp *backend.CallResourceResponse
+}
+
+func (s *resourceSender) Send(resp *backend.CallResourceResponse) error {
+	s.resp = resp
+	return nil
+}
+
+// newTestService returns a service querying a Graphite server that serves the handler. The requests received by the
+// server are recorded with their form values.
+func newTestService(t *testing.T, handler http.HandlerFunc) (*Service, *[]*http.Request) {
+	t.Helper()
+	var requests []*http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		requests = append(requests, r)
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	return &Service{
+		im:     testInstanceManager{dsInfo: datasourceInfo{HTTPClient: srv.Client(), URL: srv.URL + "/graphite"}},
+		tracer: tracing.InitializeTracerForTest(),
+	}, &requests
+}
+
+func callResource(t *testing.T, s *Service, method, resourceURL string, body string) *backend.CallResourceResponse {
+	t.Helper()
+	u, err := url.Parse(resourceURL)
+	require.NoError(t, err)
+	sender := &resourceSender{}
+	err = s.CallResource(context.Background(), &backend.CallResourceRequest{
+		Method: method,
+		Path:   u.Path,
+		URL:    resourceURL,
+		Body:   []byte(body),
+	}, sender)
+	require.NoError(t, err)
+	require.NotNil(t, sender.resp)
+	return sender.resp
+}
+
+func TestCallResource(t *testing.T) {
+	t.Run("should find metrics and normalize the nodes", func(t *testing.T) {
+		s, requests := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, `[
+				{"text": "cpu", "id": "servers.a.cpu", "leaf": 0, "expandable": 1, "allowChildren": 1},
+				{"text": "load", "id": "servers.a.load", "leaf": true, "expandable": false},
+				{"text": "mem", "id": "servers.a.mem", "leaf": "0", "allowChildren": "1"}
+			]`)
+		})
+
+		resp := callResource(t, s, http.MethodPost, "metrics/find?from=-1h", "query=servers.a.*&other=1")
+		require.Equal(t, http.StatusOK, resp.Status)
+		require.JSONEq(t, `[
+			{"text": "cpu", "id": "servers.a.cpu", "expandable": true, "leaf": false},
+			{"text": "load", "id": "servers.a.load", "expandable": false, "leaf": true},
+			{"text": "mem", "id": "servers.a.mem", "expandable": true, "leaf": false}
+		]`, string(resp.Body))
+
+		require.Len(t, *requests, 1)
+		req := (*requests)[0]
+		require.Equal(t, http.MethodPost, req.Method)
+		require.Equal(t, "/graphite/metrics/find", req.URL.Path)
+		require.Equal(t, url.Values{"query": {"servers.a.*"}, "from": {"-1h"}}, req.PostForm)
+	})
+
+	t.Run("should list tag names", func(t *testing.T) {
+		s, requests := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, `[{"tag": "host"}, {"tag": "name"}]`)
+		})
+
+		resp := callResource(t, s, http.MethodGet, "tags?filter=h.*", "")
+		require.Equal(t, http.StatusOK, resp.Status)
+		require.JSONEq(t, `["host", "name"]`, string(resp.Body))
+		require.Equal(t, "filter=h.%2A", (*requests)[0].URL.RawQuery)
+	})
+
+	t.Run("should autocomplete tags and values", func(t *testing.T) {
+		s, requests := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/graphite/tags/autoComplete/values" {
+				_, _ = io.WriteString(w, `null`)
+				return
+			}
+			_, _ = io.WriteString(w, `["host", "region"]`)
+		})
+
+		resp := callResource(t, s, http.MethodGet, "tags/autoComplete/tags?expr=name=cpu&expr=host=a&tagPrefix=h&limit=10", "")
+		require.Equal(t, http.StatusOK, resp.Status)
+		require.JSONEq(t, `["host", "region"]`, string(resp.Body))
+		require.Equal(t, []string{"name=cpu", "host=a"}, (*requests)[0].Form["expr"])
+		require.Equal(t, "h", (*requests)[0].Form.Get("tagPrefix"))
+
+		resp = callResource(t, s, http.MethodGet, "tags/autoComplete/values?tag=host", "")
+		require.Equal(t, http.StatusOK, resp.Status)
+		require.JSONEq(t, `[]`, string(resp.Body))
+	})
+
+	t.Run("should list functions with valid infinite defaults", func(t *testing.T) {
+		s, _ := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, `{"removeAboveValue": {"name": "removeAboveValue", "params": [{"name": "n", "type": "float", "default": Infinity}]}}`)
+		})
+
+		resp := callResource(t, s, http.MethodGet, "functions", "")
+		require.Equal(t, http.StatusOK, resp.Status)
+		require.JSONEq(t, `{"removeAboveValue": {"name": "removeAboveValue", "params": [{"name": "n", "type": "float", "default": "inf"}]}}`, string(resp.Body))
+	})
+
+	t.Run("should forward the errors of Graphite", func(t *testing.T) {
+		s, _ := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		})
+
+		resp := callResource(t, s, http.MethodGet, "tags", "")
+		require.Equal(t, http.StatusBadGateway, resp.Status)
+		require.JSONEq(t, `{"error": "request to Graphite failed, status: 401 Unauthorized"}`, string(resp.Body))
+	})
+
+	t.Run("should reject invalid resources", func(t *testing.T) {
+		s, requests := newTestService(t, func(w http.ResponseWriter, r *http.Request) {})
+
+		require.Equal(t, http.StatusNotFound, callResource(t, s, http.MethodGet, "render?target=a", "").Status)
+		require.Equal(t, http.StatusMethodNotAllowed, callResource(t, s, http.MethodDelete, "tags", "").Status)
+		require.Equal(t, http.StatusBadRequest, callResource(t, s, http.MethodGet, "metrics/find", "").Status)
+		require.Equal(t, http.StatusBadRequest, callResource(t, s, http.MethodGet, "tags/autoComplete/values", "").Status)
+		require.Empty(t, *requests)
+	})
+}
+
+func TestCheckHealth(t *testing.T) {
+	t.Run("should report a working data source", func(t *testing.T) {
+		s, requests := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, `[{"target": "constantLine(100) __healthcheck__", "datapoints": [[100, 1], [100, 2]]}]`)
+		})
+
+		res, err := s.CheckHealth(context.Background(), &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusOk, res.Status)
+		require.Equal(t, "/graphite/render", (*requests)[0].URL.Path)
+		require.Contains(t, (*requests)[0].PostForm.Get("target"), "constantLine(100)")
+	})
+
+	t.Run("should report failed requests", func(t *testing.T) {
+		s, _ := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		})
+
+		res, err := s.CheckHealth(context.Background(), &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusError, res.Status)
+		require.Equal(t, "Graphite health check failed: request failed, status: 403 Forbidden", res.Message)
+	})
+}
@@ -3,6 +3,7 @@ package mssql
 import (
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -48,6 +49,15 @@ func (m *msSQLMacroEngine) Interpolate(query *backend.DataQuery, timeRange backe
 	return sql, nil
 }
 
+// BindVariables replaces the references to the variables with driver parameters.
+func (m *msSQLMacroEngine) BindVariables(sql string, variables []sqleng.QueryVariable, requireBound bool) (string, []any, error) {
+	return m.ReplaceVariablesWithParameters(sql, variables, requireBound, sqleng.ParameterSyntax{
+		Placeholder: func(position int) string {
+			return "@p" + strconv.Itoa(position)
+		},
+	})
+}
+
 func (m *msSQLMacroEngine) evaluateMacro(timeRange backend.TimeRange, query *backend.DataQuery, name string, args []string) (string, error) {
 	switch name {
 	case "__time":
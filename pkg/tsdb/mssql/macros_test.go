@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
 
 	"github.com/stretchr/testify/require"
 )
@@ -256,3 +257,17 @@ func TestMacroEngineConcurrency(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestMacroEngineBindVariables(t *testing.T) {
+	engine, ok := newMssqlMacroEngine().(sqleng.SQLVariableBinder)
+	require.True(t, ok)
+
+	sql, args, err := engine.BindVariables("SELECT * FROM metric WHERE host IN ($hosts) AND value > ${min} AND $__timeFilter(time)",
+		[]sqleng.QueryVariable{
+			{Name: "hosts", Values: []string{"a", "b"}},
+			{Name: "min", Type: sqleng.VariableTypeNumber, Values: []string{"10"}},
+		}, true)
+	require.NoError(t, err)
+	require.Equal(t, "SELECT * FROM metric WHERE host IN (@p1,@p2) AND value > @p3 AND $__timeFilter(time)", sql)
+	require.Equal(t, []any{"a", "b", int64(10)}, args)
+}
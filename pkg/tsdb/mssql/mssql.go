@@ -136,6 +136,7 @@ func newInstanceSettings(cfg *setting.Cfg, logger log.Logger) datasource.Instanc
 			DSInfo:            dsInfo,
 			MetricColumnTypes: []string{"VARCHAR", "CHAR", "NVARCHAR", "NCHAR"},
 			RowLimit:          cfg.DataProxyRowLimit,
+			SchemaQueries:     schemaQueries,
 		}
 
 		queryResultTransformer := mssqlQueryResultTransformer{
@@ -294,6 +295,15 @@ func (t *mssqlQueryResultTransformer) TransformQueryError(logger log.Logger, err
 	return err
 }
 
+// CallResource serves the schema of the connected SQL database
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	dsHandler, err := s.getDataSourceHandler(ctx, req.PluginContext)
+	if err != nil {
+		return err
+	}
+	return dsHandler.CallResource(ctx, req, sender)
+}
+
 // CheckHealth pings the connected SQL database
 func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
 	dsHandler, err := s.getDataSourceHandler(ctx, req.PluginContext)
@@ -0,0 +1,25 @@
+package mssql
+
+import "github.com/grafana/grafana/pkg/tsdb/sqleng"
+
+// schemaQueries browse the schema through INFORMATION_SCHEMA and the catalog views, leaving out the system and the
+// fixed database role schemas.
+var schemaQueries = sqleng.SchemaQueries{
+	Databases: `SELECT name FROM sys.databases ORDER BY name`,
+	Schemas: `SELECT SCHEMA_NAME FROM INFORMATION_SCHEMA.SCHEMATA
+		WHERE SCHEMA_NAME NOT IN ('sys', 'INFORMATION_SCHEMA', 'guest') AND SCHEMA_NAME NOT LIKE 'db[_]%'
+		ORDER BY SCHEMA_NAME`,
+	Tables: `SELECT TABLE_NAME FROM INFORMATION_SCHEMA.TABLES
+		WHERE TABLE_SCHEMA = COALESCE(NULLIF(@p1, ''), SCHEMA_NAME())
+		ORDER BY TABLE_NAME`,
+	Columns: `SELECT COLUMN_NAME, DATA_TYPE, IS_NULLABLE FROM INFORMATION_SCHEMA.COLUMNS
+		WHERE TABLE_SCHEMA = COALESCE(NULLIF(@p1, ''), SCHEMA_NAME()) AND TABLE_NAME = @p2
+		ORDER BY ORDINAL_POSITION`,
+	Indexes: `SELECT i.name, i.is_unique, i.is_primary_key, c.name
+		FROM sys.indexes i
+		JOIN sys.index_columns ic ON ic.object_id = i.object_id AND ic.index_id = i.index_id
+		JOIN sys.columns c ON c.object_id = ic.object_id AND c.column_id = ic.column_id
+		WHERE i.object_id = OBJECT_ID(QUOTENAME(COALESCE(NULLIF(@p1, ''), SCHEMA_NAME())) + '.' + QUOTENAME(@p2))
+		AND ic.is_included_column = 0
+		ORDER BY i.name, ic.key_ordinal`,
+}
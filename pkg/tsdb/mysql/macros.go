@@ -61,6 +61,17 @@ func (m *mySQLMacroEngine) Interpolate(query *backend.DataQuery, timeRange backe
 	return sql, nil
 }
 
+// BindVariables replaces the references to the variables with driver parameters.
+func (m *mySQLMacroEngine) BindVariables(sql string, variables []sqleng.QueryVariable, requireBound bool) (string, []any, error) {
+	return m.ReplaceVariablesWithParameters(sql, variables, requireBound, sqleng.ParameterSyntax{
+		Placeholder: func(int) string {
+			return "?"
+		},
+		BackslashEscapes: true,
+		HashComments:     true,
+	})
+}
+
 func (m *mySQLMacroEngine) evaluateMacro(timeRange backend.TimeRange, query *backend.DataQuery, name string, args []string) (string, error) {
 	switch name {
 	case "__timeEpoch", "__time":
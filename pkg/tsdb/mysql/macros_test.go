@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
 
 	"github.com/stretchr/testify/require"
 )
@@ -221,3 +222,25 @@ func TestMacroEngineConcurrency(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestMacroEngineBindVariables(t *testing.T) {
+	engine, ok := newMysqlMacroEngine(backend.NewLoggerWith("logger", "test"), "error").(sqleng.SQLVariableBinder)
+	require.True(t, ok)
+
+	sql, args, err := engine.BindVariables("SELECT * FROM metric WHERE host IN ($hosts) AND value > ${min} AND $__timeFilter(time)",
+		[]sqleng.QueryVariable{
+			{Name: "hosts", Values: []string{"a", "b"}},
+			{Name: "min", Type: sqleng.VariableTypeNumber, Values: []string{"10"}},
+		}, true)
+	require.NoError(t, err)
+	require.Equal(t, "SELECT * FROM metric WHERE host IN (?,?) AND value > ? AND $__timeFilter(time)", sql)
+	require.Equal(t, []any{"a", "b", int64(10)}, args)
+
+	t.Run("should skip comments and escaped quotes of MySQL", func(t *testing.T) {
+		sql, args, err := engine.BindVariables("SELECT 'it\\'s $name', $name # $name\nFROM metric",
+			[]sqleng.QueryVariable{{Name: "name", Values: []string{"a"}}}, true)
+		require.NoError(t, err)
+		require.Equal(t, "SELECT 'it\\'s $name', ? # $name\nFROM metric", sql)
+		require.Equal(t, []any{"a"}, args)
+	})
+}
@@ -147,6 +147,7 @@ func newInstanceSettings(logger log.Logger) datasource.InstanceFactoryFunc {
 			TimeColumnNames:   []string{"time", "time_sec"},
 			MetricColumnTypes: []string{"CHAR", "VARCHAR", "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT"},
 			RowLimit:          sqlCfg.RowLimit,
+			SchemaQueries:     schemaQueries,
 		}
 
 		userFacingDefaultError, err := cfg.UserFacingDefaultError()
@@ -180,6 +181,15 @@ func (s *Service) getDataSourceHandler(ctx context.Context, pluginCtx backend.Pl
 	return instance, nil
 }
 
+// CallResource serves the schema of the connected SQL database
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	dsHandler, err := s.getDataSourceHandler(ctx, req.PluginContext)
+	if err != nil {
+		return err
+	}
+	return dsHandler.CallResource(ctx, req, sender)
+}
+
 // CheckHealth pings the connected SQL database
 func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
 	dsHandler, err := s.getDataSourceHandler(ctx, req.PluginContext)
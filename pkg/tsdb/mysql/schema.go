@@ -0,0 +1,19 @@
+package mysql
+
+import "github.com/grafana/grafana/pkg/tsdb/sqleng"
+
+// schemaQueries browse the schema through information_schema. In MySQL a schema is a database, so the tables of the
+// default schema are the tables of the database of the data source.
+var schemaQueries = sqleng.SchemaQueries{
+	Databases: `SELECT schema_name FROM information_schema.schemata ORDER BY schema_name`,
+	Schemas:   `SELECT schema_name FROM information_schema.schemata ORDER BY schema_name`,
+	Tables: `SELECT table_name FROM information_schema.tables
+		WHERE table_schema = COALESCE(NULLIF(?, ''), DATABASE())
+		ORDER BY table_name`,
+	Columns: `SELECT column_name, column_type, is_nullable FROM information_schema.columns
+		WHERE table_schema = COALESCE(NULLIF(?, ''), DATABASE()) AND table_name = ?
+		ORDER BY ordinal_position`,
+	Indexes: `SELECT index_name, non_unique = 0, index_name = 'PRIMARY', column_name FROM information_schema.statistics
+		WHERE table_schema = COALESCE(NULLIF(?, ''), DATABASE()) AND table_name = ?
+		ORDER BY index_name, seq_in_index`,
+}
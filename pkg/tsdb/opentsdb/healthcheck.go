@@ -0,0 +1,35 @@
+package opentsdb
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+// CheckHealth asks /api/suggest for at most one metric name. An empty list is a valid answer, so the check only
+// fails when the HTTP API can't be reached, rejects the credentials or returns something that is not a list.
+func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	logger := logger.FromContext(ctx)
+
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+
+	var suggestions []string
+	_, err = s.getJSON(ctx, dsInfo, "api/suggest", url.Values{"type": {"metrics"}, "q": {"cpu"}, "max": {"1"}}, &suggestions)
+	if err != nil {
+		logger.Warn("OpenTSDB health check failed", "error", err)
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusError,
+			Message: fmt.Sprintf("OpenTSDB health check failed: %s", err),
+		}, nil
+	}
+
+	return &backend.CheckHealthResult{
+		Status:  backend.HealthStatusOk,
+		Message: "Data source is working",
+	}, nil
+}
@@ -8,6 +8,9 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -38,10 +41,25 @@ func ProvideService(httpClientProvider httpclient.Provider) *Service {
 type datasourceInfo struct {
 	HTTPClient *http.Client
 	URL        string
+	// TSDBVersion is the OpenTSDB version selected in the settings: 1 for up to 2.1, 2 for 2.2, 3 for 2.3 and 4 for 2.4.
+	TSDBVersion int
+	// MsResolution is set when the timestamps are stored with millisecond resolution.
+	MsResolution bool
+	// LookupLimit is the maximum number of results of the suggest and lookup resources.
+	LookupLimit int
 }
 
 type DsAccess string
 
+// jsonData are the OpenTSDB specific settings of the data source.
+type jsonData struct {
+	TSDBVersion    int `json:"tsdbVersion"`
+	TSDBResolution int `json:"tsdbResolution"`
+	LookupLimit    int `json:"lookupLimit"`
+}
+
+const defaultLookupLimit = 1000
+
 func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.InstanceFactoryFunc {
 	return func(ctx context.Context, settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
 		opts, err := settings.HTTPClientOptions(ctx)
@@ -54,50 +72,108 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			return nil, err
 		}
 
+		settingsData := jsonData{}
+		if len(settings.JSONData) > 0 {
+			if err := json.Unmarshal(settings.JSONData, &settingsData); err != nil {
+				return nil, fmt.Errorf("error reading settings: %w", err)
+			}
+		}
+		if settingsData.TSDBVersion == 0 {
+			settingsData.TSDBVersion = 1
+		}
+		if settingsData.LookupLimit <= 0 {
+			settingsData.LookupLimit = defaultLookupLimit
+		}
+
 		model := &datasourceInfo{
-			HTTPClient: client,
-			URL:        settings.URL,
+			HTTPClient:   client,
+			URL:          settings.URL,
+			TSDBVersion:  settingsData.TSDBVersion,
+			MsResolution: settingsData.TSDBResolution == 2,
+			LookupLimit:  settingsData.LookupLimit,
 		}
 
 		return model, nil
 	}
 }
 
+// QueryData sends the queries sharing a time range to OpenTSDB in a single request, and splits the results by the
+// refID of the query they answer.
 func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
-	var tsdbQuery OpenTsdbQuery
-
 	logger := logger.FromContext(ctx)
 
-	q := req.Queries[0]
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
 
-	myRefID := q.RefID
+	result := backend.NewQueryDataResponse()
 
-	tsdbQuery.Start = q.TimeRange.From.UnixNano() / int64(time.Millisecond)
-	tsdbQuery.End = q.TimeRange.To.UnixNano() / int64(time.Millisecond)
+	type batch struct {
+		tsdbQuery OpenTsdbQuery
+		refIDs    []string
+	}
+	var batches []*batch
+	batchByRange := make(map[backend.TimeRange]*batch)
 
 	for _, query := range req.Queries {
-		metric := s.buildMetric(query)
-		tsdbQuery.Queries = append(tsdbQuery.Queries, metric)
+		metric, err := s.buildMetric(query)
+		if err != nil {
+			result.Responses[query.RefID] = backend.ErrDataResponse(backend.StatusBadRequest, err.Error())
+			continue
+		}
+		// Queries without a metric are incomplete queries of the editor, there is nothing to send.
+		if metric["metric"] == "" {
+			result.Responses[query.RefID] = backend.DataResponse{}
+			continue
+		}
+
+		b, ok := batchByRange[query.TimeRange]
+		if !ok {
+			b = &batch{tsdbQuery: OpenTsdbQuery{
+				Start:        query.TimeRange.From.UnixNano() / int64(time.Millisecond),
+				End:          query.TimeRange.To.UnixNano() / int64(time.Millisecond),
+				MsResolution: dsInfo.MsResolution,
+				// The index of the sub query a result answers is only returned from 2.3.
+				ShowQuery: dsInfo.TSDBVersion >= 3,
+			}}
+			batchByRange[query.TimeRange] = b
+			batches = append(batches, b)
+		}
+		b.tsdbQuery.Queries = append(b.tsdbQuery.Queries, metric)
+		b.refIDs = append(b.refIDs, query.RefID)
+	}
+
+	for _, b := range batches {
+		resp, err := s.doQuery(ctx, logger, dsInfo, b.tsdbQuery, b.refIDs)
+		if err != nil {
+			for _, refID := range b.refIDs {
+				result.Responses[refID] = backend.ErrDataResponse(backend.StatusBadGateway, err.Error())
+			}
+			continue
+		}
+		for refID, r := range resp.Responses {
+			result.Responses[refID] = r
+		}
 	}
 
+	return result, nil
+}
+
+func (s *Service) doQuery(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, tsdbQuery OpenTsdbQuery, refIDs []string) (*backend.QueryDataResponse, error) {
 	// TODO: Don't use global variable
 	if setting.Env == setting.Dev {
 		logger.Debug("OpenTsdb request", "params", tsdbQuery)
 	}
 
-	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
-	if err != nil {
-		return nil, err
-	}
-
 	request, err := s.createRequest(ctx, logger, dsInfo, tsdbQuery)
 	if err != nil {
-		return &backend.QueryDataResponse{}, err
+		return nil, err
 	}
 
 	res, err := dsInfo.HTTPClient.Do(request)
 	if err != nil {
-		return &backend.QueryDataResponse{}, err
+		return nil, err
 	}
 
 	defer func() {
@@ -107,12 +183,7 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		}
 	}()
 
-	result, err := s.parseResponse(logger, res, myRefID)
-	if err != nil {
-		return &backend.QueryDataResponse{}, err
-	}
-
-	return result, nil
+	return s.parseResponse(logger, res, tsdbQuery, refIDs)
 }
 
 func (s *Service) createRequest(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, data OpenTsdbQuery) (*http.Request, error) {
@@ -138,7 +209,9 @@ func (s *Service) createRequest(ctx context.Context, logger log.Logger, dsInfo *
 	return req, nil
 }
 
-func (s *Service) parseResponse(logger log.Logger, res *http.Response, myRefID string) (*backend.QueryDataResponse, error) {
+// parseResponse returns the results of the sub queries of tsdbQuery as the responses of refIDs, which are the refIDs
+// of the sub queries in the same order.
+func (s *Service) parseResponse(logger log.Logger, res *http.Response, tsdbQuery OpenTsdbQuery, refIDs []string) (*backend.QueryDataResponse, error) {
 	resp := backend.NewQueryDataResponse()
 
 	body, err := io.ReadAll(res.Body)
@@ -153,7 +226,7 @@ func (s *Service) parseResponse(logger log.Logger, res *http.Response, myRefID s
 
 	if res.StatusCode/100 != 2 {
 		logger.Info("Request failed", "status", res.Status, "body", string(body))
-		return nil, fmt.Errorf("request failed, status: %s", res.Status)
+		return nil, requestError(res, body)
 	}
 
 	var responseData []OpenTsdbResponse
@@ -163,43 +236,193 @@ func (s *Service) parseResponse(logger log.Logger, res *http.Response, myRefID s
 		return nil, err
 	}
 
-	frames := data.Frames{}
+	for _, refID := range refIDs {
+		resp.Responses[refID] = backend.DataResponse{Frames: data.Frames{}}
+	}
+
 	for _, val := range responseData {
-		timeVector := make([]time.Time, 0, len(val.DataPoints))
-		values := make([]float64, 0, len(val.DataPoints))
-		name := val.Metric
-		tags := val.Tags
-
-		for timeString, value := range val.DataPoints {
-			timestamp, err := strconv.ParseInt(timeString, 10, 64)
-			if err != nil {
-				logger.Info("Failed to unmarshal opentsdb timestamp", "timestamp", timeString)
-				return nil, err
-			}
-			timeVector = append(timeVector, time.Unix(timestamp, 0).UTC())
-			values = append(values, value)
+		frame, err := s.toDataFrame(logger, val, tsdbQuery.MsResolution)
+		if err != nil {
+			return nil, err
+		}
+
+		index := queryIndex(val, tsdbQuery.Queries)
+		if index < 0 || index >= len(refIDs) {
+			// the result is returned with the first query rather than dropped
+			logger.Warn("Unable to find the query of the result", "metric", val.Metric, "index", index)
+			index = 0
 		}
-		frames = append(frames, data.NewFrame(name,
-			data.NewField("time", nil, timeVector),
-			data.NewField("value", tags, values)))
+		refID := refIDs[index]
+		result := resp.Responses[refID]
+		result.Frames = append(result.Frames, frame)
+		resp.Responses[refID] = result
 	}
-	result := resp.Responses[myRefID]
-	result.Frames = frames
-	resp.Responses[myRefID] = result
 	return resp, nil
 }
 
-func (s *Service) buildMetric(query backend.DataQuery) map[string]any {
+// requestError returns the error of a failed request, with the message of OpenTSDB if the body has one.
+func requestError(res *http.Response, body []byte) error {
+	var errResponse OpenTsdbErrorResponse
+	if err := json.Unmarshal(body, &errResponse); err == nil && errResponse.Error.Message != "" {
+		return fmt.Errorf("request failed, status: %s, message: %s", res.Status, errResponse.Error.Message)
+	}
+	return fmt.Errorf("request failed, status: %s", res.Status)
+}
+
+func (s *Service) toDataFrame(logger log.Logger, val OpenTsdbResponse, msResolution bool) (*data.Frame, error) {
+	type point struct {
+		timestamp int64
+		value     *float64
+	}
+	points := make([]point, 0, len(val.DataPoints))
+	for timeString, raw := range val.DataPoints {
+		timestamp, err := strconv.ParseInt(timeString, 10, 64)
+		if err != nil {
+			logger.Info("Failed to unmarshal opentsdb timestamp", "timestamp", timeString)
+			return nil, err
+		}
+		value, err := parseDataPointValue(raw)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, point{timestamp: timestamp, value: value})
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].timestamp < points[j].timestamp })
+
+	timeVector := make([]time.Time, 0, len(points))
+	values := make([]*float64, 0, len(points))
+	for _, p := range points {
+		if msResolution {
+			timeVector = append(timeVector, time.UnixMilli(p.timestamp).UTC())
+		} else {
+			timeVector = append(timeVector, time.Unix(p.timestamp, 0).UTC())
+		}
+		values = append(values, p.value)
+	}
+
+	return data.NewFrame(val.Metric,
+		data.NewField("time", nil, timeVector),
+		data.NewField("value", val.Tags, values)), nil
+}
+
+// parseDataPointValue parses the value of a data point, which is null or NaN for the intervals filled by the null and
+// nan fill policies.
+func parseDataPointValue(raw any) (*float64, error) {
+	switch v := raw.(type) {
+	case nil:
+		return nil, nil
+	case float64:
+		return &v, nil
+	case string:
+		value, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid data point value %q", v)
+		}
+		return &value, nil
+	default:
+		return nil, fmt.Errorf("invalid data point value %v", v)
+	}
+}
+
+// queryIndex returns the index of the sub query a result answers, or -1 if no sub query matches. Before 2.3, the
+// results don't return the index and are matched to the first sub query with the same metric whose tags or filters
+// match the tags of the result, like the query editor does. Sub queries that only differ in their aggregation or
+// downsampling can't be told apart, so their results are all matched to the first of them.
+func queryIndex(val OpenTsdbResponse, queries []map[string]any) int {
+	if val.Query != nil && val.Query.Index != nil {
+		return *val.Query.Index
+	}
+
+	for i, q := range queries {
+		if q["metric"] != val.Metric {
+			continue
+		}
+		if filters, ok := q["filters"].([]Filter); ok {
+			if filtersMatch(filters, val.Tags) {
+				return i
+			}
+			continue
+		}
+		tags, _ := q["tags"].(map[string]any)
+		if tagsMatch(tags, val.Tags) {
+			return i
+		}
+	}
+	return -1
+}
+
+func tagsMatch(queryTags map[string]any, tags map[string]string) bool {
+	for key, v := range queryTags {
+		value := fmt.Sprint(v)
+		if value == "*" {
+			continue
+		}
+		if !slices.Contains(strings.Split(value, "|"), tags[key]) {
+			return false
+		}
+	}
+	return true
+}
+
+// filtersMatch returns whether the tags of a result match the filters of a sub query. A filter matches if the result
+// doesn't have its tag, as the tags that are not grouped by are aggregated away, or if its type is not known.
+func filtersMatch(filters []Filter, tags map[string]string) bool {
+	for _, f := range filters {
+		value, ok := tags[f.Tagk]
+		if !ok {
+			continue
+		}
+		var match bool
+		switch f.Type {
+		case "literal_or":
+			match = slices.Contains(strings.Split(f.Filter, "|"), value)
+		case "not_literal_or":
+			match = !slices.Contains(strings.Split(f.Filter, "|"), value)
+		case "iliteral_or", "not_iliteral_or":
+			match = slices.ContainsFunc(strings.Split(f.Filter, "|"), func(literal string) bool {
+				return strings.EqualFold(literal, value)
+			}) == (f.Type == "iliteral_or")
+		case "wildcard", "iwildcard":
+			expr := strings.ReplaceAll(regexp.QuoteMeta(f.Filter), `\*`, ".*")
+			if f.Type == "iwildcard" {
+				expr = "(?i)" + expr
+			}
+			match, _ = regexp.MatchString("^"+expr+"$", value)
+		case "regexp":
+			re, err := regexp.Compile(f.Filter)
+			match = err != nil || re.MatchString(value)
+		default:
+			match = true
+		}
+		if !match {
+			return false
+		}
+	}
+	return true
+}
+
+// fillPolicies are the fill policies of downsampling supported by OpenTSDB.
+var fillPolicies = map[string]bool{
+	"none": true,
+	"nan":  true,
+	"null": true,
+	"zero": true,
+}
+
+func (s *Service) buildMetric(query backend.DataQuery) (map[string]any, error) {
 	metric := make(map[string]any)
 
 	model, err := simplejson.NewJson(query.JSON)
 	if err != nil {
-		return nil
+		return nil, err
 	}
 
 	// Setting metric and aggregator
 	metric["metric"] = model.Get("metric").MustString()
 	metric["aggregator"] = model.Get("aggregator").MustString()
+	if metric["aggregator"] == "" {
+		metric["aggregator"] = "avg"
+	}
 
 	// Setting downsampling options
 	disableDownsampling := model.Get("disableDownsampling").MustBool()
@@ -209,8 +432,12 @@ func (s *Service) buildMetric(query backend.DataQuery) map[string]any {
 			downsampleInterval = "1m" // default value for blank
 		}
 		downsample := downsampleInterval + "-" + model.Get("downsampleAggregator").MustString()
-		if model.Get("downsampleFillPolicy").MustString() != "none" {
-			metric["downsample"] = downsample + "-" + model.Get("downsampleFillPolicy").MustString()
+		fillPolicy := model.Get("downsampleFillPolicy").MustString("none")
+		if !fillPolicies[fillPolicy] {
+			return nil, fmt.Errorf("invalid fill policy %q", fillPolicy)
+		}
+		if fillPolicy != "none" {
+			metric["downsample"] = downsample + "-" + fillPolicy
 		} else {
 			metric["downsample"] = downsample
 		}
@@ -222,36 +449,78 @@ func (s *Service) buildMetric(query backend.DataQuery) map[string]any {
 		rateOptions := make(map[string]any)
 		rateOptions["counter"] = model.Get("isCounter").MustBool()
 
-		counterMax, counterMaxCheck := model.CheckGet("counterMax")
+		counterMax, counterMaxCheck := optionalNumber(model, "counterMax")
 		if counterMaxCheck {
-			rateOptions["counterMax"] = counterMax.MustFloat64()
+			rateOptions["counterMax"] = counterMax
 		}
 
-		resetValue, resetValueCheck := model.CheckGet("counterResetValue")
+		resetValue, resetValueCheck := optionalNumber(model, "counterResetValue")
 		if resetValueCheck {
-			rateOptions["resetValue"] = resetValue.MustFloat64()
+			rateOptions["resetValue"] = resetValue
 		}
 
-		if !counterMaxCheck && (!resetValueCheck || resetValue.MustFloat64() == 0) {
+		if !counterMaxCheck && (!resetValueCheck || resetValue == 0) {
 			rateOptions["dropResets"] = true
 		}
 
 		metric["rateOptions"] = rateOptions
 	}
 
-	// Setting tags
-	tags, tagsCheck := model.CheckGet("tags")
-	if tagsCheck && len(tags.MustMap()) > 0 {
+	// Setting filters, which replace the tags since OpenTSDB 2.2
+	filters, err := parseFilters(model)
+	if err != nil {
+		return nil, err
+	}
+	if len(filters) > 0 {
+		metric["filters"] = filters
+	} else if tags, tagsCheck := model.CheckGet("tags"); tagsCheck && len(tags.MustMap()) > 0 {
 		metric["tags"] = tags.MustMap()
 	}
 
-	// Setting filters
-	filters, filtersCheck := model.CheckGet("filters")
-	if filtersCheck && len(filters.MustArray()) > 0 {
-		metric["filters"] = filters.MustArray()
+	// Only return the series having exactly the tags of the filters
+	if model.Get("explicitTags").MustBool() {
+		metric["explicitTags"] = true
+	}
+
+	return metric, nil
+}
+
+// optionalNumber returns the number of the field, which the query editor saves as a string.
+func optionalNumber(model *simplejson.Json, field string) (float64, bool) {
+	value, ok := model.CheckGet(field)
+	if !ok {
+		return 0, false
 	}
+	if s, err := value.String(); err == nil {
+		if s == "" {
+			return 0, false
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		return f, err == nil
+	}
+	f, err := value.Float64()
+	return f, err == nil
+}
 
-	return metric
+func parseFilters(model *simplejson.Json) ([]Filter, error) {
+	raw, ok := model.CheckGet("filters")
+	if !ok {
+		return nil, nil
+	}
+	encoded, err := raw.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var filters []Filter
+	if err := json.Unmarshal(encoded, &filters); err != nil {
+		return nil, fmt.Errorf("invalid filters: %w", err)
+	}
+	for _, f := range filters {
+		if f.Type == "" || f.Tagk == "" {
+			return nil, fmt.Errorf("invalid filter %q: the type and the tag key are required", f.Filter)
+		}
+	}
+	return filters, nil
 }
 
 func (s *Service) getDSInfo(ctx context.Context, pluginCtx backend.PluginContext) (*datasourceInfo, error) {
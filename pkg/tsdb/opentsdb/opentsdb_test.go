@@ -3,6 +3,7 @@ package opentsdb
 import (
 	"context"
 	"io"
+	"math"
 	"net/http"
 	"strings"
 	"testing"
@@ -33,7 +34,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 	t.Run("Parse response should handle invalid JSON", func(t *testing.T) {
 		response := `{ invalid }`
 
-		result, err := service.parseResponse(logger, &http.Response{Body: io.NopCloser(strings.NewReader(response))}, "A")
+		result, err := service.parseResponse(logger, &http.Response{Body: io.NopCloser(strings.NewReader(response))}, OpenTsdbQuery{}, []string{"A"})
 		require.Nil(t, result)
 		require.Error(t, err)
 	})
@@ -53,17 +54,18 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			}
 		]`
 
+		value := 50.0
 		testFrame := data.NewFrame("test",
 			data.NewField("time", nil, []time.Time{
 				time.Date(2014, 7, 16, 20, 55, 46, 0, time.UTC),
 			}),
-			data.NewField("value", map[string]string{"env": "prod", "app": "grafana"}, []float64{
-				50}),
+			data.NewField("value", map[string]string{"env": "prod", "app": "grafana"}, []*float64{
+				&value}),
 		)
 
 		resp := http.Response{Body: io.NopCloser(strings.NewReader(response))}
 		resp.StatusCode = 200
-		result, err := service.parseResponse(logger, &resp, "A")
+		result, err := service.parseResponse(logger, &resp, OpenTsdbQuery{}, []string{"A"})
 		require.NoError(t, err)
 
 		frame := result.Responses["A"]
@@ -90,17 +92,18 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			}
 		]`
 
+		value := 50.0
 		testFrame := data.NewFrame("test",
 			data.NewField("time", nil, []time.Time{
 				time.Date(2014, 7, 16, 20, 55, 46, 0, time.UTC),
 			}),
-			data.NewField("value", map[string]string{"env": "prod", "app": "grafana"}, []float64{
-				50}),
+			data.NewField("value", map[string]string{"env": "prod", "app": "grafana"}, []*float64{
+				&value}),
 		)
 
 		resp := http.Response{Body: io.NopCloser(strings.NewReader(response))}
 		resp.StatusCode = 200
-		result, err := service.parseResponse(logger, &resp, myRefid)
+		result, err := service.parseResponse(logger, &resp, OpenTsdbQuery{}, []string{myRefid})
 		require.NoError(t, err)
 
 		if diff := cmp.Diff(testFrame, result.Responses[myRefid].Frames[0], data.FrameTestCompareOptions()...); diff != "" {
@@ -122,7 +125,8 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric, err := service.buildMetric(query)
+		require.NoError(t, err)
 
 		require.Len(t, metric, 3)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -144,7 +148,8 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric, err := service.buildMetric(query)
+		require.NoError(t, err)
 
 		require.Len(t, metric, 2)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -165,7 +170,8 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric, err := service.buildMetric(query)
+		require.NoError(t, err)
 
 		require.Len(t, metric, 3)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -191,7 +197,8 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric, err := service.buildMetric(query)
+		require.NoError(t, err)
 
 		require.Len(t, metric, 3)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -222,7 +229,8 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric, err := service.buildMetric(query)
+		require.NoError(t, err)
 
 		require.Len(t, metric, 5)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -257,7 +265,8 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric, err := service.buildMetric(query)
+		require.NoError(t, err)
 
 		require.Len(t, metric, 5)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -276,4 +285,140 @@ func TestOpenTsdbExecutor(t *testing.T) {
 		require.Equal(t, float64(45), metricRateOptions["counterMax"])
 		require.Equal(t, float64(60), metricRateOptions["resetValue"])
 	})
+
+	t.Run("Build metric with filters and explicit tags", func(t *testing.T) {
+		query := backend.DataQuery{
+			JSON: []byte(`
+					{
+						"metric": "cpu.average.percent",
+						"disableDownsampling": true,
+						"explicitTags": true,
+						"tags": {
+							"env": "prod"
+						},
+						"filters": [
+							{"type": "wildcard", "tagk": "host", "filter": "web-*", "groupBy": true}
+						]
+					}`,
+			),
+		}
+
+		metric, err := service.buildMetric(query)
+		require.NoError(t, err)
+
+		require.Len(t, metric, 4)
+		require.Equal(t, "avg", metric["aggregator"])
+		require.Equal(t, []Filter{{Type: "wildcard", Tagk: "host", Filter: "web-*", GroupBy: true}}, metric["filters"])
+		require.Nil(t, metric["tags"])
+		require.True(t, metric["explicitTags"].(bool))
+	})
+
+	t.Run("Build metric with invalid fill policy or filters", func(t *testing.T) {
+		_, err := service.buildMetric(backend.DataQuery{JSON: []byte(`{"metric": "cpu", "downsampleFillPolicy": "previous"}`)})
+		require.EqualError(t, err, `invalid fill policy "previous"`)
+
+		_, err = service.buildMetric(backend.DataQuery{JSON: []byte(`{"metric": "cpu", "filters": [{"filter": "a"}]}`)})
+		require.EqualError(t, err, `invalid filter "a": the type and the tag key are required`)
+	})
+
+	t.Run("Build metric with counter options saved as strings", func(t *testing.T) {
+		query := backend.DataQuery{
+			JSON: []byte(`{"metric": "cpu", "shouldComputeRate": true, "isCounter": true, "counterMax": "45", "counterResetValue": ""}`),
+		}
+
+		metric, err := service.buildMetric(query)
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"counter": true, "counterMax": float64(45)}, metric["rateOptions"])
+	})
+
+	t.Run("Parse response should split results by query and handle filled values", func(t *testing.T) {
+		response := `
+		[
+			{"metric": "cpu", "tags": {"host": "b"}, "dps": {"1405544146000": 1, "1405544145000": null}},
+			{"metric": "mem", "tags": {"host": "a"}, "dps": {"1405544146000": "NaN"}},
+			{"metric": "cpu", "tags": {"host": "a"}, "dps": {"1405544146000": 2}}
+		]`
+		tsdbQuery := OpenTsdbQuery{
+			MsResolution: true,
+			Queries: []map[string]any{
+				{"metric": "cpu", "tags": map[string]any{"host": "a"}},
+				{"metric": "cpu", "tags": map[string]any{"host": "b|c"}},
+				{"metric": "mem", "filters": []Filter{{Type: "literal_or", Tagk: "host", Filter: "a"}}},
+			},
+		}
+
+		resp := http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(response))}
+		result, err := service.parseResponse(logger, &resp, tsdbQuery, []string{"A", "B", "C"})
+		require.NoError(t, err)
+
+		require.Len(t, result.Responses["A"].Frames, 1)
+		require.Equal(t, 2.0, *result.Responses["A"].Frames[0].Fields[1].At(0).(*float64))
+
+		frame := result.Responses["B"].Frames[0]
+		require.Equal(t, []time.Time{time.UnixMilli(1405544145000).UTC(), time.UnixMilli(1405544146000).UTC()},
+			[]time.Time{frame.Fields[0].At(0).(time.Time), frame.Fields[0].At(1).(time.Time)})
+		require.Nil(t, frame.Fields[1].At(0))
+
+		require.True(t, math.IsNaN(*result.Responses["C"].Frames[0].Fields[1].At(0).(*float64)))
+	})
+
+	t.Run("Parse response should use the index of the query", func(t *testing.T) {
+		response := `[{"metric": "cpu", "tags": {}, "dps": {}, "query": {"index": 1}}]`
+		tsdbQuery := OpenTsdbQuery{Queries: []map[string]any{{"metric": "cpu"}, {"metric": "cpu"}}}
+
+		resp := http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(response))}
+		result, err := service.parseResponse(logger, &resp, tsdbQuery, []string{"A", "B"})
+		require.NoError(t, err)
+		require.Empty(t, result.Responses["A"].Frames)
+		require.Len(t, result.Responses["B"].Frames, 1)
+	})
+
+	t.Run("Parse response should match the results to the filters of the queries", func(t *testing.T) {
+		response := `
+		[
+			{"metric": "cpu", "tags": {"host": "db-1"}, "dps": {}},
+			{"metric": "cpu", "tags": {"host": "WEB-1"}, "dps": {}},
+			{"metric": "cpu", "tags": {"host": "cache"}, "dps": {}},
+			{"metric": "cpu", "tags": {"host": "api"}, "dps": {}},
+			{"metric": "cpu", "tags": {}, "dps": {}}
+		]`
+		tsdbQuery := OpenTsdbQuery{Queries: []map[string]any{
+			{"metric": "cpu", "filters": []Filter{{Type: "iwildcard", Tagk: "host", Filter: "web-*", GroupBy: true}}},
+			{"metric": "cpu", "filters": []Filter{{Type: "regexp", Tagk: "host", Filter: "^db-[0-9]+$", GroupBy: true}}},
+			{"metric": "cpu", "filters": []Filter{{Type: "not_literal_or", Tagk: "host", Filter: "api|db-1|WEB-1", GroupBy: true}}},
+			{"metric": "cpu", "filters": []Filter{{Type: "iliteral_or", Tagk: "host", Filter: "API", GroupBy: true}}},
+		}}
+
+		resp := http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(response))}
+		result, err := service.parseResponse(logger, &resp, tsdbQuery, []string{"A", "B", "C", "D"})
+		require.NoError(t, err)
+		// the result without the tag of the filters, like an aggregated one, matches the first query
+		require.Len(t, result.Responses["A"].Frames, 2)
+		require.Len(t, result.Responses["B"].Frames, 1)
+		require.Len(t, result.Responses["C"].Frames, 1)
+		require.Len(t, result.Responses["D"].Frames, 1)
+	})
+
+	t.Run("Parse response should return the results of unknown queries with the first query", func(t *testing.T) {
+		response := `[
+			{"metric": "cpu", "tags": {}, "dps": {}, "query": {"index": -1}},
+			{"metric": "cpu", "tags": {}, "dps": {}, "query": {"index": 2}},
+			{"metric": "mem", "tags": {}, "dps": {}}
+		]`
+		tsdbQuery := OpenTsdbQuery{Queries: []map[string]any{{"metric": "cpu"}, {"metric": "cpu"}}}
+
+		resp := http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(response))}
+		result, err := service.parseResponse(logger, &resp, tsdbQuery, []string{"A", "B"})
+		require.NoError(t, err)
+		require.Len(t, result.Responses["A"].Frames, 3)
+		require.Empty(t, result.Responses["B"].Frames)
+	})
+
+	t.Run("Parse response should return the error message of OpenTSDB", func(t *testing.T) {
+		response := `{"error": {"code": 400, "message": "No such name for 'metrics': 'cpu'"}}`
+
+		resp := http.Response{StatusCode: 400, Status: "400 Bad Request", Body: io.NopCloser(strings.NewReader(response))}
+		_, err := service.parseResponse(logger, &resp, OpenTsdbQuery{}, []string{"A"})
+		require.EqualError(t, err, "request failed, status: 400 Bad Request, message: No such name for 'metrics': 'cpu'")
+	})
 }
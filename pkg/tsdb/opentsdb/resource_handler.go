@@ -0,0 +1,190 @@
+package opentsdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource"
+)
+
+// suggestTypes are the types of names the suggest API completes.
+var suggestTypes = map[string]bool{
+	"metrics": true,
+	"tagk":    true,
+	"tagv":    true,
+}
+
+// lookupResponse is the response of the lookup API, listing the time series of a metric.
+type lookupResponse struct {
+	Results []struct {
+		Tags map[string]string `json:"tags"`
+	} `json:"results"`
+}
+
+// CallResource serves the name suggestions and the tag lookups of OpenTSDB through the HTTP client of the data
+// source:
+//   - api/suggest?type=metrics|tagk|tagv&q=prefix returns the names starting with q,
+//   - tag-keys?metric=name returns the tag keys of the time series of the metric,
+//   - tag-values?metric=name&key=tagk&tag=tagk2=value returns the values of the tag key, optionally restricted to
+//     the time series having the given tags.
+//
+// Every resource returns a JSON array of strings, of at most the lookup limit of the data source.
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	logger := logger.FromContext(ctx)
+
+	if req.Method != http.MethodGet {
+		return sendResourceError(sender, http.StatusMethodNotAllowed, fmt.Errorf("invalid method %s", req.Method))
+	}
+	reqURL, err := url.Parse(req.URL)
+	if err != nil {
+		return sendResourceError(sender, http.StatusBadRequest, fmt.Errorf("invalid URL: %w", err))
+	}
+	params := reqURL.Query()
+
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		return err
+	}
+
+	var result []string
+	var status int
+	switch strings.Trim(req.Path, "/") {
+	case "api/suggest":
+		if !suggestTypes[params.Get("type")] {
+			return sendResourceError(sender, http.StatusBadRequest, fmt.Errorf("invalid suggestion type %q", params.Get("type")))
+		}
+		result, status, err = s.suggest(ctx, dsInfo, params.Get("type"), params.Get("q"))
+	case "tag-keys":
+		if params.Get("metric") == "" {
+			return sendResourceError(sender, http.StatusBadRequest, errors.New("missing metric"))
+		}
+		result, status, err = s.lookupTagKeys(ctx, dsInfo, params.Get("metric"))
+	case "tag-values":
+		if params.Get("metric") == "" || params.Get("key") == "" {
+			return sendResourceError(sender, http.StatusBadRequest, errors.New("missing metric or key"))
+		}
+		result, status, err = s.lookupTagValues(ctx, dsInfo, params.Get("metric"), params.Get("key"), params["tag"])
+	default:
+		return sendResourceError(sender, http.StatusNotFound, fmt.Errorf("unknown resource %q", req.Path))
+	}
+	if err != nil {
+		logger.Warn("OpenTSDB resource request failed", "path", req.Path, "error", err)
+		return sendResourceError(sender, status, err)
+	}
+
+	return resource.SendJSON(sender, result)
+}
+
+func (s *Service) suggest(ctx context.Context, dsInfo *datasourceInfo, suggestType, prefix string) ([]string, int, error) {
+	var suggestions []string
+	params := url.Values{
+		"type": {suggestType},
+		"q":    {prefix},
+		"max":  {strconv.Itoa(dsInfo.LookupLimit)},
+	}
+	if status, err := s.getJSON(ctx, dsInfo, "api/suggest", params, &suggestions); err != nil {
+		return nil, status, err
+	}
+	if suggestions == nil {
+		suggestions = []string{}
+	}
+	return suggestions, http.StatusOK, nil
+}
+
+func (s *Service) lookupTagKeys(ctx context.Context, dsInfo *datasourceInfo, metric string) ([]string, int, error) {
+	var lookup lookupResponse
+	params := url.Values{"m": {metric}, "limit": {strconv.Itoa(dsInfo.LookupLimit)}}
+	if status, err := s.getJSON(ctx, dsInfo, "api/search/lookup", params, &lookup); err != nil {
+		return nil, status, err
+	}
+
+	keys := []string{}
+	seen := make(map[string]bool)
+	for _, r := range lookup.Results {
+		for key := range r.Tags {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys, http.StatusOK, nil
+}
+
+func (s *Service) lookupTagValues(ctx context.Context, dsInfo *datasourceInfo, metric, key string, tags []string) ([]string, int, error) {
+	var lookup lookupResponse
+	m := metric + "{" + strings.Join(append([]string{key + "=*"}, tags...), ",") + "}"
+	params := url.Values{"m": {m}, "limit": {strconv.Itoa(dsInfo.LookupLimit)}}
+	if status, err := s.getJSON(ctx, dsInfo, "api/search/lookup", params, &lookup); err != nil {
+		return nil, status, err
+	}
+
+	values := []string{}
+	seen := make(map[string]bool)
+	for _, r := range lookup.Results {
+		if value, ok := r.Tags[key]; ok && !seen[value] {
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+	return values, http.StatusOK, nil
+}
+
+// getJSON decodes the response of the API endpoint into v, or returns the error with the status to respond with.
+func (s *Service) getJSON(ctx context.Context, dsInfo *datasourceInfo, endpoint string, params url.Values, v any) (int, error) {
+	u, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	u.Path = path.Join(u.Path, endpoint)
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	res, err := dsInfo.HTTPClient.Do(req)
+	if err != nil {
+		return http.StatusBadGateway, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return http.StatusBadGateway, err
+	}
+	// an error of OpenTSDB, even 401 or 404, is not an error of the request to Grafana
+	if res.StatusCode/100 != 2 {
+		return http.StatusBadGateway, requestError(res, body)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return http.StatusBadGateway, fmt.Errorf("invalid response from OpenTSDB: %w", err)
+	}
+	return http.StatusOK, nil
+}
+
+func sendResourceError(sender backend.CallResourceResponseSender, status int, err error) error {
+	body, jsonErr := json.Marshal(map[string]string{"error": err.Error()})
+	if jsonErr != nil {
+		return jsonErr
+	}
+	return sender.Send(&backend.CallResourceResponse{
+		Status:  status,
+		Headers: map[string][]string{"Content-Type": {"application/json"}},
+		Body:    body,
+	})
+}
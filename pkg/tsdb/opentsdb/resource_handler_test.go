@@ -0,0 +1,236 @@
+package opentsdb
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/stretchr/testify/require"
+)
+
+type testInstanceManager struct {
+	dsInfo *datasourceInfo
+}
+
+func (m testInstanceManager) Get(_ context.Context, _ backend.PluginContext) (instancemgmt.Instance, error) {
+	return m.dsInfo, nil
+}
+
+func (m testInstanceManager) Do(_ context.Context, _ backend.PluginContext, _ instancemgmt.InstanceCallbackFunc) error {
+	return nil
+}
+
+type resourceSender struct {
+	resp *backend.CallResourceResponse
+}
+
+func (s *resourceSender) Send(resp *backend.CallResourceResponse) error {
+	s.resp = resp
+	return nil
+}
+
+// newTestService returns a service querying an OpenTSDB server that serves the handler, and the requests received by
+// the server with their bodies.
+func newTestService(t *testing.T, tsdbVersion int, handler http.HandlerFunc) (*Service, *[]*http.Request, *[][]byte) {
+	t.Helper()
+	var requests []*http.Request
+	var bodies [][]byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		requests = append(requests, r)
+		bodies = append(bodies, body)
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	return &Service{im: testInstanceManager{dsInfo: &datasourceInfo{
+		HTTPClient:  srv.Client(),
+		URL:         srv.URL + "/tsdb",
+		TSDBVersion: tsdbVersion,
+		LookupLimit: 100,
+	}}}, &requests, &bodies
+}
+
+func callResource(t *testing.T, s *Service, resourceURL string) *backend.CallResourceResponse {
+	t.Helper()
+	u, err := url.Parse(resourceURL)
+	require.NoError(t, err)
+	sender := &resourceSender{}
+	err = s.CallResource(context.Background(), &backend.CallResourceRequest{
+		Method: http.MethodGet,
+		Path:   u.Path,
+		URL:    resourceURL,
+	}, sender)
+	require.NoError(t, err)
+	require.NotNil(t, sender.resp)
+	return sender.resp
+}
+
+func TestQueryData(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	timeRange := backend.TimeRange{From: from, To: from.Add(time.Hour)}
+
+	t.Run("should batch the queries of a time range and split the results", func(t *testing.T) {
+		s, requests, bodies := newTestService(t, 3, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, `[
+				{"metric": "mem", "tags": {}, "dps": {"1704067200": 2}, "query": {"index": 1}},
+				{"metric": "cpu", "tags": {}, "dps": {"1704067200": 1}, "query": {"index": 0}}
+			]`)
+		})
+
+		resp, err := s.QueryData(context.Background(), &backend.QueryDataRequest{Queries: []backend.DataQuery{
+			{RefID: "A", TimeRange: timeRange, JSON: []byte(`{"metric": "cpu", "disableDownsampling": true}`)},
+			{RefID: "B", TimeRange: timeRange, JSON: []byte(`{"metric": "mem", "disableDownsampling": true}`)},
+			{RefID: "C", TimeRange: timeRange, JSON: []byte(`{"metric": ""}`)},
+			{RefID: "D", TimeRange: timeRange, JSON: []byte(`{"metric": "cpu", "downsampleFillPolicy": "previous"}`)},
+		}})
+		require.NoError(t, err)
+
+		require.Len(t, *requests, 1)
+		require.Equal(t, "/tsdb/api/query", (*requests)[0].URL.Path)
+		var tsdbQuery OpenTsdbQuery
+		require.NoError(t, json.Unmarshal((*bodies)[0], &tsdbQuery))
+		require.Len(t, tsdbQuery.Queries, 2)
+		require.True(t, tsdbQuery.ShowQuery)
+		require.Equal(t, from.UnixMilli(), tsdbQuery.Start)
+
+		require.Equal(t, "cpu", resp.Responses["A"].Frames[0].Name)
+		require.Equal(t, "mem", resp.Responses["B"].Frames[0].Name)
+		require.NoError(t, resp.Responses["C"].Error)
+		require.Empty(t, resp.Responses["C"].Frames)
+		require.EqualError(t, resp.Responses["D"].Error, `invalid fill policy "previous"`)
+	})
+
+	t.Run("should send a request per time range", func(t *testing.T) {
+		s, requests, bodies := newTestService(t, 1, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, `[{"metric": "cpu", "tags": {}, "dps": {"1704067200": 1}}]`)
+		})
+
+		shifted := backend.TimeRange{From: from.Add(-24 * time.Hour), To: from.Add(-23 * time.Hour)}
+		resp, err := s.QueryData(context.Background(), &backend.QueryDataRequest{Queries: []backend.DataQuery{
+			{RefID: "A", TimeRange: timeRange, JSON: []byte(`{"metric": "cpu"}`)},
+			{RefID: "B", TimeRange: shifted, JSON: []byte(`{"metric": "cpu"}`)},
+		}})
+		require.NoError(t, err)
+
+		require.Len(t, *requests, 2)
+		for _, body := range *bodies {
+			var tsdbQuery OpenTsdbQuery
+			require.NoError(t, json.Unmarshal(body, &tsdbQuery))
+			require.Len(t, tsdbQuery.Queries, 1)
+			require.False(t, tsdbQuery.ShowQuery)
+		}
+		require.Len(t, resp.Responses["A"].Frames, 1)
+		require.Len(t, resp.Responses["B"].Frames, 1)
+	})
+
+	t.Run("should return the errors of OpenTSDB for the queries of the request", func(t *testing.T) {
+		s, _, _ := newTestService(t, 1, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, `{"error": {"code": 400, "message": "No such name for 'metrics': 'cpu'"}}`)
+		})
+
+		resp, err := s.QueryData(context.Background(), &backend.QueryDataRequest{Queries: []backend.DataQuery{
+			{RefID: "A", TimeRange: timeRange, JSON: []byte(`{"metric": "cpu"}`)},
+		}})
+		require.NoError(t, err)
+		require.EqualError(t, resp.Responses["A"].Error, "request failed, status: 400 Bad Request, message: No such name for 'metrics': 'cpu'")
+	})
+}
+
+func TestCallResource(t *testing.T) {
+	lookup := `{"type": "LOOKUP", "metric": "cpu", "results": [
+		{"metric": "cpu", "tags": {"host": "a", "env": "prod"}},
+		{"metric": "cpu", "tags": {"host": "b", "env": "prod"}},
+		{"metric": "cpu", "tags": {"host": "a", "env": "dev", "dc": "eu"}}
+	]}`
+
+	t.Run("should suggest names", func(t *testing.T) {
+		s, requests, _ := newTestService(t, 1, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, `["cpu.idle", "cpu.user"]`)
+		})
+
+		resp := callResource(t, s, "api/suggest?type=metrics&q=cpu")
+		require.Equal(t, http.StatusOK, resp.Status)
+		require.JSONEq(t, `["cpu.idle", "cpu.user"]`, string(resp.Body))
+		require.Equal(t, "/tsdb/api/suggest", (*requests)[0].URL.Path)
+		require.Equal(t, url.Values{"type": {"metrics"}, "q": {"cpu"}, "max": {"100"}}, (*requests)[0].URL.Query())
+	})
+
+	t.Run("should look up tag keys", func(t *testing.T) {
+		s, requests, _ := newTestService(t, 1, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, lookup)
+		})
+
+		resp := callResource(t, s, "tag-keys?metric=cpu")
+		require.Equal(t, http.StatusOK, resp.Status)
+		var keys []string
+		require.NoError(t, json.Unmarshal(resp.Body, &keys))
+		require.ElementsMatch(t, []string{"host", "env", "dc"}, keys)
+		require.Equal(t, "/tsdb/api/search/lookup", (*requests)[0].URL.Path)
+		require.Equal(t, "cpu", (*requests)[0].URL.Query().Get("m"))
+	})
+
+	t.Run("should look up tag values", func(t *testing.T) {
+		s, requests, _ := newTestService(t, 1, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, lookup)
+		})
+
+		resp := callResource(t, s, "tag-values?metric=cpu&key=host&tag=env=prod")
+		require.Equal(t, http.StatusOK, resp.Status)
+		require.JSONEq(t, `["a", "b"]`, string(resp.Body))
+		require.Equal(t, "cpu{host=*,env=prod}", (*requests)[0].URL.Query().Get("m"))
+	})
+
+	t.Run("should forward the errors of OpenTSDB", func(t *testing.T) {
+		s, _, _ := newTestService(t, 1, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		})
+
+		resp := callResource(t, s, "api/suggest?type=tagk")
+		require.Equal(t, http.StatusBadGateway, resp.Status)
+		require.JSONEq(t, `{"error": "request failed, status: 401 Unauthorized"}`, string(resp.Body))
+	})
+
+	t.Run("should reject invalid resources", func(t *testing.T) {
+		s, requests, _ := newTestService(t, 1, func(w http.ResponseWriter, r *http.Request) {})
+
+		require.Equal(t, http.StatusNotFound, callResource(t, s, "api/query").Status)
+		require.Equal(t, http.StatusBadRequest, callResource(t, s, "api/suggest?type=other").Status)
+		require.Equal(t, http.StatusBadRequest, callResource(t, s, "tag-keys").Status)
+		require.Equal(t, http.StatusBadRequest, callResource(t, s, "tag-values?metric=cpu").Status)
+		require.Empty(t, *requests)
+	})
+}
+
+func TestCheckHealth(t *testing.T) {
+	t.Run("should report a working data source", func(t *testing.T) {
+		s, requests, _ := newTestService(t, 1, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, `[]`)
+		})
+
+		res, err := s.CheckHealth(context.Background(), &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusOk, res.Status)
+		require.Equal(t, "/tsdb/api/suggest", (*requests)[0].URL.Path)
+	})
+
+	t.Run("should report failed requests", func(t *testing.T) {
+		s, _, _ := newTestService(t, 1, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		})
+
+		res, err := s.CheckHealth(context.Background(), &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusError, res.Status)
+		require.Equal(t, "OpenTSDB health check failed: request failed, status: 403 Forbidden", res.Message)
+	})
+}
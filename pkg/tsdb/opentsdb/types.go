@@ -1,13 +1,36 @@
 package opentsdb
 
 type OpenTsdbQuery struct {
-	Start   int64            `json:"start"`
-	End     int64            `json:"end"`
-	Queries []map[string]any `json:"queries"`
+	Start        int64            `json:"start"`
+	End          int64            `json:"end"`
+	Queries      []map[string]any `json:"queries"`
+	MsResolution bool             `json:"msResolution,omitempty"`
+	ShowQuery    bool             `json:"showQuery,omitempty"`
+}
+
+// Filter is a tag filter of OpenTSDB 2.2 and later.
+type Filter struct {
+	Type    string `json:"type"`
+	Tagk    string `json:"tagk"`
+	Filter  string `json:"filter"`
+	GroupBy bool   `json:"groupBy"`
 }
 
 type OpenTsdbResponse struct {
-	Metric     string             `json:"metric"`
-	Tags       map[string]string  `json:"tags"`
-	DataPoints map[string]float64 `json:"dps"`
+	Metric string            `json:"metric"`
+	Tags   map[string]string `json:"tags"`
+	// DataPoints are numbers, or null and "NaN" for filled intervals.
+	DataPoints map[string]any `json:"dps"`
+	// Query is the sub query of the result, returned when showQuery is set.
+	Query *OpenTsdbResponseQuery `json:"query"`
+}
+
+type OpenTsdbResponseQuery struct {
+	Index *int `json:"index"`
+}
+
+type OpenTsdbErrorResponse struct {
+	Error struct {
+		Message string `json:"message"`
+	} `json:"error"`
 }
@@ -0,0 +1,298 @@
+package sqleng
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource"
+)
+
+const (
+	// DefaultSchemaRowLimit is the maximum number of rows read by a schema query when the data source has no row limit.
+	DefaultSchemaRowLimit = 10000
+	// schemaCacheTTL is the time the result of a schema query is reused for before querying the database again.
+	schemaCacheTTL = time.Minute
+)
+
+// ErrSchemaNotSupported is returned when the engine of the data source does not support listing a kind of object.
+var ErrSchemaNotSupported = errors.New("not supported by the data source")
+
+// SchemaQueries are the queries used to browse the schema of the database of a data source. The queries of the tables
+// take the schema as their only parameter, and the queries of the columns and the indexes take the schema and the table,
+// in this order. An empty schema stands for the default schema of the connection. An empty query means that the engine
+// does not support listing that kind of object.
+type SchemaQueries struct {
+	// Databases returns the name of each database of the server.
+	Databases string
+	// Schemas returns the name of each schema of the database.
+	Schemas string
+	// Tables returns the name of each table and view of a schema.
+	Tables string
+	// Columns returns the name, the type and the nullability ("YES" or "NO") of each column of a table, in order.
+	Columns string
+	// Indexes returns one row per column of each index of a table: the name of the index, whether it is unique,
+	// whether it is the primary key and the name of the column, ordered by index and by position of the column.
+	Indexes string
+}
+
+// Column is a column of a table.
+type Column struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Nullable bool   `json:"nullable"`
+}
+
+// Index is an index of a table.
+type Index struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+	Unique  bool     `json:"unique"`
+	Primary bool     `json:"primary"`
+}
+
+type schemaCacheEntry struct {
+	value     any
+	expiresAt time.Time
+}
+
+type schemaCache struct {
+	mtx     sync.Mutex
+	entries map[string]schemaCacheEntry
+}
+
+// getOrLoad returns the cached value of the key, or loads it and caches it if it is missing or expired.
+// Errors are not cached.
+func getOrLoad[T any](c *schemaCache, key string, load func() (T, error)) (T, error) {
+	now := time.Now()
+
+	c.mtx.Lock()
+	if entry, ok := c.entries[key]; ok && now.Before(entry.expiresAt) {
+		c.mtx.Unlock()
+		return entry.value.(T), nil
+	}
+	c.mtx.Unlock()
+
+	value, err := load()
+	if err != nil {
+		return value, err
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	for k, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = schemaCacheEntry{value: value, expiresAt: now.Add(schemaCacheTTL)}
+	return value, nil
+}
+
+// Databases returns the names of the databases of the server.
+func (e *DataSourceHandler) Databases(ctx context.Context) ([]string, error) {
+	return getOrLoad(&e.schemaCache, "databases", func() ([]string, error) {
+		return e.queryNames(ctx, e.schemaQueries.Databases)
+	})
+}
+
+// Schemas returns the names of the schemas of the database of the data source.
+func (e *DataSourceHandler) Schemas(ctx context.Context) ([]string, error) {
+	return getOrLoad(&e.schemaCache, "schemas", func() ([]string, error) {
+		return e.queryNames(ctx, e.schemaQueries.Schemas)
+	})
+}
+
+// Tables returns the names of the tables and views of a schema, or of the default schema if schema is empty.
+func (e *DataSourceHandler) Tables(ctx context.Context, schema string) ([]string, error) {
+	return getOrLoad(&e.schemaCache, schemaCacheKey("tables", schema), func() ([]string, error) {
+		return e.queryNames(ctx, e.schemaQueries.Tables, schema)
+	})
+}
+
+// Columns returns the columns of a table, in order.
+func (e *DataSourceHandler) Columns(ctx context.Context, schema, table string) ([]Column, error) {
+	return getOrLoad(&e.schemaCache, schemaCacheKey("columns", schema, table), func() ([]Column, error) {
+		columns := make([]Column, 0)
+		err := e.querySchema(ctx, e.schemaQueries.Columns, func(rows *sql.Rows) error {
+			var c Column
+			var nullable string
+			if err := rows.Scan(&c.Name, &c.Type, &nullable); err != nil {
+				return err
+			}
+			c.Nullable = strings.EqualFold(nullable, "YES")
+			columns = append(columns, c)
+			return nil
+		}, schema, table)
+		return columns, err
+	})
+}
+
+// Indexes returns the indexes of a table, ordered by name.
+func (e *DataSourceHandler) Indexes(ctx context.Context, schema, table string) ([]Index, error) {
+	return getOrLoad(&e.schemaCache, schemaCacheKey("indexes", schema, table), func() ([]Index, error) {
+		indexes := make([]Index, 0)
+		err := e.querySchema(ctx, e.schemaQueries.Indexes, func(rows *sql.Rows) error {
+			var i Index
+			var column sql.NullString
+			if err := rows.Scan(&i.Name, &i.Unique, &i.Primary, &column); err != nil {
+				return err
+			}
+			if len(indexes) == 0 || indexes[len(indexes)-1].Name != i.Name {
+				i.Columns = make([]string, 0, 1)
+				indexes = append(indexes, i)
+			}
+			// Expression indexes have no column name.
+			if column.Valid {
+				last := &indexes[len(indexes)-1]
+				last.Columns = append(last.Columns, column.String)
+			}
+			return nil
+		}, schema, table)
+		return indexes, err
+	})
+}
+
+func (e *DataSourceHandler) queryNames(ctx context.Context, query string, args ...any) ([]string, error) {
+	names := make([]string, 0)
+	err := e.querySchema(ctx, query, func(rows *sql.Rows) error {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		names = append(names, name)
+		return nil
+	}, args...)
+	return names, err
+}
+
+// querySchema runs a schema query and calls scan for each row, reading at most the schema row limit of the data source.
+func (e *DataSourceHandler) querySchema(ctx context.Context, query string, scan func(rows *sql.Rows) error, args ...any) error {
+	if query == "" {
+		return ErrSchemaNotSupported
+	}
+
+	rows, err := e.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return e.TransformQueryError(e.log, err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			e.log.Warn("Failed to close rows", "err", err)
+		}
+	}()
+
+	limit := e.schemaRowLimit()
+	for count := int64(0); count < limit && rows.Next(); count++ {
+		if err := scan(rows); err != nil {
+			return e.TransformQueryError(e.log, err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return e.TransformQueryError(e.log, err)
+	}
+	return nil
+}
+
+func (e *DataSourceHandler) schemaRowLimit() int64 {
+	if e.rowLimit > 0 && e.rowLimit < DefaultSchemaRowLimit {
+		return e.rowLimit
+	}
+	return DefaultSchemaRowLimit
+}
+
+func schemaCacheKey(kind string, names ...string) string {
+	key := kind
+	for _, name := range names {
+		key += "/" + url.PathEscape(name)
+	}
+	return key
+}
+
+// CallResource serves the schema of the database of the data source. The supported paths are databases, schemas,
+// tables?schema=, columns?schema=&table= and indexes?schema=&table=. All of them accept a limit parameter which
+// caps the number of returned objects.
+func (e *DataSourceHandler) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return sendSchemaError(sender, http.StatusBadRequest, err)
+	}
+	params := u.Query()
+
+	limit := -1
+	if l := params.Get("limit"); l != "" {
+		limit, err = strconv.Atoi(l)
+		if err != nil || limit < 0 {
+			return sendSchemaError(sender, http.StatusBadRequest, fmt.Errorf("invalid limit %q", l))
+		}
+	}
+
+	schema, table := params.Get("schema"), params.Get("table")
+	if (req.Path == "columns" || req.Path == "indexes") && table == "" {
+		return sendSchemaError(sender, http.StatusBadRequest, errors.New("missing table"))
+	}
+
+	var result any
+	switch req.Path {
+	case "databases":
+		var databases []string
+		databases, err = e.Databases(ctx)
+		result = limitSchemaObjects(databases, limit)
+	case "schemas":
+		var schemas []string
+		schemas, err = e.Schemas(ctx)
+		result = limitSchemaObjects(schemas, limit)
+	case "tables":
+		var tables []string
+		tables, err = e.Tables(ctx, schema)
+		result = limitSchemaObjects(tables, limit)
+	case "columns":
+		var columns []Column
+		columns, err = e.Columns(ctx, schema, table)
+		result = limitSchemaObjects(columns, limit)
+	case "indexes":
+		var indexes []Index
+		indexes, err = e.Indexes(ctx, schema, table)
+		result = limitSchemaObjects(indexes, limit)
+	default:
+		return sender.Send(&backend.CallResourceResponse{
+			Status: http.StatusNotFound,
+		})
+	}
+
+	if errors.Is(err, ErrSchemaNotSupported) {
+		return sendSchemaError(sender, http.StatusNotImplemented, fmt.Errorf("%s are %w", req.Path, err))
+	}
+	if err != nil {
+		return sendSchemaError(sender, http.StatusInternalServerError, err)
+	}
+	return resource.SendJSON(sender, result)
+}
+
+func limitSchemaObjects[T any](objects []T, limit int) []T {
+	if limit >= 0 && limit < len(objects) {
+		return objects[:limit]
+	}
+	return objects
+}
+
+func sendSchemaError(sender backend.CallResourceResponseSender, status int, err error) error {
+	body, jsonErr := json.Marshal(map[string]string{"error": err.Error()})
+	if jsonErr != nil {
+		return jsonErr
+	}
+	return sender.Send(&backend.CallResourceResponse{
+		Status:  status,
+		Headers: map[string][]string{"Content-Type": {"application/json"}},
+		Body:    body,
+	})
+}
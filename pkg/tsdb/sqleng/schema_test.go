@@ -0,0 +1,162 @@
+package sqleng
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+)
+
+var sqliteSchemaQueries = SchemaQueries{
+	Databases: `SELECT name FROM pragma_database_list ORDER BY name`,
+	Tables:    `SELECT name FROM sqlite_master WHERE type IN ('table', 'view') AND ?1 IN ('', 'main') ORDER BY name`,
+	Columns: `SELECT name, type, CASE WHEN "notnull" THEN 'NO' ELSE 'YES' END FROM pragma_table_info(?2)
+		WHERE ?1 IN ('', 'main') ORDER BY cid`,
+	Indexes: `SELECT il.name, il."unique", il.origin = 'pk', ii.name FROM pragma_index_list(?2) il, pragma_index_info(il.name) ii
+		WHERE ?1 IN ('', 'main') ORDER BY il.name, ii.seqno`,
+}
+
+type schemaSender struct {
+	resp *backend.CallResourceResponse
+}
+
+func (s *schemaSender) Send(resp *backend.CallResourceResponse) error {
+	s.resp = resp
+	return nil
+}
+
+func newSchemaTestHandler(t *testing.T, rowLimit int64) (*DataSourceHandler, *sql.DB) {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+
+	_, err = db.Exec(`CREATE TABLE metric (id INTEGER PRIMARY KEY, name TEXT NOT NULL, host TEXT, value REAL);
+		CREATE UNIQUE INDEX metric_name_host ON metric (name, host);
+		CREATE TABLE event (time INTEGER, text TEXT);
+		CREATE VIEW metric_names AS SELECT DISTINCT name FROM metric;`)
+	require.NoError(t, err)
+
+	config := DataPluginConfiguration{RowLimit: rowLimit, SchemaQueries: sqliteSchemaQueries}
+	handler, err := NewQueryDataHandler("", db, config, &testQueryResultTransformer{}, nil, log.New())
+	require.NoError(t, err)
+	return handler, db
+}
+
+func callSchemaResource(t *testing.T, handler *DataSourceHandler, path, query string) *backend.CallResourceResponse {
+	t.Helper()
+	sender := &schemaSender{}
+	req := &backend.CallResourceRequest{Path: path, Method: http.MethodGet, URL: path}
+	if query != "" {
+		req.URL += "?" + query
+	}
+	require.NoError(t, handler.CallResource(context.Background(), req, sender))
+	require.NotNil(t, sender.resp)
+	return sender.resp
+}
+
+func TestSchemaResources(t *testing.T) {
+	t.Run("should list databases", func(t *testing.T) {
+		handler, _ := newSchemaTestHandler(t, 0)
+		resp := callSchemaResource(t, handler, "databases", "")
+		require.Equal(t, http.StatusOK, resp.Status)
+		require.JSONEq(t, `["main"]`, string(resp.Body))
+	})
+
+	t.Run("should list tables and views of the default schema", func(t *testing.T) {
+		handler, _ := newSchemaTestHandler(t, 0)
+		resp := callSchemaResource(t, handler, "tables", "")
+		require.Equal(t, http.StatusOK, resp.Status)
+		require.JSONEq(t, `["event", "metric", "metric_names"]`, string(resp.Body))
+
+		resp = callSchemaResource(t, handler, "tables", "schema=other")
+		require.Equal(t, http.StatusOK, resp.Status)
+		require.JSONEq(t, `[]`, string(resp.Body))
+	})
+
+	t.Run("should list columns with their types", func(t *testing.T) {
+		handler, _ := newSchemaTestHandler(t, 0)
+		resp := callSchemaResource(t, handler, "columns", "schema=main&table=metric")
+		require.Equal(t, http.StatusOK, resp.Status)
+		require.JSONEq(t, `[
+			{"name": "id", "type": "INTEGER", "nullable": true},
+			{"name": "name", "type": "TEXT", "nullable": false},
+			{"name": "host", "type": "TEXT", "nullable": true},
+			{"name": "value", "type": "REAL", "nullable": true}
+		]`, string(resp.Body))
+	})
+
+	t.Run("should list indexes with their columns", func(t *testing.T) {
+		handler, _ := newSchemaTestHandler(t, 0)
+		indexes, err := handler.Indexes(context.Background(), "", "metric")
+		require.NoError(t, err)
+		require.Equal(t, []Index{{Name: "metric_name_host", Columns: []string{"name", "host"}, Unique: true}}, indexes)
+	})
+
+	t.Run("should require a table for columns and indexes", func(t *testing.T) {
+		handler, _ := newSchemaTestHandler(t, 0)
+		for _, path := range []string{"columns", "indexes"} {
+			resp := callSchemaResource(t, handler, path, "schema=main")
+			require.Equal(t, http.StatusBadRequest, resp.Status)
+		}
+	})
+
+	t.Run("should return not implemented for objects the engine does not list", func(t *testing.T) {
+		handler, _ := newSchemaTestHandler(t, 0)
+		resp := callSchemaResource(t, handler, "schemas", "")
+		require.Equal(t, http.StatusNotImplemented, resp.Status)
+
+		var body map[string]string
+		require.NoError(t, json.Unmarshal(resp.Body, &body))
+		require.Equal(t, "schemas are not supported by the data source", body["error"])
+	})
+
+	t.Run("should return not found for unknown paths", func(t *testing.T) {
+		handler, _ := newSchemaTestHandler(t, 0)
+		resp := callSchemaResource(t, handler, "functions", "")
+		require.Equal(t, http.StatusNotFound, resp.Status)
+	})
+
+	t.Run("should limit the number of objects", func(t *testing.T) {
+		handler, _ := newSchemaTestHandler(t, 0)
+		resp := callSchemaResource(t, handler, "tables", "limit=1")
+		require.Equal(t, http.StatusOK, resp.Status)
+		require.JSONEq(t, `["event"]`, string(resp.Body))
+
+		resp = callSchemaResource(t, handler, "tables", "limit=-1")
+		require.Equal(t, http.StatusBadRequest, resp.Status)
+	})
+
+	t.Run("should not read more rows than the row limit of the data source", func(t *testing.T) {
+		handler, _ := newSchemaTestHandler(t, 2)
+		tables, err := handler.Tables(context.Background(), "")
+		require.NoError(t, err)
+		require.Equal(t, []string{"event", "metric"}, tables)
+	})
+
+	t.Run("should cache the schema", func(t *testing.T) {
+		handler, db := newSchemaTestHandler(t, 0)
+		tables, err := handler.Tables(context.Background(), "")
+		require.NoError(t, err)
+		require.Len(t, tables, 3)
+
+		_, err = db.Exec(`CREATE TABLE annotation (time INTEGER)`)
+		require.NoError(t, err)
+
+		tables, err = handler.Tables(context.Background(), "")
+		require.NoError(t, err)
+		require.Len(t, tables, 3)
+
+		// Other keys are not affected by the cached entry.
+		tables, err = handler.Tables(context.Background(), "main")
+		require.NoError(t, err)
+		require.Len(t, tables, 4)
+	})
+}
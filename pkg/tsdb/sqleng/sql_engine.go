@@ -38,6 +38,12 @@ type SqlQueryResultTransformer interface {
 	GetConverterList() []sqlutil.StringConverter
 }
 
+// SqlQueryResultConverters is implemented by the result transformers that provide the converters of the rows themselves
+// instead of string converters, for example dynamic converters for engines whose columns have no fixed type.
+type SqlQueryResultConverters interface {
+	GetConverters() []sqlutil.Converter
+}
+
 type JsonData struct {
 	MaxOpenConns            int    `json:"maxOpenConns"`
 	MaxIdleConns            int    `json:"maxIdleConns"`
@@ -59,6 +65,8 @@ type JsonData struct {
 	SecureDSProxyUsername   string `json:"secureSocksProxyUsername"`
 	AllowCleartextPasswords bool   `json:"allowCleartextPasswords"`
 	AuthenticationType      string `json:"authenticationType"`
+	// ParameterizedVariables requires the template variables of the queries to be bound as driver parameters.
+	ParameterizedVariables bool `json:"parameterizedVariables"`
 }
 
 type DataSourceInfo struct {
@@ -77,6 +85,7 @@ type DataPluginConfiguration struct {
 	TimeColumnNames   []string
 	MetricColumnTypes []string
 	RowLimit          int64
+	SchemaQueries     SchemaQueries
 }
 
 type DataSourceHandler struct {
@@ -89,6 +98,8 @@ type DataSourceHandler struct {
 	dsInfo                 DataSourceInfo
 	rowLimit               int64
 	userError              string
+	schemaQueries          SchemaQueries
+	schemaCache            schemaCache
 }
 
 type QueryJson struct {
@@ -98,6 +109,8 @@ type QueryJson struct {
 	FillMode     string  `json:"fillMode"`
 	FillValue    float64 `json:"fillValue"`
 	Format       string  `json:"format"`
+	// Variables are bound as driver parameters instead of being interpolated into RawSql.
+	Variables []QueryVariable `json:"variables"`
 }
 
 func (e *DataSourceHandler) TransformQueryError(logger log.Logger, err error) error {
@@ -124,6 +137,8 @@ func NewQueryDataHandler(userFacingDefaultError string, db *sql.DB, config DataP
 		dsInfo:                 config.DSInfo,
 		rowLimit:               config.RowLimit,
 		userError:              userFacingDefaultError,
+		schemaQueries:          config.SchemaQueries,
+		schemaCache:            schemaCache{entries: map[string]schemaCacheEntry{}},
 	}
 
 	if len(config.TimeColumnNames) > 0 {
@@ -244,8 +259,25 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 		ch <- queryResult
 	}
 
+	// template variables bound as driver parameters
+	rawSQL := queryJson.RawSql
+	var args []any
+	if requireBound := e.dsInfo.JsonData.ParameterizedVariables; len(queryJson.Variables) > 0 || requireBound {
+		binder, ok := e.macroEngine.(SQLVariableBinder)
+		if !ok {
+			errAppendDebug("binding variables failed", errors.New("parameterized variables are not supported by the data source"), rawSQL)
+			return
+		}
+		var err error
+		rawSQL, args, err = binder.BindVariables(rawSQL, queryJson.Variables, requireBound)
+		if err != nil {
+			errAppendDebug("binding variables failed", err, queryJson.RawSql)
+			return
+		}
+	}
+
 	// global substitutions
-	interpolatedQuery := Interpolate(query, timeRange, e.dsInfo.JsonData.TimeInterval, queryJson.RawSql)
+	interpolatedQuery := Interpolate(query, timeRange, e.dsInfo.JsonData.TimeInterval, rawSQL)
 
 	// data source specific substitutions
 	interpolatedQuery, err := e.macroEngine.Interpolate(&query, timeRange, interpolatedQuery)
@@ -254,7 +286,7 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 		return
 	}
 
-	rows, err := e.db.QueryContext(queryContext, interpolatedQuery)
+	rows, err := e.db.QueryContext(queryContext, interpolatedQuery, args...)
 	if err != nil {
 		errAppendDebug("db query error", e.TransformQueryError(logger, err), interpolatedQuery)
 		return
@@ -272,8 +304,13 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 	}
 
 	// Convert row.Rows to dataframe
-	stringConverters := e.queryResultTransformer.GetConverterList()
-	frame, err := sqlutil.FrameFromRows(rows, e.rowLimit, sqlutil.ToConverters(stringConverters...)...)
+	var converters []sqlutil.Converter
+	if t, ok := e.queryResultTransformer.(SqlQueryResultConverters); ok {
+		converters = t.GetConverters()
+	} else {
+		converters = sqlutil.ToConverters(e.queryResultTransformer.GetConverterList()...)
+	}
+	frame, err := sqlutil.FrameFromRows(rows, e.rowLimit, converters...)
 	if err != nil {
 		errAppendDebug("convert frame from rows error", err, interpolatedQuery)
 		return
@@ -0,0 +1,225 @@
+package sqleng
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Types of the values of a QueryVariable.
+const (
+	VariableTypeString  = "string"
+	VariableTypeNumber  = "number"
+	VariableTypeBoolean = "boolean"
+	VariableTypeTime    = "time"
+)
+
+// QueryVariable is a template variable of a query that is bound as a driver parameter instead of being
+// interpolated into the SQL text.
+type QueryVariable struct {
+	Name string `json:"name"`
+	// Type is the type of the values, string if empty.
+	Type string `json:"type"`
+	// Values are the selected values of the variable. A variable with several values expands to a list of
+	// parameters, to be used in IN lists.
+	Values []string `json:"values"`
+}
+
+// SQLVariableBinder is implemented by the macro engines of the data sources that support binding template
+// variables as driver parameters.
+type SQLVariableBinder interface {
+	// BindVariables replaces the references to the variables in sql with placeholders, and returns the rewritten
+	// sql with the parameters to execute it with. If requireBound is set, it fails if sql references other variables.
+	BindVariables(sql string, variables []QueryVariable, requireBound bool) (string, []any, error)
+}
+
+// ParameterSyntax is the syntax of the driver parameters and of the string literals of a data source.
+type ParameterSyntax struct {
+	// Placeholder returns the placeholder of the parameter at the position, starting from 1.
+	Placeholder func(position int) string
+	// BackslashEscapes is whether a backslash escapes the next character of a quoted string, as in MySQL.
+	BackslashEscapes bool
+	// HashComments is whether # starts a comment, as in MySQL.
+	HashComments bool
+}
+
+// variableRefExpr matches a reference to a variable, $name or ${name}, at the start of the text.
+var variableRefExpr = regexp.MustCompile(`^(?:\$\{(\w+)\}|\$(\w+))`)
+
+// variableLiteralExpr matches a string literal that only contains a reference to a variable, since the quotes that
+// string interpolation needs must not end up around a placeholder.
+var variableLiteralExpr = regexp.MustCompile(`^'(?:\$\{(\w+)\}|\$(\w+))'$`)
+
+// ReplaceVariablesWithParameters replaces the references to the variables in sql with the placeholders of the
+// parameters. References to macros and to unknown variables are left as they are, and so are the references in
+// comments, in quoted identifiers and in string literals, except for a string literal that only contains a reference.
+// If requireBound is set, references to unknown variables are rejected, since they would otherwise have to be
+// interpolated into the SQL text.
+func (m *SQLMacroEngineBase) ReplaceVariablesWithParameters(sql string, variables []QueryVariable, requireBound bool, syntax ParameterSyntax) (string, []any, error) {
+	values := make(map[string][]any, len(variables))
+	for _, v := range variables {
+		params, err := variableParameters(v)
+		if err != nil {
+			return "", nil, err
+		}
+		values[v.Name] = params
+	}
+
+	var args []any
+	var unbound []string
+	sql = replaceVariableReferences(sql, syntax, func(name string) (string, bool) {
+		if strings.HasPrefix(name, "__") {
+			return "", false
+		}
+		params, ok := values[name]
+		if !ok {
+			// the placeholders of the parameters of PostgreSQL, $1, are not variables
+			if strings.Trim(name, "0123456789") != "" {
+				unbound = append(unbound, name)
+			}
+			return "", false
+		}
+
+		placeholders := make([]string, 0, len(params))
+		for _, p := range params {
+			args = append(args, p)
+			placeholders = append(placeholders, syntax.Placeholder(len(args)))
+		}
+		return strings.Join(placeholders, ","), true
+	})
+	if requireBound && len(unbound) > 0 {
+		return "", nil, fmt.Errorf("variable %s is not bound as a query parameter, and the data source does not allow interpolating variables", unbound[0])
+	}
+
+	return sql, args, nil
+}
+
+// replaceVariableReferences scans sql and replaces each reference to a variable outside of comments, quoted
+// identifiers and string literals, and each string literal that only contains a reference, with the result of
+// replace for the name of the variable, unless replace returns false.
+func replaceVariableReferences(sql string, syntax ParameterSyntax, replace func(name string) (string, bool)) string {
+	var b strings.Builder
+	for i := 0; i < len(sql); {
+		var end int
+		switch {
+		case strings.HasPrefix(sql[i:], "--"):
+			end = indexFrom(sql, i+2, "\n", 0)
+		case syntax.HashComments && sql[i] == '#':
+			end = indexFrom(sql, i+1, "\n", 0)
+		case strings.HasPrefix(sql[i:], "/*"):
+			end = indexFrom(sql, i+2, "*/", len("*/"))
+		case sql[i] == '`':
+			end = quotedEnd(sql, i, false)
+		case sql[i] == '"':
+			end = quotedEnd(sql, i, syntax.BackslashEscapes)
+		case sql[i] == '\'':
+			end = quotedEnd(sql, i, syntax.BackslashEscapes)
+			if name, ok := variableName(variableLiteralExpr.FindStringSubmatch(sql[i:end])); ok {
+				if r, ok := replace(name); ok {
+					b.WriteString(r)
+					i = end
+					continue
+				}
+			}
+		case sql[i] == '$':
+			match := variableRefExpr.FindStringSubmatch(sql[i:])
+			if name, ok := variableName(match); ok {
+				end = i + len(match[0])
+				if r, ok := replace(name); ok {
+					b.WriteString(r)
+					i = end
+					continue
+				}
+			} else {
+				end = i + 1
+			}
+		default:
+			end = i + 1
+		}
+		b.WriteString(sql[i:end])
+		i = end
+	}
+	return b.String()
+}
+
+// variableName returns the name of the variable of a match of variableRefExpr or variableLiteralExpr.
+func variableName(match []string) (string, bool) {
+	if match == nil {
+		return "", false
+	}
+	if match[1] != "" {
+		return match[1], true
+	}
+	return match[2], true
+}
+
+// indexFrom returns the index after the first occurrence of substr in s from the index from, plus skip, or the length
+// of s if there is none.
+func indexFrom(s string, from int, substr string, skip int) int {
+	if i := strings.Index(s[from:], substr); i >= 0 {
+		return from + i + skip
+	}
+	return len(s)
+}
+
+// quotedEnd returns the index after the quoted string or identifier that starts at the index start. The quote is
+// escaped by doubling it, or with a backslash if backslashEscapes is set.
+func quotedEnd(s string, start int, backslashEscapes bool) int {
+	quote := s[start]
+	for i := start + 1; i < len(s); i++ {
+		switch {
+		case backslashEscapes && s[i] == '\\':
+			i++
+		case s[i] == quote:
+			if i+1 < len(s) && s[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+// variableParameters converts the values of a variable to its type. A variable without values is bound to NULL, so
+// that an IN list of it matches nothing.
+func variableParameters(v QueryVariable) ([]any, error) {
+	if len(v.Values) == 0 {
+		return []any{nil}, nil
+	}
+
+	params := make([]any, 0, len(v.Values))
+	for _, value := range v.Values {
+		var param any
+		var err error
+		switch v.Type {
+		case "", VariableTypeString:
+			param = value
+		case VariableTypeNumber:
+			if param, err = strconv.ParseInt(value, 10, 64); err != nil {
+				param, err = strconv.ParseFloat(value, 64)
+			}
+		case VariableTypeBoolean:
+			param, err = strconv.ParseBool(value)
+		case VariableTypeTime:
+			param, err = parseVariableTime(value)
+		default:
+			return nil, fmt.Errorf("variable %s has an unsupported type %q", v.Name, v.Type)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("variable %s has an invalid %s value %q", v.Name, v.Type, value)
+		}
+		params = append(params, param)
+	}
+	return params, nil
+}
+
+// parseVariableTime parses a time in RFC 3339 format or in milliseconds since the epoch.
+func parseVariableTime(value string) (time.Time, error) {
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.UnixMilli(ms).UTC(), nil
+	}
+	return time.Parse(time.RFC3339Nano, value)
+}
@@ -0,0 +1,164 @@
+package sqleng
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplaceVariablesWithParameters(t *testing.T) {
+	m := NewSQLMacroEngineBase()
+	dollar := ParameterSyntax{Placeholder: func(position int) string { return "$" + strconv.Itoa(position) }}
+
+	t.Run("should replace references with placeholders in order", func(t *testing.T) {
+		sql, args, err := m.ReplaceVariablesWithParameters(
+			"SELECT * FROM metric WHERE host IN (${hosts}) AND value > $min AND name = '$name' AND $__timeFilter(time)",
+			[]QueryVariable{
+				{Name: "hosts", Values: []string{"a", "b'; DROP TABLE metric; --"}},
+				{Name: "min", Type: VariableTypeNumber, Values: []string{"1.5"}},
+				{Name: "name", Values: []string{"cpu"}},
+			}, false, dollar)
+		require.NoError(t, err)
+		require.Equal(t, "SELECT * FROM metric WHERE host IN ($1,$2) AND value > $3 AND name = $4 AND $__timeFilter(time)", sql)
+		require.Equal(t, []any{"a", "b'; DROP TABLE metric; --", 1.5, "cpu"}, args)
+	})
+
+	t.Run("should bind each reference to a variable", func(t *testing.T) {
+		sql, args, err := m.ReplaceVariablesWithParameters("SELECT $id, $id, $other",
+			[]QueryVariable{{Name: "id", Type: VariableTypeNumber, Values: []string{"3"}}},
+			false, ParameterSyntax{Placeholder: func(int) string { return "?" }})
+		require.NoError(t, err)
+		require.Equal(t, "SELECT ?, ?, $other", sql)
+		require.Equal(t, []any{int64(3), int64(3)}, args)
+	})
+
+	t.Run("should bind a variable without values to null", func(t *testing.T) {
+		sql, args, err := m.ReplaceVariablesWithParameters("SELECT * FROM metric WHERE host IN ($hosts)",
+			[]QueryVariable{{Name: "hosts"}}, false, dollar)
+		require.NoError(t, err)
+		require.Equal(t, "SELECT * FROM metric WHERE host IN ($1)", sql)
+		require.Equal(t, []any{nil}, args)
+	})
+
+	t.Run("should convert values to the type of the variable", func(t *testing.T) {
+		_, args, err := m.ReplaceVariablesWithParameters("SELECT $b, $t",
+			[]QueryVariable{
+				{Name: "b", Type: VariableTypeBoolean, Values: []string{"true"}},
+				{Name: "t", Type: VariableTypeTime, Values: []string{"2024-01-02T03:04:05Z", "1704164645000"}},
+			}, false, dollar)
+		require.NoError(t, err)
+		ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		require.Equal(t, []any{true, ts, ts}, args)
+	})
+
+	t.Run("should fail for invalid values and types", func(t *testing.T) {
+		_, _, err := m.ReplaceVariablesWithParameters("SELECT $n",
+			[]QueryVariable{{Name: "n", Type: VariableTypeNumber, Values: []string{"1 OR 1=1"}}}, false, dollar)
+		require.EqualError(t, err, `variable n has an invalid number value "1 OR 1=1"`)
+
+		_, _, err = m.ReplaceVariablesWithParameters("SELECT $n",
+			[]QueryVariable{{Name: "n", Type: "raw", Values: []string{"1"}}}, false, dollar)
+		require.EqualError(t, err, `variable n has an unsupported type "raw"`)
+	})
+
+	t.Run("should not replace references in comments, identifiers and string literals", func(t *testing.T) {
+		sql, args, err := m.ReplaceVariablesWithParameters(
+			"SELECT '$name', 'host: $name', 'it''s $name', \"$name\", `$name` -- $name\nFROM metric /* $name */ WHERE name = ${name}",
+			[]QueryVariable{{Name: "name", Values: []string{"cpu"}}}, false, dollar)
+		require.NoError(t, err)
+		require.Equal(t, "SELECT $1, 'host: $name', 'it''s $name', \"$name\", `$name` -- $name\nFROM metric /* $name */ WHERE name = $2", sql)
+		require.Equal(t, []any{"cpu", "cpu"}, args)
+	})
+
+	t.Run("should reject references to unbound variables if required", func(t *testing.T) {
+		query := "SELECT * FROM metric WHERE host = $host AND value > $1 AND $__timeFilter(time) -- $other"
+		sql, _, err := m.ReplaceVariablesWithParameters(query, nil, false, dollar)
+		require.NoError(t, err)
+		require.Equal(t, query, sql)
+
+		_, _, err = m.ReplaceVariablesWithParameters(query, nil, true, dollar)
+		require.EqualError(t, err, "variable host is not bound as a query parameter, and the data source does not allow interpolating variables")
+
+		_, args, err := m.ReplaceVariablesWithParameters(query, []QueryVariable{{Name: "host", Values: []string{"a"}}}, true, dollar)
+		require.NoError(t, err)
+		require.Equal(t, []any{"a"}, args)
+	})
+}
+
+type bindingMacroEngine struct {
+	*SQLMacroEngineBase
+}
+
+func (m *bindingMacroEngine) Interpolate(_ *backend.DataQuery, _ backend.TimeRange, sql string) (string, error) {
+	return sql, nil
+}
+
+func (m *bindingMacroEngine) BindVariables(sql string, variables []QueryVariable, requireBound bool) (string, []any, error) {
+	return m.ReplaceVariablesWithParameters(sql, variables, requireBound, ParameterSyntax{Placeholder: func(int) string { return "?" }})
+}
+
+func TestQueryDataWithVariables(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+	_, err = db.Exec(`CREATE TABLE metric (name TEXT, value INTEGER);
+		INSERT INTO metric VALUES ('a', 1), ('b', 2), ('c', 3);`)
+	require.NoError(t, err)
+
+	query := func(t *testing.T, macroEngine SQLMacroEngine, jsonData JsonData, model QueryJson) backend.DataResponse {
+		t.Helper()
+		config := DataPluginConfiguration{RowLimit: 100, DSInfo: DataSourceInfo{JsonData: jsonData}}
+		handler, err := NewQueryDataHandler("", db, config, &testQueryResultTransformer{}, macroEngine, log.New())
+		require.NoError(t, err)
+		model.Format = "table"
+		raw, err := json.Marshal(model)
+		require.NoError(t, err)
+		resp, err := handler.QueryData(context.Background(), &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{{RefID: "A", JSON: raw}},
+		})
+		require.NoError(t, err)
+		return resp.Responses["A"]
+	}
+
+	t.Run("should execute the query with the variables as parameters", func(t *testing.T) {
+		resp := query(t, &bindingMacroEngine{NewSQLMacroEngineBase()}, JsonData{}, QueryJson{
+			RawSql: "SELECT name FROM metric WHERE name IN ($names) ORDER BY name",
+			Variables: []QueryVariable{
+				{Name: "names", Values: []string{"a", "c", "x' OR '1'='1"}},
+			},
+		})
+		require.NoError(t, resp.Error)
+		require.Len(t, resp.Frames, 1)
+		require.Equal(t, 2, resp.Frames[0].Rows())
+		require.Equal(t, "SELECT name FROM metric WHERE name IN (?,?,?) ORDER BY name", resp.Frames[0].Meta.ExecutedQueryString)
+	})
+
+	t.Run("should fail when the data source does not bind variables", func(t *testing.T) {
+		resp := query(t, &testMacroEngine{}, JsonData{}, QueryJson{
+			RawSql:    "SELECT name FROM metric WHERE name = $name",
+			Variables: []QueryVariable{{Name: "name", Values: []string{"a"}}},
+		})
+		require.EqualError(t, resp.Error, "binding variables failed: parameterized variables are not supported by the data source")
+	})
+
+	t.Run("should fail when the data source requires variables to be bound and a variable is not", func(t *testing.T) {
+		resp := query(t, &bindingMacroEngine{NewSQLMacroEngineBase()}, JsonData{ParameterizedVariables: true}, QueryJson{
+			RawSql: "SELECT name FROM metric WHERE name = $name",
+		})
+		require.EqualError(t, resp.Error, "binding variables failed: variable name is not bound as a query parameter, and the data source does not allow interpolating variables")
+	})
+}
+
+type testMacroEngine struct{}
+
+func (m *testMacroEngine) Interpolate(_ *backend.DataQuery, _ backend.TimeRange, sql string) (string, error) {
+	return sql, nil
+}
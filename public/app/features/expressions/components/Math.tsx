@@ -87,6 +87,30 @@ export const Math = ({ labelWidth, onChange, query, onRunQuery }: Props) => {
                       name="floor"
                       description="rounds the number down to the nearest integer value. It's able to operate on series or escalar values."
                     />
+                    <DocumentedFunction
+                      name="clamp_min, clamp_max"
+                      description="limits each value to be at least or at most the given scalar, for example clamp_min($A, 0). It's able to operate on series or scalar values."
+                    />
+                    <DocumentedFunction
+                      name="rate, delta"
+                      description="returns the per-second rate of change or the difference between each point of a series and the previous point"
+                    />
+                    <DocumentedFunction
+                      name="cumsum"
+                      description="returns the cumulative sum of the values of a series"
+                    />
+                    <DocumentedFunction
+                      name="shift"
+                      description="moves the timestamps of a series forward by a duration, for example $A - shift($A, 1w) for a week-over-week comparison"
+                    />
+                    <DocumentedFunction
+                      name="moving_avg"
+                      description="returns the average of the values of a series within a time window ending at each point, for example moving_avg($A, 1h)"
+                    />
+                    <DocumentedFunction
+                      name="hour, day_of_week"
+                      description="returns the hour of the day (0-23) or the day of the week (0-6, starting on Sunday) in UTC of the timestamp of each point of a series"
+                    />
                   </div>
                 </div>
               }
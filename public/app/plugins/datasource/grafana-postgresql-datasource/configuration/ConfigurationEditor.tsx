@@ -10,7 +10,13 @@ import {
 } from '@grafana/data';
 import { ConfigSection, ConfigSubSection, DataSourceDescription, Stack } from '@grafana/experimental';
 import { config } from '@grafana/runtime';
-import { ConnectionLimits, Divider, TLSSecretsConfig, useMigrateDatabaseFields } from '@grafana/sql';
+import {
+  ConnectionLimits,
+  Divider,
+  ParameterizedVariables,
+  TLSSecretsConfig,
+  useMigrateDatabaseFields,
+} from '@grafana/sql';
 import {
   Input,
   Select,
@@ -399,6 +405,8 @@ export const PostgresConfigEditor = (props: DataSourcePluginOptionsEditorProps<P
 
         <ConnectionLimits options={options} onOptionsChange={onOptionsChange} />
 
+        <ParameterizedVariables options={options} onOptionsChange={onOptionsChange} />
+
         {config.secureSocksDSProxyEnabled && (
           <SecureSocksProxySettings options={options} onOptionsChange={onOptionsChange} />
         )}
@@ -0,0 +1,51 @@
+import React from 'react';
+
+import { DataSourcePluginOptionsEditorProps, onUpdateDatasourceJsonDataOption } from '@grafana/data';
+import { ConfigSection, DataSourceDescription } from '@grafana/experimental';
+import { ConnectionLimits, Divider, ParameterizedVariables } from '@grafana/sql';
+import { Field, Input } from '@grafana/ui';
+
+import { SQLiteOptions } from '../types';
+
+export const ConfigurationEditor = (props: DataSourcePluginOptionsEditorProps<SQLiteOptions>) => {
+  const { options, onOptionsChange } = props;
+  const jsonData = options.jsonData;
+
+  const WIDTH_LONG = 40;
+
+  return (
+    <>
+      <DataSourceDescription
+        dataSourceName="SQLite"
+        docsLink="https://grafana.com/docs/grafana/latest/datasources/sqlite/"
+        hasRequiredFields={true}
+      />
+
+      <Divider />
+
+      <ConfigSection title="Connection">
+        <Field
+          label="Database file"
+          description="Path of the database file, relative to the directory set by sqlite_files_path in the Grafana configuration. The file is opened read-only."
+          required
+        >
+          <Input
+            width={WIDTH_LONG}
+            name="database"
+            value={jsonData.database || ''}
+            placeholder="metrics.db"
+            onChange={onUpdateDatasourceJsonDataOption(props, 'database')}
+          />
+        </Field>
+      </ConfigSection>
+
+      <Divider />
+
+      <ConfigSection title="Additional settings" isCollapsible>
+        <ConnectionLimits options={options} onOptionsChange={onOptionsChange} />
+
+        <ParameterizedVariables options={options} onOptionsChange={onOptionsChange} />
+      </ConfigSection>
+    </>
+  );
+};
@@ -0,0 +1,56 @@
+import { DataSourceInstanceSettings } from '@grafana/data';
+import { LanguageDefinition } from '@grafana/experimental';
+import { SqlDatasource, DB, SQLQuery, SQLSelectableValue, formatSQL } from '@grafana/sql';
+
+import { getFieldConfig, quoteLiteral, toRawSql } from './sqlUtil';
+import { SQLiteColumn, SQLiteOptions } from './types';
+
+export class SQLiteDatasource extends SqlDatasource {
+  constructor(instanceSettings: DataSourceInstanceSettings<SQLiteOptions>) {
+    super(instanceSettings);
+  }
+
+  getQueryModel() {
+    return { quoteLiteral };
+  }
+
+  getSqlLanguageDefinition(): LanguageDefinition {
+    return { id: 'sql', formatter: formatSQL };
+  }
+
+  // The schema is served by the schema resources of the backend.
+  async fetchTables(): Promise<string[]> {
+    return this.getResource<string[]>('tables');
+  }
+
+  async fetchFields(query: SQLQuery): Promise<SQLSelectableValue[]> {
+    if (!query.table) {
+      return [];
+    }
+    const columns = await this.getResource<SQLiteColumn[]>('columns', { table: query.table });
+    return columns.map(({ name, type }) => ({ label: name, value: name, type, ...getFieldConfig(type) }));
+  }
+
+  getDB(): DB {
+    if (this.db !== undefined) {
+      return this.db;
+    }
+
+    return {
+      init: () => Promise.resolve(true),
+      datasets: () => Promise.resolve([]),
+      tables: () => this.fetchTables(),
+      fields: (query: SQLQuery) => this.fetchFields(query),
+      validateQuery: (query) =>
+        Promise.resolve({ isError: false, isValid: true, query, error: '', rawSql: query.rawSql }),
+      dsID: () => this.id,
+      toRawSql,
+      functions: () => ['AVG', 'COUNT', 'MAX', 'MIN', 'SUM', 'TOTAL'],
+      getEditorLanguageDefinition: () => this.getSqlLanguageDefinition(),
+      lookup: async () => {
+        const tables = await this.fetchTables();
+        return tables.map((t) => ({ name: t, completion: t }));
+      },
+    };
+  }
+}
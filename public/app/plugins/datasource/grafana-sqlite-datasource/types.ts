@@ -0,0 +1,11 @@
+import { SQLOptions, SQLQuery } from '@grafana/sql';
+
+export interface SQLiteOptions extends SQLOptions {}
+
+export interface SQLiteQuery extends SQLQuery {}
+
+export interface SQLiteColumn {
+  name: string;
+  type: string;
+  nullable: boolean;
+}
@@ -11,7 +11,7 @@ import {
   updateDatasourcePluginResetOption,
 } from '@grafana/data';
 import { ConfigSection, ConfigSubSection, DataSourceDescription } from '@grafana/experimental';
-import { ConnectionLimits, useMigrateDatabaseFields } from '@grafana/sql';
+import { ConnectionLimits, ParameterizedVariables, useMigrateDatabaseFields } from '@grafana/sql';
 import {
   Alert,
   FieldSet,
@@ -297,6 +297,8 @@ export const ConfigurationEditor = (props: DataSourcePluginOptionsEditorProps<Ms
       >
         <ConnectionLimits options={dsSettings} onOptionsChange={onOptionsChange} />
 
+        <ParameterizedVariables options={dsSettings} onOptionsChange={onOptionsChange} />
+
         <ConfigSubSection title="Connection details">
           <Field
             description={
@@ -9,7 +9,13 @@ import {
 } from '@grafana/data';
 import { ConfigSection, ConfigSubSection, DataSourceDescription, Stack } from '@grafana/experimental';
 import { config } from '@grafana/runtime';
-import { ConnectionLimits, Divider, TLSSecretsConfig, useMigrateDatabaseFields } from '@grafana/sql';
+import {
+  ConnectionLimits,
+  Divider,
+  ParameterizedVariables,
+  TLSSecretsConfig,
+  useMigrateDatabaseFields,
+} from '@grafana/sql';
 import {
   Collapse,
   Field,
@@ -229,6 +235,8 @@ export const ConfigurationEditor = (props: DataSourcePluginOptionsEditorProps<My
 
         <ConnectionLimits options={options} onOptionsChange={onOptionsChange} />
 
+        <ParameterizedVariables options={options} onOptionsChange={onOptionsChange} />
+
         {config.secureSocksDSProxyEnabled && (
           <SecureSocksProxySettings options={options} onOptionsChange={onOptionsChange} />
         )}
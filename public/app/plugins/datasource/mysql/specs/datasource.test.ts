@@ -478,6 +478,31 @@ describe('MySQLDatasource', () => {
     });
   });
 
+  describe('When the variables are bound as query parameters', () => {
+    it('should send the values of the variables instead of interpolating them', () => {
+      const instanceSettings = {
+        jsonData: { parameterizedVariables: true },
+      } as unknown as DataSourceInstanceSettings<MySQLOptions>;
+      const values: Record<string, string[] | number> = { hosts: ['a', "b'; DROP TABLE metric; --"], min: 10 };
+      const templateSrv = {
+        containsTemplate: (text: string) => text.slice(1) in values,
+        replace: (text: string, _: unknown, format: (value: unknown) => string) => format(values[text.slice(1)]),
+      };
+      const ds = new MySqlDatasource(instanceSettings);
+      Reflect.set(ds, 'templateSrv', templateSrv);
+
+      const rawSql =
+        'SELECT * FROM metric WHERE $__timeFilter(time) AND host IN ($hosts) AND value > $min AND $unknown';
+      const query = ds.applyTemplateVariables({ refId: 'A', rawSql }, {});
+
+      expect(query.rawSql).toBe(rawSql);
+      expect(query.variables).toEqual([
+        { name: 'hosts', type: 'string', values: ['a', "b'; DROP TABLE metric; --"] },
+        { name: 'min', type: 'number', values: ['10'] },
+      ]);
+    });
+  });
+
   describe('targetContainsTemplate', () => {
     it('given query that contains template variable it should return true', () => {
       const rawSql = `SELECT